@@ -17,6 +17,7 @@ import (
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/oci"
 	"github.com/canonical/lxd/shared/simplestreams"
 )
 
@@ -58,6 +59,9 @@ type ConnectionArgs struct {
 	// OpenID Connect tokens
 	OIDCTokens *oidc.Tokens[*oidc.IDTokenClaims]
 
+	// LXD issued bearer token (used when AuthType is api.AuthenticationMethodBearer)
+	BearerToken string
+
 	// Skip automatic GetServer request upon connection
 	SkipGetServer bool
 
@@ -305,6 +309,45 @@ func ConnectSimpleStreams(url string, args *ConnectionArgs) (ImageServer, error)
 	return &server, nil
 }
 
+// ConnectOCI lets you connect to an OCI image store.
+//
+// The URL is either a "file://" path to an OCI image layout directory or oci-archive tarball, or the HTTPs
+// (or HTTP) address of a registry implementing the OCI distribution API.
+func ConnectOCI(url string, args *ConnectionArgs) (ImageServer, error) {
+	logger.Debug("Connecting to an OCI image store", logger.Ctx{"URL": url})
+
+	// Cleanup URL
+	url = strings.TrimSuffix(url, "/")
+
+	// Use empty args if not specified
+	if args == nil {
+		args = &ConnectionArgs{}
+	}
+
+	// Initialize the client struct
+	server := ProtocolOCI{
+		httpHost:        url,
+		httpUserAgent:   args.UserAgent,
+		httpCertificate: args.TLSServerCert,
+	}
+
+	// Setup the HTTP client
+	httpClient, err := tlsHTTPClient(args.HTTPClient, args.TLSClientCert, args.TLSClientKey, args.TLSCA, args.TLSServerCert, args.InsecureSkipVerify, args.Proxy, args.TransportWrapper)
+	if err != nil {
+		return nil, err
+	}
+
+	server.http = httpClient
+
+	// Get OCI client
+	server.ociClient, err = oci.NewClient(url, httpClient, args.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &server, nil
+}
+
 // Internal function called by ConnectLXD and ConnectPublicLXD.
 func httpsLXD(ctx context.Context, requestURL string, args *ConnectionArgs) (InstanceServer, error) {
 	// Use empty args if not specified
@@ -332,7 +375,7 @@ func httpsLXD(ctx context.Context, requestURL string, args *ConnectionArgs) (Ins
 		eventListeners:     make(map[string][]*EventListener),
 	}
 
-	if shared.ValueInSlice(args.AuthType, []string{api.AuthenticationMethodOIDC}) {
+	if shared.ValueInSlice(args.AuthType, []string{api.AuthenticationMethodOIDC, api.AuthenticationMethodBearer}) {
 		server.RequireAuthenticated(true)
 	}
 
@@ -349,6 +392,8 @@ func httpsLXD(ctx context.Context, requestURL string, args *ConnectionArgs) (Ins
 	server.http = httpClient
 	if args.AuthType == api.AuthenticationMethodOIDC {
 		server.setupOIDCClient(args.OIDCTokens)
+	} else if args.AuthType == api.AuthenticationMethodBearer {
+		server.bearerToken = args.BearerToken
 	}
 
 	// Test the connection and seed the server information
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/sftp"
@@ -173,6 +174,7 @@ type InstanceServer interface {
 	MigrateInstance(name string, instance api.InstancePost) (op Operation, err error)
 	DeleteInstance(name string) (op Operation, err error)
 	UpdateInstances(state api.InstancesPut, ETag string) (op Operation, err error)
+	ApplyBulkAction(req api.BulkPost) (op Operation, err error)
 	RebuildInstance(instanceName string, req api.InstanceRebuildPost) (op Operation, err error)
 	RebuildInstanceFromImage(source ImageServer, image api.Image, instanceName string, req api.InstanceRebuildPost) (op RemoteOperation, err error)
 	GetInstanceUEFIVars(name string) (instanceUEFI *api.InstanceUEFIVars, ETag string, err error)
@@ -194,6 +196,7 @@ type InstanceServer interface {
 
 	GetInstanceSnapshotNames(instanceName string) (names []string, err error)
 	GetInstanceSnapshots(instanceName string) (snapshots []api.InstanceSnapshot, err error)
+	GetInstanceSnapshotsWithArgs(instanceName string, args *ListArgs) (snapshots []api.InstanceSnapshot, err error)
 	GetInstanceSnapshot(instanceName string, name string) (snapshot *api.InstanceSnapshot, ETag string, err error)
 	CreateInstanceSnapshot(instanceName string, snapshot api.InstanceSnapshotsPost) (op Operation, err error)
 	CopyInstanceSnapshot(source InstanceServer, instanceName string, snapshot api.InstanceSnapshot, args *InstanceSnapshotCopyArgs) (op RemoteOperation, err error)
@@ -204,6 +207,7 @@ type InstanceServer interface {
 
 	GetInstanceBackupNames(instanceName string) (names []string, err error)
 	GetInstanceBackups(instanceName string) (backups []api.InstanceBackup, err error)
+	GetInstanceBackupsWithArgs(instanceName string, args *ListArgs) (backups []api.InstanceBackup, err error)
 	GetInstanceBackup(instanceName string, name string) (backup *api.InstanceBackup, ETag string, err error)
 	CreateInstanceBackup(instanceName string, backup api.InstanceBackupsPost) (op Operation, err error)
 	RenameInstanceBackup(instanceName string, name string, backup api.InstanceBackupPost) (op Operation, err error)
@@ -246,6 +250,7 @@ type InstanceServer interface {
 	// Network functions ("network" API extension)
 	GetNetworkNames() (names []string, err error)
 	GetNetworks() (networks []api.Network, err error)
+	GetNetworksWithArgs(args *ListArgs) (networks []api.Network, err error)
 	GetNetwork(name string) (network *api.Network, ETag string, err error)
 	GetNetworkLeases(name string) (leases []api.NetworkLease, err error)
 	GetNetworkState(name string) (state *api.NetworkState, err error)
@@ -309,6 +314,8 @@ type InstanceServer interface {
 	// Operation functions
 	GetOperationUUIDs() (uuids []string, err error)
 	GetOperations() (operations []api.Operation, err error)
+	GetOperationsWithArgs(args *ListArgs) (operations []api.Operation, err error)
+	GetOperationsAllProjectsWithArgs(args *ListArgs) (operations []api.Operation, err error)
 	GetOperationsAllProjects() (operations []api.Operation, err error)
 	GetOperation(uuid string) (op *api.Operation, ETag string, err error)
 	GetOperationWait(uuid string, timeout int) (op *api.Operation, ETag string, err error)
@@ -319,6 +326,7 @@ type InstanceServer interface {
 	// Profile functions
 	GetProfileNames() (names []string, err error)
 	GetProfiles() (profiles []api.Profile, err error)
+	GetProfilesWithArgs(args *ListArgs) (profiles []api.Profile, err error)
 	GetProfile(name string) (profile *api.Profile, ETag string, err error)
 	CreateProfile(profile api.ProfilesPost) (err error)
 	UpdateProfile(name string, profile api.ProfilePut, ETag string) (err error)
@@ -328,8 +336,10 @@ type InstanceServer interface {
 	// Project functions
 	GetProjectNames() (names []string, err error)
 	GetProjects() (projects []api.Project, err error)
+	GetProjectsWithArgs(args *ListArgs) (projects []api.Project, err error)
 	GetProject(name string) (project *api.Project, ETag string, err error)
 	GetProjectState(name string) (project *api.ProjectState, err error)
+	GetProjectUsage(name string, from time.Time, to time.Time) (usage *api.ProjectUsage, err error)
 	CreateProject(project api.ProjectsPost) (err error)
 	UpdateProject(name string, project api.ProjectPut, ETag string) (err error)
 	RenameProject(name string, project api.ProjectPost) (op Operation, err error)
@@ -338,6 +348,7 @@ type InstanceServer interface {
 	// Storage pool functions ("storage" API extension)
 	GetStoragePoolNames() (names []string, err error)
 	GetStoragePools() (pools []api.StoragePool, err error)
+	GetStoragePoolsWithArgs(args *ListArgs) (pools []api.StoragePool, err error)
 	GetStoragePool(name string) (pool *api.StoragePool, ETag string, err error)
 	GetStoragePoolResources(name string) (resources *api.ResourcesStoragePool, err error)
 	CreateStoragePool(pool api.StoragePoolsPost) (err error)
@@ -347,6 +358,7 @@ type InstanceServer interface {
 	// Storage bucket functions ("storage_buckets" API extension)
 	GetStoragePoolBucketNames(poolName string) ([]string, error)
 	GetStoragePoolBuckets(poolName string) ([]api.StorageBucket, error)
+	GetStoragePoolBucketsWithArgs(poolName string, args *ListArgs) ([]api.StorageBucket, error)
 	GetStoragePoolBucket(poolName string, bucketName string) (bucket *api.StorageBucket, ETag string, err error)
 	CreateStoragePoolBucket(poolName string, bucket api.StorageBucketsPost) (*api.StorageBucketKey, error)
 	UpdateStoragePoolBucket(poolName string, bucketName string, bucket api.StorageBucketPut, ETag string) (err error)
@@ -384,6 +396,7 @@ type InstanceServer interface {
 	DeleteStoragePoolVolumeSnapshot(pool string, volumeType string, volumeName string, snapshotName string) (op Operation, err error)
 	GetStoragePoolVolumeSnapshotNames(pool string, volumeType string, volumeName string) (names []string, err error)
 	GetStoragePoolVolumeSnapshots(pool string, volumeType string, volumeName string) (snapshots []api.StorageVolumeSnapshot, err error)
+	GetStoragePoolVolumeSnapshotsWithArgs(pool string, volumeType string, volumeName string, args *ListArgs) (snapshots []api.StorageVolumeSnapshot, err error)
 	GetStoragePoolVolumeSnapshot(pool string, volumeType string, volumeName string, snapshotName string) (snapshot *api.StorageVolumeSnapshot, ETag string, err error)
 	RenameStoragePoolVolumeSnapshot(pool string, volumeType string, volumeName string, snapshotName string, snapshot api.StorageVolumeSnapshotPost) (op Operation, err error)
 	UpdateStoragePoolVolumeSnapshot(pool string, volumeType string, volumeName string, snapshotName string, volume api.StorageVolumeSnapshotPut, ETag string) (err error)
@@ -391,6 +404,7 @@ type InstanceServer interface {
 	// Storage volume backup functions ("custom_volume_backup" API extension)
 	GetStoragePoolVolumeBackupNames(pool string, volName string) (names []string, err error)
 	GetStoragePoolVolumeBackups(pool string, volName string) (backups []api.StoragePoolVolumeBackup, err error)
+	GetStoragePoolVolumeBackupsWithArgs(pool string, volName string, args *ListArgs) (backups []api.StoragePoolVolumeBackup, err error)
 	GetStoragePoolVolumeBackup(pool string, volName string, name string) (backup *api.StoragePoolVolumeBackup, ETag string, err error)
 	CreateStoragePoolVolumeBackup(pool string, volName string, backup api.StoragePoolVolumeBackupsPost) (op Operation, err error)
 	RenameStoragePoolVolumeBackup(pool string, volName string, name string, backup api.StoragePoolVolumeBackupPost) (op Operation, err error)
@@ -414,6 +428,10 @@ type InstanceServer interface {
 	UpdateClusterCertificate(certs api.ClusterCertificatePut, ETag string) (err error)
 	GetClusterMemberState(name string) (*api.ClusterMemberState, string, error)
 	UpdateClusterMemberState(name string, state api.ClusterMemberStatePost) (op Operation, err error)
+	GetClusterRebalance() (plan *api.ClusterRebalance, err error)
+	RebalanceCluster() (op Operation, err error)
+	GetClusterUpgrade() (upgrade *api.ClusterUpgrade, err error)
+	UpdateClusterUpgrade(upgrade api.ClusterUpgradePost) (err error)
 	GetClusterGroups() ([]api.ClusterGroup, error)
 	GetClusterGroupNames() ([]string, error)
 	RenameClusterGroup(name string, group api.ClusterGroupPost) error
@@ -422,13 +440,26 @@ type InstanceServer interface {
 	UpdateClusterGroup(name string, group api.ClusterGroupPut, ETag string) error
 	GetClusterGroup(name string) (*api.ClusterGroup, string, error)
 
+	// Audit log functions
+	GetAuditLog(args *GetAuditLogArgs) (entries []api.AuditLogEntry, err error)
+
 	// Warning functions
 	GetWarningUUIDs() (uuids []string, err error)
 	GetWarnings() (warnings []api.Warning, err error)
+	GetWarningsWithArgs(args *ListArgs) (warnings []api.Warning, err error)
 	GetWarning(UUID string) (warning *api.Warning, ETag string, err error)
 	UpdateWarning(UUID string, warning api.WarningPut, ETag string) (err error)
 	DeleteWarning(UUID string) (err error)
 
+	// Webhook functions
+	GetWebhookNames() (names []string, err error)
+	GetWebhooks() (webhooks []api.Webhook, err error)
+	GetWebhooksWithArgs(args *ListArgs) (webhooks []api.Webhook, err error)
+	GetWebhook(name string) (webhook *api.Webhook, ETag string, err error)
+	CreateWebhook(webhook api.WebhooksPost) (err error)
+	UpdateWebhook(name string, webhook api.WebhookPut, ETag string) (err error)
+	DeleteWebhook(name string) (err error)
+
 	// Authorization functions
 	GetAuthGroupNames() (groupNames []string, err error)
 	GetAuthGroups() (groups []api.AuthGroup, err error)
@@ -440,10 +471,15 @@ type InstanceServer interface {
 	GetIdentityAuthenticationMethodsIdentifiers() (authMethodsIdentifiers map[string][]string, err error)
 	GetIdentityIdentifiersByAuthenticationMethod(authenticationMethod string) (identifiers []string, err error)
 	GetIdentities() (identities []api.Identity, err error)
+	GetIdentitiesWithArgs(args *ListArgs) (identities []api.Identity, err error)
 	GetIdentitiesByAuthenticationMethod(authenticationMethod string) (identities []api.Identity, err error)
 	GetIdentity(authenticationMethod string, nameOrIdentifier string) (identity *api.Identity, ETag string, err error)
 	GetCurrentIdentityInfo() (identityInfo *api.IdentityInfo, ETag string, err error)
 	UpdateIdentity(authenticationMethod string, nameOrIdentifier string, identityPut api.IdentityPut, ETag string) error
+	CreateIdentityBearerToken(tokenPost api.IdentityBearerTokenPost) (token *api.IdentityBearerToken, err error)
+	DeleteIdentity(authenticationMethod string, nameOrIdentifier string) error
+	GetIdentityEffectivePermissions(authenticationMethod string, nameOrIdentifier string) (permissions []api.Permission, err error)
+	CheckPermission(check api.AuthCheckPost) (result *api.AuthCheck, err error)
 	GetIdentityProviderGroupNames() (identityProviderGroupNames []string, err error)
 	GetIdentityProviderGroups() (identityProviderGroups []api.IdentityProviderGroup, err error)
 	GetIdentityProviderGroup(identityProviderGroupName string) (identityProviderGroup *api.IdentityProviderGroup, ETag string, err error)
@@ -745,3 +781,31 @@ type GetPermissionsArgs struct {
 	// level permissions will not be returned.
 	ProjectName string
 }
+
+// GetAuditLogArgs is used in the call to GetAuditLog to specify filtering behaviour.
+type GetAuditLogArgs struct {
+	// Since restricts the entries to those recorded at or after the given time.
+	Since time.Time
+
+	// Until restricts the entries to those recorded at or before the given time.
+	Until time.Time
+
+	// Identity restricts the entries to requests made by the given identity.
+	Identity string
+
+	// EntityURL restricts the entries to requests acting on the given entity.
+	EntityURL string
+}
+
+// ListArgs is used in the calls listing a collection to filter and paginate its entries.
+type ListArgs struct {
+	// Filters are the "key=value" pairs the returned entries must all match.
+	Filters []string
+
+	// Limit is the maximum number of entries to return. All the entries are returned if zero.
+	Limit int
+
+	// After is the key of the last entry of the previous page, usually its name.
+	// Only the entries sorted after it are returned.
+	After string
+}
@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -49,7 +51,8 @@ type ProtocolLXD struct {
 	clusterTarget string
 	project       string
 
-	oidcClient *oidcClient
+	oidcClient  *oidcClient
+	bearerToken string
 }
 
 // Disconnect gets rid of any background goroutines.
@@ -158,6 +161,8 @@ func (r *ProtocolLXD) DoHTTP(req *http.Request) (*http.Response, error) {
 // User-Agent (if r.httpUserAgent is set).
 // X-LXD-authenticated (if r.requireAuthenticated is set).
 // OIDC Authorization header (if r.oidcClient is set).
+// Bearer token Authorization header (if r.bearerToken is set).
+// Trace context (if the request context carries an OpenTelemetry span and a propagator is configured).
 func (r *ProtocolLXD) addClientHeaders(req *http.Request) {
 	if r.httpUserAgent != "" {
 		req.Header.Set("User-Agent", r.httpUserAgent)
@@ -169,7 +174,11 @@ func (r *ProtocolLXD) addClientHeaders(req *http.Request) {
 
 	if r.oidcClient != nil {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", r.oidcClient.getAccessToken()))
+	} else if r.bearerToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", r.bearerToken))
 	}
+
+	otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
 }
 
 // RequireAuthenticated sets whether we expect to be authenticated with the server.
@@ -0,0 +1,48 @@
+package lxd
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetAuditLog returns the entries of the audit log of the server (or of the cluster member set with UseTarget).
+func (r *ProtocolLXD) GetAuditLog(args *GetAuditLogArgs) ([]api.AuditLogEntry, error) {
+	err := r.CheckExtension("audit_log")
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	if args != nil {
+		if !args.Since.IsZero() {
+			values.Set("since", args.Since.Format(time.RFC3339))
+		}
+
+		if !args.Until.IsZero() {
+			values.Set("until", args.Until.Format(time.RFC3339))
+		}
+
+		if args.Identity != "" {
+			values.Set("identity", args.Identity)
+		}
+
+		if args.EntityURL != "" {
+			values.Set("entity", args.EntityURL)
+		}
+	}
+
+	path := "/audit"
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+
+	entries := []api.AuditLogEntry{}
+	_, err = r.queryStruct("GET", path, nil, "", &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
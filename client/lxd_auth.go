@@ -196,6 +196,22 @@ func (r *ProtocolLXD) GetIdentities() ([]api.Identity, error) {
 	return identities, nil
 }
 
+// GetIdentitiesWithArgs returns a filtered page of the identities.
+func (r *ProtocolLXD) GetIdentitiesWithArgs(args *ListArgs) ([]api.Identity, error) {
+	err := r.CheckExtension("api_pagination")
+	if err != nil {
+		return nil, err
+	}
+
+	var identities []api.Identity
+	_, err = r.queryStruct(http.MethodGet, "/auth/identities?"+listQuery(args), nil, "", &identities)
+	if err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
 // GetIdentitiesByAuthenticationMethod returns a list of identities that authenticate with the given authentication method.
 func (r *ProtocolLXD) GetIdentitiesByAuthenticationMethod(authenticationMethod string) ([]api.Identity, error) {
 	err := r.CheckExtension("access_management")
@@ -261,6 +277,70 @@ func (r *ProtocolLXD) UpdateIdentity(authenticationMethod string, nameOrIdentife
 	return nil
 }
 
+// CreateIdentityBearerToken issues a new bearer token identity. The returned token cannot be retrieved again.
+func (r *ProtocolLXD) CreateIdentityBearerToken(tokenPost api.IdentityBearerTokenPost) (*api.IdentityBearerToken, error) {
+	err := r.CheckExtension("auth_bearer_tokens")
+	if err != nil {
+		return nil, err
+	}
+
+	token := api.IdentityBearerToken{}
+	_, err = r.queryStruct(http.MethodPost, api.NewURL().Path("auth", "identities", api.AuthenticationMethodBearer).String(), tokenPost, "", &token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// DeleteIdentity deletes the identity with the given authentication method and name or identifier. Only bearer
+// token identities can be deleted.
+func (r *ProtocolLXD) DeleteIdentity(authenticationMethod string, nameOrIdentifier string) error {
+	err := r.CheckExtension("auth_bearer_tokens")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query(http.MethodDelete, api.NewURL().Path("auth", "identities", authenticationMethod, nameOrIdentifier).String(), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetIdentityEffectivePermissions returns the permissions granted to the identity by the groups it is a member of.
+func (r *ProtocolLXD) GetIdentityEffectivePermissions(authenticationMethod string, nameOrIdentifier string) ([]api.Permission, error) {
+	err := r.CheckExtension("auth_check")
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := []api.Permission{}
+	_, err = r.queryStruct(http.MethodGet, api.NewURL().Path("auth", "identities", authenticationMethod, nameOrIdentifier, "effective-permissions").String(), nil, "", &permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// CheckPermission checks whether an identity (or the caller if none is given) has an entitlement on an entity.
+func (r *ProtocolLXD) CheckPermission(check api.AuthCheckPost) (*api.AuthCheck, error) {
+	err := r.CheckExtension("auth_check")
+	if err != nil {
+		return nil, err
+	}
+
+	result := api.AuthCheck{}
+	_, err = r.queryStruct(http.MethodPost, api.NewURL().Path("auth", "check").String(), check, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 // GetIdentityProviderGroupNames returns a list of identity provider group names.
 func (r *ProtocolLXD) GetIdentityProviderGroupNames() ([]string, error) {
 	err := r.CheckExtension("access_management")
@@ -223,6 +223,68 @@ func (r *ProtocolLXD) UpdateClusterMemberState(name string, state api.ClusterMem
 	return op, nil
 }
 
+// GetClusterRebalance returns the instance moves the cluster rebalancer would perform now.
+func (r *ProtocolLXD) GetClusterRebalance() (*api.ClusterRebalance, error) {
+	err := r.CheckExtension("cluster_rebalance")
+	if err != nil {
+		return nil, err
+	}
+
+	plan := api.ClusterRebalance{}
+	_, err = r.queryStruct("GET", "/cluster/rebalance", nil, "", &plan)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+// RebalanceCluster moves instances between cluster members to reduce the load imbalance.
+func (r *ProtocolLXD) RebalanceCluster() (Operation, error) {
+	err := r.CheckExtension("cluster_rebalance")
+	if err != nil {
+		return nil, err
+	}
+
+	op, _, err := r.queryOperation("POST", "/cluster/rebalance", nil, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// GetClusterUpgrade returns the latest rolling upgrade of the cluster members.
+func (r *ProtocolLXD) GetClusterUpgrade() (*api.ClusterUpgrade, error) {
+	err := r.CheckExtension("cluster_rolling_upgrade")
+	if err != nil {
+		return nil, err
+	}
+
+	upgrade := api.ClusterUpgrade{}
+	_, err = r.queryStruct("GET", "/cluster/upgrade", nil, "", &upgrade)
+	if err != nil {
+		return nil, err
+	}
+
+	return &upgrade, nil
+}
+
+// UpdateClusterUpgrade starts, pauses, resumes or aborts a rolling upgrade of the cluster members.
+func (r *ProtocolLXD) UpdateClusterUpgrade(upgrade api.ClusterUpgradePost) error {
+	err := r.CheckExtension("cluster_rolling_upgrade")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query("POST", "/cluster/upgrade", upgrade, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // GetClusterGroups returns the cluster groups.
 func (r *ProtocolLXD) GetClusterGroups() ([]api.ClusterGroup, error) {
 	err := r.CheckExtension("clustering_groups")
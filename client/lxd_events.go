@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -14,6 +17,10 @@ import (
 
 // Event handling functions
 
+// eventsResumeTimeout is how long an event listener tries to resume its stream after the connection fails before
+// reporting the failure.
+const eventsResumeTimeout = 10 * time.Second
+
 // getEvents connects to the LXD monitoring interface.
 func (r *ProtocolLXD) getEvents(allProjects bool) (*EventListener, error) {
 	// Prevent anything else from interacting with the listeners
@@ -45,14 +52,7 @@ func (r *ProtocolLXD) getEvents(allProjects bool) (*EventListener, error) {
 	}
 
 	// Setup a new connection with LXD
-	var url string
-	var err error
-	if allProjects {
-		url, err = r.setQueryAttributes("/events?all-projects=true")
-	} else {
-		url, err = r.setQueryAttributes("/events")
-	}
-
+	url, err := r.eventsURL(allProjects, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -104,9 +104,19 @@ func (r *ProtocolLXD) getEvents(allProjects bool) (*EventListener, error) {
 
 	// Spawn the listener
 	go func() {
+		// ID of the last event received, used to resume the stream if the connection fails.
+		var lastID uint64
+
 		for {
 			_, data, err := wsConn.ReadMessage()
 			if err != nil {
+				// Try to transparently resume the stream from the last event received.
+				resumedConn := r.resumeEvents(wsConn, listener.projectName, allProjects, lastID)
+				if resumedConn != nil {
+					wsConn = resumedConn
+					continue
+				}
+
 				// Prevent anything else from interacting with the listeners
 				r.eventListenersLock.Lock()
 				defer r.eventListenersLock.Unlock()
@@ -138,6 +148,10 @@ func (r *ProtocolLXD) getEvents(allProjects bool) (*EventListener, error) {
 				continue
 			}
 
+			if event.ID > lastID {
+				lastID = event.ID
+			}
+
 			// Send the message to all handlers
 			r.eventListenersLock.Lock()
 			for _, listener := range r.eventListeners[listener.projectName] {
@@ -160,6 +174,83 @@ func (r *ProtocolLXD) getEvents(allProjects bool) (*EventListener, error) {
 	return &listener, nil
 }
 
+// eventsURL returns the URL of the event stream, replaying the events that followed the given event ID if non-zero.
+func (r *ProtocolLXD) eventsURL(allProjects bool, since uint64) (string, error) {
+	values := neturl.Values{}
+	if allProjects {
+		values.Set("all-projects", "true")
+	}
+
+	if since > 0 {
+		values.Set("since", strconv.FormatUint(since, 10))
+	}
+
+	path := "/events"
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+
+	return r.setQueryAttributes(path)
+}
+
+// resumeEvents tries to replace the failed event stream connection of the given project, replaying the events that
+// followed the given event ID. It returns nil if the stream can't be resumed, either because the connection was closed
+// on purpose, the server doesn't support replaying events, the events are no longer available or the server couldn't
+// be reached in time.
+func (r *ProtocolLXD) resumeEvents(failedConn *websocket.Conn, projectName string, allProjects bool, since uint64) *websocket.Conn {
+	// Servers not supporting replay don't set event IDs.
+	if since == 0 {
+		return nil
+	}
+
+	deadline := time.Now().Add(eventsResumeTimeout)
+	for time.Now().Before(deadline) {
+		// Stop if the connection was closed because all listeners are gone.
+		r.eventConnsLock.Lock()
+		active := r.eventConns[projectName] == failedConn
+		r.eventConnsLock.Unlock()
+
+		if !active {
+			return nil
+		}
+
+		url, err := r.eventsURL(allProjects, since)
+		if err != nil {
+			return nil
+		}
+
+		wsConn, err := r.websocket(url)
+		if err == nil {
+			r.eventListenersLock.Lock()
+			r.eventConnsLock.Lock()
+			defer r.eventListenersLock.Unlock()
+			defer r.eventConnsLock.Unlock()
+
+			// Check again in case all listeners went away while connecting.
+			if len(r.eventListeners[projectName]) == 0 || r.eventConns[projectName] != failedConn {
+				_ = wsConn.Close()
+				return nil
+			}
+
+			r.eventConns[projectName] = wsConn
+
+			return wsConn
+		}
+
+		if api.StatusErrorCheck(err, http.StatusGone) {
+			return nil
+		}
+
+		select {
+		case <-r.ctxConnected.Done():
+			return nil
+		case <-time.After(time.Second):
+		}
+	}
+
+	return nil
+}
+
 // GetEvents gets the events for the project defined on the client.
 func (r *ProtocolLXD) GetEvents() (*EventListener, error) {
 	return r.getEvents(false)
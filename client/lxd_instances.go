@@ -216,6 +216,22 @@ func (r *ProtocolLXD) UpdateInstances(state api.InstancesPut, ETag string) (Oper
 	return op, nil
 }
 
+// ApplyBulkAction applies an action to all the instances matching a filter.
+// The result for each instance is recorded in the "results" field of the operation metadata.
+func (r *ProtocolLXD) ApplyBulkAction(req api.BulkPost) (Operation, error) {
+	err := r.CheckExtension("bulk_operations")
+	if err != nil {
+		return nil, err
+	}
+
+	op, _, err := r.queryOperation("POST", "/bulk", req, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // rebuildInstance initiates a rebuild of a given instance on the LXD Protocol server and returns the corresponding operation or an error.
 func (r *ProtocolLXD) rebuildInstance(instanceName string, instance api.InstanceRebuildPost) (Operation, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -1803,6 +1819,28 @@ func (r *ProtocolLXD) GetInstanceSnapshots(instanceName string) ([]api.InstanceS
 	return snapshots, nil
 }
 
+// GetInstanceSnapshotsWithArgs returns a filtered page of the snapshots for the instance.
+func (r *ProtocolLXD) GetInstanceSnapshotsWithArgs(instanceName string, args *ListArgs) ([]api.InstanceSnapshot, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.CheckExtension("api_pagination")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []api.InstanceSnapshot{}
+
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/snapshots?%s", path, url.PathEscape(instanceName), listQuery(args)), nil, "", &snapshots)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
 // GetInstanceSnapshot returns a Snapshot struct for the provided instance and snapshot names.
 func (r *ProtocolLXD) GetInstanceSnapshot(instanceName string, name string) (*api.InstanceSnapshot, string, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -2845,6 +2883,28 @@ func (r *ProtocolLXD) GetInstanceBackups(instanceName string) ([]api.InstanceBac
 	return backups, nil
 }
 
+// GetInstanceBackupsWithArgs returns a filtered page of the backups for the instance.
+func (r *ProtocolLXD) GetInstanceBackupsWithArgs(instanceName string, args *ListArgs) ([]api.InstanceBackup, error) {
+	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.CheckExtension("api_pagination")
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []api.InstanceBackup{}
+
+	_, err = r.queryStruct("GET", fmt.Sprintf("%s/%s/backups?%s", path, url.PathEscape(instanceName), listQuery(args)), nil, "", &backups)
+	if err != nil {
+		return nil, err
+	}
+
+	return backups, nil
+}
+
 // GetInstanceBackup returns a Backup struct for the provided instance and backup names.
 func (r *ProtocolLXD) GetInstanceBackup(instanceName string, name string) (*api.InstanceBackup, string, error) {
 	path, _, err := r.instanceTypeToPath(api.InstanceTypeAny)
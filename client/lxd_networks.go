@@ -44,6 +44,23 @@ func (r *ProtocolLXD) GetNetworks() ([]api.Network, error) {
 	return networks, nil
 }
 
+// GetNetworksWithArgs returns a filtered page of the Network entries.
+func (r *ProtocolLXD) GetNetworksWithArgs(args *ListArgs) ([]api.Network, error) {
+	err := r.CheckExtension("api_pagination")
+	if err != nil {
+		return nil, err
+	}
+
+	networks := []api.Network{}
+
+	_, err = r.queryStruct("GET", "/networks?"+listQuery(args), nil, "", &networks)
+	if err != nil {
+		return nil, err
+	}
+
+	return networks, nil
+}
+
 // GetNetwork returns a Network entry for the provided name.
 func (r *ProtocolLXD) GetNetwork(name string) (*api.Network, string, error) {
 	err := r.CheckExtension("network")
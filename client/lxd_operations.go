@@ -3,6 +3,7 @@ package lxd
 import (
 	"fmt"
 	"net/url"
+	"sort"
 
 	"github.com/gorilla/websocket"
 
@@ -42,6 +43,48 @@ func (r *ProtocolLXD) GetOperations() ([]api.Operation, error) {
 	return operations, nil
 }
 
+// GetOperationsWithArgs returns a filtered page of the Operation structs.
+func (r *ProtocolLXD) GetOperationsWithArgs(args *ListArgs) ([]api.Operation, error) {
+	return r.getOperationsWithArgs(false, args)
+}
+
+// GetOperationsAllProjectsWithArgs returns a filtered page of the Operation structs of all projects.
+func (r *ProtocolLXD) GetOperationsAllProjectsWithArgs(args *ListArgs) ([]api.Operation, error) {
+	return r.getOperationsWithArgs(true, args)
+}
+
+// getOperationsWithArgs returns a filtered page of the Operation structs of the current or all projects.
+func (r *ProtocolLXD) getOperationsWithArgs(allProjects bool, args *ListArgs) ([]api.Operation, error) {
+	err := r.CheckExtension("api_pagination")
+	if err != nil {
+		return nil, err
+	}
+
+	query := listQuery(args)
+	if allProjects {
+		query += "&all-projects=true"
+	}
+
+	apiOperations := map[string][]api.Operation{}
+
+	_, err = r.queryStruct("GET", "/operations?"+query, nil, "", &apiOperations)
+	if err != nil {
+		return nil, err
+	}
+
+	// Turn it into a list of operations sorted as the page.
+	operations := []api.Operation{}
+	for _, v := range apiOperations {
+		operations = append(operations, v...)
+	}
+
+	if args != nil && (args.Limit > 0 || args.After != "") {
+		sort.Slice(operations, func(i, j int) bool { return operations[i].ID < operations[j].ID })
+	}
+
+	return operations, nil
+}
+
 // GetOperationsAllProjects returns a list of operations from all projects.
 func (r *ProtocolLXD) GetOperationsAllProjects() ([]api.Operation, error) {
 	err := r.CheckExtension("operations_get_query_all_projects")
@@ -36,6 +36,23 @@ func (r *ProtocolLXD) GetProfiles() ([]api.Profile, error) {
 	return profiles, nil
 }
 
+// GetProfilesWithArgs returns a filtered page of the available Profile structs.
+func (r *ProtocolLXD) GetProfilesWithArgs(args *ListArgs) ([]api.Profile, error) {
+	err := r.CheckExtension("api_pagination")
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := []api.Profile{}
+
+	_, err = r.queryStruct("GET", "/profiles?"+listQuery(args), nil, "", &profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
 // GetProfile returns a Profile entry for the provided name.
 func (r *ProtocolLXD) GetProfile(name string) (*api.Profile, string, error) {
 	profile := api.Profile{}
@@ -3,6 +3,7 @@ package lxd
 import (
 	"fmt"
 	"net/url"
+	"time"
 
 	"github.com/canonical/lxd/shared/api"
 )
@@ -46,6 +47,23 @@ func (r *ProtocolLXD) GetProjects() ([]api.Project, error) {
 	return projects, nil
 }
 
+// GetProjectsWithArgs returns a filtered page of the available Project structs.
+func (r *ProtocolLXD) GetProjectsWithArgs(args *ListArgs) ([]api.Project, error) {
+	err := r.CheckExtension("api_pagination")
+	if err != nil {
+		return nil, err
+	}
+
+	projects := []api.Project{}
+
+	_, err = r.queryStruct("GET", "/projects?"+listQuery(args), nil, "", &projects)
+	if err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
 // GetProject returns a Project entry for the provided name.
 func (r *ProtocolLXD) GetProject(name string) (*api.Project, string, error) {
 	err := r.CheckExtension("projects")
@@ -82,6 +100,39 @@ func (r *ProtocolLXD) GetProjectState(name string) (*api.ProjectState, error) {
 	return &projectState, nil
 }
 
+// GetProjectUsage returns the resource usage of a project between the given days (inclusive).
+// A zero from or to time selects the default, which is the current month up to the current day.
+func (r *ProtocolLXD) GetProjectUsage(name string, from time.Time, to time.Time) (*api.ProjectUsage, error) {
+	err := r.CheckExtension("project_usage_history")
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	if !from.IsZero() {
+		values.Set("from", from.Format(time.DateOnly))
+	}
+
+	if !to.IsZero() {
+		values.Set("to", to.Format(time.DateOnly))
+	}
+
+	path := fmt.Sprintf("/projects/%s/usage", url.PathEscape(name))
+	if len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+
+	projectUsage := api.ProjectUsage{}
+
+	// Fetch the raw value
+	_, err = r.queryStruct("GET", path, nil, "", &projectUsage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &projectUsage, nil
+}
+
 // CreateProject defines a new container project.
 func (r *ProtocolLXD) CreateProject(project api.ProjectsPost) error {
 	err := r.CheckExtension("projects")
@@ -42,6 +42,24 @@ func (r *ProtocolLXD) GetStoragePoolBuckets(poolName string) ([]api.StorageBucke
 	return buckets, nil
 }
 
+// GetStoragePoolBucketsWithArgs returns a filtered page of the storage buckets for the provided pool.
+func (r *ProtocolLXD) GetStoragePoolBucketsWithArgs(poolName string, args *ListArgs) ([]api.StorageBucket, error) {
+	err := r.CheckExtension("api_pagination")
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := []api.StorageBucket{}
+
+	u := api.NewURL().Path("storage-pools", poolName, "buckets")
+	_, err = r.queryStruct("GET", u.String()+"?"+listQuery(args), nil, "", &buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	return buckets, nil
+}
+
 // GetStoragePoolBucket returns a storage bucket entry for the provided pool and bucket name.
 func (r *ProtocolLXD) GetStoragePoolBucket(poolName string, bucketName string) (*api.StorageBucket, string, error) {
 	err := r.CheckExtension("storage_buckets")
@@ -46,6 +46,23 @@ func (r *ProtocolLXD) GetStoragePools() ([]api.StoragePool, error) {
 	return pools, nil
 }
 
+// GetStoragePoolsWithArgs returns a filtered page of the StoragePool entries.
+func (r *ProtocolLXD) GetStoragePoolsWithArgs(args *ListArgs) ([]api.StoragePool, error) {
+	err := r.CheckExtension("api_pagination")
+	if err != nil {
+		return nil, err
+	}
+
+	pools := []api.StoragePool{}
+
+	_, err = r.queryStruct("GET", "/storage-pools?"+listQuery(args), nil, "", &pools)
+	if err != nil {
+		return nil, err
+	}
+
+	return pools, nil
+}
+
 // GetStoragePool returns a StoragePool entry for the provided pool name.
 func (r *ProtocolLXD) GetStoragePool(name string) (*api.StoragePool, string, error) {
 	err := r.CheckExtension("storage")
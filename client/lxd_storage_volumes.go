@@ -348,6 +348,28 @@ func (r *ProtocolLXD) GetStoragePoolVolumeSnapshots(pool string, volumeType stri
 	return snapshots, nil
 }
 
+// GetStoragePoolVolumeSnapshotsWithArgs returns a filtered page of the snapshots for the storage volume.
+func (r *ProtocolLXD) GetStoragePoolVolumeSnapshotsWithArgs(pool string, volumeType string, volumeName string, args *ListArgs) ([]api.StorageVolumeSnapshot, error) {
+	err := r.CheckExtension("api_pagination")
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []api.StorageVolumeSnapshot{}
+
+	path := fmt.Sprintf("/storage-pools/%s/volumes/%s/%s/snapshots?%s",
+		url.PathEscape(pool),
+		url.PathEscape(volumeType),
+		url.PathEscape(volumeName),
+		listQuery(args))
+	_, err = r.queryStruct("GET", path, nil, "", &snapshots)
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
 // GetStoragePoolVolumeSnapshot returns a snapshots for the storage volume.
 func (r *ProtocolLXD) GetStoragePoolVolumeSnapshot(pool string, volumeType string, volumeName string, snapshotName string) (*api.StorageVolumeSnapshot, string, error) {
 	err := r.CheckExtension("storage_api_volume_snapshots")
@@ -936,6 +958,23 @@ func (r *ProtocolLXD) GetStoragePoolVolumeBackups(pool string, volName string) (
 	return backups, nil
 }
 
+// GetStoragePoolVolumeBackupsWithArgs returns a filtered page of the custom volume backups.
+func (r *ProtocolLXD) GetStoragePoolVolumeBackupsWithArgs(pool string, volName string, args *ListArgs) ([]api.StoragePoolVolumeBackup, error) {
+	err := r.CheckExtension("api_pagination")
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []api.StoragePoolVolumeBackup{}
+
+	_, err = r.queryStruct("GET", fmt.Sprintf("/storage-pools/%s/volumes/custom/%s/backups?%s", url.PathEscape(pool), url.PathEscape(volName), listQuery(args)), nil, "", &backups)
+	if err != nil {
+		return nil, err
+	}
+
+	return backups, nil
+}
+
 // GetStoragePoolVolumeBackup returns a custom volume backup.
 func (r *ProtocolLXD) GetStoragePoolVolumeBackup(pool string, volName string, name string) (*api.StoragePoolVolumeBackup, string, error) {
 	err := r.CheckExtension("custom_volume_backup")
@@ -45,6 +45,23 @@ func (r *ProtocolLXD) GetWarnings() ([]api.Warning, error) {
 	return warnings, nil
 }
 
+// GetWarningsWithArgs returns a filtered page of the warnings.
+func (r *ProtocolLXD) GetWarningsWithArgs(args *ListArgs) ([]api.Warning, error) {
+	err := r.CheckExtension("api_pagination")
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := []api.Warning{}
+
+	_, err = r.queryStruct("GET", "/warnings?"+listQuery(args), nil, "", &warnings)
+	if err != nil {
+		return nil, err
+	}
+
+	return warnings, nil
+}
+
 // GetWarning returns the warning with the given UUID.
 func (r *ProtocolLXD) GetWarning(UUID string) (*api.Warning, string, error) {
 	err := r.CheckExtension("warnings")
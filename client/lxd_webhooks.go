@@ -0,0 +1,122 @@
+package lxd
+
+import (
+	"net/url"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetWebhookNames returns the names of the webhooks.
+func (r *ProtocolLXD) GetWebhookNames() ([]string, error) {
+	err := r.CheckExtension("webhooks")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/webhooks"
+	_, err = r.queryStruct("GET", baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetWebhooks returns the webhooks.
+func (r *ProtocolLXD) GetWebhooks() ([]api.Webhook, error) {
+	err := r.CheckExtension("webhooks")
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := []api.Webhook{}
+
+	_, err = r.queryStruct("GET", "/webhooks?recursion=1", nil, "", &webhooks)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhooksWithArgs returns a filtered page of the webhooks.
+func (r *ProtocolLXD) GetWebhooksWithArgs(args *ListArgs) ([]api.Webhook, error) {
+	err := r.CheckExtension("api_pagination")
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := []api.Webhook{}
+
+	_, err = r.queryStruct("GET", "/webhooks?"+listQuery(args), nil, "", &webhooks)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhook returns the webhook with the given name.
+func (r *ProtocolLXD) GetWebhook(name string) (*api.Webhook, string, error) {
+	err := r.CheckExtension("webhooks")
+	if err != nil {
+		return nil, "", err
+	}
+
+	webhook := api.Webhook{}
+
+	etag, err := r.queryStruct("GET", "/webhooks/"+url.PathEscape(name), nil, "", &webhook)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &webhook, etag, nil
+}
+
+// CreateWebhook creates a new webhook.
+func (r *ProtocolLXD) CreateWebhook(webhook api.WebhooksPost) error {
+	err := r.CheckExtension("webhooks")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query("POST", "/webhooks", webhook, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateWebhook updates the webhook with the given name.
+func (r *ProtocolLXD) UpdateWebhook(name string, webhook api.WebhookPut, ETag string) error {
+	err := r.CheckExtension("webhooks")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query("PUT", "/webhooks/"+url.PathEscape(name), webhook, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteWebhook deletes the webhook with the given name.
+func (r *ProtocolLXD) DeleteWebhook(name string) error {
+	err := r.CheckExtension("webhooks")
+	if err != nil {
+		return err
+	}
+
+	_, _, err = r.query("DELETE", "/webhooks/"+url.PathEscape(name), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package lxd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/oci"
+)
+
+// Image handling functions
+
+// GetImages returns the images of an OCI layout or archive as Image structs.
+// OCI images are referred to by their reference, which is used as their fingerprint.
+func (r *ProtocolOCI) GetImages() ([]api.Image, error) {
+	references, err := r.ociClient.References()
+	if err != nil {
+		return nil, err
+	}
+
+	images := []api.Image{}
+	for _, reference := range references {
+		image, _, err := r.GetImage(reference)
+		if err != nil {
+			return nil, err
+		}
+
+		images = append(images, *image)
+	}
+
+	return images, nil
+}
+
+// GetImageFingerprints returns the references of the images of an OCI layout or archive.
+func (r *ProtocolOCI) GetImageFingerprints() ([]string, error) {
+	return r.ociClient.References()
+}
+
+// GetImagesWithFilter returns a filtered list of available images as Image structs.
+func (r *ProtocolOCI) GetImagesWithFilter(filters []string) ([]api.Image, error) {
+	return nil, fmt.Errorf("GetImagesWithFilter is not supported by the OCI protocol")
+}
+
+// GetImage returns an Image struct for the provided image reference.
+func (r *ProtocolOCI) GetImage(fingerprint string) (*api.Image, string, error) {
+	architectures, err := r.ociClient.Architectures(fingerprint)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed getting image: %w", err)
+	}
+
+	name, tag := oci.ParseReference(fingerprint)
+
+	image := api.Image{
+		Fingerprint: fingerprint,
+		Public:      true,
+		Type:        string(api.InstanceTypeContainer),
+		Aliases:     []api.ImageAlias{{Name: fingerprint}},
+	}
+
+	image.Properties = map[string]string{
+		"description":   fmt.Sprintf("%s (%s)", name, tag),
+		"oci.reference": fingerprint,
+	}
+
+	if len(architectures) > 0 {
+		image.Architecture = architectures[0]
+		image.Properties["architectures"] = strings.Join(architectures, ",")
+	}
+
+	return &image, "", nil
+}
+
+// GetImageFile isn't supported by the OCI protocol, images are converted by the LXD server.
+func (r *ProtocolOCI) GetImageFile(fingerprint string, req ImageFileRequest) (*ImageFileResponse, error) {
+	return nil, fmt.Errorf("Downloading image files is not supported by the OCI protocol")
+}
+
+// GetImageSecret isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetImageSecret(fingerprint string) (string, error) {
+	return "", fmt.Errorf("Private images aren't supported by the OCI protocol")
+}
+
+// GetPrivateImage isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetPrivateImage(fingerprint string, secret string) (*api.Image, string, error) {
+	return nil, "", fmt.Errorf("Private images aren't supported by the OCI protocol")
+}
+
+// GetPrivateImageFile isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetPrivateImageFile(fingerprint string, secret string, req ImageFileRequest) (*ImageFileResponse, error) {
+	return nil, fmt.Errorf("Private images aren't supported by the OCI protocol")
+}
+
+// GetImageAliases returns the references of an OCI layout or archive as ImageAliasesEntry structs.
+func (r *ProtocolOCI) GetImageAliases() ([]api.ImageAliasesEntry, error) {
+	references, err := r.ociClient.References()
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := []api.ImageAliasesEntry{}
+	for _, reference := range references {
+		aliases = append(aliases, api.ImageAliasesEntry{Name: reference, Target: reference, Type: string(api.InstanceTypeContainer)})
+	}
+
+	return aliases, nil
+}
+
+// GetImageAliasNames returns the references of an OCI layout or archive.
+func (r *ProtocolOCI) GetImageAliasNames() ([]string, error) {
+	return r.ociClient.References()
+}
+
+// GetImageAlias returns an image reference as an ImageAliasesEntry struct.
+func (r *ProtocolOCI) GetImageAlias(name string) (*api.ImageAliasesEntry, string, error) {
+	return &api.ImageAliasesEntry{Name: name, Target: name, Type: string(api.InstanceTypeContainer)}, "", nil
+}
+
+// GetImageAliasType returns an image reference as an ImageAliasesEntry struct.
+func (r *ProtocolOCI) GetImageAliasType(imageType string, name string) (*api.ImageAliasesEntry, string, error) {
+	if imageType != "" && imageType != string(api.InstanceTypeContainer) {
+		return nil, "", fmt.Errorf("OCI images can only be used for containers")
+	}
+
+	return r.GetImageAlias(name)
+}
+
+// GetImageAliasArchitectures returns a map of the architectures an image reference is available for.
+func (r *ProtocolOCI) GetImageAliasArchitectures(imageType string, name string) (map[string]*api.ImageAliasesEntry, error) {
+	alias, _, err := r.GetImageAliasType(imageType, name)
+	if err != nil {
+		return nil, err
+	}
+
+	architectures, err := r.ociClient.Architectures(name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := map[string]*api.ImageAliasesEntry{}
+	for _, architecture := range architectures {
+		entries[architecture] = alias
+	}
+
+	return entries, nil
+}
+
+// ExportImage exports (copies) an image to a remote server.
+func (r *ProtocolOCI) ExportImage(fingerprint string, image api.ImageExportPost) (Operation, error) {
+	return nil, fmt.Errorf("Exporting images is not supported by the OCI protocol")
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"os/exec"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -248,6 +249,28 @@ func parseFilters(filters []string) string {
 	return strings.Join(result, " and ")
 }
 
+// listQuery returns the query of a recursive request listing a collection with the given filtering and pagination.
+func listQuery(args *ListArgs) string {
+	values := url.Values{}
+	values.Set("recursion", "1")
+
+	if args != nil {
+		if len(args.Filters) > 0 {
+			values.Set("filter", parseFilters(args.Filters))
+		}
+
+		if args.Limit > 0 {
+			values.Set("limit", strconv.Itoa(args.Limit))
+		}
+
+		if args.After != "" {
+			values.Set("after", args.After)
+		}
+	}
+
+	return values.Encode()
+}
+
 // HTTPTransporter represents a wrapper around *http.Transport.
 // It is used to add some pre and postprocessing logic to http requests / responses.
 type HTTPTransporter interface {
@@ -0,0 +1,891 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+)
+
+// applyManifest is the desired state of a set of LXD resources.
+type applyManifest struct {
+	Projects       []applyProject       `yaml:"projects"`
+	NetworkACLs    []applyNetworkACL    `yaml:"network_acls"`
+	Networks       []applyNetwork       `yaml:"networks"`
+	StorageVolumes []applyStorageVolume `yaml:"storage_volumes"`
+	Profiles       []applyProfile       `yaml:"profiles"`
+	Instances      []applyInstance      `yaml:"instances"`
+}
+
+// applyProject is a project in a manifest.
+type applyProject struct {
+	api.ProjectsPost `yaml:",inline"`
+
+	// descriptionSet is whether the manifest sets the description, which is otherwise left as is.
+	descriptionSet bool
+}
+
+// UnmarshalYAML records whether the manifest sets the description of the project.
+func (p *applyProject) UnmarshalYAML(unmarshal func(any) error) error {
+	type plain applyProject
+	err := unmarshal((*plain)(p))
+	if err != nil {
+		return err
+	}
+
+	p.descriptionSet, err = applyDescriptionSet(unmarshal)
+	return err
+}
+
+// applyNetworkACL is a network ACL in a manifest.
+type applyNetworkACL struct {
+	api.NetworkACLsPost `yaml:",inline"`
+
+	Project string `yaml:"project"`
+
+	// descriptionSet is whether the manifest sets the description, which is otherwise left as is.
+	descriptionSet bool
+}
+
+// UnmarshalYAML records whether the manifest sets the description of the network ACL.
+func (acl *applyNetworkACL) UnmarshalYAML(unmarshal func(any) error) error {
+	type plain applyNetworkACL
+	err := unmarshal((*plain)(acl))
+	if err != nil {
+		return err
+	}
+
+	acl.descriptionSet, err = applyDescriptionSet(unmarshal)
+	return err
+}
+
+// applyNetwork is a network in a manifest.
+type applyNetwork struct {
+	api.NetworksPost `yaml:",inline"`
+
+	Project string `yaml:"project"`
+
+	// descriptionSet is whether the manifest sets the description, which is otherwise left as is.
+	descriptionSet bool
+}
+
+// UnmarshalYAML records whether the manifest sets the description of the network.
+func (n *applyNetwork) UnmarshalYAML(unmarshal func(any) error) error {
+	type plain applyNetwork
+	err := unmarshal((*plain)(n))
+	if err != nil {
+		return err
+	}
+
+	n.descriptionSet, err = applyDescriptionSet(unmarshal)
+	return err
+}
+
+// applyStorageVolume is a custom storage volume in a manifest.
+type applyStorageVolume struct {
+	api.StorageVolumePut `yaml:",inline"`
+
+	Name        string `yaml:"name"`
+	Pool        string `yaml:"pool"`
+	Project     string `yaml:"project"`
+	ContentType string `yaml:"content_type"`
+
+	// descriptionSet is whether the manifest sets the description, which is otherwise left as is.
+	descriptionSet bool
+}
+
+// UnmarshalYAML records whether the manifest sets the description of the storage volume.
+func (vol *applyStorageVolume) UnmarshalYAML(unmarshal func(any) error) error {
+	type plain applyStorageVolume
+	err := unmarshal((*plain)(vol))
+	if err != nil {
+		return err
+	}
+
+	vol.descriptionSet, err = applyDescriptionSet(unmarshal)
+	return err
+}
+
+// applyProfile is a profile in a manifest.
+type applyProfile struct {
+	api.ProfilesPost `yaml:",inline"`
+
+	Project string `yaml:"project"`
+
+	// descriptionSet is whether the manifest sets the description, which is otherwise left as is.
+	descriptionSet bool
+}
+
+// UnmarshalYAML records whether the manifest sets the description of the profile.
+func (profile *applyProfile) UnmarshalYAML(unmarshal func(any) error) error {
+	type plain applyProfile
+	err := unmarshal((*plain)(profile))
+	if err != nil {
+		return err
+	}
+
+	profile.descriptionSet, err = applyDescriptionSet(unmarshal)
+	return err
+}
+
+// applyInstance is an instance in a manifest.
+type applyInstance struct {
+	Name        string                       `yaml:"name"`
+	Project     string                       `yaml:"project"`
+	Type        string                       `yaml:"type"`
+	Image       string                       `yaml:"image"`
+	Description string                       `yaml:"description"`
+	Profiles    []string                     `yaml:"profiles"`
+	Config      map[string]string            `yaml:"config"`
+	Devices     map[string]map[string]string `yaml:"devices"`
+	Start       bool                         `yaml:"start"`
+
+	// descriptionSet is whether the manifest sets the description, which is otherwise left as is.
+	descriptionSet bool
+}
+
+// UnmarshalYAML records whether the manifest sets the description of the instance.
+func (inst *applyInstance) UnmarshalYAML(unmarshal func(any) error) error {
+	type plain applyInstance
+	err := unmarshal((*plain)(inst))
+	if err != nil {
+		return err
+	}
+
+	inst.descriptionSet, err = applyDescriptionSet(unmarshal)
+	return err
+}
+
+// applyDescriptionSet returns whether the manifest entry being unmarshaled sets a description.
+func applyDescriptionSet(unmarshal func(any) error) (bool, error) {
+	fields := map[string]any{}
+	err := unmarshal(&fields)
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := fields["description"]
+	return ok, nil
+}
+
+// applyChange is a change in the plan computed from a manifest.
+type applyChange struct {
+	action  string
+	kind    string
+	project string
+	name    string
+	details []string
+	run     func() error
+}
+
+type cmdApply struct {
+	global *cmdGlobal
+
+	flagFile   string
+	flagDryRun bool
+	flagPrune  bool
+	flagYes    bool
+}
+
+func (c *cmdApply) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("apply", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("Apply a manifest of resources")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Apply a manifest of resources
+
+The manifest lists the projects, network ACLs, networks, custom storage volumes,
+profiles and instances that should exist. The changes required to reach that state
+are shown and then applied, so applying the same manifest again doesn't change anything.
+
+The changes are only applied once confirmed, unless --yes is given or --quiet is
+given with a standard input that isn't a terminal.
+
+Only the configuration keys listed in the manifest are managed, other keys are left as is.
+Setting a key to an empty value unsets it. Descriptions, lists of profiles, devices and
+ACL rules that are left out of the manifest are left as is.
+
+With --prune, the profiles, network ACLs, networks, custom storage volumes and instances
+that aren't in the manifest are deleted from the projects that the manifest refers to.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc apply -f stack.yaml --dry-run
+    Show the changes required to apply stack.yaml.
+
+lxc apply -f stack.yaml
+    Apply stack.yaml.
+
+lxc apply -f stack.yaml --yes
+    Apply stack.yaml without asking for confirmation.`))
+
+	cmd.Flags().StringVarP(&c.flagFile, "file", "f", "", i18n.G("Manifest file (- for stdin)")+"``")
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only show the changes that would be made"))
+	cmd.Flags().BoolVar(&c.flagPrune, "prune", false, i18n.G("Delete the resources that aren't in the manifest"))
+	cmd.Flags().BoolVar(&c.flagYes, "yes", false, i18n.G("Apply the changes without asking for confirmation"))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdApply) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	if c.flagFile == "" {
+		return fmt.Errorf(i18n.G("A manifest file must be specified with --file"))
+	}
+
+	var contents []byte
+	if c.flagFile == "-" {
+		contents, err = io.ReadAll(os.Stdin)
+	} else {
+		contents, err = os.ReadFile(shared.HostPathFollow(c.flagFile))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	manifest := applyManifest{}
+	err = yaml.UnmarshalStrict(contents, &manifest)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Failed parsing manifest: %w"), err)
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	changes, err := c.plan(resource, manifest)
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Println(i18n.G("No changes"))
+		return nil
+	}
+
+	data := [][]string{}
+	for _, change := range changes {
+		data = append(data, []string{change.action, change.kind, change.project, change.name, strings.Join(change.details, ", ")})
+	}
+
+	header := []string{
+		i18n.G("ACTION"),
+		i18n.G("TYPE"),
+		i18n.G("PROJECT"),
+		i18n.G("NAME"),
+		i18n.G("CHANGES"),
+	}
+
+	err = cli.RenderTable(cli.TableFormatTable, header, data, nil)
+	if err != nil {
+		return err
+	}
+
+	if c.flagDryRun {
+		return nil
+	}
+
+	// Ask for confirmation unless told not to or when running non-interactively.
+	if !c.flagYes && !(c.global.flagQuiet && !termios.IsTerminal(getStdinFd())) {
+		if c.flagFile == "-" {
+			return fmt.Errorf(i18n.G("Use --yes to apply the changes when the manifest is read from stdin"))
+		}
+
+		apply, err := c.global.asker.AskBool(i18n.G("Apply these changes? (yes/no) [default=no]: "), "no")
+		if err != nil {
+			return err
+		}
+
+		if !apply {
+			return nil
+		}
+	}
+
+	for _, change := range changes {
+		err := change.run()
+		if err != nil {
+			return fmt.Errorf(i18n.G("Failed to %s %s %q in project %q: %w"), change.action, change.kind, change.name, change.project, err)
+		}
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Applied %d changes")+"\n", len(changes))
+	}
+
+	return nil
+}
+
+// plan computes the changes required to reach the state described by the manifest.
+// Creations and updates are ordered so that resources exist before being referred to, deletions come last in the
+// reverse order.
+func (c *cmdApply) plan(resource remoteResource, manifest applyManifest) ([]applyChange, error) {
+	info, err := resource.server.GetConnectionInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	// Resources without a project are in the current project of the remote.
+	projectName := func(name string) string {
+		if name == "" {
+			return info.Project
+		}
+
+		return name
+	}
+
+	server := func(project string) lxd.InstanceServer {
+		return resource.server.UseProject(project)
+	}
+
+	changes := []applyChange{}
+
+	// Projects that the manifest refers to and which already exist, used when pruning.
+	projects := map[string]*api.Project{}
+	checkProject := func(name string) error {
+		_, ok := projects[name]
+		if ok {
+			return nil
+		}
+
+		project, _, err := resource.server.GetProject(name)
+		if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		projects[name] = project
+		return nil
+	}
+
+	for _, p := range manifest.Projects {
+		current, etag, err := resource.server.GetProject(p.Name)
+		if err != nil {
+			if !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil, err
+			}
+
+			changes = append(changes, applyChange{action: "create", kind: "project", name: p.Name, run: func() error {
+				return resource.server.CreateProject(p.ProjectsPost)
+			}})
+
+			continue
+		}
+
+		projects[p.Name] = current
+
+		put := current.Writable()
+		details := applyUpdate(&put.Config, p.Config, &put.Description, p.Description, p.descriptionSet)
+		if len(details) > 0 {
+			changes = append(changes, applyChange{action: "update", kind: "project", name: p.Name, details: details, run: func() error {
+				return resource.server.UpdateProject(p.Name, put, etag)
+			}})
+		}
+	}
+
+	wanted := map[string]map[string]bool{}
+	want := func(kind string, project string, name string) {
+		if wanted[kind] == nil {
+			wanted[kind] = map[string]bool{}
+		}
+
+		wanted[kind][project+"/"+name] = true
+	}
+
+	for _, acl := range manifest.NetworkACLs {
+		project := projectName(acl.Project)
+		want("network ACL", project, acl.Name)
+
+		err := checkProject(project)
+		if err != nil {
+			return nil, err
+		}
+
+		current, etag, err := server(project).GetNetworkACL(acl.Name)
+		if err != nil {
+			if !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil, err
+			}
+
+			changes = append(changes, applyChange{action: "create", kind: "network ACL", project: project, name: acl.Name, run: func() error {
+				return server(project).CreateNetworkACL(acl.NetworkACLsPost)
+			}})
+
+			continue
+		}
+
+		put := current.Writable()
+		details := applyUpdate(&put.Config, acl.Config, &put.Description, acl.Description, acl.descriptionSet)
+		if acl.Ingress != nil && !applyEqual(put.Ingress, acl.Ingress) {
+			put.Ingress = acl.Ingress
+			details = append(details, "ingress")
+		}
+
+		if acl.Egress != nil && !applyEqual(put.Egress, acl.Egress) {
+			put.Egress = acl.Egress
+			details = append(details, "egress")
+		}
+
+		if len(details) > 0 {
+			changes = append(changes, applyChange{action: "update", kind: "network ACL", project: project, name: acl.Name, details: details, run: func() error {
+				return server(project).UpdateNetworkACL(acl.Name, put, etag)
+			}})
+		}
+	}
+
+	for _, network := range manifest.Networks {
+		project := projectName(network.Project)
+		want("network", project, network.Name)
+
+		err := checkProject(project)
+		if err != nil {
+			return nil, err
+		}
+
+		current, etag, err := server(project).GetNetwork(network.Name)
+		if err != nil {
+			if !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil, err
+			}
+
+			changes = append(changes, applyChange{action: "create", kind: "network", project: project, name: network.Name, run: func() error {
+				return server(project).CreateNetwork(network.NetworksPost)
+			}})
+
+			continue
+		}
+
+		put := current.Writable()
+		details := applyUpdate(&put.Config, network.Config, &put.Description, network.Description, network.descriptionSet)
+		if len(details) > 0 {
+			changes = append(changes, applyChange{action: "update", kind: "network", project: project, name: network.Name, details: details, run: func() error {
+				return server(project).UpdateNetwork(network.Name, put, etag)
+			}})
+		}
+	}
+
+	for _, vol := range manifest.StorageVolumes {
+		project := projectName(vol.Project)
+		want("storage volume", project, vol.Pool+"/"+vol.Name)
+
+		err := checkProject(project)
+		if err != nil {
+			return nil, err
+		}
+
+		current, etag, err := server(project).GetStoragePoolVolume(vol.Pool, "custom", vol.Name)
+		if err != nil {
+			if !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil, err
+			}
+
+			changes = append(changes, applyChange{action: "create", kind: "storage volume", project: project, name: vol.Pool + "/" + vol.Name, run: func() error {
+				req := api.StorageVolumesPost{
+					Name:             vol.Name,
+					Type:             "custom",
+					ContentType:      vol.ContentType,
+					StorageVolumePut: vol.StorageVolumePut,
+				}
+
+				return server(project).CreateStoragePoolVolume(vol.Pool, req)
+			}})
+
+			continue
+		}
+
+		put := current.Writable()
+		details := applyUpdate(&put.Config, vol.Config, &put.Description, vol.Description, vol.descriptionSet)
+		if len(details) > 0 {
+			changes = append(changes, applyChange{action: "update", kind: "storage volume", project: project, name: vol.Pool + "/" + vol.Name, details: details, run: func() error {
+				return server(project).UpdateStoragePoolVolume(vol.Pool, "custom", vol.Name, put, etag)
+			}})
+		}
+	}
+
+	for _, profile := range manifest.Profiles {
+		project := projectName(profile.Project)
+		want("profile", project, profile.Name)
+
+		err := checkProject(project)
+		if err != nil {
+			return nil, err
+		}
+
+		current, etag, err := server(project).GetProfile(profile.Name)
+		if err != nil {
+			if !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil, err
+			}
+
+			// The default profile is created along with the project.
+			if profile.Name == "default" {
+				changes = append(changes, applyChange{action: "update", kind: "profile", project: project, name: profile.Name, run: func() error {
+					return server(project).UpdateProfile(profile.Name, profile.ProfilePut, "")
+				}})
+
+				continue
+			}
+
+			changes = append(changes, applyChange{action: "create", kind: "profile", project: project, name: profile.Name, run: func() error {
+				return server(project).CreateProfile(profile.ProfilesPost)
+			}})
+
+			continue
+		}
+
+		put := current.Writable()
+		details := applyUpdate(&put.Config, profile.Config, &put.Description, profile.Description, profile.descriptionSet)
+		if profile.Devices != nil && !applyEqual(put.Devices, profile.Devices) {
+			put.Devices = profile.Devices
+			details = append(details, "devices")
+		}
+
+		if len(details) > 0 {
+			changes = append(changes, applyChange{action: "update", kind: "profile", project: project, name: profile.Name, details: details, run: func() error {
+				return server(project).UpdateProfile(profile.Name, put, etag)
+			}})
+		}
+	}
+
+	for _, inst := range manifest.Instances {
+		project := projectName(inst.Project)
+		want("instance", project, inst.Name)
+
+		err := checkProject(project)
+		if err != nil {
+			return nil, err
+		}
+
+		current, etag, err := server(project).GetInstance(inst.Name)
+		if err != nil {
+			if !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil, err
+			}
+
+			changes = append(changes, applyChange{action: "create", kind: "instance", project: project, name: inst.Name, run: func() error {
+				return c.createInstance(resource, server(project), inst)
+			}})
+
+			continue
+		}
+
+		put := current.Writable()
+		details := applyUpdate(&put.Config, inst.Config, &put.Description, inst.Description, inst.descriptionSet)
+		if inst.Profiles != nil && !applyEqual(put.Profiles, inst.Profiles) {
+			put.Profiles = inst.Profiles
+			details = append(details, "profiles")
+		}
+
+		if inst.Devices != nil && !applyEqual(put.Devices, inst.Devices) {
+			put.Devices = inst.Devices
+			details = append(details, "devices")
+		}
+
+		if len(details) > 0 {
+			changes = append(changes, applyChange{action: "update", kind: "instance", project: project, name: inst.Name, details: details, run: func() error {
+				op, err := server(project).UpdateInstance(inst.Name, put, etag)
+				if err != nil {
+					return err
+				}
+
+				return op.Wait()
+			}})
+		}
+	}
+
+	if c.flagPrune {
+		deletions, err := c.planPrune(server, projects, wanted)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, deletions...)
+	}
+
+	return changes, nil
+}
+
+// planPrune computes the deletions of the resources that aren't in the manifest from the existing projects that it
+// refers to. Only the resources that are specific to each project are considered.
+func (c *cmdApply) planPrune(server func(project string) lxd.InstanceServer, projects map[string]*api.Project, wanted map[string]map[string]bool) ([]applyChange, error) {
+	names := make([]string, 0, len(projects))
+	for name, project := range projects {
+		if project != nil {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	// Whether the project has its own set of resources of a kind, rather than using those of the default project.
+	hasFeature := func(project string, feature string) bool {
+		return project == api.ProjectDefaultName || shared.IsTrue(projects[project].Config[feature])
+	}
+
+	deletions := []applyChange{}
+
+	for _, project := range names {
+		instances, err := server(project).GetInstances(api.InstanceTypeAny)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, inst := range instances {
+			if wanted["instance"][project+"/"+inst.Name] {
+				continue
+			}
+
+			deletions = append(deletions, applyChange{action: "delete", kind: "instance", project: project, name: inst.Name, run: func() error {
+				return applyDeleteInstance(server(project), inst.Name)
+			}})
+		}
+	}
+
+	for _, project := range names {
+		if !hasFeature(project, "features.profiles") {
+			continue
+		}
+
+		profiles, err := server(project).GetProfiles()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, profile := range profiles {
+			if profile.Name == "default" || wanted["profile"][project+"/"+profile.Name] {
+				continue
+			}
+
+			deletions = append(deletions, applyChange{action: "delete", kind: "profile", project: project, name: profile.Name, run: func() error {
+				return server(project).DeleteProfile(profile.Name)
+			}})
+		}
+	}
+
+	for _, project := range names {
+		if !hasFeature(project, "features.storage.volumes") {
+			continue
+		}
+
+		pools, err := server(project).GetStoragePools()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pool := range pools {
+			volumes, err := server(project).GetStoragePoolVolumes(pool.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, vol := range volumes {
+				if vol.Type != "custom" || shared.IsSnapshot(vol.Name) || wanted["storage volume"][project+"/"+pool.Name+"/"+vol.Name] {
+					continue
+				}
+
+				deletions = append(deletions, applyChange{action: "delete", kind: "storage volume", project: project, name: pool.Name + "/" + vol.Name, run: func() error {
+					return server(project).DeleteStoragePoolVolume(pool.Name, "custom", vol.Name)
+				}})
+			}
+		}
+	}
+
+	for _, project := range names {
+		if !hasFeature(project, "features.networks") {
+			continue
+		}
+
+		networks, err := server(project).GetNetworks()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, network := range networks {
+			if !network.Managed || wanted["network"][project+"/"+network.Name] {
+				continue
+			}
+
+			deletions = append(deletions, applyChange{action: "delete", kind: "network", project: project, name: network.Name, run: func() error {
+				return server(project).DeleteNetwork(network.Name)
+			}})
+		}
+
+		acls, err := server(project).GetNetworkACLs()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, acl := range acls {
+			if wanted["network ACL"][project+"/"+acl.Name] {
+				continue
+			}
+
+			deletions = append(deletions, applyChange{action: "delete", kind: "network ACL", project: project, name: acl.Name, run: func() error {
+				return server(project).DeleteNetworkACL(acl.Name)
+			}})
+		}
+	}
+
+	return deletions, nil
+}
+
+// createInstance creates an instance of the manifest, from an image if one is specified, and starts it if requested.
+func (c *cmdApply) createInstance(resource remoteResource, server lxd.InstanceServer, inst applyInstance) error {
+	req := api.InstancesPost{
+		Name: inst.Name,
+		Type: api.InstanceType(inst.Type),
+		InstancePut: api.InstancePut{
+			Description: inst.Description,
+			Profiles:    inst.Profiles,
+			Config:      inst.Config,
+			Devices:     inst.Devices,
+		},
+	}
+
+	if inst.Image == "" {
+		req.Source.Type = "none"
+
+		op, err := server.CreateInstance(req)
+		if err != nil {
+			return err
+		}
+
+		err = op.Wait()
+		if err != nil {
+			return err
+		}
+	} else {
+		imgRemote, image, err := c.global.conf.ParseRemote(inst.Image)
+		if err != nil {
+			return err
+		}
+
+		imgServer, imgInfo, err := getImgInfo(server, c.global.conf, imgRemote, resource.remote, image, &req.Source)
+		if err != nil {
+			return err
+		}
+
+		op, err := server.CreateInstanceFromImage(imgServer, *imgInfo, req)
+		if err != nil {
+			return err
+		}
+
+		err = op.Wait()
+		if err != nil {
+			return err
+		}
+	}
+
+	if !inst.Start {
+		return nil
+	}
+
+	startOp, err := server.UpdateInstanceState(inst.Name, api.InstanceStatePut{Action: "start", Timeout: -1}, "")
+	if err != nil {
+		return err
+	}
+
+	return startOp.Wait()
+}
+
+// applyDeleteInstance stops an instance if it's running and deletes it.
+func applyDeleteInstance(server lxd.InstanceServer, name string) error {
+	inst, _, err := server.GetInstance(name)
+	if err != nil {
+		return err
+	}
+
+	if inst.IsActive() {
+		op, err := server.UpdateInstanceState(name, api.InstanceStatePut{Action: "stop", Timeout: -1, Force: true}, "")
+		if err != nil {
+			return err
+		}
+
+		err = op.Wait()
+		if err != nil {
+			return err
+		}
+	}
+
+	op, err := server.DeleteInstance(name)
+	if err != nil {
+		return err
+	}
+
+	return op.Wait()
+}
+
+// applyUpdate applies the desired configuration keys and description to the current ones, and returns the
+// descriptions of the changes. Keys that aren't in the desired configuration are left as is, while keys with an
+// empty value are unset. The description is only updated if descriptionSet is true.
+func applyUpdate(config *map[string]string, desired map[string]string, description *string, desiredDescription string, descriptionSet bool) []string {
+	details := []string{}
+
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := desired[key]
+		if (*config)[key] == value {
+			continue
+		}
+
+		if *config == nil {
+			*config = map[string]string{}
+		}
+
+		if value == "" {
+			delete(*config, key)
+		} else {
+			(*config)[key] = value
+		}
+
+		details = append(details, key)
+	}
+
+	if descriptionSet && *description != desiredDescription {
+		*description = desiredDescription
+		details = append(details, "description")
+	}
+
+	return details
+}
+
+// applyEqual returns whether the current and desired values are equal, treating nil and empty values the same.
+func applyEqual(current any, desired any) bool {
+	if reflect.ValueOf(current).Len() == 0 && reflect.ValueOf(desired).Len() == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(current, desired)
+}
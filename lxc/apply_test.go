@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestApplyUpdate(t *testing.T) {
+	config := map[string]string{
+		"limits.cpu":           "2",
+		"limits.memory":        "1GiB",
+		"volatile.eth0.hwaddr": "00:16:3e:00:00:01",
+	}
+
+	description := "web"
+
+	// Only the keys of the desired config are managed, empty values unset keys.
+	details := applyUpdate(&config, map[string]string{"limits.cpu": "4", "limits.memory": "", "boot.autostart": "true"}, &description, "web server", true)
+	assert.Equal(t, []string{"boot.autostart", "limits.cpu", "limits.memory", "description"}, details)
+	assert.Equal(t, map[string]string{
+		"boot.autostart":       "true",
+		"limits.cpu":           "4",
+		"volatile.eth0.hwaddr": "00:16:3e:00:00:01",
+	}, config)
+	assert.Equal(t, "web server", description)
+
+	// Applying the same state again doesn't change anything.
+	details = applyUpdate(&config, map[string]string{"limits.cpu": "4", "limits.memory": "", "boot.autostart": "true"}, &description, "web server", true)
+	assert.Empty(t, details)
+
+	// The description is left as is if the manifest doesn't set it.
+	details = applyUpdate(&config, map[string]string{}, &description, "", false)
+	assert.Empty(t, details)
+	assert.Equal(t, "web server", description)
+
+	// A nil config is initialized when keys are set.
+	var emptyConfig map[string]string
+	details = applyUpdate(&emptyConfig, map[string]string{"user.foo": "bar"}, &description, "web server", true)
+	assert.Equal(t, []string{"user.foo"}, details)
+	assert.Equal(t, map[string]string{"user.foo": "bar"}, emptyConfig)
+}
+
+func TestApplyEqual(t *testing.T) {
+	assert.True(t, applyEqual([]string{}, []string(nil)))
+	assert.True(t, applyEqual(map[string]map[string]string(nil), map[string]map[string]string{}))
+	assert.True(t, applyEqual([]string{"default", "web"}, []string{"default", "web"}))
+	assert.False(t, applyEqual([]string{"default", "web"}, []string{"web", "default"}))
+	assert.False(t, applyEqual(map[string]map[string]string{"eth0": {"type": "nic"}}, map[string]map[string]string{}))
+}
+
+func TestApplyManifestParse(t *testing.T) {
+	contents := `
+projects:
+- name: team1
+  config:
+    features.profiles: "true"
+profiles:
+- name: web
+  project: team1
+  devices:
+    eth0:
+      type: nic
+      network: lxdbr0
+instances:
+- name: web1
+  project: team1
+  description: ""
+  image: ubuntu:24.04
+  profiles: [default, web]
+  start: true
+`
+
+	manifest := applyManifest{}
+	err := yaml.UnmarshalStrict([]byte(contents), &manifest)
+	assert.NoError(t, err)
+	assert.Equal(t, []applyProject{{ProjectsPost: api.ProjectsPost{Name: "team1", ProjectPut: api.ProjectPut{Config: map[string]string{"features.profiles": "true"}}}}}, manifest.Projects)
+	assert.Equal(t, "team1", manifest.Profiles[0].Project)
+	assert.Equal(t, "lxdbr0", manifest.Profiles[0].Devices["eth0"]["network"])
+	assert.Equal(t, []string{"default", "web"}, manifest.Instances[0].Profiles)
+	assert.True(t, manifest.Instances[0].Start)
+
+	// Descriptions are only managed when set, even to an empty value.
+	assert.False(t, manifest.Profiles[0].descriptionSet)
+	assert.True(t, manifest.Instances[0].descriptionSet)
+
+	// Unknown fields are rejected.
+	err = yaml.UnmarshalStrict([]byte("instances:\n- name: web1\n  imag: ubuntu:24.04\n"), &manifest)
+	assert.Error(t, err)
+}
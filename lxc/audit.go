@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/client"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+)
+
+type cmdAudit struct {
+	global *cmdGlobal
+
+	flagSince    string
+	flagUntil    string
+	flagIdentity string
+	flagEntity   string
+	flagTarget   string
+	flagFormat   string
+}
+
+func (c *cmdAudit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("audit", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("Show the audit log")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show the audit log
+
+The audit log records the mutating API requests handled by a server.
+In a cluster, each member records the requests it handled; use --target to read the log of another member.
+
+The --since and --until flags take either an RFC3339 timestamp or a duration relative to now (e.g. 2h or 7d).`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc audit --since 24h
+    Show the requests handled in the last day.
+
+lxc audit --identity foo@example.com --entity /1.0/instances/c1
+    Show the requests made by foo@example.com on instance c1 in the default project.`))
+
+	cmd.Flags().StringVar(&c.flagSince, "since", "", i18n.G("Only show entries recorded since the given time or duration")+"``")
+	cmd.Flags().StringVar(&c.flagUntil, "until", "", i18n.G("Only show entries recorded until the given time or duration")+"``")
+	cmd.Flags().StringVar(&c.flagIdentity, "identity", "", i18n.G("Only show entries for requests made by the given identity")+"``")
+	cmd.Flags().StringVar(&c.flagEntity, "entity", "", i18n.G("Only show entries for requests acting on the given entity URL")+"``")
+	cmd.Flags().StringVar(&c.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+// parseAuditTime parses either an RFC3339 timestamp or a duration (with an additional "d" unit for days) relative to
+// the given time.
+func parseAuditTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+
+	days, ok := strings.CutSuffix(value, "d")
+	if ok {
+		n, err := strconv.Atoi(days)
+		if err == nil {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(i18n.G("Invalid time %q, must be an RFC3339 timestamp or a duration"), value)
+	}
+
+	return now.Add(-duration), nil
+}
+
+func (c *cmdAudit) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	remoteName, _, err := c.global.conf.ParseRemote(remote)
+	if err != nil {
+		return err
+	}
+
+	d, err := c.global.conf.GetInstanceServer(remoteName)
+	if err != nil {
+		return err
+	}
+
+	// Targeting
+	if c.flagTarget != "" {
+		if !d.IsClustered() {
+			return fmt.Errorf(i18n.G("To use --target, the destination remote must be a cluster"))
+		}
+
+		d = d.UseTarget(c.flagTarget)
+	}
+
+	now := time.Now()
+	auditArgs := lxd.GetAuditLogArgs{
+		Identity:  c.flagIdentity,
+		EntityURL: c.flagEntity,
+	}
+
+	auditArgs.Since, err = parseAuditTime(c.flagSince, now)
+	if err != nil {
+		return err
+	}
+
+	auditArgs.Until, err = parseAuditTime(c.flagUntil, now)
+	if err != nil {
+		return err
+	}
+
+	entries, err := d.GetAuditLog(&auditArgs)
+	if err != nil {
+		return err
+	}
+
+	// Render the table, keeping the entries in the order they were recorded.
+	data := [][]string{}
+	for _, entry := range entries {
+		data = append(data, []string{
+			entry.Date.Local().Format(time.DateTime),
+			entry.AuthenticationMethod,
+			entry.Identity,
+			entry.SourceAddress,
+			entry.Method,
+			entry.URL,
+			strconv.Itoa(entry.StatusCode),
+		})
+	}
+
+	header := []string{
+		i18n.G("DATE"),
+		i18n.G("AUTHENTICATION METHOD"),
+		i18n.G("IDENTITY"),
+		i18n.G("SOURCE"),
+		i18n.G("METHOD"),
+		i18n.G("URL"),
+		i18n.G("STATUS"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, entries)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuditTime(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	parsed, err := parseAuditTime("", now)
+	require.NoError(t, err)
+	assert.True(t, parsed.IsZero())
+
+	parsed, err = parseAuditTime("2024-06-01T00:00:00Z", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), parsed)
+
+	parsed, err = parseAuditTime("2h", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-2*time.Hour), parsed)
+
+	parsed, err = parseAuditTime("7d", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -7), parsed)
+
+	_, err = parseAuditTime("yesterday", now)
+	assert.Error(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
@@ -746,6 +747,12 @@ func (c *cmdIdentity) command() *cobra.Command {
 	identityGroupCmd := cmdIdentityGroup{global: c.global}
 	cmd.AddCommand(identityGroupCmd.command())
 
+	identityTokenCmd := cmdIdentityToken{global: c.global}
+	cmd.AddCommand(identityTokenCmd.command())
+
+	identityCheckCmd := cmdIdentityCheck{global: c.global}
+	cmd.AddCommand(identityCheckCmd.command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
@@ -755,6 +762,7 @@ func (c *cmdIdentity) command() *cobra.Command {
 type cmdIdentityList struct {
 	global     *cmdGlobal
 	flagFormat string
+	flagList   listFlags
 }
 
 func (c *cmdIdentityList) command() *cobra.Command {
@@ -767,6 +775,7 @@ func (c *cmdIdentityList) command() *cobra.Command {
 
 	cmd.RunE = c.run
 	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	c.flagList.add(cmd)
 
 	return cmd
 }
@@ -792,7 +801,18 @@ func (c *cmdIdentityList) run(cmd *cobra.Command, args []string) error {
 	resource := resources[0]
 
 	// List identities
-	identities, err := resource.server.GetIdentities()
+	listArgs, err := c.flagList.listArgs()
+	if err != nil {
+		return err
+	}
+
+	var identities []api.Identity
+	if listArgs != nil {
+		identities, err = resource.server.GetIdentitiesWithArgs(listArgs)
+	} else {
+		identities, err = resource.server.GetIdentities()
+	}
+
 	if err != nil {
 		return err
 	}
@@ -1222,6 +1242,296 @@ func (c *cmdIdentityGroupRemove) run(cmd *cobra.Command, args []string) error {
 	return resource.server.UpdateIdentity(authenticationMethod, nameOrID, identity.Writable(), eTag)
 }
 
+type cmdIdentityCheck struct {
+	global     *cmdGlobal
+	flagFormat string
+}
+
+func (c *cmdIdentityCheck) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("check", i18n.G("[<remote>:]<authentication_method>/<name_or_identifier> [<entity_type> [<entity_name>] <entitlement> [<key>=<value>...]]"))
+	cmd.Short = i18n.G("Check the permissions of an identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Check the permissions of an identity
+
+If only an identity is given, its effective permissions are listed.
+Otherwise, check whether the identity has the entitlement on the entity and show the groups and permissions granting it.
+Use "current" as the identity to check the caller.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth identity check oidc/jane.doe@example.com
+    List the effective permissions of an OIDC identity.
+
+lxc auth identity check oidc/jane.doe@example.com instance c1 can_exec project=default
+    Check whether an OIDC identity can execute commands in instance c1 of the default project.
+
+lxc auth identity check current project default can_create_instances
+    Check whether the caller can create instances in the default project.`))
+
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityCheck) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	if len(args) == 2 {
+		return fmt.Errorf(i18n.G("Missing entitlement argument"))
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing identity argument"))
+	}
+
+	authenticationMethod, nameOrID := "", ""
+	if resource.name != "current" {
+		var ok bool
+		authenticationMethod, nameOrID, ok = strings.Cut(resource.name, "/")
+		if !ok {
+			return fmt.Errorf("Malformed argument, expected `[<remote>:]<authentication_method>/<name_or_identifier>`, got %q", args[0])
+		}
+	}
+
+	// List the effective permissions.
+	if len(args) == 1 {
+		var permissions []api.Permission
+		if authenticationMethod == "" {
+			info, _, err := resource.server.GetCurrentIdentityInfo()
+			if err != nil {
+				return err
+			}
+
+			permissions = info.EffectivePermissions
+		} else {
+			permissions, err = resource.server.GetIdentityEffectivePermissions(authenticationMethod, nameOrID)
+			if err != nil {
+				return err
+			}
+		}
+
+		data := [][]string{}
+		for _, permission := range permissions {
+			data = append(data, []string{permission.EntityType, permission.EntityReference, permission.Entitlement})
+		}
+
+		sort.Sort(cli.SortColumnsNaturally(data))
+
+		header := []string{
+			i18n.G("ENTITY TYPE"),
+			i18n.G("URL"),
+			i18n.G("ENTITLEMENT"),
+		}
+
+		return cli.RenderTable(c.flagFormat, header, data, permissions)
+	}
+
+	// Check a single entitlement.
+	permission, err := parsePermissionArgs(args)
+	if err != nil {
+		return err
+	}
+
+	result, err := resource.server.CheckPermission(api.AuthCheckPost{
+		AuthenticationMethod: authenticationMethod,
+		Identity:             nameOrID,
+		EntityReference:      permission.EntityReference,
+		Entitlement:          permission.Entitlement,
+	})
+	if err != nil {
+		return err
+	}
+
+	if shared.ValueInSlice(c.flagFormat, []string{cli.TableFormatJSON, cli.TableFormatYAML}) {
+		return cli.RenderTable(c.flagFormat, nil, nil, result)
+	}
+
+	if !result.Allowed {
+		return fmt.Errorf(i18n.G("Denied: %q does not have entitlement %q on %q"), resource.name, permission.Entitlement, permission.EntityReference)
+	}
+
+	fmt.Printf(i18n.G("Allowed: %q has entitlement %q on %q")+"\n", resource.name, permission.Entitlement, permission.EntityReference)
+	if len(result.Groups) > 0 {
+		fmt.Printf(i18n.G("Granted by groups: %s")+"\n", strings.Join(result.Groups, ", "))
+	}
+
+	for _, granting := range result.Permissions {
+		fmt.Printf("  - %s %s\n", granting.Entitlement, granting.EntityReference)
+	}
+
+	return nil
+}
+
+type cmdIdentityToken struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityToken) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("token")
+	cmd.Short = i18n.G("Manage bearer token identities")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage bearer token identities`))
+
+	identityTokenCreateCmd := cmdIdentityTokenCreate{global: c.global}
+	cmd.AddCommand(identityTokenCreateCmd.command())
+
+	identityTokenDeleteCmd := cmdIdentityTokenDelete{global: c.global}
+	cmd.AddCommand(identityTokenDeleteCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+type cmdIdentityTokenCreate struct {
+	global     *cmdGlobal
+	flagExpiry string
+	flagCIDRs  []string
+	flagGroups []string
+	flagFormat string
+}
+
+func (c *cmdIdentityTokenCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<name>"))
+	cmd.Short = i18n.G("Create a bearer token identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Create a bearer token identity
+
+The token is only displayed once and cannot be retrieved again.
+Clients authenticate by sending it in the "Authorization: Bearer <token>" header.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc auth identity token create ci --expiry 30d --group ci-deployers --cidr 10.0.0.0/8
+    Create a token valid for 30 days, usable from 10.0.0.0/8, with the permissions of the "ci-deployers" group.`))
+
+	cmd.Flags().StringVar(&c.flagExpiry, "expiry", "", i18n.G("Time until the token expires (e.g. 30d, 12H)")+"``")
+	cmd.Flags().StringSliceVar(&c.flagCIDRs, "cidr", nil, i18n.G("Source subnet the token may be used from (can be repeated)")+"``")
+	cmd.Flags().StringSliceVar(&c.flagGroups, "group", nil, i18n.G("Group the token identity is a member of (can be repeated)")+"``")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "", i18n.G("Format (json|yaml)")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityTokenCreate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	if c.flagExpiry == "" {
+		return fmt.Errorf(i18n.G("An expiry must be set with --expiry"))
+	}
+
+	expiresAt, err := shared.GetExpiry(time.Now(), c.flagExpiry)
+	if err != nil {
+		return fmt.Errorf(i18n.G("Invalid expiry: %w"), err)
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing token name"))
+	}
+
+	token, err := resource.server.CreateIdentityBearerToken(api.IdentityBearerTokenPost{
+		Name:         resource.name,
+		Groups:       c.flagGroups,
+		ExpiresAt:    expiresAt,
+		AllowedCIDRs: c.flagCIDRs,
+	})
+	if err != nil {
+		return err
+	}
+
+	if shared.ValueInSlice(c.flagFormat, []string{cli.TableFormatJSON, cli.TableFormatYAML}) {
+		return cli.RenderTable(c.flagFormat, nil, nil, token)
+	} else if c.flagFormat != "" {
+		return fmt.Errorf(i18n.G("Invalid format %q"), c.flagFormat)
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Bearer token %s created (expires %s):")+"\n", token.Identifier, token.ExpiresAt.Local().Format("2006/01/02 15:04 MST"))
+	}
+
+	fmt.Println(token.Token)
+
+	return nil
+}
+
+type cmdIdentityTokenDelete struct {
+	global *cmdGlobal
+}
+
+func (c *cmdIdentityTokenDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<name_or_identifier>"))
+	cmd.Aliases = []string{"rm", "revoke"}
+	cmd.Short = i18n.G("Delete a bearer token identity")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Delete a bearer token identity
+
+The token can no longer be used once deleted.`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdIdentityTokenDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing token name"))
+	}
+
+	err = resource.server.DeleteIdentity(api.AuthenticationMethodBearer, resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Bearer token %s deleted")+"\n", resource.name)
+	}
+
+	return nil
+}
+
 type cmdPermission struct {
 	global *cmdGlobal
 }
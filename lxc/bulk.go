@@ -0,0 +1,206 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+)
+
+type cmdBulk struct {
+	global *cmdGlobal
+
+	flagConfig   []string
+	flagName     string
+	flagStateful bool
+	flagForce    bool
+	flagTimeout  int
+	flagFormat   string
+}
+
+func (c *cmdBulk) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("bulk", i18n.G("<action> [<remote>:] <key>=<value>..."))
+	cmd.Short = i18n.G("Apply an action to all the instances matching filters")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Apply an action to all the instances matching filters
+
+The action is one of start, stop, restart, snapshot, delete or patch.
+The filters are key=value pairs matching the fields of the instances, like the server side filters of "lxc list".
+An instance must match all the filters.
+
+The action runs as a single operation and the result for each instance is shown once it completes.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc bulk stop status=Running config.user.tier=web
+    Stop all the running instances with the user.tier configuration key set to "web".
+
+lxc bulk snapshot type=virtual-machine --name before-upgrade
+    Snapshot all the virtual machines.
+
+lxc bulk patch config.user.tier=web -c limits.cpu=2 -c limits.memory=
+    Set limits.cpu and unset limits.memory on the instances with user.tier set to "web".`))
+
+	cmd.Flags().StringArrayVarP(&c.flagConfig, "config", "c", nil, i18n.G("Config key/value to set with the patch action, an empty value unsets the key")+"``")
+	cmd.Flags().StringVar(&c.flagName, "name", "", i18n.G("Snapshot name")+"``")
+	cmd.Flags().BoolVar(&c.flagStateful, "stateful", false, i18n.G("Start, stop or snapshot the instances statefully"))
+	cmd.Flags().BoolVarP(&c.flagForce, "force", "f", false, i18n.G("Force the instances to stop or restart"))
+	cmd.Flags().IntVar(&c.flagTimeout, "timeout", -1, i18n.G("Time to wait for the instances to shutdown cleanly")+"``")
+	cmd.Flags().StringVar(&c.flagFormat, "format", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+// bulkFilter returns the filter expression matching all the key=value pairs.
+func bulkFilter(filters []string) (string, error) {
+	clauses := make([]string, 0, len(filters))
+	for _, filter := range filters {
+		key, value, found := strings.Cut(filter, "=")
+		if !found || key == "" {
+			return "", fmt.Errorf(i18n.G("Invalid filter %q, expected key=value"), filter)
+		}
+
+		if strings.ContainsAny(value, " \t") {
+			value = fmt.Sprintf("%q", value)
+		}
+
+		clauses = append(clauses, fmt.Sprintf("%s eq %s", key, value))
+	}
+
+	return strings.Join(clauses, " and "), nil
+}
+
+func (c *cmdBulk) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	action := args[0]
+	filters := args[1:]
+
+	// Parse remote
+	remote := ""
+	if !strings.Contains(filters[0], "=") {
+		remote = filters[0]
+		filters = filters[1:]
+	}
+
+	if len(filters) == 0 {
+		return fmt.Errorf(i18n.G("At least one filter is required"))
+	}
+
+	remoteName, _, err := c.global.conf.ParseRemote(remote)
+	if err != nil {
+		return err
+	}
+
+	d, err := c.global.conf.GetInstanceServer(remoteName)
+	if err != nil {
+		return err
+	}
+
+	req := api.BulkPost{Action: action}
+	req.Filter, err = bulkFilter(filters)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "start", "stop", "restart":
+		req.State = &api.InstanceStatePut{
+			Action:   action,
+			Timeout:  c.flagTimeout,
+			Force:    c.flagForce,
+			Stateful: c.flagStateful,
+		}
+
+	case "snapshot":
+		req.Snapshot = &api.InstanceSnapshotsPost{
+			Name:     c.flagName,
+			Stateful: c.flagStateful,
+		}
+
+	case "patch":
+		if len(c.flagConfig) == 0 {
+			return fmt.Errorf(i18n.G("No configuration to apply, use --config"))
+		}
+
+		req.Config = map[string]string{}
+		for _, entry := range c.flagConfig {
+			key, value, found := strings.Cut(entry, "=")
+			if !found {
+				return fmt.Errorf(i18n.G("Bad key=value pair: %q"), entry)
+			}
+
+			req.Config[key] = value
+		}
+
+	case "delete":
+	default:
+		return fmt.Errorf(i18n.G("Unknown action %q"), action)
+	}
+
+	op, err := d.ApplyBulkAction(req)
+	if err != nil {
+		return err
+	}
+
+	progress := cli.ProgressRenderer{
+		Quiet: c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	// The results are shown even if the action failed for some of the instances.
+	opErr := cli.CancelableWait(op, &progress)
+	progress.Done("")
+
+	var metadata struct {
+		Results []api.BulkResult `json:"results"`
+	}
+
+	data, err := json.Marshal(op.Get().Metadata)
+	if err == nil {
+		err = json.Unmarshal(data, &metadata)
+	}
+
+	if err != nil || metadata.Results == nil {
+		return opErr
+	}
+
+	rows := [][]string{}
+	for _, result := range metadata.Results {
+		status := i18n.G("OK")
+		if result.Error != "" {
+			status = result.Error
+		}
+
+		rows = append(rows, []string{result.Name, result.Project, result.Location, status})
+	}
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("PROJECT"),
+		i18n.G("LOCATION"),
+		i18n.G("RESULT"),
+	}
+
+	err = cli.RenderTable(c.flagFormat, header, rows, metadata.Results)
+	if err != nil {
+		return err
+	}
+
+	return opErr
+}
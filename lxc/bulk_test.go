@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkFilter(t *testing.T) {
+	expr, err := bulkFilter([]string{"status=Running"})
+	require.NoError(t, err)
+	assert.Equal(t, "status eq Running", expr)
+
+	expr, err = bulkFilter([]string{"status=Running", "config.user.tier=web", "description=web server"})
+	require.NoError(t, err)
+	assert.Equal(t, `status eq Running and config.user.tier eq web and description eq "web server"`, expr)
+
+	_, err = bulkFilter([]string{"Running"})
+	assert.Error(t, err)
+
+	_, err = bulkFilter([]string{"=Running"})
+	assert.Error(t, err)
+}
@@ -93,6 +93,14 @@ func (c *cmdCluster) command() *cobra.Command {
 	cmdClusterRestore := cmdClusterRestore{global: c.global, cluster: c}
 	cmd.AddCommand(cmdClusterRestore.command())
 
+	// Rebalance cluster instances
+	cmdClusterRebalance := cmdClusterRebalance{global: c.global, cluster: c}
+	cmd.AddCommand(cmdClusterRebalance.command())
+
+	// Rolling upgrades of cluster members
+	clusterUpgradeCmd := cmdClusterUpgrade{global: c.global, cluster: c}
+	cmd.AddCommand(clusterUpgradeCmd.command())
+
 	clusterGroupCmd := cmdClusterGroup{global: c.global, cluster: c}
 	cmd.AddCommand(clusterGroupCmd.command())
 
@@ -1269,3 +1277,101 @@ func (c *cmdClusterEvacuateAction) run(cmd *cobra.Command, args []string) error
 	progress.Done("")
 	return nil
 }
+
+// Cluster rebalancing.
+type cmdClusterRebalance struct {
+	global  *cmdGlobal
+	cluster *cmdCluster
+
+	flagDryRun bool
+	flagFormat string
+}
+
+func (c *cmdClusterRebalance) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("rebalance", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("Move instances to reduce the load imbalance between cluster members")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`Move instances to reduce the load imbalance between cluster members
+
+Only instances with cluster.rebalance set are moved. Use --dry-run to list the planned moves without performing them.`))
+
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, i18n.G("Only show the planned moves"))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdClusterRebalance) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) == 1 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if c.flagDryRun {
+		plan, err := resource.server.GetClusterRebalance()
+		if err != nil {
+			return err
+		}
+
+		data := [][]string{}
+		for _, move := range plan.Moves {
+			live := i18n.G("NO")
+			if move.Live {
+				live = i18n.G("YES")
+			}
+
+			data = append(data, []string{move.Name, move.Project, move.Source, move.Target, live})
+		}
+
+		header := []string{
+			i18n.G("NAME"),
+			i18n.G("PROJECT"),
+			i18n.G("SOURCE"),
+			i18n.G("TARGET"),
+			i18n.G("LIVE"),
+		}
+
+		return cli.RenderTable(c.flagFormat, header, data, plan)
+	}
+
+	op, err := resource.server.RebalanceCluster()
+	if err != nil {
+		return err
+	}
+
+	progress := cli.ProgressRenderer{
+		Format: i18n.G("Rebalancing cluster: %s"),
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = op.Wait()
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+	return nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+)
+
+type cmdClusterUpgrade struct {
+	global  *cmdGlobal
+	cluster *cmdCluster
+}
+
+// Rolling upgrades of the cluster members, with subcommands to start, show, pause, resume and abort them.
+func (c *cmdClusterUpgrade) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("upgrade")
+	cmd.Short = i18n.G("Manage rolling upgrades of the cluster members")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage rolling upgrades of the cluster members
+
+During a rolling upgrade, the cluster members are evacuated one at a time. Once an evacuated member
+runs a new version of LXD, its instances are restored and the next member is evacuated.`))
+
+	// Start
+	clusterUpgradeStartCmd := cmdClusterUpgradeStart{global: c.global, cluster: c.cluster}
+	cmd.AddCommand(clusterUpgradeStartCmd.command())
+
+	// Show
+	clusterUpgradeShowCmd := cmdClusterUpgradeShow{global: c.global, cluster: c.cluster}
+	cmd.AddCommand(clusterUpgradeShowCmd.command())
+
+	// Pause
+	clusterUpgradePauseCmd := cmdClusterUpgradeAction{global: c.global, cluster: c.cluster, action: "pause"}
+	cmd.AddCommand(clusterUpgradePauseCmd.command())
+
+	// Resume
+	clusterUpgradeResumeCmd := cmdClusterUpgradeAction{global: c.global, cluster: c.cluster, action: "resume"}
+	cmd.AddCommand(clusterUpgradeResumeCmd.command())
+
+	// Abort
+	clusterUpgradeAbortCmd := cmdClusterUpgradeAction{global: c.global, cluster: c.cluster, action: "abort"}
+	cmd.AddCommand(clusterUpgradeAbortCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+type cmdClusterUpgradeStart struct {
+	global  *cmdGlobal
+	cluster *cmdCluster
+
+	flagMembers string
+}
+
+// Starting a rolling upgrade, optionally restricted to a list of members in the given order.
+func (c *cmdClusterUpgradeStart) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("start", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("Start a rolling upgrade of the cluster members")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Start a rolling upgrade of the cluster members
+
+By default, all members are upgraded, starting with the members not taking part in the database quorum
+and ending with the database leader.`))
+
+	cmd.Flags().StringVar(&c.flagMembers, "members", "", i18n.G("Comma separated list of cluster members to upgrade, in order")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdClusterUpgradeStart) run(cmd *cobra.Command, args []string) error {
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	remote := ""
+	if len(args) == 1 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	req := api.ClusterUpgradePost{Action: "start"}
+	if c.flagMembers != "" {
+		req.Members = shared.SplitNTrimSpace(c.flagMembers, ",", -1, false)
+	}
+
+	return resource.server.UpdateClusterUpgrade(req)
+}
+
+type cmdClusterUpgradeShow struct {
+	global  *cmdGlobal
+	cluster *cmdCluster
+}
+
+// Showing the status of the latest rolling upgrade.
+func (c *cmdClusterUpgradeShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]"))
+	cmd.Short = i18n.G("Show the status of the rolling upgrade")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show the status of the rolling upgrade`))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdClusterUpgradeShow) run(cmd *cobra.Command, args []string) error {
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	remote := ""
+	if len(args) == 1 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	upgrade, err := resource.server.GetClusterUpgrade()
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(upgrade)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+type cmdClusterUpgradeAction struct {
+	global  *cmdGlobal
+	cluster *cmdCluster
+
+	action string
+}
+
+// Pausing, resuming or aborting the rolling upgrade depending on the action.
+func (c *cmdClusterUpgradeAction) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage(c.action, i18n.G("[<remote>:]"))
+
+	switch c.action {
+	case "pause":
+		cmd.Short = i18n.G("Pause the rolling upgrade")
+		cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+			`Pause the rolling upgrade
+
+The member being processed is left in its current state until the upgrade is resumed.`))
+	case "resume":
+		cmd.Short = i18n.G("Resume the rolling upgrade")
+		cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+			`Resume the rolling upgrade`))
+	case "abort":
+		cmd.Short = i18n.G("Abort the rolling upgrade")
+		cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+			`Abort the rolling upgrade
+
+Evacuated members are left evacuated and must be restored with "lxc cluster restore".`))
+	}
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdClusterUpgradeAction) run(cmd *cobra.Command, args []string) error {
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	remote := ""
+	if len(args) == 1 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	return resource.server.UpdateClusterUpgrade(api.ClusterUpgradePost{Action: c.action})
+}
@@ -74,7 +74,7 @@ func (c *Config) getPrivateRemoteByName(name string) (*Remote, error) {
 	}
 
 	// Check the remote is private.
-	if remote.Public || remote.Protocol == "simplestreams" {
+	if remote.Public || shared.ValueInSlice(remote.Protocol, []string{"simplestreams", "oci"}) {
 		return nil, fmt.Errorf("The remote isn't a private LXD server")
 	}
 
@@ -212,6 +212,16 @@ func (c *Config) GetImageServer(name string) (lxd.ImageServer, error) {
 		return d, nil
 	}
 
+	// OCI image store
+	if remote.Protocol == "oci" {
+		d, err := lxd.ConnectOCI(remote.Addr, args)
+		if err != nil {
+			return nil, err
+		}
+
+		return d, nil
+	}
+
 	// HTTPs (public LXD)
 	if remote.Public {
 		d, err := lxd.ConnectPublicLXD(remote.Addr, args)
@@ -296,7 +306,7 @@ func (c *Config) getConnectionArgs(name string) (*lxd.ConnectionArgs, error) {
 	}
 
 	// Stop here if no client certificate involved
-	if remote.Protocol == "simplestreams" || shared.ValueInSlice(remote.AuthType, []string{api.AuthenticationMethodOIDC}) {
+	if shared.ValueInSlice(remote.Protocol, []string{"simplestreams", "oci"}) || shared.ValueInSlice(remote.AuthType, []string{api.AuthenticationMethodOIDC}) {
 		return &args, nil
 	}
 
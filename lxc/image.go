@@ -233,8 +233,8 @@ func (c *cmdImageCopy) run(cmd *cobra.Command, args []string) error {
 	// Copy the image
 	var imgInfo *api.Image
 	var fp string
-	if conf.Remotes[remoteName].Protocol == "simplestreams" && !c.flagCopyAliases && len(c.flagAliases) == 0 {
-		// All simplestreams images are always public, so unless we
+	if shared.ValueInSlice(conf.Remotes[remoteName].Protocol, []string{"simplestreams", "oci"}) && !c.flagCopyAliases && len(c.flagAliases) == 0 {
+		// All simplestreams and OCI images are always public, so unless we
 		// need the aliases list too or the real fingerprint, we can skip the otherwise very expensive
 		// alias resolution and image info retrieval step.
 		imgInfo = &api.Image{}
@@ -653,8 +653,9 @@ type cmdImageImport struct {
 	global *cmdGlobal
 	image  *cmdImage
 
-	flagPublic  bool
-	flagAliases []string
+	flagPublic    bool
+	flagAliases   []string
+	flagSignature string
 }
 
 func (c *cmdImageImport) command() *cobra.Command {
@@ -668,6 +669,7 @@ Directory import is only available on Linux and must be performed as root.`))
 
 	cmd.Flags().BoolVar(&c.flagPublic, "public", false, i18n.G("Make image public"))
 	cmd.Flags().StringArrayVar(&c.flagAliases, "alias", nil, i18n.G("New aliases to add to the image")+"``")
+	cmd.Flags().StringVar(&c.flagSignature, "signature", "", i18n.G("File holding the detached signature of the image")+"``")
 	cmd.RunE = c.run
 
 	return cmd
@@ -776,6 +778,19 @@ func (c *cmdImageImport) run(cmd *cobra.Command, args []string) error {
 		image.Properties[strings.TrimSpace(fields[0])] = strings.TrimSpace(fields[1])
 	}
 
+	if c.flagSignature != "" {
+		signature, err := os.ReadFile(shared.HostPathFollow(c.flagSignature))
+		if err != nil {
+			return fmt.Errorf(i18n.G("Failed reading image signature: %w"), err)
+		}
+
+		if image.Properties == nil {
+			image.Properties = map[string]string{}
+		}
+
+		image.Properties["signature"] = strings.TrimSpace(string(signature))
+	}
+
 	progress := cli.ProgressRenderer{
 		Format: i18n.G("Transferring image: %s"),
 		Quiet:  c.global.flagQuiet,
@@ -588,6 +588,8 @@ func (c *cmdInfo) instanceInfo(d lxd.InstanceServer, name string, showLog bool)
 		}
 	}
 
+	printReplicationInfo(inst.Config)
+
 	// List snapshots
 	firstSnapshot := true
 	if len(inst.Snapshots) > 0 {
@@ -328,7 +328,11 @@ func (c *cmdInit) create(conf *config.Config, args []string, launch bool) (lxd.I
 			return nil, "", err
 		}
 
-		if conf.Remotes[iremote].Protocol != "simplestreams" {
+		if conf.Remotes[iremote].Protocol == "oci" && c.flagVM {
+			return nil, "", fmt.Errorf(i18n.G("OCI images can only be used for containers"))
+		}
+
+		if !shared.ValueInSlice(conf.Remotes[iremote].Protocol, []string{"simplestreams", "oci"}) {
 			if imgInfo.Type != "virtual-machine" && c.flagVM {
 				return nil, "", fmt.Errorf(i18n.G("Asked for a VM but image is of type container"))
 			}
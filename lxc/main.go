@@ -114,6 +114,18 @@ For help with any of those, simply call them with --help.`))
 	aliasCmd := cmdAlias{global: &globalCmd}
 	app.AddCommand(aliasCmd.command())
 
+	// apply sub-command
+	applyCmd := cmdApply{global: &globalCmd}
+	app.AddCommand(applyCmd.command())
+
+	// audit sub-command
+	auditCmd := cmdAudit{global: &globalCmd}
+	app.AddCommand(auditCmd.command())
+
+	// bulk sub-command
+	bulkCmd := cmdBulk{global: &globalCmd}
+	app.AddCommand(bulkCmd.command())
+
 	// cluster sub-command
 	clusterCmd := cmdCluster{global: &globalCmd}
 	app.AddCommand(clusterCmd.command())
@@ -218,6 +230,10 @@ For help with any of those, simply call them with --help.`))
 	renameCmd := cmdRename{global: &globalCmd}
 	app.AddCommand(renameCmd.command())
 
+	// replication sub-command
+	replicationCmd := cmdReplication{global: &globalCmd}
+	app.AddCommand(replicationCmd.command())
+
 	// restart sub-command
 	restartCmd := cmdRestart{global: &globalCmd}
 	app.AddCommand(restartCmd.command())
@@ -254,6 +270,10 @@ For help with any of those, simply call them with --help.`))
 	warningCmd := cmdWarning{global: &globalCmd}
 	app.AddCommand(warningCmd.command())
 
+	// webhook sub-command
+	webhookCmd := cmdWebhook{global: &globalCmd}
+	app.AddCommand(webhookCmd.command())
+
 	authCmd := cmdAuth{global: &globalCmd}
 	app.AddCommand(authCmd.command())
 
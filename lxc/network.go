@@ -902,6 +902,7 @@ type cmdNetworkList struct {
 	network *cmdNetwork
 
 	flagFormat string
+	flagList   listFlags
 }
 
 func (c *cmdNetworkList) command() *cobra.Command {
@@ -914,6 +915,7 @@ func (c *cmdNetworkList) command() *cobra.Command {
 
 	cmd.RunE = c.run
 	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	c.flagList.add(cmd)
 
 	return cmd
 }
@@ -943,7 +945,18 @@ func (c *cmdNetworkList) run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("Filtering isn't supported yet"))
 	}
 
-	networks, err := resource.server.GetNetworks()
+	listArgs, err := c.flagList.listArgs()
+	if err != nil {
+		return err
+	}
+
+	var networks []api.Network
+	if listArgs != nil {
+		networks, err = resource.server.GetNetworksWithArgs(listArgs)
+	} else {
+		networks, err = resource.server.GetNetworks()
+	}
+
 	if err != nil {
 		return err
 	}
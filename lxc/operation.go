@@ -96,6 +96,7 @@ type cmdOperationList struct {
 
 	flagFormat      string
 	flagAllProjects bool
+	flagList        listFlags
 }
 
 func (c *cmdOperationList) command() *cobra.Command {
@@ -107,6 +108,7 @@ func (c *cmdOperationList) command() *cobra.Command {
 		`List background operations`))
 	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
 	cmd.Flags().BoolVar(&c.flagAllProjects, "all-projects", false, i18n.G("List operations from all projects")+"``")
+	c.flagList.add(cmd)
 
 	cmd.RunE = c.run
 
@@ -136,10 +138,19 @@ func (c *cmdOperationList) run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf(i18n.G("Filtering isn't supported yet"))
 	}
 
+	listArgs, err := c.flagList.listArgs()
+	if err != nil {
+		return err
+	}
+
 	// Get operations
 	var operations []api.Operation
-	if c.flagAllProjects {
+	if c.flagAllProjects && listArgs != nil {
+		operations, err = resource.server.GetOperationsAllProjectsWithArgs(listArgs)
+	} else if c.flagAllProjects {
 		operations, err = resource.server.GetOperationsAllProjects()
+	} else if listArgs != nil {
+		operations, err = resource.server.GetOperationsWithArgs(listArgs)
 	} else {
 		operations, err = resource.server.GetOperations()
 	}
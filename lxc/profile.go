@@ -603,6 +603,7 @@ type cmdProfileList struct {
 	global     *cmdGlobal
 	profile    *cmdProfile
 	flagFormat string
+	flagList   listFlags
 }
 
 func (c *cmdProfileList) command() *cobra.Command {
@@ -615,6 +616,7 @@ func (c *cmdProfileList) command() *cobra.Command {
 
 	cmd.RunE = c.run
 	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	c.flagList.add(cmd)
 
 	return cmd
 }
@@ -640,7 +642,18 @@ func (c *cmdProfileList) run(cmd *cobra.Command, args []string) error {
 	resource := resources[0]
 
 	// List profiles
-	profiles, err := resource.server.GetProfiles()
+	listArgs, err := c.flagList.listArgs()
+	if err != nil {
+		return err
+	}
+
+	var profiles []api.Profile
+	if listArgs != nil {
+		profiles, err = resource.server.GetProfilesWithArgs(listArgs)
+	} else {
+		profiles, err = resource.server.GetProfiles()
+	}
+
 	if err != nil {
 		return err
 	}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
@@ -69,6 +70,18 @@ func (c *cmdProject) command() *cobra.Command {
 	projectGetInfo := cmdProjectInfo{global: c.global, project: c}
 	cmd.AddCommand(projectGetInfo.command())
 
+	// Usage
+	projectUsageCmd := cmdProjectUsage{global: c.global, project: c}
+	cmd.AddCommand(projectUsageCmd.command())
+
+	// Export
+	projectExportCmd := cmdProjectExport{global: c.global, project: c}
+	cmd.AddCommand(projectExportCmd.command())
+
+	// Import
+	projectImportCmd := cmdProjectImport{global: c.global, project: c}
+	cmd.AddCommand(projectImportCmd.command())
+
 	// Set default
 	projectSwitchCmd := cmdProjectSwitch{global: c.global, project: c}
 	cmd.AddCommand(projectSwitchCmd.command())
@@ -400,6 +413,7 @@ type cmdProjectList struct {
 	project *cmdProject
 
 	flagFormat string
+	flagList   listFlags
 }
 
 func (c *cmdProjectList) command() *cobra.Command {
@@ -410,6 +424,7 @@ func (c *cmdProjectList) command() *cobra.Command {
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
 		`List projects`))
 	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	c.flagList.add(cmd)
 
 	cmd.RunE = c.run
 
@@ -441,7 +456,18 @@ func (c *cmdProjectList) run(cmd *cobra.Command, args []string) error {
 	resource := resources[0]
 
 	// List projects
-	projects, err := resource.server.GetProjects()
+	listArgs, err := c.flagList.listArgs()
+	if err != nil {
+		return err
+	}
+
+	var projects []api.Project
+	if listArgs != nil {
+		projects, err = resource.server.GetProjectsWithArgs(listArgs)
+	} else {
+		projects, err = resource.server.GetProjects()
+	}
+
 	if err != nil {
 		return err
 	}
@@ -834,13 +860,16 @@ func (c *cmdProjectInfo) run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Render the output
-	byteLimits := []string{"disk", "memory"}
+	byteLimits := []string{"backups.size", "disk", "memory"}
+	bitLimits := []string{"network.egress", "network.ingress"}
 	data := [][]string{}
 	for k, v := range projectState.Resources {
 		limit := i18n.G("UNLIMITED")
 		if v.Limit >= 0 {
 			if shared.ValueInSlice(k, byteLimits) {
 				limit = units.GetByteSizeStringIEC(v.Limit, 2)
+			} else if shared.ValueInSlice(k, bitLimits) {
+				limit = fmt.Sprintf("%dbit", v.Limit)
 			} else {
 				limit = fmt.Sprintf("%d", v.Limit)
 			}
@@ -849,6 +878,8 @@ func (c *cmdProjectInfo) run(cmd *cobra.Command, args []string) error {
 		usage := ""
 		if shared.ValueInSlice(k, byteLimits) {
 			usage = units.GetByteSizeStringIEC(v.Usage, 2)
+		} else if shared.ValueInSlice(k, bitLimits) {
+			usage = fmt.Sprintf("%dbit", v.Usage)
 		} else {
 			usage = fmt.Sprintf("%d", v.Usage)
 		}
@@ -866,3 +897,94 @@ func (c *cmdProjectInfo) run(cmd *cobra.Command, args []string) error {
 
 	return cli.RenderTable(c.flagFormat, header, data, projectState)
 }
+
+// Usage.
+type cmdProjectUsage struct {
+	global  *cmdGlobal
+	project *cmdProject
+
+	flagFormat string
+	flagFrom   string
+	flagTo     string
+}
+
+func (c *cmdProjectUsage) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("usage", i18n.G("[<remote>:]<project>"))
+	cmd.Short = i18n.G("Show the resource usage history of a project")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Show the resource usage history of a project
+
+The usage is recorded per day (UTC). By default, the usage of the current month
+up to the current day is shown.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc project usage foo --from 2024-01-01 --to 2024-01-31
+    Show the resource usage of project foo in January 2024.`))
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	cmd.Flags().StringVar(&c.flagFrom, "from", "", i18n.G("First day of the period (YYYY-MM-DD)")+"``")
+	cmd.Flags().StringVar(&c.flagTo, "to", "", i18n.G("Last day of the period (YYYY-MM-DD)")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdProjectUsage) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	var from, to time.Time
+	if c.flagFrom != "" {
+		from, err = time.Parse(time.DateOnly, c.flagFrom)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Invalid date %q: %w"), c.flagFrom, err)
+		}
+	}
+
+	if c.flagTo != "" {
+		to, err = time.Parse(time.DateOnly, c.flagTo)
+		if err != nil {
+			return fmt.Errorf(i18n.G("Invalid date %q: %w"), c.flagTo, err)
+		}
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing project name"))
+	}
+
+	projectUsage, err := resource.server.GetProjectUsage(resource.name, from, to)
+	if err != nil {
+		return err
+	}
+
+	// Render the output
+	data := [][]string{
+		{i18n.G("CPU"), fmt.Sprintf(i18n.G("%.2f seconds"), projectUsage.CPUSeconds)},
+		{i18n.G("MEMORY"), fmt.Sprintf(i18n.G("%.2f GiB-hours"), projectUsage.MemoryGiBHours)},
+		{i18n.G("DISK"), fmt.Sprintf(i18n.G("%.2f GiB-days"), projectUsage.DiskGiBDays)},
+		{i18n.G("NETWORK RECEIVED"), units.GetByteSizeStringIEC(projectUsage.NetworkBytesReceived, 2)},
+		{i18n.G("NETWORK SENT"), units.GetByteSizeStringIEC(projectUsage.NetworkBytesSent, 2)},
+	}
+
+	header := []string{
+		i18n.G("RESOURCE"),
+		i18n.G("USAGE"),
+	}
+
+	if c.flagFormat == cli.TableFormatTable {
+		fmt.Printf(i18n.G("Usage from %s to %s:")+"\n", projectUsage.From.Format(time.DateOnly), projectUsage.To.Format(time.DateOnly))
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, projectUsage)
+}
@@ -0,0 +1,742 @@
+package main
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/units"
+)
+
+// projectArchiveIndexPath is the path of the index in a project archive, which is always its first entry.
+const projectArchiveIndexPath = "index.yaml"
+
+// projectArchiveIndex lists the entities of an exported project.
+// The backups of the custom volumes and instances follow the index in the archive, in that order.
+type projectArchiveIndex struct {
+	Project      api.ProjectsPost            `yaml:"project"`
+	Profiles     []api.ProfilesPost          `yaml:"profiles,omitempty"`
+	NetworkACLs  []api.NetworkACLsPost       `yaml:"network_acls,omitempty"`
+	NetworkZones []projectArchiveNetworkZone `yaml:"network_zones,omitempty"`
+	Networks     []api.NetworksPost          `yaml:"networks,omitempty"`
+	Volumes      []projectArchiveVolume      `yaml:"volumes,omitempty"`
+	Instances    []projectArchiveInstance    `yaml:"instances,omitempty"`
+}
+
+// projectArchiveNetworkZone is a network zone of an exported project along with its records.
+type projectArchiveNetworkZone struct {
+	api.NetworkZonesPost `yaml:",inline"`
+
+	Records []api.NetworkZoneRecordsPost `yaml:"records,omitempty"`
+}
+
+// projectArchiveVolume is a custom storage volume of an exported project.
+type projectArchiveVolume struct {
+	Pool     string `yaml:"pool"`
+	Name     string `yaml:"name"`
+	Location string `yaml:"location,omitempty"`
+	File     string `yaml:"file"`
+}
+
+// projectArchiveInstance is an instance of an exported project.
+type projectArchiveInstance struct {
+	Name string `yaml:"name"`
+	File string `yaml:"file"`
+}
+
+// Export.
+type cmdProjectExport struct {
+	global  *cmdGlobal
+	project *cmdProject
+
+	flagNoSnapshots          bool
+	flagOptimizedStorage     bool
+	flagCompressionAlgorithm string
+}
+
+func (c *cmdProjectExport) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("export", i18n.G("[<remote>:]<project> [<target>]"))
+	cmd.Short = i18n.G("Export projects")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Export projects as archives
+
+The archive contains the configuration of the project along with its profiles,
+networks, network ACLs and network zones when the project has its own set of them,
+and backups of its custom storage volumes and instances.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc project export team1 team1.tar
+    Export the team1 project to team1.tar.`))
+
+	cmd.Flags().BoolVar(&c.flagNoSnapshots, "no-snapshots", false, i18n.G("Don't include the snapshots of the instances and custom storage volumes"))
+	cmd.Flags().BoolVar(&c.flagOptimizedStorage, "optimized-storage", false, i18n.G("Use storage driver optimized format (can only be restored on a similar pool)"))
+	cmd.Flags().StringVar(&c.flagCompressionAlgorithm, "compression", "", i18n.G("Compression algorithm to use (none for uncompressed)")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdProjectExport) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing project name"))
+	}
+
+	project, _, err := resource.server.GetProject(resource.name)
+	if err != nil {
+		return err
+	}
+
+	server := resource.server.UseProject(project.Name)
+
+	index, err := c.index(server, project)
+	if err != nil {
+		return err
+	}
+
+	targetName := project.Name + ".tar"
+	if len(args) > 1 {
+		targetName = args[1]
+	}
+
+	target, err := os.Create(shared.HostPathFollow(targetName))
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = target.Close() }()
+
+	tarWriter := tar.NewWriter(target)
+
+	err = c.export(server, index, tarWriter)
+	if err != nil {
+		_ = target.Close()
+		_ = os.Remove(shared.HostPathFollow(targetName))
+		return err
+	}
+
+	err = tarWriter.Close()
+	if err != nil {
+		return fmt.Errorf("Failed closing archive: %w", err)
+	}
+
+	err = target.Close()
+	if err != nil {
+		return fmt.Errorf("Failed to close export file: %w", err)
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Println(i18n.G("Project exported successfully!"))
+	}
+
+	return nil
+}
+
+// index lists the entities of the project to export.
+// Profiles, networks, network ACLs and network zones are only included when the project has its own set of them.
+func (c *cmdProjectExport) index(server lxd.InstanceServer, project *api.Project) (*projectArchiveIndex, error) {
+	index := &projectArchiveIndex{
+		Project: api.ProjectsPost{
+			Name:       project.Name,
+			ProjectPut: project.Writable(),
+		},
+	}
+
+	if shared.IsTrue(project.Config["features.profiles"]) {
+		profiles, err := server.GetProfiles()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, profile := range profiles {
+			index.Profiles = append(index.Profiles, api.ProfilesPost{Name: profile.Name, ProfilePut: profile.Writable()})
+		}
+	}
+
+	if shared.IsTrue(project.Config["features.networks"]) {
+		acls, err := server.GetNetworkACLs()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, acl := range acls {
+			index.NetworkACLs = append(index.NetworkACLs, api.NetworkACLsPost{NetworkACLPost: api.NetworkACLPost{Name: acl.Name}, NetworkACLPut: acl.Writable()})
+		}
+
+		networks, err := server.GetNetworks()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, network := range networks {
+			if !network.Managed {
+				continue
+			}
+
+			index.Networks = append(index.Networks, api.NetworksPost{Name: network.Name, Type: network.Type, NetworkPut: network.Writable()})
+		}
+	}
+
+	if shared.IsTrue(project.Config["features.networks.zones"]) {
+		zones, err := server.GetNetworkZones()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, zone := range zones {
+			records, err := server.GetNetworkZoneRecords(zone.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			archiveZone := projectArchiveNetworkZone{NetworkZonesPost: api.NetworkZonesPost{Name: zone.Name, NetworkZonePut: zone.Writable()}}
+			for _, record := range records {
+				archiveZone.Records = append(archiveZone.Records, api.NetworkZoneRecordsPost{Name: record.Name, NetworkZoneRecordPut: record.Writable()})
+			}
+
+			index.NetworkZones = append(index.NetworkZones, archiveZone)
+		}
+	}
+
+	if shared.IsTrue(project.Config["features.storage.volumes"]) {
+		pools, err := server.GetStoragePools()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pool := range pools {
+			volumes, err := server.GetStoragePoolVolumes(pool.Name)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, vol := range volumes {
+				if vol.Type != "custom" || shared.IsSnapshot(vol.Name) {
+					continue
+				}
+
+				location := vol.Location
+				if location == "none" {
+					location = ""
+				}
+
+				// Volumes of local pools on different members can have the same name.
+				index.Volumes = append(index.Volumes, projectArchiveVolume{
+					Pool:     pool.Name,
+					Name:     vol.Name,
+					Location: location,
+					File:     path.Join("volumes", pool.Name, location, vol.Name),
+				})
+			}
+		}
+	}
+
+	instances, err := server.GetInstances(api.InstanceTypeAny)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, inst := range instances {
+		index.Instances = append(index.Instances, projectArchiveInstance{Name: inst.Name, File: path.Join("instances", inst.Name)})
+	}
+
+	return index, nil
+}
+
+// export writes the index and the backups of the custom volumes and instances of the project to the archive.
+func (c *cmdProjectExport) export(server lxd.InstanceServer, index *projectArchiveIndex, tarWriter *tar.Writer) error {
+	data, err := yaml.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	err = tarWriter.WriteHeader(&tar.Header{Name: projectArchiveIndexPath, Mode: 0600, Size: int64(len(data)), ModTime: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	_, err = tarWriter.Write(data)
+	if err != nil {
+		return err
+	}
+
+	for _, vol := range index.Volumes {
+		volServer := server
+		if vol.Location != "" {
+			volServer = server.UseTarget(vol.Location)
+		}
+
+		req := api.StoragePoolVolumeBackupsPost{
+			ExpiresAt:            time.Now().Add(24 * time.Hour),
+			VolumeOnly:           c.flagNoSnapshots,
+			OptimizedStorage:     c.flagOptimizedStorage,
+			CompressionAlgorithm: c.flagCompressionAlgorithm,
+		}
+
+		op, err := volServer.CreateStoragePoolVolumeBackup(vol.Pool, vol.Name, req)
+		if err != nil {
+			return fmt.Errorf("Failed creating backup of storage volume %q in pool %q: %w", vol.Name, vol.Pool, err)
+		}
+
+		err = c.addBackup(tarWriter, vol.File, fmt.Sprintf(i18n.G("Exporting storage volume %s: %%s"), vol.Name), op,
+			func(backupName string, req *lxd.BackupFileRequest) error {
+				_, err := volServer.GetStoragePoolVolumeBackupFile(vol.Pool, vol.Name, backupName, req)
+				return err
+			},
+			func(backupName string) (lxd.Operation, error) {
+				return volServer.DeleteStoragePoolVolumeBackup(vol.Pool, vol.Name, backupName)
+			})
+		if err != nil {
+			return fmt.Errorf("Failed exporting storage volume %q in pool %q: %w", vol.Name, vol.Pool, err)
+		}
+	}
+
+	for _, inst := range index.Instances {
+		req := api.InstanceBackupsPost{
+			ExpiresAt:            time.Now().Add(24 * time.Hour),
+			ContainerOnly:        c.flagNoSnapshots,
+			InstanceOnly:         c.flagNoSnapshots,
+			OptimizedStorage:     c.flagOptimizedStorage,
+			CompressionAlgorithm: c.flagCompressionAlgorithm,
+		}
+
+		op, err := server.CreateInstanceBackup(inst.Name, req)
+		if err != nil {
+			return fmt.Errorf("Failed creating backup of instance %q: %w", inst.Name, err)
+		}
+
+		err = c.addBackup(tarWriter, inst.File, fmt.Sprintf(i18n.G("Exporting instance %s: %%s"), inst.Name), op,
+			func(backupName string, req *lxd.BackupFileRequest) error {
+				_, err := server.GetInstanceBackupFile(inst.Name, backupName, req)
+				return err
+			},
+			func(backupName string) (lxd.Operation, error) {
+				return server.DeleteInstanceBackup(inst.Name, backupName)
+			})
+		if err != nil {
+			return fmt.Errorf("Failed exporting instance %q: %w", inst.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// addBackup waits for the backup created by the given operation, downloads it and adds it to the archive under the
+// given path. The backup is deleted from the server afterwards.
+func (c *cmdProjectExport) addBackup(tarWriter *tar.Writer, archivePath string, progressFormat string, op lxd.Operation, fetch func(backupName string, req *lxd.BackupFileRequest) error, remove func(backupName string) (lxd.Operation, error)) error {
+	progress := cli.ProgressRenderer{
+		Format: progressFormat,
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err := op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	// Get name of backup
+	uStr := op.Get().Resources["backups"][0]
+	u, err := url.Parse(uStr)
+	if err != nil {
+		progress.Done("")
+		return fmt.Errorf("Invalid URL %q: %w", uStr, err)
+	}
+
+	backupName, err := url.PathUnescape(path.Base(u.EscapedPath()))
+	if err != nil {
+		progress.Done("")
+		return fmt.Errorf("Invalid backup name segment in path %q: %w", u.EscapedPath(), err)
+	}
+
+	defer func() {
+		// Delete backup after we're done
+		op, err := remove(backupName)
+		if err == nil {
+			_ = op.Wait()
+		}
+	}()
+
+	// The size of the backup must be known before it is added to the archive, so it is downloaded first.
+	tmpFile, err := os.CreateTemp("", "lxc_project_export_")
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	defer func() {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+	}()
+
+	err = fetch(backupName, &lxd.BackupFileRequest{
+		BackupFile:      io.WriteSeeker(tmpFile),
+		ProgressHandler: progress.UpdateProgress,
+	})
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	fi, err := tmpFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	_, err = tmpFile.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	err = tarWriter.WriteHeader(&tar.Header{Name: archivePath, Mode: 0600, Size: fi.Size(), ModTime: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tarWriter, tmpFile)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Import.
+type cmdProjectImport struct {
+	global  *cmdGlobal
+	project *cmdProject
+
+	flagStorage string
+}
+
+func (c *cmdProjectImport) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("import", i18n.G("[<remote>:] <archive> [<project>]"))
+	cmd.Short = i18n.G("Import projects")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Import projects from archives created by "lxc project export"
+
+The project is created along with its profiles, networks, network ACLs,
+network zones, custom storage volumes and instances.
+It can be given a new name, for example to create several projects
+from the same archive.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc project import team1.tar team2
+    Create the team2 project from the export of the team1 project.`))
+
+	cmd.Flags().StringVarP(&c.flagStorage, "storage", "s", "", i18n.G("Storage pool name")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdProjectImport) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 3)
+	if exit {
+		return err
+	}
+
+	srcFilePosition := 0
+
+	// Parse remote (identify 1st argument is remote by looking for a colon at the end).
+	remote := ""
+	if len(args) > 1 && strings.HasSuffix(args[0], ":") {
+		remote = args[0]
+		srcFilePosition = 1
+	}
+
+	if len(args) < srcFilePosition+1 {
+		return errors.New(i18n.G("Missing archive name"))
+	}
+
+	srcFile := args[srcFilePosition]
+
+	projectName := ""
+	if len(args) >= srcFilePosition+2 {
+		projectName = args[srcFilePosition+1]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	file, err := os.Open(shared.HostPathFollow(srcFile))
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	tarReader := tar.NewReader(file)
+
+	index, err := projectArchiveReadIndex(tarReader)
+	if err != nil {
+		return err
+	}
+
+	if projectName == "" {
+		projectName = index.Project.Name
+	}
+
+	project := index.Project
+	project.Name = projectName
+
+	err = resource.server.CreateProject(project)
+	if err != nil {
+		return err
+	}
+
+	// Remove the partially imported project if the import fails.
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	reverter.Add(func() { _ = resource.server.DeleteProject(projectName) })
+
+	server := resource.server.UseProject(projectName)
+
+	err = c.createEntities(server, index, reverter)
+	if err != nil {
+		return err
+	}
+
+	volumes := map[string]projectArchiveVolume{}
+	for _, vol := range index.Volumes {
+		volumes[vol.File] = vol
+	}
+
+	instances := map[string]projectArchiveInstance{}
+	for _, inst := range index.Instances {
+		instances[inst.File] = inst
+	}
+
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed reading archive: %w", err)
+		}
+
+		vol, isVolume := volumes[hdr.Name]
+		inst, isInstance := instances[hdr.Name]
+
+		if !isVolume && !isInstance {
+			continue
+		}
+
+		var format string
+		if isVolume {
+			format = fmt.Sprintf(i18n.G("Importing storage volume %s: %%s"), vol.Name)
+		} else {
+			format = fmt.Sprintf(i18n.G("Importing instance %s: %%s"), inst.Name)
+		}
+
+		progress := cli.ProgressRenderer{
+			Format: format,
+			Quiet:  c.global.flagQuiet,
+		}
+
+		backupFile := &ioprogress.ProgressReader{
+			ReadCloser: io.NopCloser(tarReader),
+			Tracker: &ioprogress.ProgressTracker{
+				Length: hdr.Size,
+				Handler: func(percent int64, speed int64) {
+					progress.UpdateProgress(ioprogress.ProgressData{Text: fmt.Sprintf("%d%% (%s/s)", percent, units.GetByteSizeString(speed, 2))})
+				},
+			},
+		}
+
+		var op lxd.Operation
+		if isVolume {
+			pool := vol.Pool
+			if c.flagStorage != "" {
+				pool = c.flagStorage
+			}
+
+			op, err = server.CreateStoragePoolVolumeFromBackup(pool, lxd.StoragePoolVolumeBackupArgs{BackupFile: backupFile, Name: vol.Name})
+			reverter.Add(func() { _ = server.DeleteStoragePoolVolume(pool, "custom", vol.Name) })
+		} else {
+			op, err = server.CreateInstanceFromBackup(lxd.InstanceBackupArgs{BackupFile: backupFile, PoolName: c.flagStorage})
+			reverter.Add(func() {
+				op, err := server.DeleteInstance(inst.Name)
+				if err == nil {
+					_ = op.Wait()
+				}
+			})
+		}
+
+		if err != nil {
+			progress.Done("")
+			return err
+		}
+
+		err = cli.CancelableWait(op, &progress)
+		if err != nil {
+			progress.Done("")
+			return err
+		}
+
+		progress.Done("")
+	}
+
+	reverter.Success()
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Project %s imported successfully!")+"\n", projectName)
+	}
+
+	return nil
+}
+
+// createEntities creates the profiles, network ACLs, network zones and networks of the imported project.
+// Their removal is added to the reverter, in an order allowing the entities referring to others to be removed first.
+func (c *cmdProjectImport) createEntities(server lxd.InstanceServer, index *projectArchiveIndex, reverter *revert.Reverter) error {
+	profiles := []string{}
+	defer func() {
+		// The profiles can refer to the networks, so they're removed first.
+		for _, name := range profiles {
+			if name == "default" {
+				reverter.Add(func() { _ = server.UpdateProfile(name, api.ProfilePut{}, "") })
+			} else {
+				reverter.Add(func() { _ = server.DeleteProfile(name) })
+			}
+		}
+	}()
+
+	for _, profile := range index.Profiles {
+		var err error
+		if profile.Name == "default" {
+			err = server.UpdateProfile(profile.Name, profile.ProfilePut, "")
+		} else {
+			err = server.CreateProfile(profile)
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed creating profile %q: %w", profile.Name, err)
+		}
+
+		profiles = append(profiles, profile.Name)
+	}
+
+	// The rules of network ACLs can refer to other ACLs and to networks, so they're only set once those exist.
+	for _, acl := range index.NetworkACLs {
+		req := acl
+		req.Ingress = nil
+		req.Egress = nil
+
+		err := server.CreateNetworkACL(req)
+		if err != nil {
+			return fmt.Errorf("Failed creating network ACL %q: %w", acl.Name, err)
+		}
+
+		reverter.Add(func() { _ = server.DeleteNetworkACL(acl.Name) })
+	}
+
+	for _, zone := range index.NetworkZones {
+		err := server.CreateNetworkZone(zone.NetworkZonesPost)
+		if err != nil {
+			return fmt.Errorf("Failed creating network zone %q: %w", zone.Name, err)
+		}
+
+		reverter.Add(func() { _ = server.DeleteNetworkZone(zone.Name) })
+
+		for _, record := range zone.Records {
+			err := server.CreateNetworkZoneRecord(zone.Name, record)
+			if err != nil {
+				return fmt.Errorf("Failed creating record %q of network zone %q: %w", record.Name, zone.Name, err)
+			}
+		}
+	}
+
+	for _, network := range index.Networks {
+		err := server.CreateNetwork(network)
+		if err != nil {
+			return fmt.Errorf("Failed creating network %q: %w", network.Name, err)
+		}
+
+		reverter.Add(func() { _ = server.DeleteNetwork(network.Name) })
+	}
+
+	for _, acl := range index.NetworkACLs {
+		err := server.UpdateNetworkACL(acl.Name, acl.NetworkACLPut, "")
+		if err != nil {
+			return fmt.Errorf("Failed setting rules of network ACL %q: %w", acl.Name, err)
+		}
+
+		reverter.Add(func() {
+			_ = server.UpdateNetworkACL(acl.Name, api.NetworkACLPut{Description: acl.Description, Config: acl.Config}, "")
+		})
+	}
+
+	return nil
+}
+
+// projectArchiveReadIndex reads the index from the start of a project archive.
+func projectArchiveReadIndex(tarReader *tar.Reader) (*projectArchiveIndex, error) {
+	hdr, err := tarReader.Next()
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading archive: %w", err)
+	}
+
+	if hdr.Name != projectArchiveIndexPath {
+		return nil, fmt.Errorf("Invalid project archive: %q isn't the first entry", projectArchiveIndexPath)
+	}
+
+	data, err := io.ReadAll(tarReader)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading archive index: %w", err)
+	}
+
+	index := &projectArchiveIndex{}
+	err = yaml.Unmarshal(data, index)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing archive index: %w", err)
+	}
+
+	return index, nil
+}
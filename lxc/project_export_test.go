@@ -0,0 +1,45 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestProjectArchiveReadIndex(t *testing.T) {
+	index := projectArchiveIndex{
+		Project:   api.ProjectsPost{Name: "team1", ProjectPut: api.ProjectPut{Config: map[string]string{"features.profiles": "true"}}},
+		Profiles:  []api.ProfilesPost{{Name: "default", ProfilePut: api.ProfilePut{Config: map[string]string{}, Devices: map[string]map[string]string{}}}},
+		Instances: []projectArchiveInstance{{Name: "c1", File: "instances/c1"}},
+	}
+
+	data, err := yaml.Marshal(index)
+	require.NoError(t, err)
+
+	// The index is read from the first entry of the archive.
+	buf := &bytes.Buffer{}
+	tarWriter := tar.NewWriter(buf)
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: projectArchiveIndexPath, Mode: 0600, Size: int64(len(data))}))
+	_, err = tarWriter.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, tarWriter.Close())
+
+	readIndex, err := projectArchiveReadIndex(tar.NewReader(bytes.NewReader(buf.Bytes())))
+	require.NoError(t, err)
+	assert.Equal(t, index, *readIndex)
+
+	// Archives that don't start with an index are rejected.
+	buf = &bytes.Buffer{}
+	tarWriter = tar.NewWriter(buf)
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: "instances/c1", Mode: 0600, Size: 0}))
+	require.NoError(t, tarWriter.Close())
+
+	_, err = projectArchiveReadIndex(tar.NewReader(bytes.NewReader(buf.Bytes())))
+	assert.Error(t, err)
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+)
+
+type cmdReplication struct {
+	global *cmdGlobal
+}
+
+func (c *cmdReplication) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("replication")
+	cmd.Short = i18n.G("Manage replicas of instances and custom storage volumes")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage replicas of instances and custom storage volumes
+
+Instances and custom storage volumes are replicated to another server
+using the replication.target and replication.schedule configuration keys.`))
+
+	// Promote
+	replicationPromoteCmd := cmdReplicationPromote{global: c.global, replication: c}
+	cmd.AddCommand(replicationPromoteCmd.command())
+
+	// Promote volume
+	replicationPromoteVolumeCmd := cmdReplicationPromoteVolume{global: c.global, replication: c}
+	cmd.AddCommand(replicationPromoteVolumeCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// Promote.
+type cmdReplicationPromote struct {
+	global      *cmdGlobal
+	replication *cmdReplication
+
+	flagStart bool
+}
+
+func (c *cmdReplicationPromote) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("promote", i18n.G("[<remote>:]<instance>"))
+	cmd.Short = i18n.G("Promote an instance replica")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Promote an instance replica
+
+Once promoted, the replica can be started and is no longer refreshed
+by the replication of the original instance.`))
+	cmd.Example = cli.FormatSection("", i18n.G(
+		`lxc replication promote backup:c1 --start
+    Promote the replica of instance c1 on the "backup" remote and start it.`))
+
+	cmd.Flags().BoolVar(&c.flagStart, "start", false, i18n.G("Start the instance once promoted"))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdReplicationPromote) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing instance name"))
+	}
+
+	inst, etag, err := resource.server.GetInstance(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if shared.IsFalseOrEmpty(inst.Config["replication.replica"]) {
+		return fmt.Errorf(i18n.G("Instance %q isn't a replica"), resource.name)
+	}
+
+	writable := inst.Writable()
+	delete(writable.Config, "replication.replica")
+
+	op, err := resource.server.UpdateInstance(resource.name, writable, etag)
+	if err != nil {
+		return err
+	}
+
+	err = op.Wait()
+	if err != nil {
+		return err
+	}
+
+	if !c.flagStart {
+		return nil
+	}
+
+	req := api.InstanceStatePut{
+		Action:  "start",
+		Timeout: -1,
+	}
+
+	op, err = resource.server.UpdateInstanceState(resource.name, req, "")
+	if err != nil {
+		return err
+	}
+
+	return op.Wait()
+}
+
+// Promote volume.
+type cmdReplicationPromoteVolume struct {
+	global      *cmdGlobal
+	replication *cmdReplication
+
+	flagTarget string
+}
+
+func (c *cmdReplicationPromoteVolume) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("promote-volume", i18n.G("[<remote>:]<pool> <volume>"))
+	cmd.Short = i18n.G("Promote a custom storage volume replica")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Promote a custom storage volume replica
+
+Once promoted, the replica is no longer refreshed by the replication
+of the original volume.`))
+
+	cmd.Flags().StringVar(&c.flagTarget, "target", "", i18n.G("Cluster member name")+"``")
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdReplicationPromoteVolume) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing pool name"))
+	}
+
+	client := resource.server
+
+	// If a target was specified, use it.
+	if c.flagTarget != "" {
+		client = client.UseTarget(c.flagTarget)
+	}
+
+	vol, etag, err := client.GetStoragePoolVolume(resource.name, "custom", args[1])
+	if err != nil {
+		return err
+	}
+
+	if shared.IsFalseOrEmpty(vol.Config["replication.replica"]) {
+		return fmt.Errorf(i18n.G("Storage volume %q isn't a replica"), args[1])
+	}
+
+	writable := vol.Writable()
+	delete(writable.Config, "replication.replica")
+
+	return client.UpdateStoragePoolVolume(resource.name, "custom", args[1], writable, etag)
+}
+
+// printReplicationInfo prints the replication status of an instance or custom storage volume with the given config.
+func printReplicationInfo(config map[string]string) {
+	const layout = "2006/01/02 15:04 MST"
+
+	if shared.IsTrue(config["replication.replica"]) {
+		fmt.Println("\n" + i18n.G("Replication:"))
+		fmt.Printf("  "+i18n.G("Replica: %s")+"\n", i18n.G("yes"))
+		return
+	}
+
+	if config["replication.target"] == "" {
+		return
+	}
+
+	fmt.Println("\n" + i18n.G("Replication:"))
+	fmt.Printf("  "+i18n.G("Target: %s")+"\n", config["replication.target"])
+
+	if config["replication.schedule"] != "" {
+		fmt.Printf("  "+i18n.G("Schedule: %s")+"\n", config["replication.schedule"])
+	}
+
+	lastSuccess, err := time.Parse(time.RFC3339, config["volatile.replication.last_success"])
+	if err == nil {
+		fmt.Printf("  "+i18n.G("Last replicated: %s")+"\n", lastSuccess.Local().Format(layout))
+		fmt.Printf("  "+i18n.G("Lag: %s")+"\n", time.Since(lastSuccess).Round(time.Second))
+	} else {
+		fmt.Printf("  "+i18n.G("Last replicated: %s")+"\n", i18n.G("never"))
+	}
+
+	if config["volatile.replication.last_error"] != "" {
+		fmt.Printf("  "+i18n.G("Last error: %s")+"\n", config["volatile.replication.last_error"])
+	}
+}
@@ -576,6 +576,7 @@ type cmdStorageList struct {
 	storage *cmdStorage
 
 	flagFormat string
+	flagList   listFlags
 }
 
 func (c *cmdStorageList) command() *cobra.Command {
@@ -586,6 +587,7 @@ func (c *cmdStorageList) command() *cobra.Command {
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
 		`List available storage pools`))
 	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	c.flagList.add(cmd)
 
 	cmd.RunE = c.run
 
@@ -613,7 +615,18 @@ func (c *cmdStorageList) run(cmd *cobra.Command, args []string) error {
 	resource := resources[0]
 
 	// Get the storage pools
-	pools, err := resource.server.GetStoragePools()
+	listArgs, err := c.flagList.listArgs()
+	if err != nil {
+		return err
+	}
+
+	var pools []api.StoragePool
+	if listArgs != nil {
+		pools, err = resource.server.GetStoragePoolsWithArgs(listArgs)
+	} else {
+		pools, err = resource.server.GetStoragePools()
+	}
+
 	if err != nil {
 		return err
 	}
@@ -442,6 +442,7 @@ type cmdStorageBucketList struct {
 	global        *cmdGlobal
 	storageBucket *cmdStorageBucket
 	flagFormat    string
+	flagList      listFlags
 }
 
 func (c *cmdStorageBucketList) command() *cobra.Command {
@@ -452,6 +453,7 @@ func (c *cmdStorageBucketList) command() *cobra.Command {
 
 	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(`List storage buckets`))
 	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	c.flagList.add(cmd)
 
 	cmd.RunE = c.run
 
@@ -479,7 +481,18 @@ func (c *cmdStorageBucketList) run(cmd *cobra.Command, args []string) error {
 
 	client := resource.server
 
-	buckets, err := client.GetStoragePoolBuckets(resource.name)
+	listArgs, err := c.flagList.listArgs()
+	if err != nil {
+		return err
+	}
+
+	var buckets []api.StorageBucket
+	if listArgs != nil {
+		buckets, err = client.GetStoragePoolBucketsWithArgs(resource.name, listArgs)
+	} else {
+		buckets, err = client.GetStoragePoolBuckets(resource.name)
+	}
+
 	if err != nil {
 		return err
 	}
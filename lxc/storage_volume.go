@@ -1313,6 +1313,8 @@ func (c *cmdStorageVolumeInfo) run(cmd *cobra.Command, args []string) error {
 		fmt.Printf(i18n.G("Created: %s")+"\n", vol.CreatedAt.Local().Format(layout))
 	}
 
+	printReplicationInfo(vol.Config)
+
 	// List snapshots
 	firstSnapshot := true
 	if len(volSnapshots) > 0 {
@@ -9,6 +9,8 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/spf13/cobra"
+
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxc/config"
 	"github.com/canonical/lxd/shared"
@@ -346,6 +348,39 @@ func getServerSupportedFilters(filters []string, i interface{}) ([]string, []str
 	return supportedFilters, unsupportedFilters
 }
 
+// listFlags are the filtering and pagination flags of the commands listing a collection.
+type listFlags struct {
+	filters []string
+	limit   int
+	after   string
+}
+
+// add adds the filtering and pagination flags to a list command.
+func (f *listFlags) add(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&f.filters, "filter", nil, i18n.G("Only list the entries matching a key=value filter")+"``")
+	cmd.Flags().IntVar(&f.limit, "limit", 0, i18n.G("Maximum number of entries to list")+"``")
+	cmd.Flags().StringVar(&f.after, "after", "", i18n.G("Only list the entries sorted after the given key")+"``")
+}
+
+// listArgs returns the arguments to list a collection with, or nil if no filtering or pagination flag is set.
+func (f *listFlags) listArgs() (*lxd.ListArgs, error) {
+	if len(f.filters) == 0 && f.limit == 0 && f.after == "" {
+		return nil, nil
+	}
+
+	if f.limit < 0 {
+		return nil, fmt.Errorf(i18n.G("Invalid limit %d"), f.limit)
+	}
+
+	for _, filter := range f.filters {
+		if !strings.Contains(filter, "=") {
+			return nil, fmt.Errorf(i18n.G("Invalid filter %q, expected key=value"), filter)
+		}
+	}
+
+	return &lxd.ListArgs{Filters: f.filters, Limit: f.limit, After: f.after}, nil
+}
+
 // guessImage checks that the image name (provided by the user) is correct given an instance remote and image remote.
 func guessImage(conf *config.Config, d lxd.InstanceServer, instRemote string, imgRemote string, imageRef string) (string, string) {
 	if instRemote != imgRemote {
@@ -394,8 +429,8 @@ func getImgInfo(d lxd.InstanceServer, conf *config.Config, imgRemote string, ins
 		}
 	}
 
-	// Optimisation for simplestreams
-	if conf.Remotes[imgRemote].Protocol == "simplestreams" {
+	// Optimisation for simplestreams and OCI which are referred to by alias
+	if shared.ValueInSlice(conf.Remotes[imgRemote].Protocol, []string{"simplestreams", "oci"}) {
 		imgInfo = &api.Image{}
 		imgInfo.Fingerprint = imageRef
 		imgInfo.Public = true
@@ -59,6 +59,7 @@ type cmdWarningList struct {
 	flagColumns string
 	flagFormat  string
 	flagAll     bool
+	flagList    listFlags
 }
 
 const defaultWarningColumns = "utSscpLl"
@@ -92,6 +93,7 @@ Column shorthand chars:
 	cmd.Flags().StringVarP(&c.flagColumns, "columns", "c", defaultWarningColumns, i18n.G("Columns")+"``")
 	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
 	cmd.Flags().BoolVarP(&c.flagAll, "all", "a", false, i18n.G("List all warnings")+"``")
+	c.flagList.add(cmd)
 
 	cmd.RunE = c.run
 
@@ -115,7 +117,18 @@ func (c *cmdWarningList) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	allWarnings, err := remoteServer.GetWarnings()
+	listArgs, err := c.flagList.listArgs()
+	if err != nil {
+		return err
+	}
+
+	var allWarnings []api.Warning
+	if listArgs != nil {
+		allWarnings, err = remoteServer.GetWarningsWithArgs(listArgs)
+	} else {
+		allWarnings, err = remoteServer.GetWarnings()
+	}
+
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,634 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/i18n"
+	"github.com/canonical/lxd/shared/termios"
+)
+
+type cmdWebhook struct {
+	global *cmdGlobal
+}
+
+func (c *cmdWebhook) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("webhook")
+	cmd.Short = i18n.G("Manage webhooks")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G(
+		`Manage webhooks
+
+Webhooks deliver lifecycle and operation events to HTTP endpoints.`))
+
+	// List.
+	webhookListCmd := cmdWebhookList{global: c.global, webhook: c}
+	cmd.AddCommand(webhookListCmd.command())
+
+	// Show.
+	webhookshowCmd := cmdWebhookShow{global: c.global, webhook: c}
+	cmd.AddCommand(webhookshowCmd.command())
+
+	// Get.
+	webhookGetCmd := cmdWebhookGet{global: c.global, webhook: c}
+	cmd.AddCommand(webhookGetCmd.command())
+
+	// Create.
+	webhookCreateCmd := cmdWebhookCreate{global: c.global, webhook: c}
+	cmd.AddCommand(webhookCreateCmd.command())
+
+	// Set.
+	webhooksetCmd := cmdWebhookSet{global: c.global, webhook: c}
+	cmd.AddCommand(webhooksetCmd.command())
+
+	// Unset.
+	webhookUnsetCmd := cmdWebhookUnset{global: c.global, webhook: c, webhookset: &webhooksetCmd}
+	cmd.AddCommand(webhookUnsetCmd.command())
+
+	// Edit.
+	webhookEditCmd := cmdWebhookEdit{global: c.global, webhook: c}
+	cmd.AddCommand(webhookEditCmd.command())
+
+	// Delete.
+	webhookDeleteCmd := cmdWebhookDelete{global: c.global, webhook: c}
+	cmd.AddCommand(webhookDeleteCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+type cmdWebhookList struct {
+	global  *cmdGlobal
+	webhook *cmdWebhook
+
+	flagFormat string
+	flagList   listFlags
+}
+
+func (c *cmdWebhookList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", i18n.G("[<remote>:]"))
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = i18n.G("List available webhooks")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("List available webhooks"))
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", i18n.G("Format (csv|json|table|yaml|compact)")+"``")
+	c.flagList.add(cmd)
+
+	return cmd
+}
+
+func (c *cmdWebhookList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	// List the networks.
+	if resource.name != "" {
+		return fmt.Errorf(i18n.G("Filtering isn't supported yet"))
+	}
+
+	listArgs, err := c.flagList.listArgs()
+	if err != nil {
+		return err
+	}
+
+	var webhooks []api.Webhook
+	if listArgs != nil {
+		webhooks, err = resource.server.GetWebhooksWithArgs(listArgs)
+	} else {
+		webhooks, err = resource.server.GetWebhooks()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	for _, webhook := range webhooks {
+		types := webhook.Config["types"]
+		if types == "" {
+			types = "lifecycle"
+		}
+
+		details := []string{
+			webhook.Name,
+			webhook.Description,
+			webhook.Config["url"],
+			types,
+		}
+
+		data = append(data, details)
+	}
+
+	sort.Sort(cli.SortColumnsNaturally(data))
+
+	header := []string{
+		i18n.G("NAME"),
+		i18n.G("DESCRIPTION"),
+		i18n.G("URL"),
+		i18n.G("TYPES"),
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, webhooks)
+}
+
+// Show.
+type cmdWebhookShow struct {
+	global  *cmdGlobal
+	webhook *cmdWebhook
+}
+
+func (c *cmdWebhookShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", i18n.G("[<remote>:]<webhook>"))
+	cmd.Short = i18n.G("Show webhook configurations")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Show webhook configurations"))
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdWebhookShow) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing webhook name"))
+	}
+
+	// Show the webhook config.
+	hook, _, err := resource.server.GetWebhook(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&hook)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Get.
+type cmdWebhookGet struct {
+	global  *cmdGlobal
+	webhook *cmdWebhook
+
+	flagIsProperty bool
+}
+
+func (c *cmdWebhookGet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", i18n.G("[<remote>:]<webhook> <key>"))
+	cmd.Short = i18n.G("Get values for webhook configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Get values for webhook configuration keys"))
+	cmd.RunE = c.run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Get the key as a webhook property"))
+	return cmd
+}
+
+func (c *cmdWebhookGet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing webhook name"))
+	}
+
+	resp, _, err := resource.server.GetWebhook(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if c.flagIsProperty {
+		w := resp.Writable()
+		res, err := getFieldByJsonTag(&w, args[1])
+		if err != nil {
+			return fmt.Errorf(i18n.G("The property %q does not exist on the webhook %q: %v"), args[1], resource.name, err)
+		}
+
+		fmt.Printf("%v\n", res)
+	} else {
+		for k, v := range resp.Config {
+			if k == args[1] {
+				fmt.Printf("%s\n", v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Create.
+type cmdWebhookCreate struct {
+	global  *cmdGlobal
+	webhook *cmdWebhook
+}
+
+func (c *cmdWebhookCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", i18n.G("[<remote>:]<webhook> [key=value...]"))
+	cmd.Short = i18n.G("Create new webhooks")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Create new webhooks"))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdWebhookCreate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing webhook name"))
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var webhookPut api.WebhookPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &webhookPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the webhook.
+	webhook := api.WebhooksPost{
+		Name:       resource.name,
+		WebhookPut: webhookPut,
+	}
+
+	if webhook.Config == nil {
+		webhook.Config = map[string]string{}
+	}
+
+	for i := 1; i < len(args); i++ {
+		entry := strings.SplitN(args[i], "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf(i18n.G("Bad key/value pair: %s"), args[i])
+		}
+
+		webhook.Config[entry[0]] = entry[1]
+	}
+
+	err = resource.server.CreateWebhook(webhook)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Webhook %s created")+"\n", resource.name)
+	}
+
+	return nil
+}
+
+// Set.
+type cmdWebhookSet struct {
+	global  *cmdGlobal
+	webhook *cmdWebhook
+
+	flagIsProperty bool
+}
+
+func (c *cmdWebhookSet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", i18n.G("[<remote>:]<webhook> <key>=<value>..."))
+	cmd.Short = i18n.G("Set webhook configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Set webhook configuration keys"))
+
+	cmd.RunE = c.run
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Set the key as a webhook property"))
+
+	return cmd
+}
+
+func (c *cmdWebhookSet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing webhook name"))
+	}
+
+	// Get the webhook.
+	hook, etag, err := resource.server.GetWebhook(resource.name)
+	if err != nil {
+		return err
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[1:]...)
+	if err != nil {
+		return err
+	}
+
+	writable := hook.Writable()
+	if c.flagIsProperty {
+		if cmd.Name() == "unset" {
+			for k := range keys {
+				err := unsetFieldByJsonTag(&writable, k)
+				if err != nil {
+					return fmt.Errorf(i18n.G("Error unsetting property: %v"), err)
+				}
+			}
+		} else {
+			err := unpackKVToWritable(&writable, keys)
+			if err != nil {
+				return fmt.Errorf(i18n.G("Error setting properties: %v"), err)
+			}
+		}
+	} else {
+		for k, v := range keys {
+			writable.Config[k] = v
+		}
+	}
+
+	return resource.server.UpdateWebhook(resource.name, writable, etag)
+}
+
+// Unset.
+type cmdWebhookUnset struct {
+	global     *cmdGlobal
+	webhook    *cmdWebhook
+	webhookset *cmdWebhookSet
+
+	flagIsProperty bool
+}
+
+func (c *cmdWebhookUnset) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", i18n.G("[<remote>:]<webhook> <key>"))
+	cmd.Short = i18n.G("Unset webhook configuration keys")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Unset webhook configuration keys"))
+	cmd.RunE = c.run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, i18n.G("Unset the key as a webhook property"))
+
+	return cmd
+}
+
+func (c *cmdWebhookUnset) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	c.webhookset.flagIsProperty = c.flagIsProperty
+
+	args = append(args, "")
+	return c.webhookset.run(cmd, args)
+}
+
+// Edit.
+type cmdWebhookEdit struct {
+	global  *cmdGlobal
+	webhook *cmdWebhook
+}
+
+func (c *cmdWebhookEdit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", i18n.G("[<remote>:]<webhook>"))
+	cmd.Short = i18n.G("Edit webhook configurations as YAML")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Edit webhook configurations as YAML"))
+
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdWebhookEdit) helpTemplate() string {
+	return i18n.G(
+		`### This is a YAML representation of the webhook.
+### Any line starting with a '# will be ignored.
+###
+### A webhook consists of a description and configuration items.
+###
+### An example would look like:
+### name: chatops
+### description: Notify the operations channel
+### config:
+###  url: https://chat.example.com/hooks/lxd
+###  types: lifecycle
+###  lifecycle.actions: instance-*
+`)
+}
+
+func (c *cmdWebhookEdit) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing webhook name"))
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc webhook show` command to be passed in here, but only take the contents
+		// of the WebhookPut fields when updating the Zone. The other fields are silently discarded.
+		newdata := api.Webhook{}
+		err = yaml.UnmarshalStrict(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.UpdateWebhook(resource.name, newdata.Writable(), "")
+	}
+
+	// Get the current config.
+	hook, etag, err := resource.server.GetWebhook(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&hook)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newdata := api.Webhook{} // We show the full Zone info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newdata)
+		if err == nil {
+			err = resource.server.UpdateWebhook(resource.name, newdata.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, i18n.G("Config parsing error: %s")+"\n", err)
+			fmt.Println(i18n.G("Press enter to open the editor again or ctrl+c to abort change"))
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdWebhookDelete struct {
+	global  *cmdGlobal
+	webhook *cmdWebhook
+}
+
+func (c *cmdWebhookDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", i18n.G("[<remote>:]<webhook>"))
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = i18n.G("Delete webhooks")
+	cmd.Long = cli.FormatSection(i18n.G("Description"), i18n.G("Delete webhooks"))
+	cmd.RunE = c.run
+
+	return cmd
+}
+
+func (c *cmdWebhookDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return fmt.Errorf(i18n.G("Missing webhook name"))
+	}
+
+	// Delete the webhook.
+	err = resource.server.DeleteWebhook(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf(i18n.G("Webhook %s deleted")+"\n", resource.name)
+	}
+
+	return nil
+}
@@ -18,3 +18,21 @@ type API10Put struct {
 	// Example: true
 	Devlxd bool `json:"devlxd" yaml:"devlxd"`
 }
+
+// FilesystemFreezeDefaultTimeout is the number of seconds after which frozen guest filesystems are thawed when no
+// other timeout is requested.
+const FilesystemFreezeDefaultTimeout = 30
+
+// FilesystemFreezePost contains the fields used to freeze the guest filesystems.
+type FilesystemFreezePost struct {
+	// Number of seconds after which the filesystems are automatically thawed
+	// Example: 30
+	Timeout int `json:"timeout" yaml:"timeout"`
+}
+
+// FilesystemFreeze represents the guest filesystem freeze state.
+type FilesystemFreeze struct {
+	// Mount points which are currently frozen
+	// Example: ["/", "/home"]
+	Filesystems []string `json:"filesystems" yaml:"filesystems"`
+}
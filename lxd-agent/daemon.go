@@ -2,6 +2,7 @@ package main
 
 import (
 	"sync"
+	"time"
 
 	"github.com/canonical/lxd/lxd/events"
 )
@@ -22,6 +23,11 @@ type Daemon struct {
 	devlxdRunning bool
 	devlxdMu      sync.Mutex
 	devlxdEnabled bool
+
+	// Guest filesystems frozen through the fsfreeze endpoint.
+	fsfreezeMu     sync.Mutex
+	fsfreezeMounts []string
+	fsfreezeTimer  *time.Timer
 }
 
 // newDaemon returns a new Daemon object with the given configuration.
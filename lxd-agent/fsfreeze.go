@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	agentAPI "github.com/canonical/lxd/lxd-agent/api"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/storage/filesystem"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// fsfreezeHookDir contains executables run before freezing and after thawing the guest filesystems.
+// Each hook is called with a single "freeze" or "thaw" argument.
+const fsfreezeHookDir = "/etc/lxd-agent/fsfreeze-hook.d"
+
+// fsfreezeSkipTypes lists the filesystem types which cannot or needn't be frozen.
+var fsfreezeSkipTypes = []string{"9p", "virtiofs", "squashfs", "iso9660", "nfs", "nfs4", "cifs", "fuse", "fuseblk"}
+
+var fsfreezeCmd = APIEndpoint{
+	Name: "fsfreeze",
+	Path: "fsfreeze",
+
+	Get:    APIEndpointAction{Handler: fsfreezeGet},
+	Post:   APIEndpointAction{Handler: fsfreezePost},
+	Delete: APIEndpointAction{Handler: fsfreezeDelete},
+}
+
+func fsfreezeGet(d *Daemon, r *http.Request) response.Response {
+	d.fsfreezeMu.Lock()
+	defer d.fsfreezeMu.Unlock()
+
+	return response.SyncResponse(true, agentAPI.FilesystemFreeze{Filesystems: append([]string{}, d.fsfreezeMounts...)})
+}
+
+func fsfreezePost(d *Daemon, r *http.Request) response.Response {
+	req := agentAPI.FilesystemFreezePost{}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Timeout <= 0 {
+		req.Timeout = agentAPI.FilesystemFreezeDefaultTimeout
+	}
+
+	d.fsfreezeMu.Lock()
+	defer d.fsfreezeMu.Unlock()
+
+	if len(d.fsfreezeMounts) > 0 {
+		return response.Conflict(fmt.Errorf("Filesystems are already frozen"))
+	}
+
+	mounts, err := fsfreezeMountPoints()
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	err = fsfreezeRunHooks("freeze")
+	if err != nil {
+		_ = fsfreezeRunHooks("thaw")
+		return response.InternalError(err)
+	}
+
+	// Freeze the deepest mounts first so that parent filesystems remain writable until their children are frozen.
+	frozen := make([]string, 0, len(mounts))
+	for i := len(mounts) - 1; i >= 0; i-- {
+		err := filesystem.SyncFS(mounts[i])
+		if err != nil {
+			logger.Warn("Failed syncing filesystem", logger.Ctx{"path": mounts[i], "err": err})
+		}
+
+		_, err = shared.RunCommand("fsfreeze", "--freeze", mounts[i])
+		if err != nil {
+			fsfreezeThaw(frozen)
+			_ = fsfreezeRunHooks("thaw")
+			return response.InternalError(fmt.Errorf("Failed freezing filesystem %q: %w", mounts[i], err))
+		}
+
+		frozen = append(frozen, mounts[i])
+	}
+
+	d.fsfreezeMounts = frozen
+
+	// Never leave the guest frozen if the host fails to thaw it.
+	d.fsfreezeTimer = time.AfterFunc(time.Duration(req.Timeout)*time.Second, func() {
+		d.fsfreezeMu.Lock()
+		defer d.fsfreezeMu.Unlock()
+
+		if len(d.fsfreezeMounts) == 0 {
+			return
+		}
+
+		logger.Warn("Thawing filesystems after freeze timeout", logger.Ctx{"timeout": req.Timeout})
+		fsfreezeThaw(d.fsfreezeMounts)
+		d.fsfreezeMounts = nil
+		_ = fsfreezeRunHooks("thaw")
+	})
+
+	logger.Info("Filesystems frozen", logger.Ctx{"filesystems": frozen})
+
+	return response.SyncResponse(true, agentAPI.FilesystemFreeze{Filesystems: frozen})
+}
+
+func fsfreezeDelete(d *Daemon, r *http.Request) response.Response {
+	d.fsfreezeMu.Lock()
+	defer d.fsfreezeMu.Unlock()
+
+	if d.fsfreezeTimer != nil {
+		d.fsfreezeTimer.Stop()
+		d.fsfreezeTimer = nil
+	}
+
+	if len(d.fsfreezeMounts) == 0 {
+		return response.EmptySyncResponse
+	}
+
+	fsfreezeThaw(d.fsfreezeMounts)
+	d.fsfreezeMounts = nil
+
+	err := fsfreezeRunHooks("thaw")
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	logger.Info("Filesystems thawed")
+
+	return response.EmptySyncResponse
+}
+
+// fsfreezeThaw thaws the provided mount points in the reverse order they were frozen in.
+func fsfreezeThaw(mounts []string) {
+	for i := len(mounts) - 1; i >= 0; i-- {
+		_, err := shared.RunCommand("fsfreeze", "--unfreeze", mounts[i])
+		if err != nil {
+			logger.Error("Failed thawing filesystem", logger.Ctx{"path": mounts[i], "err": err})
+		}
+	}
+}
+
+// fsfreezeMountPoints returns the writable block-backed mount points, sorted from the root down.
+// Only one mount point is returned per underlying filesystem.
+func fsfreezeMountPoints() ([]string, error) {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	devices := map[string]bool{}
+	mounts := []string{}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Format: ID parentID major:minor root mountpoint options [optional fields...] - fstype source superoptions
+		fields := strings.Fields(scanner.Text())
+		sep := -1
+		for i, field := range fields {
+			if field == "-" {
+				sep = i
+				break
+			}
+		}
+
+		if sep < 6 || len(fields) < sep+3 {
+			continue
+		}
+
+		device := fields[2]
+		mountPoint := fields[4]
+		fsType := fields[sep+1]
+		source := fields[sep+2]
+
+		if devices[device] || !strings.HasPrefix(source, "/dev/") || shared.ValueInSlice(fsType, fsfreezeSkipTypes) {
+			continue
+		}
+
+		if shared.ValueInSlice("ro", strings.Split(fields[5], ",")) {
+			continue
+		}
+
+		devices[device] = true
+		mounts = append(mounts, strings.ReplaceAll(mountPoint, `\040`, " "))
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(mounts)
+
+	return mounts, nil
+}
+
+// fsfreezeRunHooks runs the guest hook scripts with the given action.
+// Freeze hooks run in lexical order and thaw hooks in reverse order.
+func fsfreezeRunHooks(action string) error {
+	entries, err := os.ReadDir(fsfreezeHookDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	hooks := []string{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+
+		hooks = append(hooks, filepath.Join(fsfreezeHookDir, entry.Name()))
+	}
+
+	if action == "thaw" {
+		for i, j := 0, len(hooks)-1; i < j; i, j = i+1, j-1 {
+			hooks[i], hooks[j] = hooks[j], hooks[i]
+		}
+	}
+
+	for _, hook := range hooks {
+		_, err := shared.RunCommand(hook, action)
+		if err != nil {
+			// Thaw hooks are best effort so that every hook gets a chance to resume its application.
+			if action == "thaw" {
+				logger.Error("Failed running fsfreeze hook", logger.Ctx{"hook": hook, "action": action, "err": err})
+				continue
+			}
+
+			return fmt.Errorf("Failed running fsfreeze hook %q: %w", hook, err)
+		}
+	}
+
+	return nil
+}
@@ -202,6 +202,10 @@ func restServer(d *Daemon) *http.Server {
 		d.createCmd(mux, "", c)
 	}
 
+	for _, c := range apiStreams {
+		d.createCmd(mux, "", c)
+	}
+
 	mux.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger.Info("Sending top level 404", logger.Ctx{"url": r.URL, "method": r.Method, "remote": r.RemoteAddr})
 		w.Header().Set("Content-Type", "application/json")
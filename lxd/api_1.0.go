@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"slices"
+	"strings"
 
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/auth"
@@ -24,6 +25,7 @@ import (
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	scriptletLoad "github.com/canonical/lxd/lxd/scriptlet/load"
+	"github.com/canonical/lxd/lxd/tracing"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -43,6 +45,7 @@ var api10Cmd = APIEndpoint{
 var api10 = []APIEndpoint{
 	api10Cmd,
 	api10ResourcesCmd,
+	auditLogCmd,
 	certificateCmd,
 	certificatesCmd,
 	clusterCmd,
@@ -50,6 +53,8 @@ var api10 = []APIEndpoint{
 	clusterGroupsCmd,
 	clusterNodeCmd,
 	clusterNodeStateCmd,
+	clusterRebalanceCmd,
+	clusterUpgradeCmd,
 	clusterNodesCmd,
 	clusterCertificateCmd,
 	instanceBackupCmd,
@@ -108,6 +113,7 @@ var api10 = []APIEndpoint{
 	projectCmd,
 	projectsCmd,
 	projectStateCmd,
+	projectUsageCmd,
 	storagePoolCmd,
 	storagePoolResourcesCmd,
 	storagePoolsCmd,
@@ -126,15 +132,20 @@ var api10 = []APIEndpoint{
 	storagePoolVolumeTypeStateCmd,
 	warningsCmd,
 	warningCmd,
+	bulkCmd,
+	webhooksCmd,
+	webhookCmd,
 	metricsCmd,
 	identitiesCmd,
 	identitiesByAuthenticationMethodCmd,
 	identityCmd,
+	identityEffectivePermissionsCmd,
 	authGroupsCmd,
 	authGroupCmd,
 	identityProviderGroupsCmd,
 	identityProviderGroupCmd,
 	permissionsCmd,
+	authCheckCmd,
 	storageVolumesCmd,
 	storageVolumesTypeCmd,
 }
@@ -223,7 +234,7 @@ func api10Get(d *Daemon, r *http.Request) response.Response {
 	s := d.State()
 
 	// Get the authentication methods.
-	authMethods := []string{api.AuthenticationMethodTLS}
+	authMethods := []string{api.AuthenticationMethodTLS, api.AuthenticationMethodBearer}
 
 	oidcIssuer, oidcClientID, _, _ := s.GlobalConfig.OIDCServer()
 	if oidcIssuer != "" && oidcClientID != "" {
@@ -759,7 +770,7 @@ func doAPI10Update(d *Daemon, r *http.Request, req api.ServerPut, patch bool) re
 	})
 
 	// Notify the other nodes about changes
-	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
 		return response.SmartError(err)
 	}
@@ -809,7 +820,8 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 	maasChanged := false
 	bgpChanged := false
 	dnsChanged := false
-	lokiChanged := false
+	sinksChanged := false
+	tracingChanged := false
 	acmeDomainChanged := false
 	acmeCAURLChanged := false
 	oidcChanged := false
@@ -845,6 +857,18 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 				d.taskPruneImages.Reset()
 			}
 
+		case "images.simplestreams":
+			if !s.OS.MockMode {
+				d.taskImageStreams.Reset()
+			}
+
+		case "cluster.rebalance.threshold":
+			fallthrough
+		case "cluster.rebalance.interval":
+			if d.taskClusterRebalance != nil {
+				d.taskClusterRebalance.Reset()
+			}
+
 		case "core.bgp_asn":
 			bgpChanged = true
 		case "loki.api.url":
@@ -862,13 +886,19 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		case "loki.loglevel":
 			fallthrough
 		case "loki.types":
-			lokiChanged = true
+			sinksChanged = true
 		case "acme.ca_url":
 			acmeCAURLChanged = true
 		case "acme.domain":
 			acmeDomainChanged = true
 		case "oidc.issuer", "oidc.client.id", "oidc.audience", "oidc.groups.claim":
 			oidcChanged = true
+		case "tracing.otlp.address", "tracing.sample_ratio":
+			tracingChanged = true
+		default:
+			if strings.HasPrefix(key, "logging.") {
+				sinksChanged = true
+			}
 		}
 	}
 
@@ -985,16 +1015,15 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
-	if lokiChanged {
-		lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiInstance, lokiLoglevel, lokiLabels, lokiTypes := clusterConfig.LokiServer()
+	if sinksChanged {
+		d.setupSinks(eventSinkConfigs(clusterConfig))
+	}
 
-		if lokiURL == "" || lokiLoglevel == "" || len(lokiTypes) == 0 {
-			d.internalListener.RemoveHandler("loki")
-		} else {
-			err := d.setupLoki(lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiInstance, lokiLoglevel, lokiLabels, lokiTypes)
-			if err != nil {
-				return err
-			}
+	if tracingChanged {
+		tracingAddress, tracingSampleRatio := clusterConfig.Tracing()
+		err := tracing.Setup(d.shutdownCtx, tracingAddress, tracingSampleRatio, d.serverName)
+		if err != nil {
+			return err
 		}
 	}
 
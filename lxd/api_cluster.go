@@ -1101,6 +1101,22 @@ func clusterAcceptMember(client lxd.InstanceServer, name string, address string,
 //  ---
 //  produces:
 //    - application/json
+//  parameters:
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -1144,6 +1160,22 @@ func clusterAcceptMember(client lxd.InstanceServer, name string, address string,
 //	---
 //	produces:
 //	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -1173,7 +1205,11 @@ func clusterAcceptMember(client lxd.InstanceServer, name string, address string,
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
 func clusterNodesGet(d *Daemon, r *http.Request) response.Response {
-	recursion := util.IsRecursionRequest(r)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
 	s := d.State()
 
 	leaderAddress, err := d.gateway.LeaderAddress()
@@ -1194,7 +1230,7 @@ func clusterNodesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	var members []db.NodeInfo
+	var memberNames []string
 	var membersInfo []api.ClusterMember
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		failureDomains, err := tx.GetFailureDomainsNames(ctx)
@@ -1207,7 +1243,7 @@ func clusterNodesGet(d *Daemon, r *http.Request) response.Response {
 			return fmt.Errorf("Failed loading member failure domains: %w", err)
 		}
 
-		members, err = tx.GetNodes(ctx)
+		members, err := tx.GetNodes(ctx)
 		if err != nil {
 			return fmt.Errorf("Failed getting cluster members: %w", err)
 		}
@@ -1217,6 +1253,11 @@ func clusterNodesGet(d *Daemon, r *http.Request) response.Response {
 			return fmt.Errorf("Failed getting max member version: %w", err)
 		}
 
+		upgradeStatuses, err := tx.GetClusterUpgradeMemberStatuses(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting rolling upgrade status: %w", err)
+		}
+
 		args := db.NodeInfoArgs{
 			LeaderAddress:        leaderAddress,
 			FailureDomains:       failureDomains,
@@ -1224,33 +1265,34 @@ func clusterNodesGet(d *Daemon, r *http.Request) response.Response {
 			OfflineThreshold:     s.GlobalConfig.OfflineThreshold(),
 			MaxMemberVersion:     maxVersion,
 			RaftNodes:            raftNodes,
+			UpgradeStatuses:      upgradeStatuses,
 		}
 
-		if recursion {
-			membersInfo = make([]api.ClusterMember, 0, len(members))
-			for i := range members {
-				member, err := members[i].ToAPI(ctx, tx, args)
-				if err != nil {
-					return err
-				}
-
-				membersInfo = append(membersInfo, *member)
-			}
+		names := make([]string, 0, len(members))
+		membersByName := make(map[string]db.NodeInfo, len(members))
+		for _, member := range members {
+			names = append(names, member.Name)
+			membersByName[member.Name] = member
 		}
 
-		return nil
+		memberNames, membersInfo, err = listEntries(opts, names, func(name string) (*api.ClusterMember, error) {
+			member := membersByName[name]
+			return member.ToAPI(ctx, tx, args)
+		})
+
+		return err
 	})
 	if err != nil {
 		return response.SmartError(err)
 	}
 
-	if recursion {
+	if opts.recursion {
 		return response.SyncResponse(true, membersInfo)
 	}
 
-	urls := make([]string, 0, len(members))
-	for _, member := range members {
-		u := api.NewURL().Path(version.APIVersion, "cluster", "members", member.Name)
+	urls := make([]string, 0, len(memberNames))
+	for _, memberName := range memberNames {
+		u := api.NewURL().Path(version.APIVersion, "cluster", "members", memberName)
 		urls = append(urls, u.String())
 	}
 
@@ -1489,6 +1531,11 @@ func clusterNodeGet(d *Daemon, r *http.Request) response.Response {
 			return fmt.Errorf("Failed getting max member version: %w", err)
 		}
 
+		upgradeStatuses, err := tx.GetClusterUpgradeMemberStatuses(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting rolling upgrade status: %w", err)
+		}
+
 		args := db.NodeInfoArgs{
 			LeaderAddress:        leaderAddress,
 			FailureDomains:       failureDomains,
@@ -1496,6 +1543,7 @@ func clusterNodeGet(d *Daemon, r *http.Request) response.Response {
 			OfflineThreshold:     s.GlobalConfig.OfflineThreshold(),
 			MaxMemberVersion:     maxVersion,
 			RaftNodes:            raftNodes,
+			UpgradeStatuses:      upgradeStatuses,
 		}
 
 		memberInfo, err = member.ToAPI(ctx, tx, args)
@@ -3086,6 +3134,12 @@ func internalClusterHeal(d *Daemon, r *http.Request) response.Response {
 			return nil
 		}
 
+		// Fence the clients the offline member uses to access the instance volumes.
+		err = clusterFenceInstanceLocks(context.TODO(), s, inst, pool, startInstance)
+		if err != nil {
+			return fmt.Errorf("Failed fencing instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
+		}
+
 		// Migrate the instance.
 		req := api.InstancePost{
 			Migration: true,
@@ -3303,9 +3357,28 @@ func evacuateInstances(ctx context.Context, opts evacuateOpts) error {
 				return err
 			}
 
+			// Only keep the members satisfying the placement group rules of the instance, ignoring the
+			// other instances of the group that are being evacuated too.
+			candidateMembers, err = instancePlacementCandidates(ctx, tx, instProject.Name, inst.Name(), inst.ExpandedConfig(), candidateMembers, func(groupInst db.InstanceArgs) string {
+				if groupInst.Node == opts.srcMemberName {
+					return ""
+				}
+
+				return groupInst.Node
+			})
+			if err != nil {
+				return err
+			}
+
 			return nil
 		})
 		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				// Skip migration if the placement group rules can't be satisfied.
+				l.Warn("No migration target available for instance", logger.Ctx{"err": err})
+				continue
+			}
+
 			return err
 		}
 
@@ -3429,6 +3502,34 @@ func restoreClusterMember(d *Daemon, r *http.Request) response.Response {
 		for _, inst := range instances {
 			l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
+			// Check that moving the instance back satisfies its placement group rules. The other instances
+			// of the group which are being restored too are considered to be back on the origin member.
+			err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				originNode, err := tx.GetNodeByName(ctx, originName)
+				if err != nil {
+					return fmt.Errorf("Failed to get node %q: %w", originName, err)
+				}
+
+				_, err = instancePlacementCandidates(ctx, tx, inst.Project().Name, inst.Name(), inst.ExpandedConfig(), []db.NodeInfo{originNode}, func(groupInst db.InstanceArgs) string {
+					if groupInst.Config["volatile.evacuate.origin"] == originName {
+						return originName
+					}
+
+					return groupInst.Node
+				})
+
+				return err
+			})
+			if err != nil {
+				if api.StatusErrorCheck(err, http.StatusNotFound) {
+					// Leave the instance where it is if the placement group rules can't be satisfied.
+					l.Warn("Not restoring instance to its origin member", logger.Ctx{"err": err})
+					continue
+				}
+
+				return err
+			}
+
 			// Check if live-migratable.
 			_, live := inst.CanMigrate()
 
@@ -3653,6 +3754,22 @@ func clusterGroupsPost(d *Daemon, r *http.Request) response.Response {
 //  ---
 //  produces:
 //    - application/json
+//  parameters:
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -3696,6 +3813,22 @@ func clusterGroupsPost(d *Daemon, r *http.Request) response.Response {
 //	---
 //	produces:
 //	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -3731,45 +3864,37 @@ func clusterGroupsGet(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("This server is not clustered"))
 	}
 
-	recursion := util.IsRecursionRequest(r)
-
-	var result any
-
-	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		var err error
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
 
-		if recursion {
-			clusterGroups, err := dbCluster.GetClusterGroups(ctx, tx.Tx())
-			if err != nil {
-				return err
-			}
+	var groupNames []string
+	var apiClusterGroups []api.ClusterGroup
 
-			for i := range clusterGroups {
-				nodeClusterGroups, err := dbCluster.GetNodeClusterGroups(ctx, tx.Tx(), dbCluster.NodeClusterGroupFilter{GroupID: &clusterGroups[i].ID})
-				if err != nil {
-					return err
-				}
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		clusterGroups, err := dbCluster.GetClusterGroups(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
 
-				clusterGroups[i].Nodes = make([]string, 0, len(nodeClusterGroups))
-				for _, node := range nodeClusterGroups {
-					clusterGroups[i].Nodes = append(clusterGroups[i].Nodes, node.Node)
-				}
-			}
+		names := make([]string, 0, len(clusterGroups))
+		clusterGroupsByName := make(map[string]dbCluster.ClusterGroup, len(clusterGroups))
+		for _, clusterGroup := range clusterGroups {
+			names = append(names, clusterGroup.Name)
+			clusterGroupsByName[clusterGroup.Name] = clusterGroup
+		}
 
-			apiClusterGroups := make([]*api.ClusterGroup, len(clusterGroups))
-			for i, clusterGroup := range clusterGroups {
-				members, err := tx.GetClusterGroupNodes(ctx, clusterGroup.Name)
-				if err != nil {
-					return err
-				}
+		groupNames, apiClusterGroups, err = listEntries(opts, names, func(name string) (*api.ClusterGroup, error) {
+			clusterGroup := clusterGroupsByName[name]
 
-				apiClusterGroups[i] = db.ClusterGroupToAPI(&clusterGroup, members)
+			members, err := tx.GetClusterGroupNodes(ctx, clusterGroup.Name)
+			if err != nil {
+				return nil, err
 			}
 
-			result = apiClusterGroups
-		} else {
-			result, err = tx.GetClusterGroupURIs(ctx, dbCluster.ClusterGroupFilter{})
-		}
+			return db.ClusterGroupToAPI(&clusterGroup, members), nil
+		})
 
 		return err
 	})
@@ -3777,7 +3902,16 @@ func clusterGroupsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	return response.SyncResponse(true, result)
+	if opts.recursion {
+		return response.SyncResponse(true, apiClusterGroups)
+	}
+
+	urls := make([]string, 0, len(groupNames))
+	for _, groupName := range groupNames {
+		urls = append(urls, api.NewURL().Path(version.APIVersion, "cluster", "groups", groupName).String())
+	}
+
+	return response.SyncResponse(true, urls)
 }
 
 // swagger:operation GET /1.0/cluster/groups/{name} cluster-groups cluster_group_get
@@ -4478,6 +4612,13 @@ func autoHealCluster(ctx context.Context, s *state.State, offlineMembers []db.No
 	}
 
 	for _, member := range offlineMembers {
+		// Don't restart the instances elsewhere unless the member is known not to be running them anymore.
+		err = clusterFenceMember(ctx, s, member)
+		if err != nil {
+			logger.Error("Failed fencing offline cluster member, skipping healing", logger.Ctx{"member": member.Name, "err": err})
+			continue
+		}
+
 		logger.Info("Healing cluster member instances", logger.Ctx{"member": member.Name})
 		_, _, err = dest.RawQuery("POST", fmt.Sprintf("/internal/cluster/heal/%s", member.Name), nil, "")
 		if err != nil {
@@ -49,6 +49,7 @@ var apiInternal = []APIEndpoint{
 	internalClusterRaftNodeCmd,
 	internalClusterRebalanceCmd,
 	internalClusterHealCmd,
+	internalClusterDatabaseBackupCmd,
 	internalContainerOnStartCmd,
 	internalContainerOnStopCmd,
 	internalContainerOnStopNSCmd,
@@ -61,6 +62,7 @@ var apiInternal = []APIEndpoint{
 	internalSQLCmd,
 	internalWarningCreateCmd,
 	internalIdentityCacheRefreshCmd,
+	internalWebhooksRefreshCmd,
 }
 
 var internalShutdownCmd = APIEndpoint{
@@ -142,6 +144,12 @@ var internalIdentityCacheRefreshCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: internalIdentityCacheRefresh, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
 }
 
+var internalWebhooksRefreshCmd = APIEndpoint{
+	Path: "webhooks-refresh",
+
+	Post: APIEndpointAction{Handler: internalWebhooksRefresh, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
 type internalImageOptimizePost struct {
 	Image api.Image `json:"image" yaml:"image"`
 	Pool  string    `json:"pool"  yaml:"pool"`
@@ -1042,3 +1050,14 @@ func internalIdentityCacheRefresh(d *Daemon, r *http.Request) response.Response
 	d.State().UpdateIdentityCache()
 	return response.EmptySyncResponse
 }
+
+func internalWebhooksRefresh(d *Daemon, r *http.Request) response.Response {
+	logger.Debug("Received webhooks update notification - reloading webhooks")
+
+	err := d.refreshWebhooks(r.Context())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
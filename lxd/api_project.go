@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/units"
 	"github.com/canonical/lxd/shared/validate"
 )
 
@@ -55,6 +57,12 @@ var projectStateCmd = APIEndpoint{
 	Get: APIEndpointAction{Handler: projectStateGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanView, "name")},
 }
 
+var projectUsageCmd = APIEndpoint{
+	Path: "projects/{name}/usage",
+
+	Get: APIEndpointAction{Handler: projectUsageGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanView, "name")},
+}
+
 // swagger:operation GET /1.0/projects projects projects_get
 //
 //  Get the projects
@@ -64,6 +72,22 @@ var projectStateCmd = APIEndpoint{
 //  ---
 //  produces:
 //    - application/json
+//  parameters:
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -107,6 +131,22 @@ var projectStateCmd = APIEndpoint{
 //	---
 //	produces:
 //	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -138,62 +178,66 @@ var projectStateCmd = APIEndpoint{
 func projectsGet(d *Daemon, r *http.Request) response.Response {
 	s := d.State()
 
-	recursion := util.IsRecursionRequest(r)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
 
 	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), auth.EntitlementCanView, entity.TypeProject)
 	if err != nil {
 		return response.InternalError(err)
 	}
 
-	var apiProjects []*api.Project
-	var projectURLs []string
+	var apiProjects []api.Project
+	var projectNames []string
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		allProjects, err := cluster.GetProjects(ctx, tx.Tx())
 		if err != nil {
 			return err
 		}
 
-		projects := make([]cluster.Project, 0, len(allProjects))
+		allowedNames := make([]string, 0, len(allProjects))
+		projectsByName := make(map[string]cluster.Project, len(allProjects))
 		for _, project := range allProjects {
 			if userHasPermission(entity.ProjectURL(project.Name)) {
-				projects = append(projects, project)
+				allowedNames = append(allowedNames, project.Name)
+				projectsByName[project.Name] = project
 			}
 		}
 
-		if recursion {
-			apiProjects = make([]*api.Project, 0, len(projects))
-			for _, project := range projects {
-				apiProject, err := project.ToAPI(ctx, tx.Tx())
-				if err != nil {
-					return err
-				}
+		projectNames, apiProjects, err = listEntries(opts, allowedNames, func(projectName string) (*api.Project, error) {
+			project := projectsByName[projectName]
 
-				apiProject.UsedBy, err = projectUsedBy(ctx, tx, &project)
-				if err != nil {
-					return err
-				}
-
-				apiProjects = append(apiProjects, apiProject)
+			apiProject, err := project.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return nil, err
 			}
-		} else {
-			projectURLs = make([]string, 0, len(projects))
-			for _, project := range projects {
-				projectURLs = append(projectURLs, entity.ProjectURL(project.Name).String())
+
+			apiProject.UsedBy, err = projectUsedBy(ctx, tx, &project)
+			if err != nil {
+				return nil, err
 			}
-		}
 
-		return nil
+			return apiProject, nil
+		})
+
+		return err
 	})
 	if err != nil {
 		return response.SmartError(err)
 	}
 
-	if !recursion {
+	if !opts.recursion {
+		projectURLs := make([]string, 0, len(projectNames))
+		for _, projectName := range projectNames {
+			projectURLs = append(projectURLs, entity.ProjectURL(projectName).String())
+		}
+
 		return response.SyncResponse(true, projectURLs)
 	}
 
-	for _, apiProject := range apiProjects {
-		apiProject.UsedBy = projecthelpers.FilterUsedBy(s.Authorizer, r, apiProject.UsedBy)
+	for i := range apiProjects {
+		apiProjects[i].UsedBy = projecthelpers.FilterUsedBy(s.Authorizer, r, apiProjects[i].UsedBy)
 	}
 
 	return response.SyncResponse(true, apiProjects)
@@ -948,6 +992,87 @@ func projectStateGet(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, &state)
 }
 
+// swagger:operation GET /1.0/projects/{name}/usage projects project_usage_get
+//
+//	Get the project usage history
+//
+//	Gets the resource usage of a specific project over a period of time.
+//	The usage is recorded per day (UTC), both ends of the period are included.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: from
+//	    description: First day of the period (defaults to the first day of the current month)
+//	    type: string
+//	    example: 2024-01-01
+//	  - in: query
+//	    name: to
+//	    description: Last day of the period (defaults to the current day)
+//	    type: string
+//	    example: 2024-01-31
+//	responses:
+//	  "200":
+//	    description: Project usage
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ProjectUsage"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func projectUsageGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	from, to, err := projectUsagePeriod(request.QueryParam(r, "from"), request.QueryParam(r, "to"), time.Now())
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	var usage *db.ProjectUsage
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		usage, err = tx.GetProjectUsage(ctx, name, from, to)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, api.ProjectUsage{
+		From:                 from,
+		To:                   to,
+		CPUSeconds:           usage.CPUSeconds,
+		MemoryGiBHours:       usage.MemoryGiBHours,
+		DiskGiBDays:          usage.DiskGiBDays,
+		NetworkBytesReceived: usage.NetworkBytesReceived,
+		NetworkBytesSent:     usage.NetworkBytesSent,
+	})
+}
+
 // Check if a project is empty.
 func projectIsEmpty(ctx context.Context, project *cluster.Project, tx *db.ClusterTx) (bool, error) {
 	instances, err := cluster.GetInstances(ctx, tx.Tx(), cluster.InstanceFilter{Project: &project.Name})
@@ -1020,6 +1145,15 @@ func isEitherAllowOrBlockOrManaged(value string) error {
 	return validate.Optional(validate.IsOneOf("block", "allow", "managed"))(value)
 }
 
+func isBitRate(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	_, err := units.ParseBitSizeString(value)
+	return err
+}
+
 func projectValidateConfig(s *state.State, config map[string]string) error {
 	// Validate the project configuration.
 	projectConfigKeys := map[string]func(value string) error{
@@ -1103,6 +1237,12 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  type: string
 		//  shortdesc: Default architecture to use in a mixed-architecture cluster
 		"images.default_architecture": validate.Optional(validate.IsArchitecture),
+		// lxdmeta:generate(entities=project; group=specific; key=images.require_signature)
+		// When set, overrides the server {config:option}`server-images:images.require_signature` option for the project.
+		// ---
+		//  type: bool
+		//  shortdesc: Whether images in the project must be signed by a trusted key
+		"images.require_signature": validate.Optional(validate.IsBool),
 		// lxdmeta:generate(entities=project; group=specific; key=images.remote_cache_expiry)
 		// Specify the number of days after which the unused cached image expires.
 		// ---
@@ -1157,6 +1297,37 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  type: integer
 		//  shortdesc: Maximum number of networks that the project can have
 		"limits.networks": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.snapshots)
+		// This value is the maximum number of instance and custom volume snapshots in the project, including scheduled snapshots.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum number of snapshots that can be created in the project
+		"limits.snapshots": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.backups)
+		// This value is the maximum number of instance and custom volume backups in the project.
+		// ---
+		//  type: integer
+		//  shortdesc: Maximum number of backups that can be created in the project
+		"limits.backups": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.backups.size)
+		// This value is the maximum value of the aggregate size of the backup tarballs of the project.
+		// A backup that would exceed it is removed once created.
+		// ---
+		//  type: string
+		//  shortdesc: Maximum disk space used by the backups of the project
+		"limits.backups.size": validate.Optional(validate.IsSize),
+		// lxdmeta:generate(entities=project; group=limits; key=limits.network.ingress)
+		// This value is the maximum value for the sum of the individual `limits.ingress` (or `limits.max`) configurations set on the NIC devices of the instances of the project.
+		// ---
+		//  type: string
+		//  shortdesc: Maximum aggregate incoming bandwidth of the instances of the project
+		"limits.network.ingress": isBitRate,
+		// lxdmeta:generate(entities=project; group=limits; key=limits.network.egress)
+		// This value is the maximum value for the sum of the individual `limits.egress` (or `limits.max`) configurations set on the NIC devices of the instances of the project.
+		// ---
+		//  type: string
+		//  shortdesc: Maximum aggregate outgoing bandwidth of the instances of the project
+		"limits.network.egress": isBitRate,
 		// lxdmeta:generate(entities=project; group=restricted; key=restricted)
 		// This option must be enabled to allow the `restricted.*` keys to take effect.
 		// To temporarily remove the restrictions, you can disable this option instead of clearing the related keys.
@@ -1370,6 +1541,14 @@ func projectValidateConfig(s *state.State, config map[string]string) error {
 		//  defaultdesc: `block`
 		//  shortdesc: Which network zones can be used in this project
 		"restricted.networks.zones": validate.IsListOf(validate.IsAny),
+		// lxdmeta:generate(entities=project; group=restricted; key=restricted.replication)
+		// Possible values are `allow` or `block`.
+		// When set to `allow`, instances and custom volumes can be replicated to another server, but only to the project of the same name on that server.
+		// ---
+		//  type: string
+		//  defaultdesc: `block`
+		//  shortdesc: Whether to prevent replicating instances or volumes to another server
+		"restricted.replication": isEitherAllowOrBlock,
 		// lxdmeta:generate(entities=project; group=restricted; key=restricted.snapshots)
 		//
 		// ---
@@ -0,0 +1,166 @@
+// Package audit contains helpers used to record API mutations in the audit log.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// IsMutating returns whether requests with the given HTTP method may modify state and should be recorded.
+func IsMutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	return true
+}
+
+// ResponseRecorder wraps an http.ResponseWriter to capture the status code written to it.
+type ResponseRecorder struct {
+	http.ResponseWriter
+
+	statusCode int
+}
+
+// NewResponseRecorder returns a ResponseRecorder writing to w.
+func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{ResponseWriter: w}
+}
+
+// WriteHeader records the status code and passes it to the underlying writer.
+func (r *ResponseRecorder) WriteHeader(statusCode int) {
+	if r.statusCode == 0 {
+		r.statusCode = statusCode
+	}
+
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes to the underlying writer, recording an implicit 200 status if no status was written.
+func (r *ResponseRecorder) Write(b []byte) (int, error) {
+	if r.statusCode == 0 {
+		r.statusCode = http.StatusOK
+	}
+
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush flushes the underlying writer if it supports it.
+func (r *ResponseRecorder) Flush() {
+	flusher, ok := r.ResponseWriter.(http.Flusher)
+	if ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the underlying connection. The request is recorded as having switched protocols.
+func (r *ResponseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("Response writer does not support hijacking")
+	}
+
+	if r.statusCode == 0 {
+		r.statusCode = http.StatusSwitchingProtocols
+	}
+
+	return hijacker.Hijack()
+}
+
+// StatusCode returns the status code written to the response, or 200 if nothing was written.
+func (r *ResponseRecorder) StatusCode() int {
+	if r.statusCode == 0 {
+		return http.StatusOK
+	}
+
+	return r.statusCode
+}
+
+// BodyDigestLimit is the number of bytes at the start of a request body included in its digest, so that recording
+// large uploads doesn't require reading them whole.
+const BodyDigestLimit = 1024 * 1024
+
+// BodyDigester wraps a request body to compute the SHA-256 digest of the first BodyDigestLimit bytes read from it.
+type BodyDigester struct {
+	body      io.ReadCloser
+	hash      hash.Hash
+	remaining int64
+}
+
+// NewBodyDigester returns a BodyDigester reading from body.
+func NewBodyDigester(body io.ReadCloser) *BodyDigester {
+	return &BodyDigester{body: body, hash: sha256.New(), remaining: BodyDigestLimit}
+}
+
+// Read reads from the body and adds the data to the digest until the limit is reached.
+func (d *BodyDigester) Read(p []byte) (int, error) {
+	n, err := d.body.Read(p)
+
+	digested := min(int64(n), d.remaining)
+	_, _ = d.hash.Write(p[:digested])
+	d.remaining -= digested
+
+	return n, err
+}
+
+// Close closes the body.
+func (d *BodyDigester) Close() error {
+	return d.body.Close()
+}
+
+// Sum reads the data left unread by the handler up to the limit and returns the hex encoded SHA-256 digest of the
+// start of the body.
+func (d *BodyDigester) Sum() string {
+	if d.remaining > 0 {
+		_, _ = io.CopyN(io.Discard, d, d.remaining)
+	}
+
+	return hex.EncodeToString(d.hash.Sum(nil))
+}
+
+// EntityFromURL returns the type and URL of the entity a request URL acts on. Trailing path elements that don't
+// belong to an entity (e.g. "/state" or "/snapshots") are dropped. Empty values are returned for requests made to
+// collections or to endpoints that don't act on an entity.
+func EntityFromURL(u *url.URL) (entity.Type, string) {
+	prefix := "/" + version.APIVersion
+
+	path := u.Path
+	if u.RawPath != "" {
+		path = u.RawPath
+	}
+
+	for {
+		candidate := url.URL{Path: path, RawPath: path, RawQuery: u.RawQuery}
+
+		entityType, projectName, location, pathArgs, err := entity.ParseURL(candidate)
+		if err == nil {
+			entityURL, err := entityType.URL(projectName, location, pathArgs...)
+			if err != nil {
+				return "", ""
+			}
+
+			return entityType, entityURL.String()
+		}
+
+		// Stop before trimming the path down to a collection.
+		if !strings.Contains(strings.TrimPrefix(path, prefix+"/"), "/") {
+			break
+		}
+
+		path = path[:strings.LastIndex(path, "/")]
+	}
+
+	return "", ""
+}
@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/entity"
+)
+
+func TestIsMutating(t *testing.T) {
+	assert.False(t, IsMutating(http.MethodGet))
+	assert.False(t, IsMutating(http.MethodHead))
+	assert.True(t, IsMutating(http.MethodPost))
+	assert.True(t, IsMutating(http.MethodPut))
+	assert.True(t, IsMutating(http.MethodPatch))
+	assert.True(t, IsMutating(http.MethodDelete))
+}
+
+func TestResponseRecorder(t *testing.T) {
+	r := NewResponseRecorder(httptest.NewRecorder())
+	assert.Equal(t, http.StatusOK, r.StatusCode())
+
+	r.WriteHeader(http.StatusAccepted)
+	_, err := r.Write([]byte("{}"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, r.StatusCode())
+
+	r = NewResponseRecorder(httptest.NewRecorder())
+	_, err = r.Write([]byte("{}"))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, r.StatusCode())
+}
+
+func TestBodyDigester(t *testing.T) {
+	body := []byte(`{"name": "c1"}`)
+	expected := sha256.Sum256(body)
+
+	d := NewBodyDigester(io.NopCloser(bytes.NewReader(body)))
+
+	// Partially read bodies are still fully digested.
+	buf := make([]byte, 4)
+	_, err := d.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(expected[:]), d.Sum())
+
+	// Only the start of large bodies is digested, and the rest isn't read.
+	body = bytes.Repeat([]byte("a"), BodyDigestLimit+10)
+	expected = sha256.Sum256(body[:BodyDigestLimit])
+
+	reader := bytes.NewReader(body)
+	d = NewBodyDigester(io.NopCloser(reader))
+	assert.Equal(t, hex.EncodeToString(expected[:]), d.Sum())
+	assert.Equal(t, 10, reader.Len())
+}
+
+func TestEntityFromURL(t *testing.T) {
+	tests := []struct {
+		url        string
+		entityType entity.Type
+		entityURL  string
+	}{
+		{"/1.0", entity.TypeServer, "/1.0"},
+		{"/1.0/instances", "", ""},
+		{"/1.0/instances/c1?project=foo", entity.TypeInstance, "/1.0/instances/c1?project=foo"},
+		{"/1.0/instances/c1/state", entity.TypeInstance, "/1.0/instances/c1?project=default"},
+		{"/1.0/instances/c1/snapshots", entity.TypeInstance, "/1.0/instances/c1?project=default"},
+		{"/1.0/instances/c1/snapshots/snap0", entity.TypeInstanceSnapshot, "/1.0/instances/c1/snapshots/snap0?project=default"},
+		{"/1.0/projects/foo", entity.TypeProject, "/1.0/projects/foo"},
+		{"/1.0/unknown/foo/bar", "", ""},
+	}
+
+	for _, test := range tests {
+		u, err := url.Parse(test.url)
+		require.NoError(t, err)
+
+		entityType, entityURL := EntityFromURL(u)
+		assert.Equal(t, test.entityType, entityType, test.url)
+		assert.Equal(t, test.entityURL, entityURL, test.url)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/audit"
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+var auditLogCmd = APIEndpoint{
+	Path: "audit",
+
+	Get: APIEndpointAction{Handler: auditLogGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanViewAuditLog)},
+}
+
+// swagger:operation GET /1.0/audit audit audit_get
+//
+//	Get the audit log
+//
+//	Returns the mutating API requests recorded in the audit log of the cluster member, oldest first.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: target
+//	    description: Cluster member name
+//	    type: string
+//	    example: lxd01
+//	  - in: query
+//	    name: since
+//	    description: Only return entries recorded at or after this time (RFC3339)
+//	    type: string
+//	    example: 2024-06-01T00:00:00Z
+//	  - in: query
+//	    name: until
+//	    description: Only return entries recorded at or before this time (RFC3339)
+//	    type: string
+//	    example: 2024-06-02T00:00:00Z
+//	  - in: query
+//	    name: identity
+//	    description: Only return entries for requests made by this identity
+//	    type: string
+//	    example: foo@example.com
+//	  - in: query
+//	    name: entity
+//	    description: Only return entries for requests acting on this entity URL
+//	    type: string
+//	    example: /1.0/instances/c1?project=default
+//	responses:
+//	  "200":
+//	    description: Audit log entries
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of audit log entries
+//	          items:
+//	            $ref: "#/definitions/AuditLogEntry"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func auditLogGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	// If a target was specified, forward the request to the relevant node.
+	resp := forwardedResponseIfTargetIsRemote(s, r)
+	if resp != nil {
+		return resp
+	}
+
+	filter := db.AuditLogFilter{
+		Identity: request.QueryParam(r, "identity"),
+		Entity:   request.QueryParam(r, "entity"),
+	}
+
+	var err error
+	for key, value := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		param := request.QueryParam(r, key)
+		if param == "" {
+			continue
+		}
+
+		*value, err = time.Parse(time.RFC3339, param)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid %q time %q: %w", key, param, err))
+		}
+	}
+
+	var entries []api.AuditLogEntry
+	err = s.DB.Node.Transaction(r.Context(), func(ctx context.Context, tx *db.NodeTx) error {
+		entries, err = tx.GetAuditLogEntries(ctx, filter)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	for i := range entries {
+		entries[i].Location = s.ServerName
+	}
+
+	return response.SyncResponse(true, entries)
+}
+
+// recordAuditLogEntry records a mutating API request in the audit log of the local member once it has been handled.
+// Notifications sent between cluster members on their own behalf are not recorded.
+func recordAuditLogEntry(s *state.State, r *http.Request, date time.Time, body *audit.BodyDigester, w *audit.ResponseRecorder) {
+	protocol, _ := r.Context().Value(request.CtxProtocol).(string)
+	forwardedUsername, _ := r.Context().Value(request.CtxForwardedUsername).(string)
+	if protocol == auth.AuthenticationMethodCluster && forwardedUsername == "" {
+		return
+	}
+
+	requestor := request.CreateRequestor(r)
+	entityType, entityURL := audit.EntityFromURL(r.URL)
+
+	entry := api.AuditLogEntry{
+		Date:                 date,
+		Identity:             requestor.Username,
+		AuthenticationMethod: requestor.Protocol,
+		SourceAddress:        requestor.Address,
+		Method:               r.Method,
+		URL:                  r.URL.RequestURI(),
+		EntityType:           string(entityType),
+		EntityURL:            entityURL,
+		BodySHA256:           body.Sum(),
+		StatusCode:           w.StatusCode(),
+	}
+
+	err := s.DB.Node.Transaction(context.Background(), func(ctx context.Context, tx *db.NodeTx) error {
+		return tx.CreateAuditLogEntry(ctx, entry)
+	})
+	if err != nil {
+		logger.Error("Failed recording audit log entry", logger.Ctx{"method": entry.Method, "url": entry.URL, "err": err})
+	}
+}
+
+// This task function deletes the audit log entries older than the configured retention. It's started by the Daemon
+// and will run once every 24h.
+func expireAuditLogTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		st := d.State()
+
+		retention := st.GlobalConfig.AuditLogRetentionDays()
+		if retention <= 0 {
+			return
+		}
+
+		opRun := func(op *operations.Operation) error {
+			return st.DB.Node.Transaction(ctx, func(ctx context.Context, tx *db.NodeTx) error {
+				return tx.DeleteAuditLogEntriesBefore(ctx, time.Now().AddDate(0, 0, -int(retention)))
+			})
+		}
+
+		op, err := operations.OperationCreate(st, "", operations.OperationClassTask, operationtype.AuditLogExpire, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating audit log expiry operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Expiring audit log entries")
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting audit log expiry operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed expiring audit log entries", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Done expiring audit log entries")
+	}
+
+	return f, task.Daily()
+}
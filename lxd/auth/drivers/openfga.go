@@ -269,6 +269,47 @@ func (e *embeddedOpenFGA) CheckPermission(ctx context.Context, entityURL *api.UR
 	return nil
 }
 
+// CheckGroupPermission returns true if membership of the given group alone grants the entitlement on the entity.
+func (e *embeddedOpenFGA) CheckGroupPermission(ctx context.Context, groupName string, entityURL *api.URL, entitlement auth.Entitlement) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Deconstruct the given URL.
+	entityType, projectName, location, pathArguments, err := entity.ParseURL(entityURL.URL)
+	if err != nil {
+		return false, fmt.Errorf("Authorization driver failed to parse entity URL %q: %w", entityURL.String(), err)
+	}
+
+	// Construct the URL in a standardised form (adding the project parameter if it was not present).
+	entityURL, err = entityType.URL(projectName, location, pathArguments...)
+	if err != nil {
+		return false, fmt.Errorf("Failed to standardize entity URL: %w", err)
+	}
+
+	// Check the relation for the members of the group rather than for a particular identity.
+	req := &openfgav1.CheckRequest{
+		StoreId: dummyDatastoreULID,
+		TupleKey: &openfgav1.CheckRequestTupleKey{
+			User:     fmt.Sprintf("%s:%s#member", entity.TypeAuthGroup, entity.AuthGroupURL(groupName).String()),
+			Relation: string(entitlement),
+			Object:   fmt.Sprintf("%s:%s", entityType, entityURL.String()),
+		},
+	}
+
+	resp, err := e.server.Check(ctx, req)
+	if err != nil {
+		// Attempt to extract the internal error. This allows bubbling errors up from the OpenFGA datastore implementation.
+		var openFGAInternalError openFGAErrors.InternalError
+		if errors.As(err, &openFGAInternalError) {
+			err = openFGAInternalError.Internal()
+		}
+
+		return false, fmt.Errorf("Failed to check OpenFGA relation: %w", err)
+	}
+
+	return resp.GetAllowed(), nil
+}
+
 // GetPermissionChecker returns a PermissionChecker using the embedded OpenFGA server.
 func (e *embeddedOpenFGA) GetPermissionChecker(ctx context.Context, entitlement auth.Entitlement, entityType entity.Type) (auth.PermissionChecker, error) {
 	logCtx := logger.Ctx{"entity_type": entityType, "entitlement": entitlement}
@@ -40,6 +40,13 @@ type Authorizer interface {
 	GetPermissionChecker(ctx context.Context, entitlement Entitlement, entityType entity.Type) (PermissionChecker, error)
 }
 
+// GroupPermissionChecker is implemented by authorizers that grant entitlements via group membership. It is used to
+// explain which groups grant an entitlement to an identity.
+type GroupPermissionChecker interface {
+	// CheckGroupPermission returns true if membership of the given group alone grants the entitlement on the entity.
+	CheckGroupPermission(ctx context.Context, groupName string, entityURL *api.URL, entitlement Entitlement) (bool, error)
+}
+
 // IsDeniedError returns true if the error is not found or forbidden. This is because the CheckPermission method on
 // Authorizer will return a not found error if the requestor does not have access to view the resource. If a requestor
 // has view access, but not edit access a forbidden error is returned.
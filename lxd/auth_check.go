@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/identity"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+)
+
+var authCheckCmd = APIEndpoint{
+	Name: "auth-check",
+	Path: "auth/check",
+	Post: APIEndpointAction{
+		Handler:       authCheck,
+		AccessHandler: allowAuthenticated,
+	},
+}
+
+// swagger:operation POST /1.0/auth/check auth auth_check_post
+//
+//	Check a permission
+//
+//	Checks whether an identity (or the caller) has an entitlement on an entity and returns the groups and permissions
+//	that grant it. Checking the permissions of another identity requires the `can_view_permissions` entitlement on the server.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: check
+//	    description: Permission check request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/AuthCheckPost"
+//	responses:
+//	  "200":
+//	    description: Permission check result
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/AuthCheck"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func authCheck(d *Daemon, r *http.Request) response.Response {
+	var req api.AuthCheckPost
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Failed to unmarshal request body: %w", err))
+	}
+
+	u, err := url.Parse(req.EntityReference)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid entity URL %q: %w", req.EntityReference, err))
+	}
+
+	entityType, projectName, location, pathArgs, err := entity.ParseURL(*u)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid entity URL %q: %w", req.EntityReference, err))
+	}
+
+	entityURL, err := entityType.URL(projectName, location, pathArgs...)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	entitlement := auth.Entitlement(req.Entitlement)
+	err = auth.ValidateEntitlement(entityType, entitlement)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	s := d.State()
+
+	// By default the caller is checked using the request context as is.
+	ctx := r.Context()
+	if req.AuthenticationMethod != "" || req.Identity != "" {
+		err = identity.ValidateAuthenticationMethod(req.AuthenticationMethod)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		var id *dbCluster.Identity
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			id, err = dbCluster.GetIdentityByNameOrIdentifier(ctx, tx.Tx(), req.AuthenticationMethod, req.Identity)
+			return err
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		err = s.Authorizer.CheckPermission(r.Context(), entity.IdentityURL(req.AuthenticationMethod, id.Identifier), auth.EntitlementCanView)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		err = checkCallerCanViewPermissionsOf(r, s.Authorizer, d.identityCache, req.AuthenticationMethod, id.Identifier)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// Check as if the request had been made by the identity. Identity provider groups are only known for the
+		// duration of a session so are not considered.
+		ctx = context.WithValue(ctx, request.CtxTrusted, true)
+		ctx = context.WithValue(ctx, request.CtxUsername, id.Identifier)
+		ctx = context.WithValue(ctx, request.CtxProtocol, req.AuthenticationMethod)
+		ctx = context.WithValue(ctx, request.CtxIdentityProviderGroups, []string{})
+	}
+
+	result := api.AuthCheck{
+		Groups:      []string{},
+		Permissions: []api.Permission{},
+	}
+
+	err = s.Authorizer.CheckPermission(ctx, entityURL, entitlement)
+	if err != nil && !auth.IsDeniedError(err) {
+		return response.SmartError(err)
+	}
+
+	result.Allowed = err == nil
+	if !result.Allowed {
+		return response.SyncResponse(true, result)
+	}
+
+	// Work out which groups grant the entitlement if the authorizer supports it.
+	groupChecker, ok := s.Authorizer.(auth.GroupPermissionChecker)
+	if !ok {
+		return response.SyncResponse(true, result)
+	}
+
+	isAdmin, err := auth.IsServerAdmin(ctx, d.identityCache)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if isAdmin {
+		return response.SyncResponse(true, result)
+	}
+
+	id, err := auth.GetIdentityFromCtx(ctx, d.identityCache)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	groups, err := effectiveGroupsFromCtx(ctx, d.identityCache, id)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	for _, groupName := range groups {
+		granted, err := groupChecker.CheckGroupPermission(ctx, groupName, entityURL, entitlement)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if granted {
+			result.Groups = append(result.Groups, groupName)
+		}
+	}
+
+	if len(result.Groups) == 0 {
+		return response.SyncResponse(true, result)
+	}
+
+	// Report the permissions of the granting groups that apply to the entity, its project or the server.
+	relevantURLs := []string{entityURL.String(), entity.ServerURL().String()}
+	requiresProject, _ := entityType.RequiresProject()
+	if requiresProject {
+		relevantURLs = append(relevantURLs, entity.ProjectURL(projectName).String())
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		permissions, err := getEffectivePermissions(ctx, tx, result.Groups)
+		if err != nil {
+			return err
+		}
+
+		for _, permission := range permissions {
+			if shared.ValueInSlice(permission.EntityReference, relevantURLs) {
+				result.Permissions = append(result.Permissions, permission)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, result)
+}
+
+// effectiveGroupsFromCtx returns the groups the identity is a member of, including those mapped from the identity
+// provider groups in the context.
+func effectiveGroupsFromCtx(ctx context.Context, identityCache *identity.Cache, id *identity.CacheEntry) ([]string, error) {
+	groups := append([]string{}, id.Groups...)
+
+	idpGroups, err := auth.GetIdentityProviderGroupsFromCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get caller identity provider groups: %w", err)
+	}
+
+	for _, idpGroup := range idpGroups {
+		lxdGroups, err := identityCache.GetIdentityProviderGroupMapping(idpGroup)
+		if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return nil, fmt.Errorf("Failed to get identity provider group mapping for group %q: %w", idpGroup, err)
+		} else if err != nil {
+			continue
+		}
+
+		for _, lxdGroup := range lxdGroups {
+			if !shared.ValueInSlice(lxdGroup, groups) {
+				groups = append(groups, lxdGroup)
+			}
+		}
+	}
+
+	return groups, nil
+}
@@ -89,6 +89,22 @@ func validateGroupName(name string) error {
 //	---
 //	produces:
 //	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -132,6 +148,22 @@ func validateGroupName(name string) error {
 //	---
 //	produces:
 //	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -162,6 +194,14 @@ func validateGroupName(name string) error {
 //	    $ref: "#/responses/InternalServerError"
 func getAuthGroups(d *Daemon, r *http.Request) response.Response {
 	recursion := request.QueryParam(r, "recursion")
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// The details of the groups are needed to return or filter them.
+	withDetails := recursion == "1" || opts.filtered()
+
 	s := d.State()
 
 	canViewGroup, err := s.Authorizer.GetPermissionChecker(r.Context(), auth.EntitlementCanView, entity.TypeAuthGroup)
@@ -201,7 +241,7 @@ func getAuthGroups(d *Daemon, r *http.Request) response.Response {
 			return nil
 		}
 
-		if recursion == "1" {
+		if withDetails {
 			// If recursing, we need all identities for all groups, all IDP groups for all groups,
 			// all permissions for all groups, and finally the URLs that those permissions apply to.
 			groupsIdentities, err = dbCluster.GetAllIdentitiesByAuthGroupIDs(ctx, tx.Tx())
@@ -232,13 +272,13 @@ func getAuthGroups(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	if recursion == "1" {
+	apiGroups := make([]api.AuthGroup, 0, len(groups))
+	if withDetails {
 		authGroupPermissionsByGroupID := make(map[int][]dbCluster.Permission, len(groups))
 		for _, permission := range authGroupPermissions {
 			authGroupPermissionsByGroupID[permission.GroupID] = append(authGroupPermissionsByGroupID[permission.GroupID], permission)
 		}
 
-		apiGroups := make([]api.AuthGroup, 0, len(groups))
 		for _, group := range groups {
 			var apiPermissions []api.Permission
 
@@ -279,11 +319,23 @@ func getAuthGroups(d *Daemon, r *http.Request) response.Response {
 			})
 		}
 
+	} else {
+		for _, group := range groups {
+			apiGroups = append(apiGroups, api.AuthGroup{Name: group.Name})
+		}
+	}
+
+	apiGroups, err = listPage(opts, apiGroups, func(group api.AuthGroup) string { return group.Name })
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if recursion == "1" {
 		return response.SyncResponse(true, apiGroups)
 	}
 
-	groupURLs := make([]string, 0, len(groups))
-	for _, group := range groups {
+	groupURLs := make([]string, 0, len(apiGroups))
+	for _, group := range apiGroups {
 		groupURLs = append(groupURLs, entity.AuthGroupURL(group.Name).String())
 	}
 
@@ -706,7 +758,7 @@ func renameAuthGroup(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Notify other cluster members to update their identity cache.
-	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
 		return response.SmartError(err)
 	}
@@ -767,7 +819,7 @@ func deleteAuthGroup(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Notify other cluster members to update their identity cache.
-	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
 		return response.SmartError(err)
 	}
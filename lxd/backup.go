@@ -30,6 +30,7 @@ import (
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/units"
+	"github.com/canonical/lxd/shared/version"
 )
 
 // Create a new backup.
@@ -66,6 +67,12 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		})
 	})
 
+	instName, backupName, _ := api.GetParentAndSnapshotName(args.Name)
+	err = op.AddRollback(api.NewURL().Path(version.APIVersion, "instances", instName, "backups", backupName).Project(sourceInst.Project().Name), "")
+	if err != nil {
+		return err
+	}
+
 	// Get the backup struct.
 	b, err := instance.BackupLoadByName(s, sourceInst.Project().Name, args.Name)
 	if err != nil {
@@ -194,7 +201,12 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		return fmt.Errorf("Error writing backup index file: %w", err)
 	}
 
-	err = pool.BackupInstance(sourceInst, tarWriter, b.OptimizedStorage(), !b.InstanceOnly(), nil)
+	// Freeze the guest filesystems only while the storage driver takes the snapshot the backup is read from.
+	freeze := func() func() {
+		return instanceFreezeFilesystems(sourceInst, op)
+	}
+
+	err = pool.BackupInstance(sourceInst, tarWriter, b.OptimizedStorage(), !b.InstanceOnly(), freeze, nil)
 	if err != nil {
 		return fmt.Errorf("Backup create: %w", err)
 	}
@@ -221,6 +233,24 @@ func backupCreate(s *state.State, args db.InstanceBackup, sourceInst instance.In
 		return fmt.Errorf("Error closing tar file: %w", err)
 	}
 
+	// Record the size of the backup and check it against the project limits.
+	fi, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err := tx.UpdateInstanceBackupSize(ctx, args.Name, fi.Size())
+		if err != nil {
+			return err
+		}
+
+		return project.CheckBackupsSizeLimit(tx, sourceInst.Project().Name)
+	})
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 	s.Events.SendLifecycle(sourceInst.Project().Name, lifecycle.InstanceBackupCreated.Event(args.Name, b.Instance(), nil))
 
@@ -382,7 +412,7 @@ func pruneExpiredInstanceBackups(ctx context.Context, s *state.State) error {
 	return nil
 }
 
-func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, projectName string, poolName string, volumeName string) error {
+func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, projectName string, poolName string, volumeName string, op *operations.Operation) error {
 	l := logger.AddContext(logger.Ctx{"project": projectName, "storage_volume": volumeName, "name": args.Name})
 	l.Debug("Volume backup started")
 	defer l.Debug("Volume backup finished")
@@ -415,6 +445,12 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 		})
 	})
 
+	_, backupName, _ := api.GetParentAndSnapshotName(args.Name)
+	err = op.AddRollback(api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "volumes", dbCluster.StoragePoolVolumeTypeNameCustom, volumeName, "backups", backupName).Project(projectName), "")
+	if err != nil {
+		return err
+	}
+
 	var backupRow db.StoragePoolVolumeBackup
 
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -526,6 +562,24 @@ func volumeBackupCreate(s *state.State, args db.StoragePoolVolumeBackup, project
 		return fmt.Errorf("Error closing tar file: %w", err)
 	}
 
+	// Record the size of the backup and check it against the project limits.
+	fi, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err := tx.UpdateStoragePoolVolumeBackupSize(ctx, backupRow.Name, fi.Size())
+		if err != nil {
+			return err
+		}
+
+		return project.CheckBackupsSizeLimit(tx, projectName)
+	})
+	if err != nil {
+		return err
+	}
+
 	revert.Success()
 	return nil
 }
@@ -0,0 +1,565 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/locking"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/filter"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/validate"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var bulkCmd = APIEndpoint{
+	Path: "bulk",
+
+	Post: APIEndpointAction{Handler: bulkPost, AccessHandler: allowProjectResourceList},
+}
+
+// bulkActionEntitlement returns the entitlement on an instance required to apply a bulk action to it.
+func bulkActionEntitlement(action string) (auth.Entitlement, error) {
+	switch action {
+	case string(instancetype.Start), string(instancetype.Stop), string(instancetype.Restart):
+		return auth.EntitlementCanUpdateState, nil
+	case "snapshot":
+		return auth.EntitlementCanManageSnapshots, nil
+	case "delete":
+		return auth.EntitlementCanDelete, nil
+	case "patch":
+		return auth.EntitlementCanEdit, nil
+	}
+
+	return "", fmt.Errorf("Unknown action %q", action)
+}
+
+// swagger:operation POST /1.0/bulk bulk bulk_post
+//
+//	Apply an action to instances matching a filter
+//
+//	Starts, stops, restarts, snapshots, deletes or updates the configuration of all the instances of the project
+//	matching the filter, across all cluster members.
+//	The result of the action for each instance is recorded in the "results" field of the operation metadata.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: bulk
+//	    description: Bulk action
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/BulkPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func bulkPost(d *Daemon, r *http.Request) response.Response {
+	// Don't mess with instances while in setup mode.
+	<-d.waitReady.Done()
+
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	req := api.BulkPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	entitlement, err := bulkActionEntitlement(req.Action)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Applying an action to all the instances is only ever done explicitly.
+	if req.Filter == "" {
+		return response.BadRequest(fmt.Errorf("A filter is required"))
+	}
+
+	clauses, err := filter.Parse(req.Filter, filter.QueryOperatorSet())
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid filter: %w", err))
+	}
+
+	switch req.Action {
+	case string(instancetype.Start), string(instancetype.Stop), string(instancetype.Restart):
+		if req.State == nil {
+			req.State = &api.InstanceStatePut{Timeout: -1}
+		}
+
+		req.State.Action = req.Action
+	case "snapshot":
+		if req.Snapshot == nil {
+			req.Snapshot = &api.InstanceSnapshotsPost{}
+		}
+
+		if req.Snapshot.Name != "" {
+			err = validate.IsURLSegmentSafe(req.Snapshot.Name)
+			if err != nil {
+				return response.BadRequest(fmt.Errorf("Invalid snapshot name: %w", err))
+			}
+		}
+
+	case "patch":
+		if len(req.Config) == 0 {
+			return response.BadRequest(fmt.Errorf("No configuration to apply"))
+		}
+	}
+
+	userCanView, err := s.Authorizer.GetPermissionChecker(r.Context(), auth.EntitlementCanView, entity.TypeInstance)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), entitlement, entity.TypeInstance)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Only the instances of this member are matched, the other members match their own.
+	instances, err := bulkLocalInstances(r.Context(), s, projectName, *clauses, userCanView)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	results := []api.BulkResult{}
+	resultsLock := sync.Mutex{}
+	addResults := func(newResults ...api.BulkResult) {
+		resultsLock.Lock()
+		results = append(results, newResults...)
+		resultsLock.Unlock()
+	}
+
+	do := func(op *operations.Operation) error {
+		localAction := func() {
+			// Limit action concurrency to number of instances or number of CPU cores (which ever is less).
+			wgAction := sync.WaitGroup{}
+			instActionCh := make(chan instance.Instance)
+			maxConcurrent := runtime.NumCPU()
+			instCount := len(instances)
+			if instCount < maxConcurrent {
+				maxConcurrent = instCount
+			}
+
+			for i := 0; i < maxConcurrent; i++ {
+				go func(instActionCh <-chan instance.Instance) {
+					for inst := range instActionCh {
+						result := api.BulkResult{
+							Name:     inst.Name(),
+							Project:  inst.Project().Name,
+							Location: inst.Location(),
+						}
+
+						if !userHasPermission(entity.InstanceURL(inst.Project().Name, inst.Name())) {
+							result.Error = http.StatusText(http.StatusForbidden)
+						} else {
+							err := bulkInstanceAction(s, op, inst, req)
+							if err != nil {
+								result.Error = err.Error()
+							}
+						}
+
+						addResults(result)
+						wgAction.Done()
+					}
+				}(instActionCh)
+			}
+
+			wgAction.Add(len(instances))
+			for _, inst := range instances {
+				instActionCh <- inst
+			}
+
+			wgAction.Wait()
+			close(instActionCh)
+		}
+
+		// Only apply the action locally if asked by another cluster member or if not clustered.
+		if isClusterNotification(r) || !s.ServerClustered {
+			localAction()
+		} else {
+			var members []db.NodeInfo
+			err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				var err error
+
+				members, err = tx.GetNodes(ctx)
+				if err != nil {
+					return fmt.Errorf("Failed getting cluster members: %w", err)
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			localClusterAddress := s.LocalConfig.ClusterAddress()
+			networkCert := s.Endpoints.NetworkCert()
+
+			wgMembers := sync.WaitGroup{}
+			for _, member := range members {
+				wgMembers.Add(1)
+				go func(member db.NodeInfo) {
+					defer wgMembers.Done()
+
+					if member.Address == localClusterAddress {
+						localAction()
+						return
+					}
+
+					memberResults, err := bulkRemoteAction(member, networkCert, s.ServerCert(), r, projectName, req)
+					if err != nil {
+						addResults(api.BulkResult{Location: member.Name, Error: err.Error()})
+						return
+					}
+
+					addResults(memberResults...)
+				}(member)
+			}
+
+			wgMembers.Wait()
+		}
+
+		sort.SliceStable(results, func(i, j int) bool {
+			if results[i].Project == results[j].Project {
+				return results[i].Name < results[j].Name
+			}
+
+			return results[i].Project < results[j].Project
+		})
+
+		// The instances of the other members are only known once they matched the filter.
+		resources := map[string][]api.URL{}
+		for _, result := range results {
+			if result.Name == "" {
+				continue
+			}
+
+			resources["instances"] = append(resources["instances"], *api.NewURL().Path(version.APIVersion, "instances", result.Name).Project(projectName))
+		}
+
+		err := op.UpdateResources(resources)
+		if err != nil {
+			return err
+		}
+
+		err = op.UpdateMetadata(map[string]any{"results": results})
+		if err != nil {
+			return err
+		}
+
+		failures := 0
+		for _, result := range results {
+			if result.Error != "" {
+				failures++
+			}
+		}
+
+		if failures > 0 {
+			return fmt.Errorf("Failed applying %q to %d out of %d instances", req.Action, failures, len(results))
+		}
+
+		return nil
+	}
+
+	resources := map[string][]api.URL{}
+	for _, inst := range instances {
+		resources["instances"] = append(resources["instances"], *api.NewURL().Path(version.APIVersion, "instances", inst.Name()).Project(projectName))
+	}
+
+	op, err := operations.OperationCreate(s, projectName, operations.OperationClassTask, operationtype.BulkInstances, resources, nil, do, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// bulkLocalInstances returns the instances of the project located on this member that the user can view and that
+// match the filter.
+func bulkLocalInstances(ctx context.Context, s *state.State, projectName string, clauses filter.ClauseSet, userCanView auth.PermissionChecker) ([]instance.Instance, error) {
+	insts, err := instanceLoadNodeProjectAll(ctx, s, projectName, instancetype.Any)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instances for project %q: %w", projectName, err)
+	}
+
+	instances := []instance.Instance{}
+	for _, inst := range insts {
+		if !userCanView(entity.InstanceURL(inst.Project().Name, inst.Name())) {
+			continue
+		}
+
+		// Match the same representation as the filter of the instance list.
+		c, _, err := inst.Render()
+		if err != nil {
+			return nil, fmt.Errorf("Failed rendering instance %q: %w", inst.Name(), err)
+		}
+
+		matched, err := instance.FilterFull([]*api.InstanceFull{{Instance: *c.(*api.Instance)}}, clauses)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(matched) > 0 {
+			instances = append(instances, inst)
+		}
+	}
+
+	return instances, nil
+}
+
+// bulkRemoteAction applies a bulk action to the instances of another cluster member and returns their results.
+func bulkRemoteAction(member db.NodeInfo, networkCert *shared.CertInfo, serverCert *shared.CertInfo, r *http.Request, projectName string, req api.BulkPost) ([]api.BulkResult, error) {
+	client, err := cluster.Connect(member.Address, networkCert, serverCert, r, true)
+	if err != nil {
+		return nil, err
+	}
+
+	client = client.UseProject(projectName)
+
+	op, err := client.ApplyBulkAction(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failure of some of the instances is reported in their results.
+	waitErr := op.Wait()
+
+	var metadata struct {
+		Results []api.BulkResult `json:"results"`
+	}
+
+	data, err := json.Marshal(op.Get().Metadata)
+	if err == nil {
+		err = json.Unmarshal(data, &metadata)
+	}
+
+	if err != nil || metadata.Results == nil {
+		if waitErr != nil {
+			return nil, waitErr
+		}
+
+		return nil, fmt.Errorf("Missing results of cluster member %q", member.Name)
+	}
+
+	return metadata.Results, nil
+}
+
+// bulkInstanceAction applies the action of a bulk request to a local instance.
+func bulkInstanceAction(s *state.State, op *operations.Operation, inst instance.Instance, req api.BulkPost) error {
+	inst.SetOperation(op)
+
+	switch req.Action {
+	case string(instancetype.Start):
+		// Nothing to do if already running.
+		if inst.IsRunning() && !inst.IsFrozen() {
+			return nil
+		}
+
+		return doInstanceStatePut(inst, *req.State)
+	case string(instancetype.Stop), string(instancetype.Restart):
+		// Nothing to do if already stopped.
+		if !inst.IsRunning() {
+			return nil
+		}
+
+		return doInstanceStatePut(inst, *req.State)
+	case "snapshot":
+		return bulkInstanceSnapshot(s, inst, *req.Snapshot)
+	case "delete":
+		if inst.IsRunning() {
+			return fmt.Errorf("Instance is running")
+		}
+
+		return inst.Delete(false)
+	case "patch":
+		return bulkInstancePatch(s, inst, req.Config)
+	}
+
+	return fmt.Errorf("Unknown action %q", req.Action)
+}
+
+// bulkInstanceSnapshot creates a snapshot of a local instance.
+func bulkInstanceSnapshot(s *state.State, inst instance.Instance, req api.InstanceSnapshotsPost) error {
+	// Check the snapshot limit of the project and create the snapshot serially per project, so that the limit
+	// isn't exceeded by the snapshots of other instances being created concurrently on this member.
+	unlock, err := locking.Lock(s.ShutdownCtx, fmt.Sprintf("BulkSnapshot_%s", inst.Project().Name))
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), inst.Project().Name)
+		if err != nil {
+			return err
+		}
+
+		p, err := dbProject.ToAPI(ctx, tx.Tx())
+		if err != nil {
+			return err
+		}
+
+		return project.AllowSnapshotCreation(tx, p)
+	})
+	if err != nil {
+		return err
+	}
+
+	if req.Name == "" {
+		req.Name, err = instance.NextSnapshotName(s, inst, "snap%d")
+		if err != nil {
+			return err
+		}
+	}
+
+	var expiry time.Time
+	if req.ExpiresAt != nil {
+		expiry = *req.ExpiresAt
+	} else {
+		expiry, err = shared.GetExpiry(time.Now(), inst.ExpandedConfig()["snapshots.expiry"])
+		if err != nil {
+			return err
+		}
+	}
+
+	// Stateful snapshots include the memory state so only stateless ones need the guest quiesced.
+	thaw := func() {}
+	if !req.Stateful {
+		thaw = instanceFreezeFilesystems(inst, nil)
+	}
+
+	err = inst.Snapshot(req.Name, expiry, req.Stateful)
+	thaw()
+	if err != nil {
+		return err
+	}
+
+	// Other members create the snapshots of their instances concurrently, so the limit is checked again once the
+	// snapshot is recorded and the snapshot is deleted if the limit is exceeded.
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return project.CheckSnapshotsLimit(tx, inst.Project().Name)
+	})
+	if err != nil {
+		snap, loadErr := instance.LoadByProjectAndName(s, inst.Project().Name, inst.Name()+shared.SnapshotDelimiter+req.Name)
+		if loadErr == nil {
+			loadErr = snap.Delete(true)
+		}
+
+		if loadErr != nil {
+			logger.Warn("Failed deleting snapshot exceeding the project limit", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "snapshot": req.Name, "err": loadErr})
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// bulkInstancePatch sets the given configuration keys of a local instance, unsetting the ones with an empty value.
+func bulkInstancePatch(s *state.State, inst instance.Instance, config map[string]string) error {
+	unlock, err := instanceOperationLock(s.ShutdownCtx, inst.Project().Name, inst.Name())
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	newConfig := make(map[string]string, len(inst.LocalConfig()))
+	for key, value := range inst.LocalConfig() {
+		newConfig[key] = value
+	}
+
+	for key, value := range config {
+		if value == "" {
+			delete(newConfig, key)
+		} else {
+			newConfig[key] = value
+		}
+	}
+
+	profileNames := make([]string, 0, len(inst.Profiles()))
+	for _, profile := range inst.Profiles() {
+		profileNames = append(profileNames, profile.Name)
+	}
+
+	req := api.InstancePut{
+		Config:      newConfig,
+		Devices:     inst.LocalDevices().CloneNative(),
+		Ephemeral:   inst.IsEphemeral(),
+		Profiles:    profileNames,
+		Description: inst.Description(),
+	}
+
+	// Check project limits.
+	apiProfiles := make([]api.Profile, 0, len(profileNames))
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		profiles, err := dbCluster.GetProfilesIfEnabled(ctx, tx.Tx(), inst.Project().Name, profileNames)
+		if err != nil {
+			return err
+		}
+
+		for _, profile := range profiles {
+			apiProfile, err := profile.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			apiProfiles = append(apiProfiles, *apiProfile)
+		}
+
+		return project.AllowInstanceUpdate(s.GlobalConfig, tx, inst.Project().Name, inst.Name(), req, inst.LocalConfig())
+	})
+	if err != nil {
+		return err
+	}
+
+	args := db.InstanceArgs{
+		Architecture: inst.Architecture(),
+		Config:       newConfig,
+		Description:  inst.Description(),
+		Devices:      inst.LocalDevices(),
+		Ephemeral:    inst.IsEphemeral(),
+		Profiles:     apiProfiles,
+		Project:      inst.Project().Name,
+	}
+
+	return inst.Update(args, true)
+}
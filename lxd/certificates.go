@@ -60,6 +60,22 @@ var certificateCmd = APIEndpoint{
 //  ---
 //  produces:
 //    - application/json
+//  parameters:
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -103,6 +119,22 @@ var certificateCmd = APIEndpoint{
 //	---
 //	produces:
 //	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -132,7 +164,11 @@ var certificateCmd = APIEndpoint{
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
 func certificatesGet(d *Daemon, r *http.Request) response.Response {
-	recursion := util.IsRecursionRequest(r)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
 	s := d.State()
 
 	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), auth.EntitlementCanView, entity.TypeCertificate)
@@ -140,10 +176,9 @@ func certificatesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	if recursion {
+	if opts.recursion || opts.filtered() {
 		var certResponses []api.Certificate
 		var baseCerts []dbCluster.Certificate
-		var err error
 		err = d.State().DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 			baseCerts, err = dbCluster.GetCertificates(ctx, tx.Tx())
 			if err != nil {
@@ -170,16 +205,33 @@ func certificatesGet(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
-		return response.SyncResponse(true, certResponses)
+		certResponses, err = listPage(opts, certResponses, func(cert api.Certificate) string { return cert.Fingerprint })
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if opts.recursion {
+			return response.SyncResponse(true, certResponses)
+		}
+
+		body := make([]string, 0, len(certResponses))
+		for _, cert := range certResponses {
+			body = append(body, fmt.Sprintf("/%s/certificates/%s", version.APIVersion, cert.Fingerprint))
+		}
+
+		return response.SyncResponse(true, body)
 	}
 
-	body := []string{}
+	fingerprints := []string{}
 	for _, identity := range d.identityCache.GetByAuthenticationMethod(api.AuthenticationMethodTLS) {
-		if !userHasPermission(entity.CertificateURL(identity.Identifier)) {
-			continue
+		if userHasPermission(entity.CertificateURL(identity.Identifier)) {
+			fingerprints = append(fingerprints, identity.Identifier)
 		}
+	}
 
-		certificateURL := fmt.Sprintf("/%s/certificates/%s", version.APIVersion, identity.Identifier)
+	body := []string{}
+	for _, fingerprint := range listKeys(opts, fingerprints) {
+		certificateURL := fmt.Sprintf("/%s/certificates/%s", version.APIVersion, fingerprint)
 		body = append(body, certificateURL)
 	}
 
@@ -667,7 +719,7 @@ func certificatesPost(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Send a notification to other cluster members to refresh their identity cache.
-	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
 		return response.SmartError(err)
 	}
@@ -1001,7 +1053,7 @@ func doCertificateUpdate(d *Daemon, dbInfo api.Certificate, req api.CertificateP
 	}
 
 	// Notify other cluster members to update their identity cache.
-	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
 		return response.SmartError(err)
 	}
@@ -1112,7 +1164,7 @@ func certificateDelete(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Notify other cluster members so that they update their identity cache.
-	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
 		return response.SmartError(err)
 	}
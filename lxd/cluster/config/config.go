@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/canonical/lxd/lxd/config"
 	"github.com/canonical/lxd/lxd/db"
 	scriptletLoad "github.com/canonical/lxd/lxd/scriptlet/load"
+	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/validate"
 )
@@ -54,6 +56,11 @@ func (c *Config) BGPASN() int64 {
 	return c.m.GetInt64("core.bgp_asn")
 }
 
+// AuditLogRetentionDays returns the number of days after which audit log entries are deleted.
+func (c *Config) AuditLogRetentionDays() int64 {
+	return c.m.GetInt64("core.audit_log_retention")
+}
+
 // HTTPSAllowedHeaders returns the relevant CORS setting.
 func (c *Config) HTTPSAllowedHeaders() string {
 	return c.m.GetString("core.https_allowed_headers")
@@ -179,6 +186,21 @@ func (c *Config) ImagesRemoteCacheExpiryDays() int64 {
 	return c.m.GetInt64("images.remote_cache_expiry")
 }
 
+// ImagesRequireSignature returns whether images must be signed by a trusted key.
+func (c *Config) ImagesRequireSignature() bool {
+	return c.m.GetBool("images.require_signature")
+}
+
+// ImagesTrustedKeys returns the PEM encoded public keys trusted to sign images.
+func (c *Config) ImagesTrustedKeys() string {
+	return c.m.GetString("images.trusted_keys")
+}
+
+// ImagesSimpleStreams returns whether the public images are served as a simplestreams image server.
+func (c *Config) ImagesSimpleStreams() bool {
+	return c.m.GetBool("images.simplestreams")
+}
+
 // InstancesNICHostname returns hostname mode to use for instance NICs.
 func (c *Config) InstancesNICHostname() string {
 	return c.m.GetString("instances.nic.host_name")
@@ -207,6 +229,39 @@ func (c *Config) LokiServer() (apiURL string, authUsername string, authPassword
 	return c.m.GetString("loki.api.url"), c.m.GetString("loki.auth.username"), c.m.GetString("loki.auth.password"), c.m.GetString("loki.api.ca_cert"), c.m.GetString("loki.instance"), c.m.GetString("loki.loglevel"), labels, types
 }
 
+// Tracing returns the address of the OTLP collector to send traces to and the fraction of the traces to send.
+func (c *Config) Tracing() (address string, sampleRatio float64) {
+	// The error can be ignored as the value is validated.
+	sampleRatio, _ = strconv.ParseFloat(c.m.GetString("tracing.sample_ratio"), 64)
+
+	return c.m.GetString("tracing.otlp.address"), sampleRatio
+}
+
+// LoggingSinks returns the configuration keys of the event sinks, indexed by sink name. The keys are returned
+// without their "logging.<name>." prefix.
+func (c *Config) LoggingSinks() map[string]map[string]string {
+	sinks := map[string]map[string]string{}
+
+	for key, value := range c.m.Dump() {
+		if !strings.HasPrefix(key, "logging.") {
+			continue
+		}
+
+		name, subKey, ok := strings.Cut(strings.TrimPrefix(key, "logging."), ".")
+		if !ok {
+			continue
+		}
+
+		if sinks[name] == nil {
+			sinks[name] = map[string]string{}
+		}
+
+		sinks[name][subKey], _ = value.(string)
+	}
+
+	return sinks
+}
+
 // ACME returns all ACME settings needed for certificate renewal.
 func (c *Config) ACME() (domain string, email string, caURL string, agreeTOS bool) {
 	return c.m.GetString("acme.domain"), c.m.GetString("acme.email"), c.m.GetString("acme.ca_url"), c.m.GetBool("acme.agree_tos")
@@ -247,6 +302,24 @@ func (c *Config) ClusterHealingThreshold() time.Duration {
 	return healingThreshold
 }
 
+// ClusterDatabaseBackup returns the schedule of the cluster database backups and the number of backups to keep.
+func (c *Config) ClusterDatabaseBackup() (schedule string, retention int64) {
+	return c.m.GetString("cluster.database_backup.schedule"), c.m.GetInt64("cluster.database_backup.retention")
+}
+
+// ClusterHealingFence returns the command confirming that an offline member is powered off, and whether the
+// Ceph clients of offline members must be blocklisted before their instances are evacuated.
+func (c *Config) ClusterHealingFence() (command string, ceph bool) {
+	return c.m.GetString("cluster.healing_fence_command"), c.m.GetBool("cluster.healing_fence_ceph")
+}
+
+// ClusterRebalance returns the load imbalance threshold (in percent) above which instances are moved between
+// cluster members, the interval between rebalancing runs and the maximum number of instances moved per run.
+// A zero threshold means that automatic rebalancing is disabled.
+func (c *Config) ClusterRebalance() (threshold int64, interval time.Duration, batch int64) {
+	return c.m.GetInt64("cluster.rebalance.threshold"), time.Duration(c.m.GetInt64("cluster.rebalance.interval")) * time.Minute, c.m.GetInt64("cluster.rebalance.batch")
+}
+
 // Dump current configuration keys and their values. Keys with values matching
 // their defaults are omitted.
 func (c *Config) Dump() map[string]any {
@@ -359,6 +432,84 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Threshold when to evacuate an offline cluster member
 	"cluster.healing_threshold": {Type: config.Int64, Default: "0"},
 
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.database_backup.retention)
+	// Specify the number of scheduled cluster database backups to keep. Older backups are deleted.
+	// To keep all backups, set this option to `0`.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `7`
+	//  shortdesc: Number of cluster database backups to keep
+	"cluster.database_backup.retention": {Type: config.Int64, Default: "7", Validator: validate.IsInRange(0, 10000)},
+
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.database_backup.schedule)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable scheduled backups.
+	// Backups are created by the cluster leader.
+	//
+	// See {ref}`cluster-database-backup` for more information.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: empty
+	//  shortdesc: Schedule for cluster database backups
+	"cluster.database_backup.schedule": {Type: config.String, Validator: validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"}))},
+
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.healing_fence_ceph)
+	// Whether to blocklist the Ceph clients of an offline cluster member using the RBD images of its instances,
+	// and to remove their locks, before the instances are restarted elsewhere.
+	//
+	// See {ref}`cluster-healing-fencing` for more information.
+	// ---
+	//  type: bool
+	//  scope: global
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to fence offline members in Ceph before healing
+	"cluster.healing_fence_ceph": {Type: config.Bool, Default: "false"},
+
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.healing_fence_command)
+	// Specify a command that is run by the cluster leader before evacuating an offline cluster member.
+	// The command gets the name and address of the member in the `LXD_MEMBER_NAME` and `LXD_MEMBER_ADDRESS`
+	// environment variables, and must only exit successfully once the member is confirmed to be powered off.
+	// The offline member isn't evacuated if the command fails.
+	//
+	// See {ref}`cluster-healing-fencing` for more information.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Command fencing an offline member before healing
+	"cluster.healing_fence_command": {Type: config.String},
+
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.rebalance.batch)
+	//
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `1`
+	//  shortdesc: Maximum number of instances moved per rebalancing run
+	"cluster.rebalance.batch": {Type: config.Int64, Default: "1", Validator: validate.IsInRange(1, 100)},
+
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.rebalance.interval)
+	//
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `60`
+	//  shortdesc: Interval in minutes between automatic rebalancing runs
+	"cluster.rebalance.interval": {Type: config.Int64, Default: "60", Validator: validate.IsInRange(1, 10080)},
+
+	// lxdmeta:generate(entities=server; group=cluster; key=cluster.rebalance.threshold)
+	// Specify the difference in load (in percent) between the most and the least loaded cluster members above which
+	// instances that opted in with {config:option}`instance-miscellaneous:cluster.rebalance` are moved automatically.
+	// To disable automatic rebalancing, set this option to `0`.
+	//
+	// See {ref}`clustering-rebalance` for more information.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `0`
+	//  shortdesc: Load imbalance threshold triggering instance moves
+	"cluster.rebalance.threshold": {Type: config.Int64, Default: "0", Validator: validate.IsInRange(0, 100)},
+
 	// lxdmeta:generate(entities=server; group=cluster; key=cluster.join_token_expiry)
 	//
 	// ---
@@ -397,6 +548,16 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to enforce authentication on the metrics endpoint
 	"core.metrics_authentication": {Type: config.Bool, Default: "true"},
 
+	// lxdmeta:generate(entities=server; group=core; key=core.audit_log_retention)
+	// Each cluster member records the mutating API requests it handles in its audit log.
+	// Entries older than the given number of days are deleted daily. Set to `0` to keep all entries.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `90`
+	//  shortdesc: Number of days to keep audit log entries for
+	"core.audit_log_retention": {Type: config.Int64, Default: "90", Validator: validate.Optional(validate.IsUint32)},
+
 	// lxdmeta:generate(entities=server; group=core; key=core.bgp_asn)
 	//
 	// ---
@@ -543,6 +704,37 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: When an unused cached remote image is flushed
 	"images.remote_cache_expiry": {Type: config.Int64, Default: "10"},
 
+	// lxdmeta:generate(entities=server; group=images; key=images.require_signature)
+	// When enabled, images that aren't signed by one of the keys in {config:option}`server-images:images.trusted_keys`
+	// are rejected when uploaded, copied from a remote or automatically updated.
+	// This can be overridden per project.
+	// See {ref}`images-signing` for more information.
+	// ---
+	//  type: bool
+	//  scope: global
+	//  defaultdesc: `false`
+	//  shortdesc: Whether images must be signed by a trusted key
+	"images.require_signature": {Type: config.Bool, Default: "false"},
+
+	// lxdmeta:generate(entities=server; group=images; key=images.simplestreams)
+	// When enabled, the public images of the `default` project are published under `/streams/v1/`,
+	// so that other servers can add this server as a `simplestreams` remote.
+	// See {ref}`images-simplestreams-server` for more information.
+	// ---
+	//  type: bool
+	//  scope: global
+	//  defaultdesc: `false`
+	//  shortdesc: Whether to serve public images as a simplestreams image server
+	"images.simplestreams": {Type: config.Bool, Default: "false"},
+
+	// lxdmeta:generate(entities=server; group=images; key=images.trusted_keys)
+	// Specify one or more PEM encoded Ed25519 public keys.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Public keys trusted to sign images
+	"images.trusted_keys": {Validator: validate.Optional(trustedKeysValidator)},
+
 	// lxdmeta:generate(entities=server; group=miscellaneous; key=instances.nic.host_name)
 	// Possible values are `random` and `mac`.
 	//
@@ -572,6 +764,131 @@ var ConfigSchema = config.Schema{
 	//  shortdesc: Whether to set `migration.stateful` to `true` for the instances
 	"instances.migration.stateful": {Type: config.Bool, Default: "false"},
 
+	// lxdmeta:generate(entities=server; group=logging; key=logging.NAME.target.type)
+	// Possible values are `loki`, `syslog`, `otlp` and `file`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Type of the event sink
+	"logging.*.target.type": {Validator: validate.Optional(validate.IsOneOf("loki", "syslog", "otlp", "file"))},
+
+	// lxdmeta:generate(entities=server; group=logging; key=logging.NAME.target.address)
+	// For `loki` targets, specify the URL of the Loki server (for example, `https://loki.example.com:3100`).
+	// For `syslog` targets, specify the protocol (`tcp`, `udp` or `tls`), name or IP and port of the syslog server (for example, `tls://syslog.example.com:6514`).
+	// For `otlp` targets, specify the URL of the OTLP/HTTP endpoint (for example, `https://otel.example.com:4318`). LXD adds the `/v1/logs` path if the URL doesn't contain a path.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Address of the event sink
+	"logging.*.target.address": {},
+
+	// lxdmeta:generate(entities=server; group=logging; key=logging.NAME.target.username)
+	// This option applies to `loki` and `otlp` targets.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: User name used for authentication
+	"logging.*.target.username": {},
+
+	// lxdmeta:generate(entities=server; group=logging; key=logging.NAME.target.password)
+	// This option applies to `loki` and `otlp` targets.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Password used for authentication
+	"logging.*.target.password": {},
+
+	// lxdmeta:generate(entities=server; group=logging; key=logging.NAME.target.ca_cert)
+	// This option applies to `loki`, `otlp` and `syslog` targets using TLS.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: CA certificate for the server
+	"logging.*.target.ca_cert": {},
+
+	// lxdmeta:generate(entities=server; group=logging; key=logging.NAME.target.instance)
+	// This option applies to `loki` targets.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: Local server host name or cluster member name
+	//  shortdesc: Name to use as the instance field in Loki events
+	"logging.*.target.instance": {},
+
+	// lxdmeta:generate(entities=server; group=logging; key=logging.NAME.target.labels)
+	// This option applies to `loki` targets.
+	// Specify a comma-separated list of values that should be used as labels for a Loki log entry.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Labels for a Loki log entry
+	"logging.*.target.labels": {},
+
+	// lxdmeta:generate(entities=server; group=logging; key=logging.NAME.target.path)
+	// This option applies to `file` targets.
+	// Specify the name of the file within the LXD log directory. The events are written to it as JSON lines.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Name of the log file
+	"logging.*.target.path": {Validator: func(value string) error {
+		if value == "" {
+			return nil
+		}
+
+		if filepath.Base(value) != value || value == "." || value == ".." {
+			return fmt.Errorf("Must be a file name")
+		}
+
+		return nil
+	}},
+
+	// lxdmeta:generate(entities=server; group=logging; key=logging.NAME.target.max_size)
+	// This option applies to `file` targets. The file is rotated once it reaches this size.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `100MiB`
+	//  shortdesc: Maximum size of the log file
+	"logging.*.target.max_size": {Validator: validate.Optional(validate.IsSize)},
+
+	// lxdmeta:generate(entities=server; group=logging; key=logging.NAME.target.max_files)
+	// This option applies to `file` targets.
+	// ---
+	//  type: integer
+	//  scope: global
+	//  defaultdesc: `5`
+	//  shortdesc: Number of rotated log files to keep
+	"logging.*.target.max_files": {Validator: validate.Optional(validate.IsUint32)},
+
+	// lxdmeta:generate(entities=server; group=logging; key=logging.NAME.types)
+	// Specify a comma-separated list of events to send to the event sink.
+	// The events can be any combination of `lifecycle`, `logging`, and `ovn`.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `lifecycle,logging`
+	//  shortdesc: Events to send to the event sink
+	"logging.*.types": {Validator: validate.Optional(validate.IsListOf(validate.IsOneOf("lifecycle", "logging", "ovn")))},
+
+	// lxdmeta:generate(entities=server; group=logging; key=logging.NAME.logging.level)
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `info`
+	//  shortdesc: Minimum log level to send to the event sink
+	"logging.*.logging.level": {Validator: logLevelValidator},
+
+	// lxdmeta:generate(entities=server; group=logging; key=logging.NAME.projects)
+	// Specify a comma-separated list of projects whose events are sent to the event sink.
+	// Events that aren't tied to a project, like most `logging` events, are always sent.
+	// If empty, events from all projects are sent.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Projects whose events are sent to the event sink
+	"logging.*.projects": {Validator: validate.Optional(validate.IsListOf(validate.IsNotEmpty))},
+
 	// lxdmeta:generate(entities=server; group=loki; key=loki.auth.username)
 	//
 	// ---
@@ -734,6 +1051,25 @@ var ConfigSchema = config.Schema{
 	//  defaultdesc: Content of `/etc/ovn/key_host` if present
 	//  shortdesc: OVN SSL client key
 	"network.ovn.client_key": {Default: ""},
+
+	// lxdmeta:generate(entities=server; group=tracing; key=tracing.otlp.address)
+	// Specify the URL of the collector, for example, `http://otel.example.com:4317`.
+	// Traces are sent over an unencrypted connection unless the URL uses the `https` scheme.
+	// ---
+	//  type: string
+	//  scope: global
+	//  shortdesc: Address of the OTLP/gRPC collector to send traces to
+	"tracing.otlp.address": {Validator: validate.Optional(validate.IsRequestURL)},
+
+	// lxdmeta:generate(entities=server; group=tracing; key=tracing.sample_ratio)
+	// Specify a value between `0` and `1`.
+	// Traces started by a caller that already sampled them are always exported.
+	// ---
+	//  type: string
+	//  scope: global
+	//  defaultdesc: `1`
+	//  shortdesc: Fraction of the traces to export
+	"tracing.sample_ratio": {Validator: sampleRatioValidator, Default: "1"},
 }
 
 func expiryValidator(value string) error {
@@ -758,6 +1094,19 @@ func logLevelValidator(value string) error {
 	return nil
 }
 
+func sampleRatioValidator(value string) error {
+	ratio, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("Value is not a number")
+	}
+
+	if ratio < 0 || ratio > 1 {
+		return fmt.Errorf("Value must be between 0 and 1")
+	}
+
+	return nil
+}
+
 func offlineThresholdDefault() string {
 	return strconv.Itoa(db.DefaultOfflineThreshold)
 }
@@ -817,3 +1166,8 @@ func maxStandByValidator(value string) error {
 
 	return nil
 }
+
+func trustedKeysValidator(value string) error {
+	_, err := util.ParseSigningKeys(value)
+	return err
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/lxd/tracing"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/version"
@@ -29,6 +30,11 @@ import (
 // to the UserAgentNotifier value, which can be used in some cases to distinguish
 // between a regular client request and an internal cluster request.
 func Connect(address string, networkCert *shared.CertInfo, serverCert *shared.CertInfo, r *http.Request, notify bool) (lxd.InstanceServer, error) {
+	return connect(context.Background(), address, networkCert, serverCert, r, notify)
+}
+
+// connect is like Connect, but the requests of the returned client use ctx.
+func connect(ctx context.Context, address string, networkCert *shared.CertInfo, serverCert *shared.CertInfo, r *http.Request, notify bool) (lxd.InstanceServer, error) {
 	// Wait for a connection to the events API first for non-notify connections.
 	if !notify {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(10)*time.Second)
@@ -67,6 +73,9 @@ func Connect(address string, networkCert *shared.CertInfo, serverCert *shared.Ce
 
 			req.Header.Add(request.HeaderForwardedAddress, r.RemoteAddr)
 
+			// Continue the trace of the forwarded request.
+			tracing.Inject(ctx, req.Header)
+
 			identityProviderGroupsAny := ctx.Value(request.CtxIdentityProviderGroups)
 			if ok {
 				identityProviderGroups, ok := identityProviderGroupsAny.([]string)
@@ -85,7 +94,7 @@ func Connect(address string, networkCert *shared.CertInfo, serverCert *shared.Ce
 	}
 
 	url := fmt.Sprintf("https://%s", address)
-	return lxd.ConnectLXD(url, args)
+	return lxd.ConnectLXDWithContext(ctx, url, args)
 }
 
 // ConnectIfInstanceIsRemote figures out the address of the cluster member which is running the instance with the
@@ -6,9 +6,12 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/tracing"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/logger"
 )
@@ -31,6 +34,15 @@ const (
 // NewNotifier builds a Notifier that can be used to notify other peers using
 // the given policy.
 func NewNotifier(state *state.State, networkCert *shared.CertInfo, serverCert *shared.CertInfo, policy NotifierPolicy) (Notifier, error) {
+	return NewNotifierContext(context.Background(), state, networkCert, serverCert, policy)
+}
+
+// NewNotifierContext is like NewNotifier, but the notifications are traced as part of the span in ctx, if any.
+// The notification requests carry the trace context.
+func NewNotifierContext(ctx context.Context, state *state.State, networkCert *shared.CertInfo, serverCert *shared.CertInfo, policy NotifierPolicy) (Notifier, error) {
+	// Only the span of ctx is kept as the notifier may be used after ctx is done.
+	ctx = tracing.Detach(ctx)
+
 	localClusterAddress := state.LocalConfig.ClusterAddress()
 
 	// Fast-track the case where we're not clustered at all.
@@ -92,7 +104,11 @@ func NewNotifier(state *state.State, networkCert *shared.CertInfo, serverCert *s
 			logger.Debugf("Notify node %s of state changes", address)
 			go func(i int, address string) {
 				defer wg.Done()
-				client, err := Connect(address, networkCert, serverCert, nil, true)
+
+				spanCtx, span := tracing.Start(ctx, "cluster notify", attribute.String("server.address", address))
+				defer func() { tracing.End(span, errs[i]) }()
+
+				client, err := connect(spanCtx, address, networkCert, serverCert, nil, true)
 				if err != nil {
 					errs[i] = fmt.Errorf("failed to connect to peer %s: %w", address, err)
 					return
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var internalClusterDatabaseBackupCmd = APIEndpoint{
+	Path: "cluster/database-backup",
+
+	Post: APIEndpointAction{Handler: internalClusterDatabaseBackupPost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+// clusterDatabaseBackupPrefix is the file name prefix of the cluster database backups.
+const clusterDatabaseBackupPrefix = "global-"
+
+// clusterDatabaseBackupCertificates are the files of the LXD directory included in the cluster database backups.
+var clusterDatabaseBackupCertificates = []string{"cluster.crt", "cluster.key", "cluster.ca"}
+
+// clusterDatabaseBackupMetadata describes a cluster database backup.
+type clusterDatabaseBackupMetadata struct {
+	CreatedAt     time.Time `yaml:"created_at"`
+	Member        string    `yaml:"member"`
+	Version       string    `yaml:"version"`
+	Schema        int       `yaml:"schema"`
+	APIExtensions int       `yaml:"api_extensions"`
+}
+
+// internalClusterDatabaseBackup is the result of a cluster database backup.
+type internalClusterDatabaseBackup struct {
+	Path string `json:"path" yaml:"path"`
+}
+
+func internalClusterDatabaseBackupPost(d *Daemon, r *http.Request) response.Response {
+	path, err := clusterDatabaseBackupCreate(r.Context(), d.State())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, internalClusterDatabaseBackup{Path: path})
+}
+
+// clusterDatabaseBackupCreate writes a consistent snapshot of the global database, along with the cluster
+// certificate, to a compressed tarball in the database backups directory and returns its path.
+func clusterDatabaseBackupCreate(ctx context.Context, s *state.State) (string, error) {
+	tx, err := s.DB.Cluster.DB().BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("Failed to start transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	dump, err := query.Dump(ctx, tx, false)
+	if err != nil {
+		return "", fmt.Errorf("Failed to dump global database: %w", err)
+	}
+
+	_ = tx.Rollback()
+
+	metadata, err := yaml.Marshal(clusterDatabaseBackupMetadata{
+		CreatedAt:     time.Now().UTC(),
+		Member:        s.ServerName,
+		Version:       version.Version,
+		Schema:        dbCluster.SchemaVersion,
+		APIExtensions: version.APIExtensionsCount(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	dir := shared.VarPath("backups", "database")
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", fmt.Errorf("Failed to create database backups directory: %w", err)
+	}
+
+	name := clusterDatabaseBackupPrefix + time.Now().UTC().Format("20060102-150405") + ".tar.gz"
+
+	// Write to a temporary file first so that partial backups are never left around.
+	tmpFile, err := os.CreateTemp(dir, ".tmp-")
+	if err != nil {
+		return "", fmt.Errorf("Failed to create database backup file: %w", err)
+	}
+
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+	defer func() { _ = tmpFile.Close() }()
+
+	gzWriter := gzip.NewWriter(tmpFile)
+	tarWriter := tar.NewWriter(gzWriter)
+
+	addFile := func(name string, content []byte) error {
+		err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), ModTime: time.Now()})
+		if err != nil {
+			return err
+		}
+
+		_, err = tarWriter.Write(content)
+		return err
+	}
+
+	err = addFile("metadata.yaml", metadata)
+	if err != nil {
+		return "", fmt.Errorf("Failed to write database backup: %w", err)
+	}
+
+	err = addFile("global.sql", []byte(dump))
+	if err != nil {
+		return "", fmt.Errorf("Failed to write database backup: %w", err)
+	}
+
+	for _, certName := range clusterDatabaseBackupCertificates {
+		content, err := os.ReadFile(shared.VarPath(certName))
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return "", err
+		}
+
+		err = addFile(certName, content)
+		if err != nil {
+			return "", fmt.Errorf("Failed to write database backup: %w", err)
+		}
+	}
+
+	err = tarWriter.Close()
+	if err != nil {
+		return "", fmt.Errorf("Failed to write database backup: %w", err)
+	}
+
+	err = gzWriter.Close()
+	if err != nil {
+		return "", fmt.Errorf("Failed to write database backup: %w", err)
+	}
+
+	err = tmpFile.Close()
+	if err != nil {
+		return "", fmt.Errorf("Failed to write database backup: %w", err)
+	}
+
+	path := filepath.Join(dir, name)
+	err = os.Rename(tmpFile.Name(), path)
+	if err != nil {
+		return "", fmt.Errorf("Failed to write database backup: %w", err)
+	}
+
+	return path, nil
+}
+
+// clusterDatabaseBackupPrune deletes the oldest cluster database backups of the database backups directory,
+// keeping the given number of backups.
+func clusterDatabaseBackupPrune(dir string, retention int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("Failed to list database backups: %w", err)
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), clusterDatabaseBackupPrefix) && strings.HasSuffix(entry.Name(), ".tar.gz") {
+			names = append(names, entry.Name())
+		}
+	}
+
+	if len(names) <= retention {
+		return nil
+	}
+
+	// The names contain the creation time so sort in the same order.
+	sort.Strings(names)
+
+	for _, name := range names[:len(names)-retention] {
+		err = os.Remove(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("Failed to delete database backup %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// clusterDatabaseBackupRead returns the metadata and the files of a cluster database backup.
+func clusterDatabaseBackupRead(r io.Reader) (*clusterDatabaseBackupMetadata, map[string][]byte, error) {
+	gzReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to read database backup: %w", err)
+	}
+
+	files := map[string][]byte{}
+	tarReader := tar.NewReader(gzReader)
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to read database backup: %w", err)
+		}
+
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed to read database backup: %w", err)
+		}
+
+		files[hdr.Name] = content
+	}
+
+	if files["metadata.yaml"] == nil || files["global.sql"] == nil {
+		return nil, nil, fmt.Errorf("Invalid database backup")
+	}
+
+	metadata := clusterDatabaseBackupMetadata{}
+	err = yaml.Unmarshal(files["metadata.yaml"], &metadata)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to parse database backup metadata: %w", err)
+	}
+
+	return &metadata, files, nil
+}
+
+func clusterDatabaseBackupTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		schedule, retention := s.GlobalConfig.ClusterDatabaseBackup()
+		if schedule == "" || !snapshotIsScheduledNow(schedule, 0) {
+			return
+		}
+
+		// In a cluster, only the leader backs up the database.
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil && !errors.Is(err, cluster.ErrNodeIsNotClustered) {
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		if err == nil && s.LocalConfig.ClusterAddress() != leader {
+			return
+		}
+
+		logger.Info("Backing up the cluster database")
+
+		path, err := clusterDatabaseBackupCreate(ctx, s)
+		if err != nil {
+			logger.Error("Failed backing up the cluster database", logger.Ctx{"err": err})
+			return
+		}
+
+		if retention > 0 {
+			err = clusterDatabaseBackupPrune(filepath.Dir(path), int(retention))
+			if err != nil {
+				logger.Error("Failed pruning cluster database backups", logger.Ctx{"err": err})
+			}
+		}
+
+		logger.Info("Done backing up the cluster database", logger.Ctx{"path": path})
+	}
+
+	return f, task.Every(time.Minute)
+}
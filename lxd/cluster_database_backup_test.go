@@ -0,0 +1,66 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterDatabaseBackupPrune(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"global-20240101-000000.tar.gz", "global-20240103-000000.tar.gz", "global-20240102-000000.tar.gz", "other.tar.gz"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0600))
+	}
+
+	require.NoError(t, clusterDatabaseBackupPrune(dir, 2))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	// The oldest backup is deleted, other files are left alone.
+	assert.Equal(t, []string{"global-20240102-000000.tar.gz", "global-20240103-000000.tar.gz", "other.tar.gz"}, names)
+}
+
+func TestClusterDatabaseBackupRead(t *testing.T) {
+	newBackup := func(files map[string]string) *bytes.Buffer {
+		buf := &bytes.Buffer{}
+		gzWriter := gzip.NewWriter(buf)
+		tarWriter := tar.NewWriter(gzWriter)
+
+		for name, content := range files {
+			require.NoError(t, tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content))}))
+			_, err := tarWriter.Write([]byte(content))
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, tarWriter.Close())
+		require.NoError(t, gzWriter.Close())
+
+		return buf
+	}
+
+	metadata, files, err := clusterDatabaseBackupRead(newBackup(map[string]string{
+		"metadata.yaml": "member: lxd01\nversion: \"6.1\"\nschema: 74\n",
+		"global.sql":    "COMMIT;\n",
+		"cluster.crt":   "cert",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "lxd01", metadata.Member)
+	assert.Equal(t, 74, metadata.Schema)
+	assert.Equal(t, "cert", string(files["cluster.crt"]))
+
+	_, _, err = clusterDatabaseBackupRead(newBackup(map[string]string{"metadata.yaml": "member: lxd01\n"}))
+	assert.EqualError(t, err, "Invalid database backup")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// clusterFenceCommandTimeout is the maximum time given to the fencing command to confirm that an offline
+// cluster member is powered off.
+const clusterFenceCommandTimeout = 5 * time.Minute
+
+// clusterFenceMember makes sure that an offline cluster member can't access its instances anymore before they are
+// restarted on other members. The configured fencing command must confirm that the member is powered off.
+// The member is fenced in Ceph per instance by clusterFenceInstanceLocks.
+func clusterFenceMember(ctx context.Context, s *state.State, member db.NodeInfo) error {
+	command, _ := s.GlobalConfig.ClusterHealingFence()
+	if command == "" {
+		return nil
+	}
+
+	logger.Info("Fencing offline cluster member", logger.Ctx{"member": member.Name})
+
+	cmdCtx, cancel := context.WithTimeout(ctx, clusterFenceCommandTimeout)
+	defer cancel()
+
+	env := append(os.Environ(), "LXD_MEMBER_NAME="+member.Name, "LXD_MEMBER_ADDRESS="+member.Address)
+	_, _, err := shared.RunCommandSplit(cmdCtx, env, nil, "sh", "-c", command)
+	if err != nil {
+		return fmt.Errorf("Fencing command failed for cluster member %q: %w", member.Name, err)
+	}
+
+	return nil
+}
+
+// clusterFenceInstanceLocks blocklists the Ceph clients of an offline cluster member using the RBD images of an
+// instance and removes their locks, so that the instance can be started on another member.
+// An instance that is going to be started fails to be fenced if no client could be found, as it can't be told
+// apart from a member still using its images.
+func clusterFenceInstanceLocks(ctx context.Context, s *state.State, inst instance.Instance, pool storagePools.Pool, start bool) error {
+	_, ceph := s.GlobalConfig.ClusterHealingFence()
+	if !ceph || pool.Driver().Info().Name != "ceph" {
+		return nil
+	}
+
+	volType, err := storagePools.InstanceTypeToVolumeType(inst.Type())
+	if err != nil {
+		return err
+	}
+
+	vol := pool.GetVolume(volType, storagePools.InstanceContentType(inst), project.Instance(inst.Project().Name, inst.Name()), nil)
+	vols := []storageDrivers.Volume{vol}
+	if inst.Type() == instancetype.VM {
+		vols = append(vols, vol.NewVMBlockFilesystemVolume())
+	}
+
+	config := pool.Driver().Config()
+	fenced := []string{}
+	for _, vol := range vols {
+		addresses, err := storageDrivers.CephRBDClientsFence(ctx, config["ceph.cluster_name"], config["ceph.user.name"], config["ceph.osd.pool_name"], storageDrivers.CephGetRBDImageName(vol, "", false))
+		if err != nil {
+			return err
+		}
+
+		fenced = append(fenced, addresses...)
+	}
+
+	if start && len(fenced) == 0 {
+		return fmt.Errorf("No Ceph client of the offline member could be found to fence")
+	}
+
+	if len(fenced) > 0 {
+		logger.Info("Fenced Ceph clients of instance", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "addresses": fenced})
+	}
+
+	return nil
+}
@@ -0,0 +1,660 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+var clusterRebalanceCmd = APIEndpoint{
+	Path: "cluster/rebalance",
+
+	Get:  APIEndpointAction{Handler: clusterRebalanceGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanView)},
+	Post: APIEndpointAction{Handler: clusterRebalancePost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+// clusterRebalanceCPUSampleInterval is the interval between the two samples of the CPU time used by the instances
+// from which their CPU usage is computed.
+const clusterRebalanceCPUSampleInterval = 5 * time.Second
+
+// clusterRebalanceMember is a cluster member considered by the rebalancer.
+type clusterRebalanceMember struct {
+	name        string
+	address     string
+	cpuLoad     float64 // Estimated CPU load in percent.
+	memoryLoad  float64 // Memory usage in percent.
+	cpuTotal    uint64  // Number of CPU threads.
+	memoryTotal uint64
+}
+
+// load returns the estimated load of the member in percent, the highest of its CPU and memory loads.
+func (m *clusterRebalanceMember) load() float64 {
+	return max(m.cpuLoad, m.memoryLoad)
+}
+
+// clusterRebalanceInstance is a running instance that opted in to be moved by the rebalancer.
+type clusterRebalanceInstance struct {
+	name    string
+	project string
+	member  string
+	group   string   // Placement group, at most one instance of a group is moved per run.
+	cpu     float64  // CPU usage in number of CPU threads.
+	memory  uint64   // Memory usage in bytes.
+	live    bool     // Whether the instance can be live-migrated.
+	targets []string // Cluster members the instance can be moved to.
+}
+
+// swagger:operation GET /1.0/cluster/rebalance cluster cluster_rebalance_get
+//
+//	Get the planned instance moves
+//
+//	Computes the instance moves the cluster rebalancer would perform now, without performing them.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Planned instance moves
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ClusterRebalance"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterRebalanceGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("This server is not clustered"))
+	}
+
+	plan, err := clusterRebalancePlanGet(r.Context(), s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, plan)
+}
+
+// swagger:operation POST /1.0/cluster/rebalance cluster cluster_rebalance_post
+//
+//	Rebalance the cluster
+//
+//	Moves instances between cluster members to reduce the load imbalance.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterRebalancePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("This server is not clustered"))
+	}
+
+	run := func(op *operations.Operation) error {
+		plan, err := clusterRebalancePlanGet(context.TODO(), s)
+		if err != nil {
+			return err
+		}
+
+		return clusterRebalanceMoves(s, r, plan.Moves, op)
+	}
+
+	op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterRebalance, nil, nil, run, nil, nil, r)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return operations.OperationResponse(op)
+}
+
+// clusterRebalancePlanGet gathers the load of the cluster members and of the instances that can be moved, and
+// plans the moves reducing the imbalance according to the configured threshold and batch size.
+func clusterRebalancePlanGet(ctx context.Context, s *state.State) (*api.ClusterRebalance, error) {
+	threshold, _, batch := s.GlobalConfig.ClusterRebalance()
+
+	members, instances, err := clusterRebalanceLoad(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &api.ClusterRebalance{
+		Members: make(map[string]float64, len(members)),
+	}
+
+	for name, member := range members {
+		plan.Members[name] = math.Round(member.load()*10) / 10
+	}
+
+	plan.Moves = clusterRebalancePlan(members, instances, float64(threshold), int(batch))
+
+	return plan, nil
+}
+
+// clusterRebalanceLoad returns the online cluster members with their estimated load, and the running instances
+// that can be moved by the rebalancer.
+//
+// The CPU load of a member is its one minute load average divided by its number of CPU threads, and its memory load
+// is its memory usage. The CPU usage of an instance is computed from two samples of the CPU time it used, taken
+// clusterRebalanceCPUSampleInterval apart.
+func clusterRebalanceLoad(ctx context.Context, s *state.State) (map[string]*clusterRebalanceMember, []*clusterRebalanceInstance, error) {
+	members := map[string]*clusterRebalanceMember{}
+	instances := []*clusterRebalanceInstance{}
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		allMembers, err := tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		for _, member := range allMembers {
+			if member.State != db.ClusterMemberStateCreated || member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+				continue
+			}
+
+			members[member.Name] = &clusterRebalanceMember{name: member.Name, address: member.Address}
+		}
+
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+			if members[inst.Node] == nil {
+				return nil
+			}
+
+			expandedConfig := instancetype.ExpandInstanceConfig(nil, inst.Config, inst.Profiles)
+
+			mode := expandedConfig["cluster.rebalance"]
+			if mode == "" || mode == "none" {
+				return nil
+			}
+
+			candidateMembers, err := tx.GetCandidateMembers(ctx, allMembers, []int{inst.Architecture}, "", project.GetRestrictedClusterGroups(&p), s.GlobalConfig.OfflineThreshold())
+			if err != nil {
+				return err
+			}
+
+			candidateMembers, err = instancePlacementCandidates(ctx, tx, p.Name, inst.Name, expandedConfig, candidateMembers, nil)
+			if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+				return err
+			}
+
+			rebalanceInst := &clusterRebalanceInstance{
+				name:    inst.Name,
+				project: p.Name,
+				member:  inst.Node,
+				live:    mode != "migrate",
+			}
+
+			if expandedConfig["placement.group"] != "" {
+				rebalanceInst.group = p.Name + "/" + expandedConfig["placement.group"]
+			}
+
+			for _, candidateMember := range candidateMembers {
+				if candidateMember.Name != inst.Node {
+					rebalanceInst.targets = append(rebalanceInst.targets, candidateMember.Name)
+				}
+			}
+
+			if len(rebalanceInst.targets) > 0 {
+				instances = append(instances, rebalanceInst)
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	clients := make(map[string]lxd.InstanceServer, len(members))
+	for name, member := range members {
+		l := logger.AddContext(logger.Ctx{"member": name})
+
+		client, err := cluster.Connect(member.address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+		if err != nil {
+			l.Warn("Failed connecting to cluster member, skipping rebalancing", logger.Ctx{"err": err})
+			delete(members, name)
+			continue
+		}
+
+		resources, err := client.GetServerResources()
+		if err != nil {
+			l.Warn("Failed getting cluster member resources, skipping rebalancing", logger.Ctx{"err": err})
+			delete(members, name)
+			continue
+		}
+
+		memberState, _, err := client.GetClusterMemberState(name)
+		if err != nil {
+			l.Warn("Failed getting cluster member state, skipping rebalancing", logger.Ctx{"err": err})
+			delete(members, name)
+			continue
+		}
+
+		if resources.Memory.Total == 0 || resources.CPU.Total == 0 {
+			delete(members, name)
+			continue
+		}
+
+		member.cpuTotal = resources.CPU.Total
+		member.memoryTotal = resources.Memory.Total
+		member.memoryLoad = float64(resources.Memory.Used) / float64(resources.Memory.Total) * 100
+
+		if len(memberState.SysInfo.LoadAverages) > 0 {
+			member.cpuLoad = memberState.SysInfo.LoadAverages[0] / float64(resources.CPU.Total) * 100
+		}
+
+		clients[name] = client
+	}
+
+	sampled := make([]*clusterRebalanceInstance, 0, len(instances))
+	cpuUsages := make(map[*clusterRebalanceInstance]int64, len(instances))
+	for _, rebalanceInst := range instances {
+		client := clients[rebalanceInst.member]
+		if client == nil {
+			continue
+		}
+
+		inst, err := instance.LoadByProjectAndName(s, rebalanceInst.project, rebalanceInst.name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		canMigrate, canLiveMigrate := inst.CanMigrate()
+		if !canMigrate {
+			continue
+		}
+
+		if inst.ExpandedConfig()["cluster.rebalance"] == "live-migrate" && !canLiveMigrate {
+			continue
+		}
+
+		rebalanceInst.live = rebalanceInst.live && canLiveMigrate
+
+		instState, _, err := client.UseProject(rebalanceInst.project).GetInstanceState(rebalanceInst.name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed getting state of instance %q in project %q: %w", rebalanceInst.name, rebalanceInst.project, err)
+		}
+
+		// Stopped instances don't add any load.
+		if instState.StatusCode != api.Running {
+			continue
+		}
+
+		rebalanceInst.memory = uint64(max(instState.Memory.Usage, 0))
+		cpuUsages[rebalanceInst] = instState.CPU.Usage
+		sampled = append(sampled, rebalanceInst)
+	}
+
+	if len(sampled) == 0 {
+		return members, sampled, nil
+	}
+
+	// Sample the CPU time used by the instances a second time to compute their CPU usage.
+	sampleStart := time.Now()
+	select {
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case <-time.After(clusterRebalanceCPUSampleInterval):
+	}
+
+	movable := make([]*clusterRebalanceInstance, 0, len(sampled))
+	for _, rebalanceInst := range sampled {
+		instState, _, err := clients[rebalanceInst.member].UseProject(rebalanceInst.project).GetInstanceState(rebalanceInst.name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed getting state of instance %q in project %q: %w", rebalanceInst.name, rebalanceInst.project, err)
+		}
+
+		if instState.StatusCode != api.Running {
+			continue
+		}
+
+		elapsed := time.Since(sampleStart)
+		rebalanceInst.cpu = float64(max(instState.CPU.Usage-cpuUsages[rebalanceInst], 0)) / float64(elapsed.Nanoseconds())
+		movable = append(movable, rebalanceInst)
+	}
+
+	return members, movable, nil
+}
+
+// clusterRebalancePlan returns up to batch instance moves, each one moving an instance from the most loaded
+// member to a less loaded one, as long as the load difference between the most and least loaded members is
+// above the threshold and the move reduces it.
+//
+// The CPU and memory loads of the members are updated separately with the planned moves, the load of a member
+// being the highest of the two.
+func clusterRebalancePlan(members map[string]*clusterRebalanceMember, instances []*clusterRebalanceInstance, threshold float64, batch int) []api.ClusterRebalanceMove {
+	moves := []api.ClusterRebalanceMove{}
+
+	// Work on a copy of the loads as they are updated with the planned moves.
+	cpuLoads := make(map[string]float64, len(members))
+	memoryLoads := make(map[string]float64, len(members))
+	names := make([]string, 0, len(members))
+	for name, member := range members {
+		cpuLoads[name] = member.cpuLoad
+		memoryLoads[name] = member.memoryLoad
+		names = append(names, name)
+	}
+
+	load := func(name string) float64 {
+		return max(cpuLoads[name], memoryLoads[name])
+	}
+
+	// instanceLoad returns the CPU and memory loads an instance adds to a member.
+	instanceLoad := func(inst *clusterRebalanceInstance, member *clusterRebalanceMember) (float64, float64) {
+		return inst.cpu / float64(member.cpuTotal) * 100, float64(inst.memory) / float64(member.memoryTotal) * 100
+	}
+
+	moved := map[*clusterRebalanceInstance]bool{}
+	movedGroups := map[string]bool{}
+
+	for len(moves) < batch && len(names) > 1 {
+		sort.Slice(names, func(i, j int) bool {
+			if load(names[i]) == load(names[j]) {
+				return names[i] < names[j]
+			}
+
+			return load(names[i]) > load(names[j])
+		})
+
+		source := names[0]
+		if load(source)-load(names[len(names)-1]) <= threshold {
+			break
+		}
+
+		var bestInst *clusterRebalanceInstance
+		var bestTarget string
+		var bestSourceCPU, bestSourceMemory, bestTargetCPU, bestTargetMemory float64
+		bestPeak := load(source)
+
+		for _, inst := range instances {
+			if inst.member != source || moved[inst] || (inst.group != "" && movedGroups[inst.group]) {
+				continue
+			}
+
+			for _, target := range inst.targets {
+				targetMember := members[target]
+				if targetMember == nil || target == source {
+					continue
+				}
+
+				instSourceCPU, instSourceMemory := instanceLoad(inst, members[source])
+				instTargetCPU, instTargetMemory := instanceLoad(inst, targetMember)
+
+				sourceCPU := max(cpuLoads[source]-instSourceCPU, 0)
+				sourceMemory := max(memoryLoads[source]-instSourceMemory, 0)
+				targetCPU := cpuLoads[target] + instTargetCPU
+				targetMemory := memoryLoads[target] + instTargetMemory
+
+				sourceLoad := max(sourceCPU, sourceMemory)
+				targetLoad := max(targetCPU, targetMemory)
+
+				// Only consider moves reducing the imbalance between the two members.
+				if math.Abs(sourceLoad-targetLoad) >= load(source)-load(target) {
+					continue
+				}
+
+				// Pick the move leading to the lowest peak load.
+				peak := max(sourceLoad, targetLoad)
+				if peak < bestPeak {
+					bestInst = inst
+					bestTarget = target
+					bestPeak = peak
+					bestSourceCPU = sourceCPU
+					bestSourceMemory = sourceMemory
+					bestTargetCPU = targetCPU
+					bestTargetMemory = targetMemory
+				}
+			}
+		}
+
+		if bestInst == nil {
+			break
+		}
+
+		moves = append(moves, api.ClusterRebalanceMove{
+			Name:    bestInst.name,
+			Project: bestInst.project,
+			Source:  source,
+			Target:  bestTarget,
+			Live:    bestInst.live,
+		})
+
+		moved[bestInst] = true
+		if bestInst.group != "" {
+			movedGroups[bestInst.group] = true
+		}
+
+		cpuLoads[source] = bestSourceCPU
+		memoryLoads[source] = bestSourceMemory
+		cpuLoads[bestTarget] = bestTargetCPU
+		memoryLoads[bestTarget] = bestTargetMemory
+	}
+
+	return moves
+}
+
+// clusterRebalanceMoves performs the planned instance moves.
+func clusterRebalanceMoves(s *state.State, r *http.Request, moves []api.ClusterRebalanceMove, op *operations.Operation) error {
+	metadata := make(map[string]any)
+
+	for _, move := range moves {
+		l := logger.AddContext(logger.Ctx{"project": move.Project, "instance": move.Name, "source": move.Source, "target": move.Target})
+
+		inst, err := instance.LoadByProjectAndName(s, move.Project, move.Name)
+		if err != nil {
+			return fmt.Errorf("Failed to load instance %q in project %q: %w", move.Name, move.Project, err)
+		}
+
+		// Skip instances which were moved since the plan was made.
+		if inst.Location() != move.Source {
+			continue
+		}
+
+		var sourceMember db.NodeInfo
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			sourceMember, err = tx.GetNodeByName(ctx, move.Source)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to get node %q: %w", move.Source, err)
+		}
+
+		source, err := cluster.Connect(sourceMember.Address, s.Endpoints.NetworkCert(), s.ServerCert(), r, true)
+		if err != nil {
+			return fmt.Errorf("Failed to connect to source: %w", err)
+		}
+
+		source = source.UseProject(move.Project)
+
+		if !move.Live {
+			metadata["rebalance_progress"] = fmt.Sprintf("Stopping %q in project %q", move.Name, move.Project)
+			_ = op.UpdateMetadata(metadata)
+
+			timeout, err := strconv.Atoi(inst.ExpandedConfig()["boot.host_shutdown_timeout"])
+			if err != nil {
+				timeout = evacuateHostShutdownDefaultTimeout
+			}
+
+			stopOp, err := source.UpdateInstanceState(move.Name, api.InstanceStatePut{Action: "stop", Timeout: timeout}, "")
+			if err != nil {
+				return fmt.Errorf("Failed to stop instance %q: %w", move.Name, err)
+			}
+
+			err = stopOp.Wait()
+			if err != nil {
+				l.Warn("Failed shutting down instance, forcing stop", logger.Ctx{"err": err})
+
+				stopOp, err = source.UpdateInstanceState(move.Name, api.InstanceStatePut{Action: "stop", Force: true}, "")
+				if err != nil {
+					return fmt.Errorf("Failed to stop instance %q: %w", move.Name, err)
+				}
+
+				err = stopOp.Wait()
+				if err != nil && !strings.Contains(err.Error(), "The instance is already stopped") {
+					return fmt.Errorf("Failed to stop instance %q: %w", move.Name, err)
+				}
+			}
+		}
+
+		metadata["rebalance_progress"] = fmt.Sprintf("Migrating %q in project %q from %q to %q", move.Name, move.Project, move.Source, move.Target)
+		_ = op.UpdateMetadata(metadata)
+
+		migrationOp, err := source.UseTarget(move.Target).MigrateInstance(move.Name, api.InstancePost{Name: move.Name, Migration: true, Live: move.Live})
+		if err != nil {
+			return fmt.Errorf("Migration API failure: %w", err)
+		}
+
+		err = migrationOp.Wait()
+		if err != nil {
+			return fmt.Errorf("Failed to wait for migration to finish: %w", err)
+		}
+
+		if move.Live {
+			continue
+		}
+
+		metadata["rebalance_progress"] = fmt.Sprintf("Starting %q in project %q", move.Name, move.Project)
+		_ = op.UpdateMetadata(metadata)
+
+		startOp, err := source.UpdateInstanceState(move.Name, api.InstanceStatePut{Action: "start"}, "")
+		if err != nil {
+			return fmt.Errorf("Failed to start instance %q: %w", move.Name, err)
+		}
+
+		err = startOp.Wait()
+		if err != nil {
+			return fmt.Errorf("Failed to start instance %q: %w", move.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func clusterRebalanceTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		threshold, _, _ := s.GlobalConfig.ClusterRebalance()
+		if threshold == 0 {
+			return // Skip rebalancing if it's disabled.
+		}
+
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil {
+			if !errors.Is(err, cluster.ErrNodeIsNotClustered) {
+				logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			}
+
+			return
+		}
+
+		if s.LocalConfig.ClusterAddress() != leader {
+			return // Skip rebalancing if not cluster leader.
+		}
+
+		plan, err := clusterRebalancePlanGet(ctx, s)
+		if err != nil {
+			logger.Error("Failed planning cluster rebalancing", logger.Ctx{"err": err})
+			return
+		}
+
+		if len(plan.Moves) == 0 {
+			return
+		}
+
+		opRun := func(op *operations.Operation) error {
+			return clusterRebalanceMoves(s, nil, plan.Moves, op)
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ClusterRebalance, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating cluster rebalancing operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Rebalancing cluster instances", logger.Ctx{"moves": len(plan.Moves)})
+
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting cluster rebalancing operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed rebalancing cluster instances", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Done rebalancing cluster instances")
+	}
+
+	// Skip the first run so that members have time to settle after startup.
+	first := true
+	schedule := func() (time.Duration, error) {
+		threshold, interval, _ := d.State().GlobalConfig.ClusterRebalance()
+		if threshold == 0 {
+			return 0, nil
+		}
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestClusterRebalancePlan(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+
+	newMembers := func() map[string]*clusterRebalanceMember {
+		return map[string]*clusterRebalanceMember{
+			"m1": {name: "m1", memoryLoad: 80, cpuTotal: 16, memoryTotal: 100 * gib},
+			"m2": {name: "m2", memoryLoad: 20, cpuTotal: 16, memoryTotal: 100 * gib},
+			"m3": {name: "m3", memoryLoad: 50, cpuTotal: 16, memoryTotal: 100 * gib},
+		}
+	}
+
+	instances := []*clusterRebalanceInstance{
+		{name: "small", project: "default", member: "m1", memory: 5 * gib, live: true, targets: []string{"m2", "m3"}},
+		{name: "medium", project: "default", member: "m1", memory: 25 * gib, targets: []string{"m2", "m3"}},
+		{name: "huge", project: "default", member: "m1", memory: 70 * gib, live: true, targets: []string{"m2", "m3"}},
+		{name: "pinned", project: "default", member: "m1", memory: 30 * gib, targets: []string{"m3"}},
+	}
+
+	// The move leading to the lowest peak load is picked first.
+	moves := clusterRebalancePlan(newMembers(), instances, 10, 1)
+	assert.Equal(t, []api.ClusterRebalanceMove{{Name: "medium", Project: "default", Source: "m1", Target: "m2"}}, moves)
+
+	// Further moves are planned on the updated loads until the imbalance is below the threshold.
+	moves = clusterRebalancePlan(newMembers(), instances, 5, 5)
+	assert.Len(t, moves, 2)
+	assert.Equal(t, "small", moves[1].Name)
+
+	// Nothing to do below the threshold.
+	assert.Empty(t, clusterRebalancePlan(newMembers(), instances, 60, 5))
+
+	// Moves not reducing the imbalance are never planned.
+	assert.Empty(t, clusterRebalancePlan(newMembers(), instances[2:3], 10, 5))
+
+	// At most one instance of a placement group is moved per run.
+	grouped := []*clusterRebalanceInstance{
+		{name: "a", project: "default", member: "m1", group: "default/web", memory: 10 * gib, targets: []string{"m2"}},
+		{name: "b", project: "default", member: "m1", group: "default/web", memory: 10 * gib, targets: []string{"m2"}},
+	}
+
+	assert.Len(t, clusterRebalancePlan(newMembers(), grouped, 10, 5), 1)
+
+	// CPU and memory are planned against separately.
+	cpuMembers := map[string]*clusterRebalanceMember{
+		"m1": {name: "m1", cpuLoad: 90, memoryLoad: 30, cpuTotal: 10, memoryTotal: 100 * gib},
+		"m2": {name: "m2", cpuLoad: 10, memoryLoad: 60, cpuTotal: 10, memoryTotal: 100 * gib},
+	}
+
+	cpuInstances := []*clusterRebalanceInstance{
+		// Moving the memory bound instance barely reduces the load of m1 while raising the memory load of m2.
+		{name: "memory", project: "default", member: "m1", cpu: 0.5, memory: 25 * gib, targets: []string{"m2"}},
+		{name: "cpu", project: "default", member: "m1", cpu: 3, memory: 5 * gib, targets: []string{"m2"}},
+	}
+
+	moves = clusterRebalancePlan(cpuMembers, cpuInstances, 10, 1)
+	assert.Equal(t, []api.ClusterRebalanceMove{{Name: "cpu", Project: "default", Source: "m1", Target: "m2"}}, moves)
+}
@@ -0,0 +1,529 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+var clusterUpgradeCmd = APIEndpoint{
+	Path: "cluster/upgrade",
+
+	Get:  APIEndpointAction{Handler: clusterUpgradeGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanView)},
+	Post: APIEndpointAction{Handler: clusterUpgradePost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+// swagger:operation GET /1.0/cluster/upgrade cluster cluster_upgrade_get
+//
+//	Get the rolling upgrade
+//
+//	Gets the status of the latest rolling upgrade of the cluster members.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Rolling upgrade
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/ClusterUpgrade"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterUpgradeGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("This server is not clustered"))
+	}
+
+	var upgrade *db.ClusterUpgrade
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		upgrade, err = tx.GetClusterUpgrade(ctx)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, upgrade.ToAPI())
+}
+
+// swagger:operation POST /1.0/cluster/upgrade cluster cluster_upgrade_post
+//
+//	Control the rolling upgrade
+//
+//	Starts, pauses, resumes or aborts a rolling upgrade of the cluster members.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: cluster
+//	    description: Rolling upgrade action
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ClusterUpgradePost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterUpgradePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return response.BadRequest(fmt.Errorf("This server is not clustered"))
+	}
+
+	req := api.ClusterUpgradePost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	switch req.Action {
+	case "start":
+		err = clusterUpgradeStart(r.Context(), s, d.gateway, req.Members)
+	case "pause", "resume", "abort":
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			upgrade, err := tx.GetClusterUpgrade(ctx)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case req.Action == "pause" && upgrade.Status == db.ClusterUpgradeStatusRunning:
+				return tx.UpdateClusterUpgradeStatus(ctx, upgrade.ID, db.ClusterUpgradeStatusPaused, "Paused by user")
+			case req.Action == "resume" && upgrade.Status == db.ClusterUpgradeStatusPaused:
+				return tx.UpdateClusterUpgradeStatus(ctx, upgrade.ID, db.ClusterUpgradeStatusRunning, "")
+			case req.Action == "abort" && upgrade.IsActive():
+				return tx.UpdateClusterUpgradeStatus(ctx, upgrade.ID, db.ClusterUpgradeStatusAborted, "Aborted by user")
+			}
+
+			return api.StatusErrorf(http.StatusBadRequest, "Cannot %s a rolling upgrade which is %s", req.Action, db.ClusterUpgradeStatuses[upgrade.Status])
+		})
+	default:
+		return response.BadRequest(fmt.Errorf("Unknown action %q", req.Action))
+	}
+
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Have the leader pick up the change right away.
+	if d.taskClusterUpgrade != nil && shared.ValueInSlice(req.Action, []string{"start", "resume"}) {
+		d.taskClusterUpgrade.Reset()
+	}
+
+	return response.EmptySyncResponse
+}
+
+// clusterUpgradeStart records a new rolling upgrade of the given cluster members, in order. All the cluster
+// members are upgraded if none is given.
+func clusterUpgradeStart(ctx context.Context, s *state.State, gateway *cluster.Gateway, memberNames []string) error {
+	var members []db.NodeInfo
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		upgrade, err := tx.GetClusterUpgrade(ctx)
+		if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		if upgrade != nil && upgrade.IsActive() {
+			return api.StatusErrorf(http.StatusBadRequest, "A rolling upgrade is already %s", db.ClusterUpgradeStatuses[upgrade.Status])
+		}
+
+		members, err = tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		if member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+			return api.StatusErrorf(http.StatusBadRequest, "Cluster member %q is offline", member.Name)
+		}
+	}
+
+	if len(memberNames) > 0 {
+		byName := make(map[string]db.NodeInfo, len(members))
+		for _, member := range members {
+			byName[member.Name] = member
+		}
+
+		members = make([]db.NodeInfo, 0, len(memberNames))
+		for _, name := range memberNames {
+			member, ok := byName[name]
+			if !ok {
+				return api.StatusErrorf(http.StatusBadRequest, "Cluster member %q not found or listed more than once", name)
+			}
+
+			members = append(members, member)
+			delete(byName, name)
+		}
+	} else {
+		leaderAddress, err := gateway.LeaderAddress()
+		if err != nil {
+			return fmt.Errorf("Failed getting leader address: %w", err)
+		}
+
+		var raftNodes []db.RaftNode
+		err = s.DB.Node.Transaction(ctx, func(ctx context.Context, tx *db.NodeTx) error {
+			raftNodes, err = tx.GetRaftNodes(ctx)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed loading RAFT nodes: %w", err)
+		}
+
+		voters := map[string]bool{}
+		for _, raftNode := range raftNodes {
+			if raftNode.Role == db.RaftVoter {
+				voters[raftNode.Address] = true
+			}
+		}
+
+		members = clusterUpgradeOrder(members, voters, leaderAddress)
+	}
+
+	upgradeMembers := make([]db.ClusterUpgradeMember, 0, len(members))
+	for _, member := range members {
+		client, err := cluster.Connect(member.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+		if err != nil {
+			return fmt.Errorf("Failed connecting to cluster member %q: %w", member.Name, err)
+		}
+
+		server, _, err := client.GetServer()
+		if err != nil {
+			return fmt.Errorf("Failed getting version of cluster member %q: %w", member.Name, err)
+		}
+
+		upgradeMembers = append(upgradeMembers, db.ClusterUpgradeMember{
+			NodeID:        member.ID,
+			Name:          member.Name,
+			Version:       server.Environment.ServerVersion,
+			Schema:        member.Schema,
+			APIExtensions: member.APIExtensions,
+		})
+	}
+
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.CreateClusterUpgrade(ctx, "Starting rolling upgrade", upgradeMembers)
+	})
+}
+
+// clusterUpgradeOrder returns the default upgrade order of the cluster members: members not taking part in the
+// database quorum first, then the database voters, and the database leader last.
+func clusterUpgradeOrder(members []db.NodeInfo, voters map[string]bool, leaderAddress string) []db.NodeInfo {
+	rank := func(member db.NodeInfo) int {
+		switch {
+		case member.Address == leaderAddress:
+			return 2
+		case voters[member.Address]:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	ordered := make([]db.NodeInfo, len(members))
+	copy(ordered, members)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rank(ordered[i]) < rank(ordered[j])
+	})
+
+	return ordered
+}
+
+// clusterUpgradeMemberCheck returns whether the cluster member runs a different version of LXD than when the
+// rolling upgrade was started, and whether it is currently serving API requests.
+//
+// A member upgraded to a version with database schema or API changes is blocked until all the other members are
+// upgraded too, in which case the new versions are only found in the database.
+func clusterUpgradeMemberCheck(s *state.State, node db.NodeInfo, member db.ClusterUpgradeMember) (upgraded bool, reachable bool) {
+	upgraded = node.Schema != member.Schema || node.APIExtensions != member.APIExtensions
+
+	if node.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+		return upgraded, false
+	}
+
+	client, err := cluster.Connect(node.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+	if err != nil {
+		return upgraded, false
+	}
+
+	server, _, err := client.GetServer()
+	if err != nil {
+		return upgraded, false
+	}
+
+	return upgraded || server.Environment.ServerVersion != member.Version, true
+}
+
+// clusterUpgradeMemberState evacuates or restores a cluster member, unless it already is in the wanted state.
+func clusterUpgradeMemberState(s *state.State, node db.NodeInfo, action string) error {
+	evacuated := node.State == db.ClusterMemberStateEvacuated
+	if (action == "evacuate") == evacuated {
+		return nil
+	}
+
+	client, err := cluster.Connect(node.Address, s.Endpoints.NetworkCert(), s.ServerCert(), nil, true)
+	if err != nil {
+		return fmt.Errorf("Failed connecting to cluster member %q: %w", node.Name, err)
+	}
+
+	op, err := client.UpdateClusterMemberState(node.Name, api.ClusterMemberStatePost{Action: action})
+	if err != nil {
+		return fmt.Errorf("Failed to %s cluster member %q: %w", action, node.Name, err)
+	}
+
+	err = op.Wait()
+	if err != nil {
+		return fmt.Errorf("Failed to %s cluster member %q: %w", action, node.Name, err)
+	}
+
+	return nil
+}
+
+// clusterUpgradeStep moves the running rolling upgrade forward, if any.
+//
+// Members are evacuated one at a time and then waited for to be upgraded. Upgraded members are restored as soon
+// as they serve API requests again. Members blocked on the upgrade of the others are left evacuated while the
+// next member is processed.
+func clusterUpgradeStep(ctx context.Context, s *state.State) error {
+	var upgrade *db.ClusterUpgrade
+	nodes := map[int64]db.NodeInfo{}
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		upgrade, err = tx.GetClusterUpgrade(ctx)
+		if err != nil {
+			return err
+		}
+
+		members, err := tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		for _, member := range members {
+			nodes[member.ID] = member
+		}
+
+		return nil
+	})
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	if upgrade.Status != db.ClusterUpgradeStatusRunning {
+		return nil
+	}
+
+	setMember := func(member db.ClusterUpgradeMember, status int) error {
+		return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateClusterUpgradeMemberStatus(ctx, upgrade.ID, member.NodeID, status)
+		})
+	}
+
+	setUpgrade := func(status int, message string) error {
+		return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateClusterUpgradeStatus(ctx, upgrade.ID, status, message)
+		})
+	}
+
+	restore := func(member db.ClusterUpgradeMember) error {
+		logger.Info("Restoring upgraded cluster member", logger.Ctx{"member": member.Name})
+
+		err := setMember(member, db.ClusterUpgradeMemberRestoring)
+		if err != nil {
+			return err
+		}
+
+		err = clusterUpgradeMemberState(s, nodes[member.NodeID], "restore")
+		if err != nil {
+			return err
+		}
+
+		return setMember(member, db.ClusterUpgradeMemberDone)
+	}
+
+	// Restore the members which were blocked on the upgrade of the others and are now back.
+	for i, member := range upgrade.Members {
+		if member.Status != db.ClusterUpgradeMemberUpgraded && member.Status != db.ClusterUpgradeMemberRestoring {
+			continue
+		}
+
+		_, reachable := clusterUpgradeMemberCheck(s, nodes[member.NodeID], member)
+		if !reachable {
+			continue
+		}
+
+		err = restore(member)
+		if err != nil {
+			return err
+		}
+
+		upgrade.Members[i].Status = db.ClusterUpgradeMemberDone
+	}
+
+	var current *db.ClusterUpgradeMember
+	blocked := 0
+	for i, member := range upgrade.Members {
+		switch member.Status {
+		case db.ClusterUpgradeMemberPending, db.ClusterUpgradeMemberEvacuating, db.ClusterUpgradeMemberUpgrading:
+			if current == nil {
+				current = &upgrade.Members[i]
+			}
+
+		case db.ClusterUpgradeMemberUpgraded, db.ClusterUpgradeMemberRestoring:
+			blocked++
+		}
+	}
+
+	if current == nil {
+		if blocked > 0 {
+			return setUpgrade(db.ClusterUpgradeStatusRunning, "Waiting for the upgraded cluster members to come back online")
+		}
+
+		logger.Info("Rolling upgrade of the cluster completed")
+		return setUpgrade(db.ClusterUpgradeStatusCompleted, "")
+	}
+
+	switch current.Status {
+	case db.ClusterUpgradeMemberPending, db.ClusterUpgradeMemberEvacuating:
+		logger.Info("Evacuating cluster member for rolling upgrade", logger.Ctx{"member": current.Name})
+
+		err = setMember(*current, db.ClusterUpgradeMemberEvacuating)
+		if err != nil {
+			return err
+		}
+
+		err = setUpgrade(db.ClusterUpgradeStatusRunning, fmt.Sprintf("Evacuating cluster member %q", current.Name))
+		if err != nil {
+			return err
+		}
+
+		err = clusterUpgradeMemberState(s, nodes[current.NodeID], "evacuate")
+		if err != nil {
+			return err
+		}
+
+		err = setMember(*current, db.ClusterUpgradeMemberUpgrading)
+		if err != nil {
+			return err
+		}
+
+		return setUpgrade(db.ClusterUpgradeStatusRunning, fmt.Sprintf("Waiting for cluster member %q to be upgraded", current.Name))
+
+	case db.ClusterUpgradeMemberUpgrading:
+		upgraded, reachable := clusterUpgradeMemberCheck(s, nodes[current.NodeID], *current)
+		if !upgraded {
+			return nil
+		}
+
+		if !reachable {
+			// The member is blocked until the other members are upgraded, move on to the next one.
+			return setMember(*current, db.ClusterUpgradeMemberUpgraded)
+		}
+
+		return restore(*current)
+	}
+
+	return nil
+}
+
+func clusterUpgradeTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil {
+			if !errors.Is(err, cluster.ErrNodeIsNotClustered) {
+				logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			}
+
+			return
+		}
+
+		if s.LocalConfig.ClusterAddress() != leader {
+			return // Skip rolling upgrades if not cluster leader.
+		}
+
+		stepErr := clusterUpgradeStep(ctx, s)
+		if stepErr == nil {
+			return
+		}
+
+		logger.Error("Failed rolling upgrade step, pausing the upgrade", logger.Ctx{"err": stepErr})
+
+		// Pause the upgrade so that the failure can be looked into before going any further.
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			upgrade, err := tx.GetClusterUpgrade(ctx)
+			if err != nil {
+				return err
+			}
+
+			return tx.UpdateClusterUpgradeStatus(ctx, upgrade.ID, db.ClusterUpgradeStatusPaused, fmt.Sprintf("Paused after failure: %v", stepErr))
+		})
+		if err != nil {
+			logger.Error("Failed pausing rolling upgrade", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(30 * time.Second)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/lxd/db"
+)
+
+func TestClusterUpgradeOrder(t *testing.T) {
+	members := []db.NodeInfo{
+		{Name: "m1", Address: "10.0.0.1:8443"},
+		{Name: "m2", Address: "10.0.0.2:8443"},
+		{Name: "m3", Address: "10.0.0.3:8443"},
+		{Name: "m4", Address: "10.0.0.4:8443"},
+		{Name: "m5", Address: "10.0.0.5:8443"},
+	}
+
+	voters := map[string]bool{"10.0.0.1:8443": true, "10.0.0.2:8443": true, "10.0.0.4:8443": true}
+
+	names := func(members []db.NodeInfo) []string {
+		result := []string{}
+		for _, member := range members {
+			result = append(result, member.Name)
+		}
+
+		return result
+	}
+
+	// Members outside of the database quorum first, the database leader last.
+	assert.Equal(t, []string{"m3", "m5", "m1", "m4", "m2"}, names(clusterUpgradeOrder(members, voters, "10.0.0.2:8443")))
+
+	// The given members are left untouched.
+	assert.Equal(t, "m1", members[0].Name)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/filter"
+)
+
+// listOptions are the filtering and pagination options of a request listing a collection.
+type listOptions struct {
+	recursion bool
+	clauses   *filter.ClauseSet
+
+	// limit is the maximum number of entries to return, all of them if zero.
+	limit int
+
+	// after is the key of the last entry of the previous page. Only the entries sorted after it are returned.
+	after string
+}
+
+// parseListOptions parses the "recursion", "filter", "limit" and "after" query parameters of a list request.
+func parseListOptions(r *http.Request) (*listOptions, error) {
+	opts := &listOptions{
+		recursion: util.IsRecursionRequest(r),
+		after:     request.QueryParam(r, "after"),
+	}
+
+	var err error
+	opts.clauses, err = filter.Parse(request.QueryParam(r, "filter"), filter.QueryOperatorSet())
+	if err != nil {
+		return nil, fmt.Errorf("Invalid filter: %w", err)
+	}
+
+	limit := request.QueryParam(r, "limit")
+	if limit != "" {
+		opts.limit, err = strconv.Atoi(limit)
+		if err != nil || opts.limit < 0 {
+			return nil, fmt.Errorf("Invalid limit %q", limit)
+		}
+	}
+
+	return opts, nil
+}
+
+// filtered returns whether the entries must match a filter.
+func (opts *listOptions) filtered() bool {
+	return opts.clauses != nil && len(opts.clauses.Clauses) > 0
+}
+
+// paginated returns whether a single page of the collection is requested.
+func (opts *listOptions) paginated() bool {
+	return opts.limit > 0 || opts.after != ""
+}
+
+// pageOnly returns the options to paginate entries that have already been filtered.
+func (opts *listOptions) pageOnly() *listOptions {
+	return &listOptions{recursion: opts.recursion, limit: opts.limit, after: opts.after}
+}
+
+// match returns whether the entry matches the filter, if any.
+func (opts *listOptions) match(entry any) (bool, error) {
+	if !opts.filtered() {
+		return true, nil
+	}
+
+	return filter.Match(entry, *opts.clauses)
+}
+
+// listKeys returns the keys of the entries that may be part of the requested page, in order. When paginating, the
+// keys are sorted and only the ones after the cursor are kept. The list is also truncated to the page size unless
+// the entries still have to be loaded, as they may then be left out.
+func listKeys(opts *listOptions, keys []string) []string {
+	if !opts.paginated() {
+		return keys
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if opts.after == "" || key > opts.after {
+			sorted = append(sorted, key)
+		}
+	}
+
+	sort.Strings(sorted)
+
+	if !opts.recursion && !opts.filtered() && opts.limit > 0 && len(sorted) > opts.limit {
+		sorted = sorted[:opts.limit]
+	}
+
+	return sorted
+}
+
+// listEntries returns the keys and the entries of the requested page of a collection. The entries are loaded with
+// the load function only when they have to be filtered or returned. The load function may return nil to leave an
+// entry out of the collection.
+func listEntries[T any](opts *listOptions, keys []string, load func(key string) (*T, error)) ([]string, []T, error) {
+	resultKeys := []string{}
+	resultEntries := []T{}
+
+	for _, key := range listKeys(opts, keys) {
+		if opts.limit > 0 && len(resultKeys) >= opts.limit {
+			break
+		}
+
+		if opts.recursion || opts.filtered() {
+			entry, err := load(key)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if entry == nil {
+				continue
+			}
+
+			match, err := opts.match(*entry)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if !match {
+				continue
+			}
+
+			resultEntries = append(resultEntries, *entry)
+		}
+
+		resultKeys = append(resultKeys, key)
+	}
+
+	return resultKeys, resultEntries, nil
+}
+
+// listPage filters the loaded entries of a collection and returns the requested page. The key function returns the
+// key of an entry used as pagination cursor.
+func listPage[T any](opts *listOptions, entries []T, key func(entry T) string) ([]T, error) {
+	entryKeys := make([]string, 0, len(entries))
+	entriesByKey := make(map[string]T, len(entries))
+	for _, entry := range entries {
+		entryKey := key(entry)
+		entryKeys = append(entryKeys, entryKey)
+		entriesByKey[entryKey] = entry
+	}
+
+	opts = &listOptions{recursion: true, clauses: opts.clauses, limit: opts.limit, after: opts.after}
+
+	// Entries with the same key can only be told apart when not paginating.
+	if !opts.paginated() {
+		result := []T{}
+		for _, entry := range entries {
+			match, err := opts.match(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			if match {
+				result = append(result, entry)
+			}
+		}
+
+		return result, nil
+	}
+
+	_, result, err := listEntries(opts, entryKeys, func(entryKey string) (*T, error) {
+		entry := entriesByKey[entryKey]
+		return &entry, nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestParseListOptions(t *testing.T) {
+	r := httptest.NewRequest("GET", "/1.0/networks?recursion=1&filter=type+eq+bridge&limit=2&after=lxdbr0", nil)
+	opts, err := parseListOptions(r)
+	require.NoError(t, err)
+	assert.True(t, opts.recursion)
+	assert.True(t, opts.filtered())
+	assert.True(t, opts.paginated())
+	assert.Equal(t, 2, opts.limit)
+	assert.Equal(t, "lxdbr0", opts.after)
+
+	r = httptest.NewRequest("GET", "/1.0/networks", nil)
+	opts, err = parseListOptions(r)
+	require.NoError(t, err)
+	assert.False(t, opts.filtered())
+	assert.False(t, opts.paginated())
+
+	r = httptest.NewRequest("GET", "/1.0/networks?limit=-1", nil)
+	_, err = parseListOptions(r)
+	assert.Error(t, err)
+
+	r = httptest.NewRequest("GET", "/1.0/networks?filter=type+eq", nil)
+	_, err = parseListOptions(r)
+	assert.Error(t, err)
+}
+
+func TestListPage(t *testing.T) {
+	networks := []api.Network{
+		{Name: "net3", Type: "bridge"},
+		{Name: "net1", Type: "bridge"},
+		{Name: "net4", Type: "macvlan"},
+		{Name: "net2", Type: "bridge"},
+	}
+
+	networkName := func(network api.Network) string { return network.Name }
+	names := func(networks []api.Network) []string {
+		result := []string{}
+		for _, network := range networks {
+			result = append(result, network.Name)
+		}
+
+		return result
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"net3", "net1", "net4", "net2"}},
+		{"filter=type+eq+bridge", []string{"net3", "net1", "net2"}},
+		{"limit=2", []string{"net1", "net2"}},
+		{"limit=2&after=net2", []string{"net3", "net4"}},
+		{"after=net4", []string{}},
+		{"filter=type+eq+bridge&limit=2&after=net1", []string{"net2", "net3"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			opts, err := parseListOptions(httptest.NewRequest("GET", "/1.0/networks?"+test.query, nil))
+			require.NoError(t, err)
+
+			page, err := listPage(opts, networks, networkName)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, names(page))
+		})
+	}
+}
+
+func TestListEntries(t *testing.T) {
+	loaded := []string{}
+	load := func(name string) (*api.Network, error) {
+		loaded = append(loaded, name)
+		if name == "net2" {
+			return nil, nil
+		}
+
+		return &api.Network{Name: name}, nil
+	}
+
+	opts, err := parseListOptions(httptest.NewRequest("GET", "/1.0/networks?limit=2", nil))
+	require.NoError(t, err)
+
+	// Entries aren't loaded when only their keys are returned.
+	keys, entries, err := listEntries(opts, []string{"net3", "net2", "net1"}, load)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"net1", "net2"}, keys)
+	assert.Empty(t, entries)
+	assert.Empty(t, loaded)
+
+	opts.recursion = true
+	keys, entries, err = listEntries(opts, []string{"net3", "net2", "net1"}, load)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"net1", "net3"}, keys)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, []string{"net1", "net2", "net3"}, loaded)
+}
@@ -71,12 +71,24 @@ func (m *Map) Change(changes map[string]any) (map[string]string, error) {
 
 	// Any key not explicitly set, is considered unset.
 	for name, key := range m.schema {
+		if isDynamic(name) {
+			continue
+		}
+
 		_, ok := values[name]
 		if !ok {
 			values[name] = key.Default
 		}
 	}
 
+	// The same goes for the dynamic keys currently set.
+	for name := range m.values {
+		_, ok := values[name]
+		if !ok && !shared.IsUserConfig(name) {
+			values[name] = ""
+		}
+	}
+
 	names, err := m.update(values)
 
 	changed := map[string]string{}
@@ -93,7 +105,7 @@ func (m *Map) Dump() map[string]any {
 	values := map[string]any{}
 
 	for name, value := range m.values {
-		key, ok := m.schema[name]
+		key, ok := m.schema.getKey(name)
 		if ok {
 			// Schema key
 			value := m.GetRaw(name)
@@ -217,7 +229,7 @@ func (m *Map) set(name string, value string, initial bool) (bool, error) {
 		return true, nil
 	}
 
-	key, ok := m.schema[name]
+	key, ok := m.schema.getKey(name)
 	if !ok {
 		return false, fmt.Errorf("Unknown key")
 	}
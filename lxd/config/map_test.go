@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/canonical/lxd/lxd/config"
+	"github.com/canonical/lxd/shared/validate"
 )
 
 // Loading a config Map initializes it with the given values.
@@ -282,6 +283,35 @@ func TestMap_Dump(t *testing.T) {
 	assert.Equal(t, dump, m.Dump())
 }
 
+// Dynamic keys match any name with a single segment in place of the wildcard.
+func TestMap_DynamicKeys(t *testing.T) {
+	schema := config.Schema{
+		"foo":           {},
+		"sink.*.type":   {Validator: validate.Optional(validate.IsOneOf("a", "b"))},
+		"sink.*.target": {},
+	}
+
+	m, err := config.Load(schema, map[string]string{"sink.one.type": "a", "sink.two.type": "b"})
+	require.NoError(t, err)
+	assert.Equal(t, "a", m.GetString("sink.one.type"))
+	assert.Equal(t, "", m.GetString("sink.three.type"))
+
+	// Dynamic keys that aren't passed are unset.
+	changed, err := m.Change(map[string]any{"sink.one.type": "a", "sink.one.target": "x"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"sink.one.target": "x", "sink.two.type": ""}, changed)
+	assert.Equal(t, map[string]any{"sink.one.type": "a", "sink.one.target": "x"}, m.Dump())
+
+	_, err = m.Change(map[string]any{"sink.one.type": "c"})
+	assert.EqualError(t, err, `Cannot set "sink.one.type" to "c": Invalid value "c" (not one of [a b])`)
+
+	_, err = m.Change(map[string]any{"sink.one.other": "x"})
+	assert.EqualError(t, err, `Cannot set "sink.one.other" to "x": Unknown key`)
+
+	_, err = m.Change(map[string]any{"sink..type": "a"})
+	assert.EqualError(t, err, `Cannot set "sink..type" to "a": Unknown key`)
+}
+
 // The various GetXXX methods return typed values.
 func TestMap_Getters(t *testing.T) {
 	schema := config.Schema{
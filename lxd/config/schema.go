@@ -27,19 +27,41 @@ func (s Schema) Keys() []string {
 }
 
 // Defaults returns a map of all key names in the schema along with their default
-// values.
+// values. Dynamic keys are not included.
 func (s Schema) Defaults() map[string]any {
 	values := make(map[string]any, len(s))
 	for name, key := range s {
+		if isDynamic(name) {
+			continue
+		}
+
 		values[name] = key.Default
 	}
 
 	return values
 }
 
+// Get the Key associated with the given name. Dynamic keys, declared with a "*"
+// segment (for example "logging.*.target.type"), match any name having a single
+// non-empty segment in its place.
+func (s Schema) getKey(name string) (Key, bool) {
+	key, ok := s[name]
+	if ok {
+		return key, true
+	}
+
+	for pattern, key := range s {
+		if isDynamic(pattern) && matchDynamic(pattern, name) {
+			return key, true
+		}
+	}
+
+	return Key{}, false
+}
+
 // Get the Key associated with the given name, or panic.
 func (s Schema) mustGetKey(name string) Key {
-	key, ok := s[name]
+	key, ok := s.getKey(name)
 	if !ok {
 		panic(fmt.Sprintf("Attempt to access unknown key %q", name))
 	}
@@ -47,6 +69,36 @@ func (s Schema) mustGetKey(name string) Key {
 	return key
 }
 
+// isDynamic returns whether the given schema key name contains a wildcard segment.
+func isDynamic(pattern string) bool {
+	return strings.Contains(pattern, "*")
+}
+
+// matchDynamic returns whether the given key name matches the dynamic schema key name.
+func matchDynamic(pattern string, name string) bool {
+	patternFields := strings.Split(pattern, ".")
+	nameFields := strings.Split(name, ".")
+	if len(patternFields) != len(nameFields) {
+		return false
+	}
+
+	for i := range patternFields {
+		if patternFields[i] == "*" {
+			if nameFields[i] == "" {
+				return false
+			}
+
+			continue
+		}
+
+		if patternFields[i] != nameFields[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Assert that the Key with the given name as the given type. Panic if no Key
 // with such name exists, or if it does not match the tiven type.
 func (s Schema) assertKeyType(name string, code Type) {
@@ -29,6 +29,7 @@ import (
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/acme"
 	"github.com/canonical/lxd/lxd/apparmor"
+	"github.com/canonical/lxd/lxd/audit"
 	"github.com/canonical/lxd/lxd/auth"
 	authDrivers "github.com/canonical/lxd/lxd/auth/drivers"
 	"github.com/canonical/lxd/lxd/auth/oidc"
@@ -51,7 +52,6 @@ import (
 	instanceDrivers "github.com/canonical/lxd/lxd/instance/drivers"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/lifecycle"
-	"github.com/canonical/lxd/lxd/loki"
 	"github.com/canonical/lxd/lxd/maas"
 	networkZone "github.com/canonical/lxd/lxd/network/zone"
 	"github.com/canonical/lxd/lxd/node"
@@ -60,6 +60,7 @@ import (
 	"github.com/canonical/lxd/lxd/rsync"
 	scriptletLoad "github.com/canonical/lxd/lxd/scriptlet/load"
 	"github.com/canonical/lxd/lxd/seccomp"
+	"github.com/canonical/lxd/lxd/sink"
 	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
@@ -67,9 +68,11 @@ import (
 	"github.com/canonical/lxd/lxd/storage/s3/miniod"
 	"github.com/canonical/lxd/lxd/sys"
 	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/tracing"
 	"github.com/canonical/lxd/lxd/ucred"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/lxd/warnings"
+	"github.com/canonical/lxd/lxd/webhook"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/cancel"
@@ -100,7 +103,10 @@ type Daemon struct {
 
 	// Indexes of tasks that need to be reset when their execution interval changes
 	taskPruneImages      *task.Task
+	taskImageStreams     *task.Task
 	taskClusterHeartbeat *task.Task
+	taskClusterRebalance *task.Task
+	taskClusterUpgrade   *task.Task
 
 	// Stores startup time of daemon
 	startTime time.Time
@@ -152,7 +158,12 @@ type Daemon struct {
 	// Server's UUID from file.
 	serverUUID string
 
-	lokiClient *loki.Client
+	// Event sinks, indexed by internal listener handler name.
+	sinks   map[string]sink.Sink
+	sinksMu sync.Mutex
+
+	// Webhooks.
+	webhooks *webhook.Dispatcher
 
 	// HTTP-01 challenge provider for ACME
 	http01Provider acme.HTTP01Provider
@@ -351,11 +362,11 @@ func allowProjectResourceList(d *Daemon, r *http.Request) response.Response {
 
 // Authenticate validates an incoming http Request
 // It will check over what protocol it came, what type of request it is and
-// will validate the TLS certificate or OIDC token.
+// will validate the TLS certificate, OIDC token or bearer token.
 //
 // This does not perform authorization, only validates authentication.
 // Returns whether trusted or not, the username (or certificate fingerprint) of the trusted client, and the type of
-// client that has been authenticated (cluster, unix, oidc or tls).
+// client that has been authenticated (cluster, unix, oidc, bearer or tls).
 func (d *Daemon) Authenticate(w http.ResponseWriter, r *http.Request) (trusted bool, username string, method string, identityProviderGroups []string, err error) {
 	// Perform mTLS check against server certificates. If this passes, the request was made by another cluster member
 	// and the protocol is auth.AuthenticationMethodCluster.
@@ -402,6 +413,17 @@ func (d *Daemon) Authenticate(w http.ResponseWriter, r *http.Request) (trusted b
 		return false, "", "", nil, fmt.Errorf("Bad/missing TLS on network query")
 	}
 
+	// LXD issued bearer tokens. These are checked before OIDC as both use the Authorization header.
+	token, ok := identity.IsBearerTokenRequest(r)
+	if ok {
+		id, err := d.identityCache.GetByBearerToken(token, r.RemoteAddr)
+		if err != nil {
+			return false, "", "", nil, fmt.Errorf("Failed bearer token authentication: %w", err)
+		}
+
+		return true, id.Identifier, api.AuthenticationMethodBearer, nil, nil
+	}
+
 	if d.oidcVerifier != nil && d.oidcVerifier.IsRequest(r) {
 		result, err := d.oidcVerifier.Auth(d.shutdownCtx, w, r)
 		if err != nil {
@@ -539,7 +561,7 @@ func (d *Daemon) handleOIDCAuthenticationResult(r *http.Request, result *oidc.Au
 	if action != "" {
 		// Notify other nodes about the new identity.
 		s := d.State()
-		notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+		notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 		if err != nil {
 			return fmt.Errorf("Failed to notify cluster members of new or updated OIDC identity: %w", err)
 		}
@@ -641,6 +663,10 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 			}
 		}
 
+		// Trace the request, continuing the trace of the caller if any.
+		r, span := tracing.StartRequest(r, uri)
+		defer span.End()
+
 		// Authentication
 		trusted, username, protocol, identityProviderGroups, err := d.Authenticate(w, r)
 		if err != nil {
@@ -664,6 +690,19 @@ func (d *Daemon) createCmd(restAPI *mux.Router, version string, c APIEndpoint) {
 		// Set the "trusted" value in the request context.
 		request.SetCtxValue(r, request.CtxTrusted, trusted)
 
+		// Record the mutating requests of authenticated clients in the audit log once handled, including those
+		// that are rejected. Unauthenticated requests aren't recorded so that they can't flood the audit log.
+		if trusted && version == "1.0" && audit.IsMutating(r.Method) {
+			recorder := audit.NewResponseRecorder(w)
+			digester := audit.NewBodyDigester(r.Body)
+			date := time.Now()
+
+			w = recorder
+			r.Body = digester
+
+			defer func() { recordAuditLogEntry(d.State(), r, date, digester, recorder) }()
+		}
+
 		// Reject internal queries to remote, non-cluster, clients
 		if version == "internal" && !shared.ValueInSlice(protocol, []string{auth.AuthenticationMethodUnix, auth.AuthenticationMethodCluster}) {
 			// Except for the initial cluster accept request (done over trusted TLS)
@@ -885,46 +924,83 @@ func (d *Daemon) Init() error {
 	return nil
 }
 
-func (d *Daemon) setupLoki(URL string, cert string, key string, caCert string, instanceName string, logLevel string, labels []string, types []string) error {
-	// Stop any existing loki client.
-	if d.lokiClient != nil {
-		d.lokiClient.Stop()
+// eventSinkConfigs returns the configuration keys of the event sinks, indexed by internal listener handler name.
+// The "loki.*" server configuration keys configure the "loki" sink.
+func eventSinkConfigs(config *clusterConfig.Config) map[string]map[string]string {
+	configs := map[string]map[string]string{}
+
+	lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiInstance, lokiLoglevel, lokiLabels, lokiTypes := config.LokiServer()
+	if lokiURL != "" && lokiLoglevel != "" && len(lokiTypes) > 0 {
+		configs["loki"] = map[string]string{
+			"target.type":     sink.TypeLoki,
+			"target.address":  lokiURL,
+			"target.username": lokiUsername,
+			"target.password": lokiPassword,
+			"target.ca_cert":  lokiCACert,
+			"target.instance": lokiInstance,
+			"target.labels":   strings.Join(lokiLabels, ","),
+			"logging.level":   lokiLoglevel,
+			"types":           strings.Join(lokiTypes, ","),
+		}
 	}
 
-	// Check basic requirements for starting a new client.
-	if URL == "" || logLevel == "" || len(types) == 0 {
-		return nil
+	for name, sinkConfig := range config.LoggingSinks() {
+		configs["logging."+name] = sinkConfig
 	}
 
-	// Validate the URL.
-	u, err := url.Parse(URL)
-	if err != nil {
-		return err
+	return configs
+}
+
+// setupSinks replaces the running event sinks with the ones described by the given configurations.
+// Incomplete or invalid sink configurations are skipped.
+func (d *Daemon) setupSinks(configs map[string]map[string]string) {
+	d.sinksMu.Lock()
+	defer d.sinksMu.Unlock()
+
+	// Stop the existing sinks.
+	for name, s := range d.sinks {
+		d.internalListener.RemoveHandler(name)
+		s.Stop()
 	}
 
+	d.sinks = make(map[string]sink.Sink, len(configs))
+
 	// Handle standalone systems.
 	var location string
 	if !d.serverClustered {
 		hostname, err := os.Hostname()
 		if err != nil {
-			return err
+			logger.Warn("Failed getting host name, not starting event sinks", logger.Ctx{"err": err})
+			return
 		}
 
 		location = hostname
-		if instanceName == "" {
-			instanceName = hostname
-		}
-	} else if instanceName == "" {
-		instanceName = d.serverName
 	}
 
-	// Start a new client.
-	d.lokiClient = loki.NewClient(d.shutdownCtx, u, cert, key, caCert, instanceName, location, logLevel, labels, types)
+	for name, config := range configs {
+		cfg, err := sink.ParseConfig(name, config, shared.LogPath())
+		if err != nil {
+			logger.Warn("Skipping invalid event sink", logger.Ctx{"sink": name, "err": err})
+			continue
+		}
 
-	// Attach the new client to the log handler.
-	d.internalListener.AddHandler("loki", d.lokiClient.HandleEvent)
+		cfg.Location = location
+		if cfg.Type == sink.TypeLoki && cfg.Instance == "" {
+			cfg.Instance = location
+			if d.serverClustered {
+				cfg.Instance = d.serverName
+			}
+		}
 
-	return nil
+		s, err := sink.New(d.shutdownCtx, cfg)
+		if err != nil {
+			logger.Warn("Failed starting event sink", logger.Ctx{"sink": name, "err": err})
+			continue
+		}
+
+		d.sinks[name] = s
+		d.internalListener.AddHandler(name, s.HandleEvent)
+	}
 }
 
 func (d *Daemon) init() error {
@@ -944,6 +1020,9 @@ func (d *Daemon) init() error {
 	// Setup internal event listener
 	d.internalListener = events.NewInternalListener(d.shutdownCtx, d.events)
 
+	// Setup webhook dispatcher
+	d.webhooks = webhook.NewDispatcher(d.shutdownCtx, d.webhookDeadLetter, d.webhookDropped)
+
 	// Lets check if there's an existing LXD running
 	err = endpoints.CheckAlreadyRunning(d.UnixSocket())
 	if err != nil {
@@ -1111,6 +1190,20 @@ func (d *Daemon) init() error {
 		logger.Infof(" - unprivileged file capabilities: no")
 	}
 
+	d.os.CRIUVersion, d.os.CRIU = canUseCRIU()
+	if d.os.CRIU {
+		logger.Infof(" - checkpoint/restore (CRIU %s): yes", d.os.CRIUVersion)
+
+		d.os.CRIUMemDirtyTracking = canUseCRIUMemDirtyTracking()
+		if d.os.CRIUMemDirtyTracking {
+			logger.Info(" - checkpoint/restore dirty memory tracking: yes")
+		} else {
+			logger.Info(" - checkpoint/restore dirty memory tracking: no")
+		}
+	} else {
+		logger.Info(" - checkpoint/restore (CRIU): no")
+	}
+
 	dbWarnings = append(dbWarnings, d.os.CGInfo.Warnings()...)
 
 	logger.Infof(" - cgroup layout: %s", d.os.CGInfo.Mode())
@@ -1516,7 +1609,8 @@ func (d *Daemon) init() error {
 
 	maasAPIURL, maasAPIKey = d.globalConfig.MAASController()
 	d.gateway.HeartbeatOfflineThreshold = d.globalConfig.OfflineThreshold()
-	lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiInstance, lokiLoglevel, lokiLabels, lokiTypes := d.globalConfig.LokiServer()
+	sinkConfigs := eventSinkConfigs(d.globalConfig)
+	tracingAddress, tracingSampleRatio := d.globalConfig.Tracing()
 	oidcIssuer, oidcClientID, oidcAudience, oidcGroupsClaim := d.globalConfig.OIDCServer()
 	syslogSocketEnabled := d.localConfig.SyslogSocket()
 	instancePlacementScriptlet := d.globalConfig.InstancesPlacementScriptlet()
@@ -1524,12 +1618,19 @@ func (d *Daemon) init() error {
 	d.endpoints.NetworkUpdateTrustedProxy(d.globalConfig.HTTPSTrustedProxy())
 	d.globalConfigMu.Unlock()
 
-	// Setup Loki logger.
-	if lokiURL != "" {
-		err = d.setupLoki(lokiURL, lokiUsername, lokiPassword, lokiCACert, lokiInstance, lokiLoglevel, lokiLabels, lokiTypes)
-		if err != nil {
-			return err
-		}
+	// Setup event sinks.
+	d.setupSinks(sinkConfigs)
+
+	// Setup tracing.
+	err = tracing.Setup(d.shutdownCtx, tracingAddress, tracingSampleRatio, d.serverName)
+	if err != nil {
+		return fmt.Errorf("Failed setting up tracing: %w", err)
+	}
+
+	// Setup webhooks.
+	err = d.refreshWebhooks(d.shutdownCtx)
+	if err != nil {
+		return err
 	}
 
 	if syslogSocketEnabled {
@@ -1740,6 +1841,12 @@ func (d *Daemon) init() error {
 		return fmt.Errorf("Failed deleting volatile.last_state.ready: %w", err)
 	}
 
+	// Roll back the operations interrupted by the last shutdown.
+	err = recoverInterruptedOperations(d.State())
+	if err != nil {
+		logger.Warn("Failed recovering interrupted operations", logger.Ctx{"err": err})
+	}
+
 	close(d.setupChan)
 
 	_ = d.db.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -1777,12 +1884,18 @@ func (d *Daemon) init() error {
 		// Log expiry (daily)
 		d.tasks.Add(expireLogsTask(d.State()))
 
+		// Remove expired audit log entries (daily)
+		d.tasks.Add(expireAuditLogTask(d))
+
 		// Remove expired images (daily)
 		d.taskPruneImages = d.tasks.Add(pruneExpiredImagesTask(d))
 
 		// Auto-update images (every 6 hours, configurable)
 		d.tasks.Add(autoUpdateImagesTask(d))
 
+		// Update the simplestreams image index (hourly)
+		d.taskImageStreams = d.tasks.Add(imageStreamsUpdateTask(d))
+
 		// Auto-update instance types (daily)
 		d.tasks.Add(instanceRefreshTypesTask(d))
 
@@ -1803,6 +1916,15 @@ func (d *Daemon) init() error {
 
 		// Remove expired tokens (hourly)
 		d.tasks.Add(autoRemoveExpiredTokensTask(d))
+
+		// Back up the global database on schedule
+		d.tasks.Add(clusterDatabaseBackupTask(d))
+
+		// Replicate instances and custom volumes on schedule
+		d.tasks.Add(replicationTask(d))
+
+		// Record the resource usage of the projects
+		d.tasks.Add(projectUsageTask(d))
 	}
 
 	// Start all background tasks
@@ -1841,6 +1963,12 @@ func (d *Daemon) startClusterTasks() {
 	// Perform automatic evacuation for offline cluster members
 	d.clusterTasks.Add(autoHealClusterTask(d))
 
+	// Move instances between cluster members to reduce load imbalance
+	d.taskClusterRebalance = d.clusterTasks.Add(clusterRebalanceTask(d))
+
+	// Move rolling upgrades of the cluster members forward
+	d.taskClusterUpgrade = d.clusterTasks.Add(clusterUpgradeTask(d))
+
 	// Start all background tasks
 	d.clusterTasks.Start(d.shutdownCtx)
 }
@@ -1848,6 +1976,8 @@ func (d *Daemon) startClusterTasks() {
 func (d *Daemon) stopClusterTasks() {
 	_ = d.clusterTasks.Stop(3 * time.Second)
 	d.clusterTasks = task.NewGroup()
+	d.taskClusterRebalance = nil
+	d.taskClusterUpgrade = nil
 }
 
 // numRunningInstances returns the number of running instances.
@@ -2027,6 +2157,11 @@ func (d *Daemon) Stop(ctx context.Context, sig os.Signal) error {
 		trackError(d.seccomp.Stop(), "Stop seccomp")
 	}
 
+	// Send the pending spans.
+	tracingCtx, tracingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	trackError(tracing.Shutdown(tracingCtx), "Stop tracing")
+	tracingCancel()
+
 	n = len(errs)
 	if n > 0 {
 		format := "%v"
@@ -8,9 +8,11 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/lifecycle"
@@ -23,8 +25,10 @@ import (
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/cancel"
+	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/ioprogress"
 	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/oci"
 	"github.com/canonical/lxd/shared/units"
 	"github.com/canonical/lxd/shared/version"
 )
@@ -46,6 +50,7 @@ type ImageDownloadArgs struct {
 	Budget            int64
 	SourceProjectName string
 	UserRequested     bool
+	Signature         string // Overrides the signature of the image provided by the source.
 }
 
 // imageOperationLock acquires a lock for operating on an image and returns the unlock function.
@@ -65,6 +70,9 @@ func ImageDownload(r *http.Request, s *state.State, op *operations.Operation, ar
 	var remote lxd.ImageServer
 	var info *api.Image
 
+	var ociClient *oci.Client
+	var ociImage *oci.Image
+
 	// Default protocol is LXD. Copy so that local modifications aren't propagated to args.
 	protocol := args.Protocol
 	if protocol == "" {
@@ -122,6 +130,40 @@ func ImageDownload(r *http.Request, s *state.State, op *operations.Operation, ar
 
 			fp = info.Fingerprint
 		}
+	} else if protocol == "oci" {
+		if args.Type != "" && args.Type != "container" {
+			return nil, fmt.Errorf("OCI images can only be used for containers")
+		}
+
+		// Local image stores are read from the server's filesystem, so only server administrators may use them.
+		// Requests made by LXD itself, like image auto-updates, were already checked when the image was added.
+		if strings.HasPrefix(args.Server, "file://") && r != nil {
+			err = s.Authorizer.CheckPermission(r.Context(), entity.ServerURL(), auth.EntitlementCanEdit)
+			if auth.IsDeniedError(err) {
+				return nil, api.StatusErrorf(http.StatusForbidden, "Only server administrators can use local OCI image stores")
+			} else if err != nil {
+				return nil, err
+			}
+		}
+
+		ociClient, ociImage, err = imageResolveOCI(s, args.Server, args.Certificate, alias)
+		if err != nil {
+			return nil, err
+		}
+
+		// Reuse the image previously converted from the same manifest.
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			fp, err = tx.GetImageFingerprintWithProperty(ctx, "oci.digest", ociImage.Digest)
+
+			return err
+		})
+		if err != nil {
+			if !response.IsNotFoundError(err) {
+				return nil, err
+			}
+
+			fp = ociImage.Fingerprint()
+		}
 	}
 
 	// Ensure we are the only ones operating on this image.
@@ -200,6 +242,15 @@ func ImageDownload(r *http.Request, s *state.State, op *operations.Operation, ar
 			return err
 		})
 		if err == nil {
+			if args.Signature != "" {
+				imgInfo.Properties["signature"] = args.Signature
+			}
+
+			err = imageCheckSignature(s, args.ProjectName, imgInfo.Fingerprint, imgInfo.Properties)
+			if err != nil {
+				return nil, err
+			}
+
 			var nodeAddress string
 
 			err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -545,10 +596,30 @@ func ImageDownload(r *http.Request, s *state.State, op *operations.Operation, ar
 		if err != nil {
 			return nil, err
 		}
+	} else if protocol == "oci" {
+		info, err = imageDownloadOCI(s, ociClient, ociImage, destName, args.Budget, progress)
+		if err != nil {
+			return nil, err
+		}
+
+		fp = info.Fingerprint
 	} else {
 		return nil, fmt.Errorf("Unsupported protocol: %v", protocol)
 	}
 
+	if args.Signature != "" {
+		if info.Properties == nil {
+			info.Properties = map[string]string{}
+		}
+
+		info.Properties["signature"] = args.Signature
+	}
+
+	err = imageCheckSignature(s, args.ProjectName, info.Fingerprint, info.Properties)
+	if err != nil {
+		return nil, err
+	}
+
 	// Override visiblity
 	info.Public = args.Public
 
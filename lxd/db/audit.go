@@ -0,0 +1,96 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// AuditLogFilter restricts the audit log entries returned by GetAuditLogEntries. Zero values match everything.
+type AuditLogFilter struct {
+	Since    time.Time
+	Until    time.Time
+	Identity string
+	Entity   string
+}
+
+// CreateAuditLogEntry appends an entry to the audit log of the local member.
+func (n *NodeTx) CreateAuditLogEntry(ctx context.Context, entry api.AuditLogEntry) error {
+	stmt := `
+INSERT INTO audit_log (date, username, protocol, source_address, method, url, entity_type, entity_url, body_sha256, status_code)
+  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+	_, err := n.tx.ExecContext(ctx, stmt, entry.Date.UTC(), entry.Identity, entry.AuthenticationMethod, entry.SourceAddress, entry.Method, entry.URL, entry.EntityType, entry.EntityURL, entry.BodySHA256, entry.StatusCode)
+	if err != nil {
+		return fmt.Errorf("Failed recording audit log entry: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuditLogEntries returns the audit log entries of the local member matching the filter, oldest first.
+func (n *NodeTx) GetAuditLogEntries(ctx context.Context, filter AuditLogFilter) ([]api.AuditLogEntry, error) {
+	var where []string
+	var args []any
+
+	if !filter.Since.IsZero() {
+		where = append(where, "date >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+
+	if !filter.Until.IsZero() {
+		where = append(where, "date <= ?")
+		args = append(args, filter.Until.UTC())
+	}
+
+	if filter.Identity != "" {
+		where = append(where, "username = ?")
+		args = append(args, filter.Identity)
+	}
+
+	if filter.Entity != "" {
+		where = append(where, "entity_url = ?")
+		args = append(args, filter.Entity)
+	}
+
+	sql := "SELECT date, username, protocol, source_address, method, url, entity_type, entity_url, body_sha256, status_code FROM audit_log"
+	if len(where) > 0 {
+		sql += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	sql += " ORDER BY id"
+
+	entries := []api.AuditLogEntry{}
+	err := query.Scan(ctx, n.tx, sql, func(scan func(dest ...any) error) error {
+		entry := api.AuditLogEntry{}
+		err := scan(&entry.Date, &entry.Identity, &entry.AuthenticationMethod, &entry.SourceAddress, &entry.Method, &entry.URL, &entry.EntityType, &entry.EntityURL, &entry.BodySHA256, &entry.StatusCode)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, entry)
+
+		return nil
+	}, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading audit log entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// DeleteAuditLogEntriesBefore deletes the audit log entries of the local member recorded before the given date.
+func (n *NodeTx) DeleteAuditLogEntriesBefore(ctx context.Context, date time.Time) error {
+	_, err := n.tx.ExecContext(ctx, "DELETE FROM audit_log WHERE date < ?", date.UTC())
+	if err != nil {
+		return fmt.Errorf("Failed deleting audit log entries: %w", err)
+	}
+
+	return nil
+}
@@ -541,3 +541,59 @@ func (c *ClusterTx) RenameVolumeBackup(ctx context.Context, oldName, newName str
 
 	return nil
 }
+
+// UpdateInstanceBackupSize records the size of the tarball of the instance backup with the given name.
+func (c *ClusterTx) UpdateInstanceBackupSize(ctx context.Context, name string, size int64) error {
+	id, err := c.getInstanceBackupID(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.tx.ExecContext(ctx, "UPDATE instances_backups SET size = ? WHERE id = ?", size, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateStoragePoolVolumeBackupSize records the size of the tarball of the volume backup with the given name.
+func (c *ClusterTx) UpdateStoragePoolVolumeBackupSize(ctx context.Context, name string, size int64) error {
+	id, err := c.getStoragePoolVolumeBackupID(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.tx.ExecContext(ctx, "UPDATE storage_volumes_backups SET size = ? WHERE id = ?", size, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetProjectBackupsUsage returns the number of instance and custom volume backups in the given project along with
+// the total size of their tarballs.
+func (c *ClusterTx) GetProjectBackupsUsage(ctx context.Context, projectName string) (count int64, size int64, err error) {
+	q := `
+SELECT COUNT(*), IFNULL(SUM(size), 0) FROM (
+  SELECT instances_backups.size AS size
+    FROM instances_backups
+    JOIN instances ON instances.id = instances_backups.instance_id
+    JOIN projects ON projects.id = instances.project_id
+   WHERE projects.name = ?
+  UNION ALL
+  SELECT storage_volumes_backups.size AS size
+    FROM storage_volumes_backups
+    JOIN storage_volumes ON storage_volumes.id = storage_volumes_backups.storage_volume_id
+    JOIN projects ON projects.id = storage_volumes.project_id
+   WHERE projects.name = ?
+)
+`
+	err = c.tx.QueryRowContext(ctx, q, projectName, projectName).Scan(&count, &size)
+	if err != nil {
+		return -1, -1, fmt.Errorf("Failed counting backups of project %q: %w", projectName, err)
+	}
+
+	return count, size, nil
+}
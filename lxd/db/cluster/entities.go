@@ -491,6 +491,7 @@ SELECT
 		CASE identities.auth_method
 			WHEN %d THEN '%s'
 			WHEN %d THEN '%s'
+			WHEN %d THEN '%s'
 		END,
 		identities.identifier
 	) 
@@ -499,6 +500,7 @@ FROM identities
 	entityTypeIdentity,
 	authMethodTLS, api.AuthenticationMethodTLS,
 	authMethodOIDC, api.AuthenticationMethodOIDC,
+	authMethodBearer, api.AuthenticationMethodBearer,
 )
 
 // identityEntityByID gets the entity of type entity.TypeIdentity with a particular ID.
@@ -1007,10 +1009,12 @@ WHERE '' = ?
 	AND CASE identities.auth_method 
 		WHEN %d THEN '%s' 
 		WHEN %d THEN '%s' 
+		WHEN %d THEN '%s' 
 	END = ? 
 	AND identities.identifier = ?
 `, authMethodTLS, api.AuthenticationMethodTLS,
-	authMethodOIDC, api.AuthenticationMethodOIDC)
+	authMethodOIDC, api.AuthenticationMethodOIDC,
+	authMethodBearer, api.AuthenticationMethodBearer)
 
 // identityIDFromURLStatements is a map of entity.Type to a statement that can be used to get the ID of the entity from its URL.
 var entityIDFromURLStatements = map[entity.Type]string{
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/certificate"
@@ -57,8 +58,9 @@ import (
 type AuthMethod string
 
 const (
-	authMethodTLS  int64 = 1
-	authMethodOIDC int64 = 2
+	authMethodTLS    int64 = 1
+	authMethodOIDC   int64 = 2
+	authMethodBearer int64 = 3
 )
 
 // Scan implements sql.Scanner for AuthMethod. This converts the integer value back into the correct API constant or
@@ -83,6 +85,8 @@ func (a *AuthMethod) Scan(value any) error {
 		*a = api.AuthenticationMethodTLS
 	case authMethodOIDC:
 		*a = api.AuthenticationMethodOIDC
+	case authMethodBearer:
+		*a = api.AuthenticationMethodBearer
 	default:
 		return fmt.Errorf("Unknown authentication method `%d`", authMethodInt)
 	}
@@ -97,6 +101,8 @@ func (a AuthMethod) Value() (driver.Value, error) {
 		return authMethodTLS, nil
 	case api.AuthenticationMethodOIDC:
 		return authMethodOIDC, nil
+	case api.AuthenticationMethodBearer:
+		return authMethodBearer, nil
 	}
 
 	return nil, fmt.Errorf("Invalid authentication method %q", a)
@@ -117,6 +123,7 @@ const (
 	identityTypeCertificateMetricsRestricted   int64 = 4
 	identityTypeOIDCClient                     int64 = 5
 	identityTypeCertificateMetricsUnrestricted int64 = 6
+	identityTypeBearerToken                    int64 = 7
 )
 
 // Scan implements sql.Scanner for IdentityType. This converts the integer value back into the correct API constant or
@@ -149,6 +156,8 @@ func (i *IdentityType) Scan(value any) error {
 		*i = api.IdentityTypeCertificateMetricsUnrestricted
 	case identityTypeOIDCClient:
 		*i = api.IdentityTypeOIDCClient
+	case identityTypeBearerToken:
+		*i = api.IdentityTypeBearerToken
 	default:
 		return fmt.Errorf("Unknown identity type `%d`", identityTypeInt)
 	}
@@ -171,6 +180,8 @@ func (i IdentityType) Value() (driver.Value, error) {
 		return identityTypeCertificateMetricsUnrestricted, nil
 	case api.IdentityTypeOIDCClient:
 		return identityTypeOIDCClient, nil
+	case api.IdentityTypeBearerToken:
+		return identityTypeBearerToken, nil
 	}
 
 	return nil, fmt.Errorf("Invalid identity type %q", i)
@@ -305,6 +316,38 @@ func (i Identity) Subject() (string, error) {
 	return metadata.Subject, nil
 }
 
+// BearerTokenMetadata contains metadata for bearer token identities.
+type BearerTokenMetadata struct {
+	SecretHash   string    `json:"secret_hash"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	AllowedCIDRs []string  `json:"allowed_cidrs"`
+}
+
+// BearerToken returns the bearer token from the identity metadata. The AuthMethod of the Identity must be
+// api.AuthenticationMethodBearer.
+func (i Identity) BearerToken() (*identity.BearerToken, error) {
+	if i.AuthMethod != api.AuthenticationMethodBearer {
+		return nil, fmt.Errorf("Cannot extract bearer token from identity: Identity has authentication method %q (%q required)", i.AuthMethod, api.AuthenticationMethodBearer)
+	}
+
+	var metadata BearerTokenMetadata
+	err := json.Unmarshal([]byte(i.Metadata), &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal bearer token metadata: %w", err)
+	}
+
+	allowedCIDRs, err := identity.ParseCIDRs(metadata.AllowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity.BearerToken{
+		SecretHash:   metadata.SecretHash,
+		ExpiresAt:    metadata.ExpiresAt,
+		AllowedCIDRs: allowedCIDRs,
+	}, nil
+}
+
 // ToAPI converts an Identity to an api.Identity, executing database queries as necessary.
 func (i *Identity) ToAPI(ctx context.Context, tx *sql.Tx, canViewGroup auth.PermissionChecker) (*api.Identity, error) {
 	groups, err := GetAuthGroupsByIdentityID(ctx, tx, i.ID)
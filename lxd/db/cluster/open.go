@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync/atomic"
@@ -149,13 +150,19 @@ func EnsureSchema(db *sql.DB, address string, dir string) (bool, error) {
 		return err
 	}
 
+	// Restore a backup of the database, if one was staged with "lxd cluster restore-database".
+	err := restoreFromFile(db, filepath.Join(dir, "restore.global.sql"))
+	if err != nil {
+		return false, err
+	}
+
 	schema := Schema()
 	schema.File(filepath.Join(dir, "patch.global.sql")) // Optional custom queries
 	schema.Check(check)
 	schema.Hook(hook)
 
 	var initial int
-	err := query.Retry(context.TODO(), func(ctx context.Context) error {
+	err = query.Retry(context.TODO(), func(ctx context.Context) error {
 		var err error
 		initial, err = schema.Ensure(db)
 		if err != nil {
@@ -290,3 +297,34 @@ func checkClusterIsUpgradable(ctx context.Context, tx *sql.Tx, target [2]int) er
 }
 
 var errSomeNodesAreBehind = fmt.Errorf("Some cluster members are behind this cluster member's version")
+
+// restoreFromFile replaces the content of the database with the SQL text dump found at the given path, if any.
+// The file is removed once restored, after which the schema updates bring the restored database up to date.
+func restoreFromFile(db *sql.DB, path string) error {
+	if !shared.PathExists(path) {
+		return nil
+	}
+
+	dump, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to read %q: %w", path, err)
+	}
+
+	logger.Warn("Restoring the global database from a backup", logger.Ctx{"path": path})
+
+	err = query.Retry(context.TODO(), func(ctx context.Context) error {
+		return query.Transaction(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+			return query.Restore(ctx, tx, string(dump))
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to restore the global database from %q: %w", path, err)
+	}
+
+	err = os.Remove(path)
+	if err != nil {
+		return fmt.Errorf("Failed to remove %q: %w", path, err)
+	}
+
+	return nil
+}
@@ -35,6 +35,26 @@ CREATE TABLE "cluster_groups" (
     description TEXT NOT NULL,
     UNIQUE (name)
 );
+CREATE TABLE cluster_upgrades (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    message TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE TABLE cluster_upgrades_members (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    cluster_upgrade_id INTEGER NOT NULL,
+    node_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    version TEXT NOT NULL,
+    schema INTEGER NOT NULL,
+    api_extensions INTEGER NOT NULL,
+    FOREIGN KEY (cluster_upgrade_id) REFERENCES cluster_upgrades (id) ON DELETE CASCADE,
+    FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
+    UNIQUE (cluster_upgrade_id, node_id)
+);
 CREATE TABLE config (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     key TEXT NOT NULL,
@@ -162,6 +182,7 @@ CREATE TABLE "instances_backups" (
     expiry_date DATETIME,
     container_only INTEGER NOT NULL default 0,
     optimized_storage INTEGER NOT NULL default 0,
+    size INTEGER NOT NULL DEFAULT 0,
     FOREIGN KEY (instance_id) REFERENCES "instances" (id) ON DELETE CASCADE,
     UNIQUE (instance_id, name)
 );
@@ -434,6 +455,23 @@ CREATE TABLE "operations" (
     FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
     FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
 );
+CREATE TABLE operations_records (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    uuid TEXT NOT NULL,
+    node_id INTEGER NOT NULL,
+    project_id INTEGER,
+    type INTEGER NOT NULL,
+    class INTEGER NOT NULL,
+    status_code INTEGER NOT NULL,
+    resources TEXT NOT NULL,
+    rollback TEXT NOT NULL,
+    error TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE (uuid),
+    FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
+    FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
 CREATE TABLE "profiles" (
     id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
     name TEXT NOT NULL,
@@ -481,6 +519,18 @@ CREATE TABLE "projects_config" (
     FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE,
     UNIQUE (project_id, key)
 );
+CREATE TABLE projects_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    date DATETIME NOT NULL,
+    cpu_seconds REAL NOT NULL DEFAULT 0,
+    memory_gib_hours REAL NOT NULL DEFAULT 0,
+    disk_gib_days REAL NOT NULL DEFAULT 0,
+    network_bytes_received INTEGER NOT NULL DEFAULT 0,
+    network_bytes_sent INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE,
+    UNIQUE (project_id, date)
+);
 CREATE TABLE "storage_buckets" (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 	name TEXT NOT NULL,
@@ -597,6 +647,7 @@ CREATE TABLE "storage_volumes_backups" (
     expiry_date DATETIME,
     volume_only INTEGER NOT NULL default 0,
     optimized_storage INTEGER NOT NULL default 0,
+    size INTEGER NOT NULL DEFAULT 0,
     FOREIGN KEY (storage_volume_id) REFERENCES "storage_volumes" (id) ON DELETE CASCADE,
     UNIQUE (storage_volume_id, name)
 );
@@ -661,6 +712,20 @@ CREATE TABLE "warnings" (
 	FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
 );
 CREATE UNIQUE INDEX warnings_unique_node_id_project_id_entity_type_code_entity_id_type_code ON warnings(IFNULL(node_id, -1), IFNULL(project_id, -1), entity_type_code, entity_id, type_code);
+CREATE TABLE webhooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (name)
+);
+CREATE TABLE webhooks_config (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    webhook_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    FOREIGN KEY (webhook_id) REFERENCES "webhooks" (id) ON DELETE CASCADE,
+    UNIQUE (webhook_id, key)
+);
 
-INSERT INTO schema (version, updated_at) VALUES (73, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (78, strftime("%s"))
 `
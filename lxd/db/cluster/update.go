@@ -110,6 +110,126 @@ var updates = map[int]schema.Update{
 	71: updateFromV70,
 	72: updateFromV71,
 	73: updateFromV72,
+	74: updateFromV73,
+	75: updateFromV74,
+	76: updateFromV75,
+	77: updateFromV76,
+	78: updateFromV77,
+}
+
+func updateFromV77(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE operations_records (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    uuid TEXT NOT NULL,
+    node_id INTEGER NOT NULL,
+    project_id INTEGER,
+    type INTEGER NOT NULL,
+    class INTEGER NOT NULL,
+    status_code INTEGER NOT NULL,
+    resources TEXT NOT NULL,
+    rollback TEXT NOT NULL,
+    error TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE (uuid),
+    FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
+    FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV76(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE webhooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    UNIQUE (name)
+);
+CREATE TABLE webhooks_config (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    webhook_id INTEGER NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    FOREIGN KEY (webhook_id) REFERENCES "webhooks" (id) ON DELETE CASCADE,
+    UNIQUE (webhook_id, key)
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV75(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+ALTER TABLE instances_backups ADD COLUMN size INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE storage_volumes_backups ADD COLUMN size INTEGER NOT NULL DEFAULT 0;
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV74(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE projects_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    project_id INTEGER NOT NULL,
+    date DATETIME NOT NULL,
+    cpu_seconds REAL NOT NULL DEFAULT 0,
+    memory_gib_hours REAL NOT NULL DEFAULT 0,
+    disk_gib_days REAL NOT NULL DEFAULT 0,
+    network_bytes_received INTEGER NOT NULL DEFAULT 0,
+    network_bytes_sent INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (project_id) REFERENCES "projects" (id) ON DELETE CASCADE,
+    UNIQUE (project_id, date)
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func updateFromV73(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+CREATE TABLE cluster_upgrades (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    message TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE TABLE cluster_upgrades_members (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    cluster_upgrade_id INTEGER NOT NULL,
+    node_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    version TEXT NOT NULL,
+    schema INTEGER NOT NULL,
+    api_extensions INTEGER NOT NULL,
+    FOREIGN KEY (cluster_upgrade_id) REFERENCES cluster_upgrades (id) ON DELETE CASCADE,
+    FOREIGN KEY (node_id) REFERENCES "nodes" (id) ON DELETE CASCADE,
+    UNIQUE (cluster_upgrade_id, node_id)
+);
+`)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
 func updateFromV72(ctx context.Context, tx *sql.Tx) error {
@@ -0,0 +1,224 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Rolling upgrade statuses.
+const (
+	ClusterUpgradeStatusRunning = iota
+	ClusterUpgradeStatusPaused
+	ClusterUpgradeStatusAborted
+	ClusterUpgradeStatusCompleted
+)
+
+// ClusterUpgradeStatuses maps rolling upgrade statuses to their names.
+var ClusterUpgradeStatuses = map[int]string{
+	ClusterUpgradeStatusRunning:   "running",
+	ClusterUpgradeStatusPaused:    "paused",
+	ClusterUpgradeStatusAborted:   "aborted",
+	ClusterUpgradeStatusCompleted: "completed",
+}
+
+// Rolling upgrade statuses of a cluster member.
+const (
+	ClusterUpgradeMemberPending = iota
+	ClusterUpgradeMemberEvacuating
+	ClusterUpgradeMemberUpgrading
+	ClusterUpgradeMemberUpgraded
+	ClusterUpgradeMemberRestoring
+	ClusterUpgradeMemberDone
+)
+
+// ClusterUpgradeMemberStatuses maps rolling upgrade member statuses to their names.
+var ClusterUpgradeMemberStatuses = map[int]string{
+	ClusterUpgradeMemberPending:    "pending",
+	ClusterUpgradeMemberEvacuating: "evacuating",
+	ClusterUpgradeMemberUpgrading:  "upgrading",
+	ClusterUpgradeMemberUpgraded:   "upgraded",
+	ClusterUpgradeMemberRestoring:  "restoring",
+	ClusterUpgradeMemberDone:       "done",
+}
+
+// clusterUpgradeMemberMessages describes the rolling upgrade statuses in the cluster member list.
+var clusterUpgradeMemberMessages = map[int]string{
+	ClusterUpgradeMemberEvacuating: "Rolling upgrade: evacuating",
+	ClusterUpgradeMemberUpgrading:  "Rolling upgrade: waiting to be upgraded",
+	ClusterUpgradeMemberUpgraded:   "Rolling upgrade: upgraded, waiting for the other members",
+	ClusterUpgradeMemberRestoring:  "Rolling upgrade: restoring",
+}
+
+// ClusterUpgrade is a rolling upgrade of the cluster members.
+type ClusterUpgrade struct {
+	ID        int64
+	Status    int
+	Message   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Members   []ClusterUpgradeMember
+}
+
+// ClusterUpgradeMember is a cluster member taking part in a rolling upgrade.
+type ClusterUpgradeMember struct {
+	NodeID        int64
+	Name          string
+	Status        int
+	Version       string // LXD version of the member when the upgrade was started.
+	Schema        int    // Schema version of the member when the upgrade was started.
+	APIExtensions int    // Number of API extensions of the member when the upgrade was started.
+}
+
+// IsActive returns true if the rolling upgrade is running or paused.
+func (u ClusterUpgrade) IsActive() bool {
+	return u.Status == ClusterUpgradeStatusRunning || u.Status == ClusterUpgradeStatusPaused
+}
+
+// ToAPI converts the rolling upgrade to its API representation.
+func (u ClusterUpgrade) ToAPI() *api.ClusterUpgrade {
+	result := &api.ClusterUpgrade{
+		Status:    ClusterUpgradeStatuses[u.Status],
+		Message:   u.Message,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+		Members:   make([]api.ClusterUpgradeMember, 0, len(u.Members)),
+	}
+
+	for _, member := range u.Members {
+		result.Members = append(result.Members, api.ClusterUpgradeMember{
+			Name:    member.Name,
+			Status:  ClusterUpgradeMemberStatuses[member.Status],
+			Version: member.Version,
+		})
+	}
+
+	return result
+}
+
+// GetClusterUpgrade returns the latest rolling upgrade of the cluster, with its members in upgrade order.
+func (c *ClusterTx) GetClusterUpgrade(ctx context.Context) (*ClusterUpgrade, error) {
+	upgrade := ClusterUpgrade{}
+
+	stmt := `SELECT id, status, message, created_at, updated_at FROM cluster_upgrades ORDER BY id DESC LIMIT 1`
+	err := c.tx.QueryRowContext(ctx, stmt).Scan(&upgrade.ID, &upgrade.Status, &upgrade.Message, &upgrade.CreatedAt, &upgrade.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, api.StatusErrorf(http.StatusNotFound, "No rolling upgrade found")
+		}
+
+		return nil, fmt.Errorf("Failed loading rolling upgrade: %w", err)
+	}
+
+	stmt = `
+SELECT cluster_upgrades_members.node_id, nodes.name, cluster_upgrades_members.status, cluster_upgrades_members.version,
+       cluster_upgrades_members.schema, cluster_upgrades_members.api_extensions
+  FROM cluster_upgrades_members
+  JOIN nodes ON nodes.id = cluster_upgrades_members.node_id
+ WHERE cluster_upgrades_members.cluster_upgrade_id = ?
+ ORDER BY cluster_upgrades_members.position
+`
+	err = query.Scan(ctx, c.tx, stmt, func(scan func(dest ...any) error) error {
+		member := ClusterUpgradeMember{}
+
+		err := scan(&member.NodeID, &member.Name, &member.Status, &member.Version, &member.Schema, &member.APIExtensions)
+		if err != nil {
+			return err
+		}
+
+		upgrade.Members = append(upgrade.Members, member)
+
+		return nil
+	}, upgrade.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading rolling upgrade members: %w", err)
+	}
+
+	return &upgrade, nil
+}
+
+// CreateClusterUpgrade replaces any previous rolling upgrade with a new running one for the given members.
+func (c *ClusterTx) CreateClusterUpgrade(ctx context.Context, message string, members []ClusterUpgradeMember) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM cluster_upgrades")
+	if err != nil {
+		return fmt.Errorf("Failed deleting previous rolling upgrades: %w", err)
+	}
+
+	now := time.Now().UTC()
+	result, err := c.tx.ExecContext(ctx, "INSERT INTO cluster_upgrades (status, message, created_at, updated_at) VALUES (?, ?, ?, ?)", ClusterUpgradeStatusRunning, message, now, now)
+	if err != nil {
+		return fmt.Errorf("Failed creating rolling upgrade: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("Failed getting rolling upgrade ID: %w", err)
+	}
+
+	for i, member := range members {
+		_, err = c.tx.ExecContext(ctx, "INSERT INTO cluster_upgrades_members (cluster_upgrade_id, node_id, position, status, version, schema, api_extensions) VALUES (?, ?, ?, ?, ?, ?, ?)", id, member.NodeID, i, ClusterUpgradeMemberPending, member.Version, member.Schema, member.APIExtensions)
+		if err != nil {
+			return fmt.Errorf("Failed adding cluster member %q to rolling upgrade: %w", member.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateClusterUpgradeStatus updates the status and message of a rolling upgrade.
+func (c *ClusterTx) UpdateClusterUpgradeStatus(ctx context.Context, id int64, status int, message string) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE cluster_upgrades SET status=?, message=?, updated_at=? WHERE id=?", status, message, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("Failed updating rolling upgrade: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateClusterUpgradeMemberStatus updates the status of a cluster member taking part in a rolling upgrade.
+func (c *ClusterTx) UpdateClusterUpgradeMemberStatus(ctx context.Context, id int64, nodeID int64, status int) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE cluster_upgrades_members SET status=? WHERE cluster_upgrade_id=? AND node_id=?", status, id, nodeID)
+	if err != nil {
+		return fmt.Errorf("Failed updating rolling upgrade member: %w", err)
+	}
+
+	_, err = c.tx.ExecContext(ctx, "UPDATE cluster_upgrades SET updated_at=? WHERE id=?", time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("Failed updating rolling upgrade: %w", err)
+	}
+
+	return nil
+}
+
+// GetClusterUpgradeMemberStatuses returns the status of each cluster member taking part in the active rolling
+// upgrade, keyed by member name. The map is empty if no rolling upgrade is active.
+func (c *ClusterTx) GetClusterUpgradeMemberStatuses(ctx context.Context) (map[string]int, error) {
+	statuses := map[string]int{}
+
+	upgrade, err := c.GetClusterUpgrade(ctx)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return statuses, nil
+		}
+
+		return nil, err
+	}
+
+	if !upgrade.IsActive() {
+		return statuses, nil
+	}
+
+	for _, member := range upgrade.Members {
+		statuses[member.Name] = member.Status
+	}
+
+	return statuses, nil
+}
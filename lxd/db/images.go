@@ -23,6 +23,7 @@ var ImageSourceProtocol = map[int]string{
 	0: "lxd",
 	1: "direct",
 	2: "simplestreams",
+	3: "oci",
 }
 
 // GetLocalImagesFingerprints returns the fingerprints of all local images.
@@ -291,6 +292,28 @@ func (c *ClusterTx) GetCachedImageSourceFingerprint(ctx context.Context, server
 	return fingerprints[0], nil
 }
 
+// GetImageFingerprintWithProperty returns the fingerprint of the most recent image having the given property
+// value, regardless of its project.
+func (c *ClusterTx) GetImageFingerprintWithProperty(ctx context.Context, key string, value string) (string, error) {
+	q := `SELECT images.fingerprint
+			FROM images_properties
+			INNER JOIN images
+			ON images_properties.image_id=images.id
+			WHERE images_properties.key=? AND images_properties.value=?
+			ORDER BY images.creation_date DESC`
+
+	fingerprints, err := query.SelectStrings(ctx, c.tx, q, key, value)
+	if err != nil {
+		return "", err
+	}
+
+	if len(fingerprints) == 0 {
+		return "", api.StatusErrorf(http.StatusNotFound, "Image not found")
+	}
+
+	return fingerprints[0], nil
+}
+
 // ImageExists returns whether an image with the given fingerprint exists.
 func (c *ClusterTx) ImageExists(ctx context.Context, project string, fingerprint string) (bool, error) {
 	table := "images JOIN projects ON projects.id = images.project_id"
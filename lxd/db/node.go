@@ -86,6 +86,7 @@ type NodeInfoArgs struct {
 	OfflineThreshold     time.Duration
 	MaxMemberVersion     [2]int
 	RaftNodes            []RaftNode
+	UpgradeStatuses      map[string]int
 }
 
 // ToAPI returns a LXD API entry.
@@ -167,6 +168,12 @@ func (n NodeInfo) ToAPI(ctx context.Context, tx *ClusterTx, args NodeInfoArgs) (
 		}
 	}
 
+	// Report the progress of an active rolling upgrade.
+	upgradeMessage, ok := clusterUpgradeMemberMessages[args.UpgradeStatuses[n.Name]]
+	if ok {
+		result.Message = upgradeMessage
+	}
+
 	return &result, nil
 }
 
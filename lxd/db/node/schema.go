@@ -6,6 +6,20 @@ package node
 // modify the database schema, please add a new schema update to update.go
 // and the run 'make update-schema'.
 const freshSchema = `
+CREATE TABLE audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    date DATETIME NOT NULL,
+    username TEXT NOT NULL,
+    protocol TEXT NOT NULL,
+    source_address TEXT NOT NULL,
+    method TEXT NOT NULL,
+    url TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_url TEXT NOT NULL,
+    body_sha256 TEXT NOT NULL,
+    status_code INTEGER NOT NULL
+);
+CREATE INDEX audit_log_date_idx ON audit_log (date);
 CREATE TABLE certificates (
 	id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
 	fingerprint TEXT NOT NULL,
@@ -34,5 +48,5 @@ CREATE TABLE raft_nodes (
     UNIQUE (address)
 );
 
-INSERT INTO schema (version, updated_at) VALUES (43, strftime("%s"))
+INSERT INTO schema (version, updated_at) VALUES (44, strftime("%s"))
 `
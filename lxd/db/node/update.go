@@ -99,6 +99,7 @@ var updates = map[int]schema.Update{
 	41: updateFromV40,
 	42: updateFromV41,
 	43: updateFromV42,
+	44: updateFromV43,
 }
 
 // UpdateFromPreClustering is the last schema version where clustering support
@@ -107,6 +108,28 @@ const UpdateFromPreClustering = 36
 
 // Schema updates begin here
 
+// updateFromV43 adds the audit_log table recording the API mutations handled by the member.
+func updateFromV43(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    date DATETIME NOT NULL,
+    username TEXT NOT NULL,
+    protocol TEXT NOT NULL,
+    source_address TEXT NOT NULL,
+    method TEXT NOT NULL,
+    url TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_url TEXT NOT NULL,
+    body_sha256 TEXT NOT NULL,
+    status_code INTEGER NOT NULL
+);
+CREATE INDEX audit_log_date_idx ON audit_log (date);
+`
+	_, err := tx.Exec(stmt)
+	return err
+}
+
 // updateFromV42 ensures key and value fields in config table are TEXT NOT NULL.
 func updateFromV42(ctx context.Context, tx *sql.Tx) error {
 	stmt := `
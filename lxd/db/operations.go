@@ -4,10 +4,15 @@ package db
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
 	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
 )
 
 // GetAllNodesWithOperations returns a list of nodes that have operations in any project.
@@ -66,3 +71,147 @@ WHERE (projects.name = ? OR operations.project_id IS NULL) and operations.type =
 
 	return ops, nil
 }
+
+// OperationRecord is the state of a long-running operation recorded in the database, so that the operation can be
+// recovered if interrupted by a restart of LXD.
+type OperationRecord struct {
+	UUID       string
+	Location   string
+	Project    string
+	Type       operationtype.Type
+	Class      int64
+	StatusCode api.StatusCode
+	Resources  map[string][]string
+
+	// Rollback is the list of the entities to delete if the operation is interrupted.
+	Rollback []OperationRollback
+
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// OperationRollback is an entity created by an operation, to delete if the operation is interrupted.
+type OperationRollback struct {
+	// URL of the entity.
+	URL string `json:"url"`
+
+	// UUID of the entity created by the operation (if it has one). An entity found at the URL with a different
+	// UUID was created by someone else and is left untouched.
+	UUID string `json:"uuid,omitempty"`
+}
+
+// operationRecordColumns is the list of columns scanned by scanOperationRecord.
+const operationRecordColumns = `
+operations_records.uuid, nodes.name, coalesce(projects.name, ''), operations_records.type, operations_records.class,
+operations_records.status_code, operations_records.resources, operations_records.rollback, operations_records.error,
+operations_records.created_at, operations_records.updated_at
+  FROM operations_records
+  JOIN nodes ON nodes.id = operations_records.node_id
+  LEFT JOIN projects ON projects.id = operations_records.project_id`
+
+// scanOperationRecord scans a row of operationRecordColumns.
+func scanOperationRecord(scan func(dest ...any) error) (*OperationRecord, error) {
+	record := OperationRecord{}
+
+	var resources string
+	var rollback string
+
+	err := scan(&record.UUID, &record.Location, &record.Project, &record.Type, &record.Class, &record.StatusCode, &resources, &rollback, &record.Error, &record.CreatedAt, &record.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(resources), &record.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing resources of operation %q: %w", record.UUID, err)
+	}
+
+	err = json.Unmarshal([]byte(rollback), &record.Rollback)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing rollback of operation %q: %w", record.UUID, err)
+	}
+
+	return &record, nil
+}
+
+// CreateOrReplaceOperationRecord records the state of an operation running on the local member.
+func (c *ClusterTx) CreateOrReplaceOperationRecord(ctx context.Context, record OperationRecord) error {
+	resources, err := json.Marshal(record.Resources)
+	if err != nil {
+		return err
+	}
+
+	rollback, err := json.Marshal(record.Rollback)
+	if err != nil {
+		return err
+	}
+
+	stmt := `
+INSERT OR REPLACE INTO operations_records (uuid, node_id, project_id, type, class, status_code, resources, rollback, error, created_at, updated_at)
+  VALUES (?, ?, (SELECT id FROM projects WHERE name = ?), ?, ?, ?, ?, ?, ?, ?, ?)
+`
+	_, err = c.tx.ExecContext(ctx, stmt, record.UUID, c.nodeID, record.Project, record.Type, record.Class, record.StatusCode, string(resources), string(rollback), record.Error, record.CreatedAt, record.UpdatedAt)
+	return err
+}
+
+// GetOperationRecord returns the recorded state of the operation with the given UUID.
+func (c *ClusterTx) GetOperationRecord(ctx context.Context, uuid string) (*OperationRecord, error) {
+	var record *OperationRecord
+
+	err := query.Scan(ctx, c.tx, "SELECT "+operationRecordColumns+" WHERE operations_records.uuid = ?", func(scan func(dest ...any) error) error {
+		var err error
+		record, err = scanOperationRecord(scan)
+		return err
+	}, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if record == nil {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Operation not found")
+	}
+
+	return record, nil
+}
+
+// GetInterruptedOperationRecords returns the recorded state of the operations of the local member which didn't
+// reach a final status.
+func (c *ClusterTx) GetInterruptedOperationRecords(ctx context.Context) ([]OperationRecord, error) {
+	records := []OperationRecord{}
+
+	stmt := "SELECT " + operationRecordColumns + " WHERE operations_records.node_id = ? AND operations_records.status_code IN (?, ?, ?) ORDER BY operations_records.id"
+	err := query.Scan(ctx, c.tx, stmt, func(scan func(dest ...any) error) error {
+		record, err := scanOperationRecord(scan)
+		if err != nil {
+			return err
+		}
+
+		records = append(records, *record)
+		return nil
+	}, c.nodeID, api.Pending, api.Running, api.Cancelling)
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// UpdateOperationRecordStatus updates the status and error of the recorded operation with the given UUID.
+func (c *ClusterTx) UpdateOperationRecordStatus(ctx context.Context, uuid string, status api.StatusCode, errMsg string) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE operations_records SET status_code = ?, error = ?, updated_at = ? WHERE uuid = ?", status, errMsg, time.Now().UTC(), uuid)
+	return err
+}
+
+// DeleteOperationRecord deletes the recorded state of the operation with the given UUID.
+func (c *ClusterTx) DeleteOperationRecord(ctx context.Context, uuid string) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM operations_records WHERE uuid = ?", uuid)
+	return err
+}
+
+// DeleteOperationRecordsBefore deletes the recorded state of the operations which reached a final status before
+// the given time.
+func (c *ClusterTx) DeleteOperationRecordsBefore(ctx context.Context, before time.Time) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM operations_records WHERE status_code NOT IN (?, ?, ?) AND updated_at < ?", api.Pending, api.Running, api.Cancelling, before.UTC())
+	return err
+}
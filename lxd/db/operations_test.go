@@ -4,7 +4,9 @@ package db_test
 
 import (
 	"context"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -12,6 +14,7 @@ import (
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/shared/api"
 )
 
 // Add, get and remove an operation.
@@ -109,3 +112,52 @@ func TestOperationNoProject(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, len(ops), 0)
 }
+
+// Record an operation, find it as interrupted, mark it as failed and prune it.
+func TestOperationRecord(t *testing.T) {
+	tx, cleanup := db.NewTestClusterTx(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	record := db.OperationRecord{
+		UUID:       "abcd",
+		Project:    "default",
+		Type:       operationtype.InstanceCreate,
+		StatusCode: api.Running,
+		Resources:  map[string][]string{"instances": {"/1.0/instances/c1"}},
+		Rollback:   []db.OperationRollback{{URL: "/1.0/instances/c1?project=default", UUID: "9c5e8bd1-0a4b-4b8e-9f0a-5d0c8e1b2f3a"}},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	err := tx.CreateOrReplaceOperationRecord(ctx, record)
+	require.NoError(t, err)
+
+	records, err := tx.GetInterruptedOperationRecords(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "none", records[0].Location)
+	assert.Equal(t, "default", records[0].Project)
+	assert.Equal(t, record.Resources, records[0].Resources)
+	assert.Equal(t, record.Rollback, records[0].Rollback)
+
+	err = tx.UpdateOperationRecordStatus(ctx, "abcd", api.Failure, "Interrupted")
+	require.NoError(t, err)
+
+	records, err = tx.GetInterruptedOperationRecords(ctx)
+	require.NoError(t, err)
+	assert.Len(t, records, 0)
+
+	got, err := tx.GetOperationRecord(ctx, "abcd")
+	require.NoError(t, err)
+	assert.Equal(t, api.Failure, got.StatusCode)
+	assert.Equal(t, "Interrupted", got.Error)
+
+	err = tx.DeleteOperationRecordsBefore(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	_, err = tx.GetOperationRecord(ctx, "abcd")
+	assert.True(t, api.StatusErrorCheck(err, http.StatusNotFound))
+}
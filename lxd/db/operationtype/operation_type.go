@@ -76,6 +76,11 @@ const (
 	RenewServerCertificate
 	RemoveExpiredTokens
 	ClusterHeal
+	ImagesStreamsUpdate
+	ClusterRebalance
+	Replicate
+	AuditLogExpire
+	BulkInstances
 )
 
 // Description return a human-readable description of the operation type.
@@ -199,11 +204,33 @@ func (t Type) Description() string {
 		return "Remove expired tokens"
 	case ClusterHeal:
 		return "Healing cluster"
+	case ImagesStreamsUpdate:
+		return "Updating simplestreams image index"
+	case ClusterRebalance:
+		return "Rebalancing cluster instances"
+	case Replicate:
+		return "Replicating instances and storage volumes"
+	case AuditLogExpire:
+		return "Expiring audit log entries"
+	case BulkInstances:
+		return "Applying bulk action to instances"
 	default:
 		return "Executing operation"
 	}
 }
 
+// Persistent returns whether operations of this type are recorded in the database so that they can be recovered
+// if interrupted by a restart of LXD. This is the case of long-running operations like copies, migrations, backups
+// and image downloads.
+func (t Type) Persistent() bool {
+	switch t {
+	case BackupCreate, BackupRestore, InstanceCreate, InstanceMigrate, InstanceLiveMigrate, InstanceRebuild, SnapshotTransfer, ImageDownload, ImageRefresh, VolumeCopy, VolumeCreate, VolumeMigrate, VolumeMove, CustomVolumeBackupCreate, CustomVolumeBackupRestore:
+		return true
+	}
+
+	return false
+}
+
 // Permission returns the entity.Type and auth.Entitlement required to cancel the operation.
 func (t Type) Permission() (entity.Type, auth.Entitlement) {
 	switch t {
@@ -0,0 +1,84 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/cluster"
+)
+
+// ProjectUsage is the resource usage of a project.
+type ProjectUsage struct {
+	CPUSeconds           float64
+	MemoryGiBHours       float64
+	DiskGiBDays          float64
+	NetworkBytesReceived int64
+	NetworkBytesSent     int64
+}
+
+// AddProjectUsage adds the given usage to the usage history of a project for the day of the given date.
+func (c *ClusterTx) AddProjectUsage(ctx context.Context, projectName string, date time.Time, usage ProjectUsage) error {
+	projectID, err := cluster.GetProjectID(ctx, c.tx, projectName)
+	if err != nil {
+		return err
+	}
+
+	stmt := `
+INSERT INTO projects_usage (project_id, date, cpu_seconds, memory_gib_hours, disk_gib_days, network_bytes_received, network_bytes_sent)
+  VALUES (?, ?, ?, ?, ?, ?, ?)
+  ON CONFLICT (project_id, date) DO UPDATE SET
+    cpu_seconds = cpu_seconds + excluded.cpu_seconds,
+    memory_gib_hours = memory_gib_hours + excluded.memory_gib_hours,
+    disk_gib_days = disk_gib_days + excluded.disk_gib_days,
+    network_bytes_received = network_bytes_received + excluded.network_bytes_received,
+    network_bytes_sent = network_bytes_sent + excluded.network_bytes_sent
+`
+	_, err = c.tx.ExecContext(ctx, stmt, projectID, ProjectUsageDay(date), usage.CPUSeconds, usage.MemoryGiBHours, usage.DiskGiBDays, usage.NetworkBytesReceived, usage.NetworkBytesSent)
+	if err != nil {
+		return fmt.Errorf("Failed recording usage of project %q: %w", projectName, err)
+	}
+
+	return nil
+}
+
+// GetProjectUsage returns the total usage of a project between the days of the given dates (inclusive).
+func (c *ClusterTx) GetProjectUsage(ctx context.Context, projectName string, from time.Time, to time.Time) (*ProjectUsage, error) {
+	projectID, err := cluster.GetProjectID(ctx, c.tx, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := ProjectUsage{}
+
+	stmt := `
+SELECT IFNULL(SUM(cpu_seconds), 0), IFNULL(SUM(memory_gib_hours), 0), IFNULL(SUM(disk_gib_days), 0),
+       IFNULL(SUM(network_bytes_received), 0), IFNULL(SUM(network_bytes_sent), 0)
+  FROM projects_usage
+ WHERE project_id = ? AND date >= ? AND date <= ?
+`
+	err = c.tx.QueryRowContext(ctx, stmt, projectID, ProjectUsageDay(from), ProjectUsageDay(to)).Scan(&usage.CPUSeconds, &usage.MemoryGiBHours, &usage.DiskGiBDays, &usage.NetworkBytesReceived, &usage.NetworkBytesSent)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading usage of project %q: %w", projectName, err)
+	}
+
+	return &usage, nil
+}
+
+// DeleteProjectUsageBefore deletes the usage history of all projects before the day of the given date.
+func (c *ClusterTx) DeleteProjectUsageBefore(ctx context.Context, date time.Time) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM projects_usage WHERE date < ?", ProjectUsageDay(date))
+	if err != nil {
+		return fmt.Errorf("Failed deleting project usage history: %w", err)
+	}
+
+	return nil
+}
+
+// ProjectUsageDay returns the start of the day (UTC) of the given date, which the usage history is recorded by.
+func ProjectUsageDay(date time.Time) time.Time {
+	year, month, day := date.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
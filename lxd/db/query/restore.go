@@ -0,0 +1,91 @@
+package query
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Restore replaces all the tables, views and triggers of the database with the ones found in the given SQL text
+// dump, as produced by Dump.
+func Restore(ctx context.Context, tx *sql.Tx, dump string) error {
+	header := "PRAGMA foreign_keys=OFF;\nBEGIN TRANSACTION;\n"
+	footer := "COMMIT;\n"
+
+	if !strings.HasPrefix(dump, header) || !strings.HasSuffix(dump, footer) {
+		return fmt.Errorf("Invalid database dump")
+	}
+
+	// Foreign keys can't be disabled within a transaction, so only check them once everything is restored.
+	_, err := tx.ExecContext(ctx, "PRAGMA defer_foreign_keys=ON")
+	if err != nil {
+		return fmt.Errorf("Failed to defer foreign keys: %w", err)
+	}
+
+	for _, kind := range []string{"trigger", "view"} {
+		names, err := SelectStrings(ctx, tx, "SELECT name FROM sqlite_master WHERE type = ? AND name NOT LIKE 'sqlite_%'", kind)
+		if err != nil {
+			return fmt.Errorf("Failed to get %s names: %w", kind, err)
+		}
+
+		for _, name := range names {
+			_, err = tx.ExecContext(ctx, fmt.Sprintf("DROP %s %q", strings.ToUpper(kind), name))
+			if err != nil {
+				return fmt.Errorf("Failed to drop %s %q: %w", kind, name, err)
+			}
+		}
+	}
+
+	tables, err := SelectStrings(ctx, tx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return fmt.Errorf("Failed to get table names: %w", err)
+	}
+
+	// Tables referenced by others are dropped last, as dropping a table referenced by an existing one fails.
+	parents := make(map[string][]string, len(tables))
+	for _, table := range tables {
+		parents[table], err = SelectStrings(ctx, tx, `SELECT DISTINCT "table" FROM pragma_foreign_key_list(?)`, table)
+		if err != nil {
+			return fmt.Errorf("Failed to get foreign keys of table %q: %w", table, err)
+		}
+	}
+
+	for len(tables) > 0 {
+		next := 0
+		for i, table := range tables {
+			referenced := false
+			for _, other := range tables {
+				if other == table {
+					continue
+				}
+
+				for _, parent := range parents[other] {
+					if parent == table {
+						referenced = true
+						break
+					}
+				}
+			}
+
+			if !referenced {
+				next = i
+				break
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE %q", tables[next]))
+		if err != nil {
+			return fmt.Errorf("Failed to drop table %q: %w", tables[next], err)
+		}
+
+		tables = append(tables[:next], tables[next+1:]...)
+	}
+
+	_, err = tx.ExecContext(ctx, strings.TrimSuffix(strings.TrimPrefix(dump, header), footer))
+	if err != nil {
+		return fmt.Errorf("Failed to restore database dump: %w", err)
+	}
+
+	return nil
+}
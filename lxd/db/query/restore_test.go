@@ -0,0 +1,31 @@
+package query_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/db/query"
+)
+
+func TestRestore(t *testing.T) {
+	dump, err := query.Dump(context.Background(), newTxForDump(t, "global"), false)
+	require.NoError(t, err)
+
+	// Restoring replaces the existing tables and data.
+	tx := newTxForDump(t, "local")
+	err = query.Restore(context.Background(), tx, dump)
+	require.NoError(t, err)
+
+	restored, err := query.Dump(context.Background(), tx, false)
+	require.NoError(t, err)
+	assert.Equal(t, dump, restored)
+}
+
+func TestRestoreInvalidDump(t *testing.T) {
+	tx := newTxForDump(t, "local")
+	err := query.Restore(context.Background(), tx, "DROP TABLE config;")
+	assert.EqualError(t, err, "Invalid database dump")
+}
@@ -5,6 +5,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
 	"github.com/canonical/lxd/lxd/db/cluster"
@@ -96,3 +97,28 @@ func (c *ClusterTx) GetInstanceSnapshotID(ctx context.Context, project, instance
 	id, err := cluster.GetInstanceSnapshotID(ctx, c.tx, project, instance, name)
 	return int(id), err
 }
+
+// GetProjectSnapshotsCount returns the number of instance and custom volume snapshots in the given project.
+func (c *ClusterTx) GetProjectSnapshotsCount(ctx context.Context, projectName string) (int64, error) {
+	q := `
+SELECT
+  (SELECT COUNT(*)
+     FROM instances_snapshots
+     JOIN instances ON instances.id = instances_snapshots.instance_id
+     JOIN projects ON projects.id = instances.project_id
+    WHERE projects.name = ?)
+  +
+  (SELECT COUNT(*)
+     FROM storage_volumes_snapshots
+     JOIN storage_volumes ON storage_volumes.id = storage_volumes_snapshots.storage_volume_id
+     JOIN projects ON projects.id = storage_volumes.project_id
+    WHERE projects.name = ? AND storage_volumes.type = ?)
+`
+	var count int64
+	err := c.tx.QueryRowContext(ctx, q, projectName, projectName, cluster.StoragePoolVolumeTypeCustom).Scan(&count)
+	if err != nil {
+		return -1, fmt.Errorf("Failed counting snapshots of project %q: %w", projectName, err)
+	}
+
+	return count, nil
+}
@@ -58,6 +58,8 @@ const (
 	StoragePoolUnvailable
 	// UnableToUpdateClusterCertificate represents the unable to update cluster certificate warning.
 	UnableToUpdateClusterCertificate
+	// WebhookDeliveryFailed represents the failure to deliver an event to a webhook.
+	WebhookDeliveryFailed
 )
 
 // TypeNames associates a warning code to its name.
@@ -88,6 +90,7 @@ var TypeNames = map[Type]string{
 	InstanceTypeNotOperational:             "Instance type not operational",
 	StoragePoolUnvailable:                  "Storage pool unavailable",
 	UnableToUpdateClusterCertificate:       "Unable to update cluster certificate",
+	WebhookDeliveryFailed:                  "Failed to deliver event to webhook",
 }
 
 // Severity returns the severity of the warning type.
@@ -145,6 +148,8 @@ func (t Type) Severity() Severity {
 		return SeverityHigh
 	case UnableToUpdateClusterCertificate:
 		return SeverityLow
+	case WebhookDeliveryFailed:
+		return SeverityModerate
 	}
 
 	return SeverityLow
@@ -0,0 +1,170 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetWebhookNames returns the names of the existing webhooks.
+func (c *ClusterTx) GetWebhookNames(ctx context.Context) ([]string, error) {
+	return query.SelectStrings(ctx, c.tx, "SELECT name FROM webhooks ORDER BY name")
+}
+
+// GetWebhooks returns all the webhooks.
+func (c *ClusterTx) GetWebhooks(ctx context.Context) ([]api.Webhook, error) {
+	webhooks := []api.Webhook{}
+	ids := []int64{}
+
+	err := query.Scan(ctx, c.tx, "SELECT id, name, description FROM webhooks ORDER BY name", func(scan func(dest ...any) error) error {
+		var id int64
+		webhook := api.Webhook{}
+
+		err := scan(&id, &webhook.Name, &webhook.Description)
+		if err != nil {
+			return err
+		}
+
+		ids = append(ids, id)
+		webhooks = append(webhooks, webhook)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range webhooks {
+		err = webhookConfig(ctx, c, ids[i], &webhooks[i])
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading config of webhook %q: %w", webhooks[i].Name, err)
+		}
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhook returns the webhook with the given name.
+func (c *ClusterTx) GetWebhook(ctx context.Context, name string) (int64, *api.Webhook, error) {
+	var id = int64(-1)
+
+	webhook := api.Webhook{
+		Name: name,
+	}
+
+	err := c.tx.QueryRowContext(ctx, "SELECT id, description FROM webhooks WHERE name=? LIMIT 1", name).Scan(&id, &webhook.Description)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return -1, nil, api.StatusErrorf(http.StatusNotFound, "Webhook not found")
+		}
+
+		return -1, nil, err
+	}
+
+	err = webhookConfig(ctx, c, id, &webhook)
+	if err != nil {
+		return -1, nil, fmt.Errorf("Failed loading config: %w", err)
+	}
+
+	return id, &webhook, nil
+}
+
+// webhookConfig populates the config map of the webhook with the given ID.
+func webhookConfig(ctx context.Context, tx *ClusterTx, id int64, webhook *api.Webhook) error {
+	q := `
+		SELECT key, value
+		FROM webhooks_config
+		WHERE webhook_id=?
+	`
+
+	webhook.Config = make(map[string]string)
+	return query.Scan(ctx, tx.Tx(), q, func(scan func(dest ...any) error) error {
+		var key, value string
+
+		err := scan(&key, &value)
+		if err != nil {
+			return err
+		}
+
+		_, found := webhook.Config[key]
+		if found {
+			return fmt.Errorf("Duplicate config row found for key %q for webhook ID %d", key, id)
+		}
+
+		webhook.Config[key] = value
+
+		return nil
+	}, id)
+}
+
+// CreateWebhook creates a new webhook.
+func (c *ClusterTx) CreateWebhook(ctx context.Context, info api.WebhooksPost) (int64, error) {
+	result, err := c.tx.ExecContext(ctx, "INSERT INTO webhooks (name, description) VALUES (?, ?)", info.Name, info.Description)
+	if err != nil {
+		return -1, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, err
+	}
+
+	err = webhookConfigAdd(ctx, c.tx, id, info.Config)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// webhookConfigAdd inserts webhook config keys.
+func webhookConfigAdd(ctx context.Context, tx *sql.Tx, id int64, config map[string]string) error {
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO webhooks_config (webhook_id, key, value) VALUES(?, ?, ?)")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.ExecContext(ctx, id, k, v)
+		if err != nil {
+			return fmt.Errorf("Failed inserting config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateWebhook updates the webhook with the given ID.
+func (c *ClusterTx) UpdateWebhook(ctx context.Context, id int64, info api.WebhookPut) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE webhooks SET description=? WHERE id=?", info.Description, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.tx.ExecContext(ctx, "DELETE FROM webhooks_config WHERE webhook_id=?", id)
+	if err != nil {
+		return err
+	}
+
+	return webhookConfigAdd(ctx, c.tx, id, info.Config)
+}
+
+// DeleteWebhook deletes the webhook with the given ID.
+func (c *ClusterTx) DeleteWebhook(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM webhooks WHERE id=?", id)
+
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/canonical/lxd/lxd/auth"
@@ -100,6 +101,23 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 		return api.StatusErrorf(http.StatusForbidden, "Forbidden")
 	}
 
+	// Check the events to replay are still available before upgrading the connection so that the client can
+	// tell it needs to resynchronise.
+	var since uint64
+	sinceStr := request.QueryParam(r, "since")
+	if sinceStr != "" {
+		var err error
+		since, err = strconv.ParseUint(sinceStr, 10, 64)
+		if err != nil || since == 0 {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid event ID %q", sinceStr)
+		}
+
+		err = s.Events.CheckReplay(since)
+		if err != nil {
+			return err
+		}
+	}
+
 	l := logger.AddContext(logger.Ctx{"remote": r.RemoteAddr})
 
 	var excludeLocations []string
@@ -156,7 +174,7 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 	defer func() { _ = conn.Close() }() // Ensure listener below ends when this function ends.
 
 	listenerConnection := events.NewWebsocketListenerConnection(conn)
-	listener, err := s.Events.AddListener(projectName, allProjects, projectPermissionFunc, listenerConnection, types, excludeSources, recvFunc, excludeLocations)
+	listener, err := s.Events.AddListener(projectName, allProjects, projectPermissionFunc, listenerConnection, types, excludeSources, recvFunc, excludeLocations, since)
 	if err != nil {
 		l.Warn("Failed to add event listener", logger.Ctx{"err": err})
 		return nil
@@ -191,13 +209,22 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 //	    name: all-projects
 //	    description: Retrieve instances from all projects
 //	    type: boolean
+//	  - in: query
+//	    name: since
+//	    description: Replay the events that followed the event with this ID before sending new events
+//	    type: integer
+//	    example: 1718000000000042
 //	responses:
 //	  "200":
 //	    description: Websocket message (JSON)
 //	    schema:
 //	      $ref: "#/definitions/Event"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
 //	  "403":
 //	    $ref: "#/responses/Forbidden"
+//	  "410":
+//	    description: The events to replay are no longer available
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
 func eventsGet(d *Daemon, r *http.Request) response.Response {
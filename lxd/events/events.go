@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
@@ -28,6 +29,15 @@ const EventSourcePull = 1
 // EventSourcePush indicates the event was received from an event listener client connected to us.
 const EventSourcePush = 2
 
+// historySize is the number of events kept by the server so they can be replayed to listeners resuming a stream.
+const historySize = 1024
+
+// historyEntry is an event kept by the server for replay along with where it was received from.
+type historyEntry struct {
+	event  api.Event
+	source EventSource
+}
+
 // InjectFunc is used to inject an event received by a listener into the local events dispatcher.
 type InjectFunc func(event api.Event, eventSource EventSource)
 
@@ -41,10 +51,23 @@ type Server struct {
 	listeners map[string]*Listener
 	notify    NotifyFunc
 	location  string
+
+	// lastID is the sequence ID of the last event dispatched.
+	lastID uint64
+
+	// history is a ring buffer of the last dispatched events (other than logging events) and historyNext is the
+	// index the next event is stored at. evictedID is the sequence ID of the last event dropped from it.
+	history     []historyEntry
+	historyNext int
+	evictedID   uint64
 }
 
 // NewServer returns a new event server.
 func NewServer(debug bool, verbose bool, notify NotifyFunc) *Server {
+	// Start the sequence IDs from the current time so that they keep increasing across restarts, allowing
+	// listeners to detect that the events they missed are no longer available.
+	firstID := uint64(time.Now().UnixMicro())
+
 	server := &Server{
 		serverCommon: serverCommon{
 			debug:   debug,
@@ -52,6 +75,9 @@ func NewServer(debug bool, verbose bool, notify NotifyFunc) *Server {
 		},
 		listeners: map[string]*Listener{},
 		notify:    notify,
+		lastID:    firstID,
+		evictedID: firstID,
+		history:   make([]historyEntry, 0, historySize),
 	}
 
 	return server
@@ -66,8 +92,31 @@ func (s *Server) SetLocalLocation(location string) {
 	s.location = location
 }
 
+// CheckReplay returns an error if the events dispatched after the event with the given sequence ID cannot be
+// replayed, either because some of them were dropped from the history or because the ID is unknown.
+func (s *Server) CheckReplay(since uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.checkReplay(since)
+}
+
+func (s *Server) checkReplay(since uint64) error {
+	if since > s.lastID {
+		return api.StatusErrorf(http.StatusGone, "Unknown event ID %d", since)
+	}
+
+	if since < s.evictedID {
+		return api.StatusErrorf(http.StatusGone, "Events following event ID %d are no longer available", since)
+	}
+
+	return nil
+}
+
 // AddListener creates and returns a new event listener.
-func (s *Server) AddListener(projectName string, allProjects bool, projectPermissionFunc auth.PermissionChecker, connection EventListenerConnection, messageTypes []string, excludeSources []EventSource, recvFunc EventHandler, excludeLocations []string) (*Listener, error) {
+// If since is non-zero, the events dispatched after the event with that sequence ID are replayed to the listener
+// before any new event.
+func (s *Server) AddListener(projectName string, allProjects bool, projectPermissionFunc auth.PermissionChecker, connection EventListenerConnection, messageTypes []string, excludeSources []EventSource, recvFunc EventHandler, excludeLocations []string, since uint64) (*Listener, error) {
 	if allProjects && projectName != "" {
 		return nil, fmt.Errorf("Cannot specify project name when listening for events on all projects")
 	}
@@ -92,6 +141,7 @@ func (s *Server) AddListener(projectName string, allProjects bool, projectPermis
 		projectPermissionFunc: projectPermissionFunc,
 		excludeSources:        excludeSources,
 		excludeLocations:      excludeLocations,
+		ready:                 make(chan struct{}),
 	}
 
 	s.lock.Lock()
@@ -101,10 +151,39 @@ func (s *Server) AddListener(projectName string, allProjects bool, projectPermis
 		return nil, fmt.Errorf("A listener with ID %q already exists", listener.id)
 	}
 
+	// Collect the events to replay while holding the lock so that none are missed or duplicated.
+	var replay []api.Event
+	if since > 0 {
+		err := s.checkReplay(since)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range s.history {
+			entry := s.history[(s.historyNext+i)%len(s.history)]
+			if entry.event.ID > since && listener.wants(entry.event, entry.source) {
+				replay = append(replay, entry.event)
+			}
+		}
+	}
+
 	s.listeners[listener.id] = listener
 
 	go listener.start()
 
+	// New events are only sent to the listener once the replayed events have been sent.
+	go func() {
+		defer close(listener.ready)
+
+		for _, event := range replay {
+			err := listener.WriteJSON(event)
+			if err != nil {
+				listener.Close()
+				return
+			}
+		}
+	}()
+
 	return listener, nil
 }
 
@@ -157,16 +236,6 @@ func (s *Server) Inject(event api.Event, eventSource EventSource) {
 }
 
 func (s *Server) broadcast(event api.Event, eventSource EventSource) error {
-	sourceInSlice := func(source EventSource, sources []EventSource) bool {
-		for _, i := range sources {
-			if source == i {
-				return true
-			}
-		}
-
-		return false
-	}
-
 	s.lock.Lock()
 
 	// Set the Location for local events to the local serverName if not already populated (do it here rather
@@ -175,6 +244,23 @@ func (s *Server) broadcast(event api.Event, eventSource EventSource) error {
 		event.Location = s.location
 	}
 
+	// Assign the next sequence ID and keep the event for replay. Logging events are not kept as they would
+	// quickly push all other events out of the history.
+	s.lastID++
+	event.ID = s.lastID
+
+	if event.Type != api.EventTypeLogging {
+		entry := historyEntry{event: event, source: eventSource}
+		if len(s.history) < historySize {
+			s.history = append(s.history, entry)
+		} else {
+			s.evictedID = s.history[s.historyNext].event.ID
+			s.history[s.historyNext] = entry
+		}
+
+		s.historyNext = (s.historyNext + 1) % historySize
+	}
+
 	// If a notifcation hook is present, then call it for locally produced events.
 	// This can be used to send local events to another target (such as an event-hub member).
 	if s.notify != nil && eventSource == EventSourceLocal {
@@ -183,26 +269,7 @@ func (s *Server) broadcast(event api.Event, eventSource EventSource) error {
 
 	listeners := s.listeners
 	for _, listener := range listeners {
-		// If the event is project specific, check if the listener is requesting events from that project.
-		if event.Project != "" && !listener.allProjects && event.Project != listener.projectName {
-			continue
-		}
-
-		// If the event is project specific, ensure we have permission to view it.
-		if event.Project != "" && !listener.projectPermissionFunc(entity.ProjectURL(event.Project)) {
-			continue
-		}
-
-		if sourceInSlice(eventSource, listener.excludeSources) {
-			continue
-		}
-
-		if !shared.ValueInSlice(event.Type, listener.messageTypes) {
-			continue
-		}
-
-		// If the event doesn't come from this member and has been excluded by listener, don't deliver it.
-		if eventSource != EventSourceLocal && shared.ValueInSlice(event.Location, listener.excludeLocations) {
+		if !listener.wants(event, eventSource) {
 			continue
 		}
 
@@ -212,6 +279,9 @@ func (s *Server) broadcast(event api.Event, eventSource EventSource) error {
 				return
 			}
 
+			// Wait for any replayed events to be sent first.
+			<-listener.ready
+
 			// Make sure we're not done already
 			if listener.IsClosed() {
 				// Remove the listener from the list
@@ -247,4 +317,35 @@ type Listener struct {
 	projectPermissionFunc auth.PermissionChecker
 	excludeSources        []EventSource
 	excludeLocations      []string
+
+	// ready is closed once the replayed events have been sent to the listener.
+	ready chan struct{}
+}
+
+// wants returns whether the event should be delivered to the listener.
+func (l *Listener) wants(event api.Event, eventSource EventSource) bool {
+	// If the event is project specific, check if the listener is requesting events from that project.
+	if event.Project != "" && !l.allProjects && event.Project != l.projectName {
+		return false
+	}
+
+	// If the event is project specific, ensure we have permission to view it.
+	if event.Project != "" && !l.projectPermissionFunc(entity.ProjectURL(event.Project)) {
+		return false
+	}
+
+	if shared.ValueInSlice(eventSource, l.excludeSources) {
+		return false
+	}
+
+	if !shared.ValueInSlice(event.Type, l.messageTypes) {
+		return false
+	}
+
+	// If the event doesn't come from this member and has been excluded by listener, don't deliver it.
+	if eventSource != EventSourceLocal && shared.ValueInSlice(event.Location, l.excludeLocations) {
+		return false
+	}
+
+	return true
 }
@@ -0,0 +1,112 @@
+package events
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// testConnection is an EventListenerConnection recording the events written to it.
+type testConnection struct {
+	events chan api.Event
+}
+
+func (c *testConnection) Reader(ctx context.Context, recvFunc EventHandler) {
+	<-ctx.Done()
+}
+
+func (c *testConnection) WriteJSON(event any) error {
+	c.events <- event.(api.Event)
+	return nil
+}
+
+func (c *testConnection) Close() error {
+	return nil
+}
+
+func (c *testConnection) LocalAddr() net.Addr {
+	return nil
+}
+
+func (c *testConnection) RemoteAddr() net.Addr {
+	return nil
+}
+
+func (c *testConnection) next(t *testing.T) api.Event {
+	select {
+	case event := <-c.events:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for event")
+		return api.Event{}
+	}
+}
+
+func TestServerReplay(t *testing.T) {
+	s := NewServer(false, false, nil)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.Send("default", api.EventTypeLifecycle, api.EventLifecycle{Action: "instance-started"}))
+	}
+
+	// Record the events as they are dispatched.
+	first := &testConnection{events: make(chan api.Event, 10)}
+	_, err := s.AddListener("", true, nil, first, []string{api.EventTypeLifecycle}, nil, nil, nil, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send("default", api.EventTypeLifecycle, api.EventLifecycle{Action: "instance-stopped"}))
+	require.NoError(t, s.Send("default", api.EventTypeLogging, api.EventLogging{Message: "not replayed"}))
+	require.NoError(t, s.Send("default", api.EventTypeLifecycle, api.EventLifecycle{Action: "instance-deleted"}))
+
+	// Events are delivered concurrently so may be received out of order.
+	stopped := first.next(t)
+	deleted := first.next(t)
+	if stopped.ID > deleted.ID {
+		stopped, deleted = deleted, stopped
+	}
+
+	assert.Equal(t, stopped.ID+2, deleted.ID)
+
+	// A listener resuming from the stopped event only gets the events that followed it.
+	second := &testConnection{events: make(chan api.Event, 10)}
+	_, err = s.AddListener("", true, nil, second, []string{api.EventTypeLifecycle}, nil, nil, nil, stopped.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, deleted.ID, second.next(t).ID)
+	assert.Empty(t, second.events)
+
+	// Unknown IDs can't be replayed.
+	err = s.CheckReplay(deleted.ID + 10)
+	assert.True(t, api.StatusErrorCheck(err, http.StatusGone))
+}
+
+func TestServerReplayEvicted(t *testing.T) {
+	s := NewServer(false, false, nil)
+
+	conn := &testConnection{events: make(chan api.Event, historySize+10)}
+	_, err := s.AddListener("", true, nil, conn, []string{api.EventTypeLifecycle}, nil, nil, nil, 0)
+	require.NoError(t, err)
+
+	for i := 0; i < historySize+1; i++ {
+		require.NoError(t, s.Send("default", api.EventTypeLifecycle, api.EventLifecycle{Action: "instance-updated"}))
+	}
+
+	var firstID uint64
+	for i := 0; i < historySize+1; i++ {
+		event := conn.next(t)
+		if firstID == 0 || event.ID < firstID {
+			firstID = event.ID
+		}
+	}
+
+	// The first event was dropped from the history so resuming from before it is not possible anymore.
+	assert.True(t, api.StatusErrorCheck(s.CheckReplay(firstID-1), http.StatusGone))
+	assert.NoError(t, s.CheckReplay(firstID))
+}
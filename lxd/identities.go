@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -43,6 +44,10 @@ var identitiesByAuthenticationMethodCmd = APIEndpoint{
 		Handler:       getIdentities,
 		AccessHandler: allowAuthenticated,
 	},
+	Post: APIEndpointAction{
+		Handler:       createIdentity,
+		AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanCreateIdentities),
+	},
 }
 
 var identityCmd = APIEndpoint{
@@ -60,6 +65,19 @@ var identityCmd = APIEndpoint{
 		Handler:       patchIdentity,
 		AccessHandler: identityAccessHandler(auth.EntitlementCanEdit),
 	},
+	Delete: APIEndpointAction{
+		Handler:       deleteIdentity,
+		AccessHandler: identityAccessHandler(auth.EntitlementCanDelete),
+	},
+}
+
+var identityEffectivePermissionsCmd = APIEndpoint{
+	Name: "identity",
+	Path: "auth/identities/{authenticationMethod}/{nameOrIdentifier}/effective-permissions",
+	Get: APIEndpointAction{
+		Handler:       getIdentityEffectivePermissions,
+		AccessHandler: identityAccessHandler(auth.EntitlementCanView),
+	},
 }
 
 const (
@@ -118,6 +136,22 @@ func identityAccessHandler(entitlement auth.Entitlement) func(d *Daemon, r *http
 //	---
 //	produces:
 //	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -161,6 +195,22 @@ func identityAccessHandler(entitlement auth.Entitlement) func(d *Daemon, r *http
 //	---
 //	produces:
 //	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -286,6 +336,14 @@ func getIdentities(d *Daemon, r *http.Request) response.Response {
 	}
 
 	recursion := r.URL.Query().Get("recursion")
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// The groups of the identities are needed to return or filter them.
+	withGroups := recursion == "1" || opts.filtered()
+
 	s := d.State()
 	canViewIdentity, err := s.Authorizer.GetPermissionChecker(r.Context(), auth.EntitlementCanView, entity.TypeIdentity)
 	if err != nil {
@@ -324,14 +382,14 @@ func getIdentities(d *Daemon, r *http.Request) response.Response {
 			return nil
 		}
 
-		if recursion == "1" && len(identities) == 1 {
+		if withGroups && len(identities) == 1 {
 			// It's likely that the user can only view themselves. If so we can optimise here by only getting the
 			// groups for that user.
 			apiIdentity, err = identities[0].ToAPI(ctx, tx.Tx(), canViewGroup)
 			if err != nil {
 				return err
 			}
-		} else if recursion == "1" {
+		} else if withGroups {
 			// Otherwise, get all groups and populate the identities outside of the transaction.
 			groupsByIdentityID, err = dbCluster.GetAllAuthGroupsByIdentityIDs(ctx, tx.Tx())
 			if err != nil {
@@ -345,12 +403,11 @@ func getIdentities(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	// Optimisation for user that can only view themselves.
+	var apiIdentities []api.Identity
 	if apiIdentity != nil {
-		return response.SyncResponse(true, []api.Identity{*apiIdentity})
-	}
-
-	if recursion == "1" {
+		// Optimisation for user that can only view themselves.
+		apiIdentities = []api.Identity{*apiIdentity}
+	} else {
 		// Convert the []cluster.Group in the groupsByIdentityID map to string slices of the group names.
 		groupNamesByIdentityID := make(map[int][]string, len(groupsByIdentityID))
 		for identityID, groups := range groupsByIdentityID {
@@ -361,7 +418,7 @@ func getIdentities(d *Daemon, r *http.Request) response.Response {
 			}
 		}
 
-		apiIdentities := make([]api.Identity, 0, len(identities))
+		apiIdentities = make([]api.Identity, 0, len(identities))
 		for _, id := range identities {
 			apiIdentities = append(apiIdentities, api.Identity{
 				AuthenticationMethod: string(id.AuthMethod),
@@ -371,18 +428,173 @@ func getIdentities(d *Daemon, r *http.Request) response.Response {
 				Groups:               groupNamesByIdentityID[id.ID],
 			})
 		}
+	}
+
+	apiIdentities, err = listPage(opts, apiIdentities, func(id api.Identity) string { return id.Identifier })
+	if err != nil {
+		return response.SmartError(err)
+	}
 
+	if recursion == "1" {
 		return response.SyncResponse(true, apiIdentities)
 	}
 
-	urls := make([]string, 0, len(identities))
-	for _, id := range identities {
-		urls = append(urls, entity.IdentityURL(string(id.AuthMethod), id.Identifier).String())
+	urls := make([]string, 0, len(apiIdentities))
+	for _, id := range apiIdentities {
+		urls = append(urls, entity.IdentityURL(id.AuthenticationMethod, id.Identifier).String())
 	}
 
 	return response.SyncResponse(true, urls)
 }
 
+// swagger:operation POST /1.0/auth/identities/{authenticationMethod} identities identities_post
+//
+//	Create a new identity
+//
+//	Issues a new bearer token identity. Only the `bearer` authentication method is supported.
+//	The token is only returned in this response and cannot be retrieved again.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: token
+//	    description: Bearer token request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/IdentityBearerTokenPost"
+//	responses:
+//	  "200":
+//	    description: Bearer token
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/IdentityBearerToken"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+//	  "501":
+//	    $ref: "#/responses/NotImplemented"
+func createIdentity(d *Daemon, r *http.Request) response.Response {
+	authenticationMethod, err := url.PathUnescape(mux.Vars(r)["authenticationMethod"])
+	if err != nil {
+		return response.InternalError(fmt.Errorf("Failed to unescape path argument: %w", err))
+	}
+
+	err = identity.ValidateAuthenticationMethod(authenticationMethod)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if authenticationMethod != api.AuthenticationMethodBearer {
+		return response.NotImplemented(fmt.Errorf("Creating identities with authentication method %q is not supported", authenticationMethod))
+	}
+
+	var tokenPost api.IdentityBearerTokenPost
+	err = json.NewDecoder(r.Body).Decode(&tokenPost)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Failed to unmarshal request body: %w", err))
+	}
+
+	if tokenPost.Name == "" {
+		return response.BadRequest(fmt.Errorf("Bearer token name is required"))
+	}
+
+	if tokenPost.ExpiresAt.IsZero() {
+		return response.BadRequest(fmt.Errorf("Bearer token expiry is required"))
+	}
+
+	if !tokenPost.ExpiresAt.After(time.Now()) {
+		return response.BadRequest(fmt.Errorf("Bearer token expiry must be in the future"))
+	}
+
+	_, err = identity.ParseCIDRs(tokenPost.AllowedCIDRs)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	identifier, secret, token, err := identity.NewBearerToken()
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	metadata, err := json.Marshal(dbCluster.BearerTokenMetadata{
+		SecretHash:   identity.HashBearerTokenSecret(secret),
+		ExpiresAt:    tokenPost.ExpiresAt.UTC(),
+		AllowedCIDRs: tokenPost.AllowedCIDRs,
+	})
+	if err != nil {
+		return response.InternalError(fmt.Errorf("Failed to marshal bearer token metadata: %w", err))
+	}
+
+	s := d.State()
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, err := dbCluster.CreateIdentity(ctx, tx.Tx(), dbCluster.Identity{
+			AuthMethod: api.AuthenticationMethodBearer,
+			Type:       api.IdentityTypeBearerToken,
+			Identifier: identifier,
+			Name:       tokenPost.Name,
+			Metadata:   string(metadata),
+		})
+		if err != nil {
+			return err
+		}
+
+		return dbCluster.SetIdentityAuthGroups(ctx, tx.Tx(), int(id), tokenPost.Groups)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Notify other cluster members to update their identity cache.
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = notifier(func(client lxd.InstanceServer) error {
+		_, _, err := client.RawQuery(http.MethodPost, "/internal/identity-cache-refresh", nil, "")
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Send a lifecycle event for the identity creation.
+	lc := lifecycle.IdentityCreated.Event(api.AuthenticationMethodBearer, identifier, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	s.UpdateIdentityCache()
+
+	resp := api.IdentityBearerToken{
+		Identifier: identifier,
+		Token:      token,
+		ExpiresAt:  tokenPost.ExpiresAt.UTC(),
+	}
+
+	return response.SyncResponseLocation(true, resp, entity.IdentityURL(api.AuthenticationMethodBearer, identifier).String())
+}
+
 // swagger:operation GET /1.0/auth/identities/{authenticationMethod}/{nameOrIdentifier} identities identity_get
 //
 //	Get the identity
@@ -529,23 +741,9 @@ func getCurrentIdentityInfo(d *Daemon, r *http.Request) response.Response {
 			}
 		}
 
-		permissions, err := dbCluster.GetDistinctPermissionsByGroupNames(ctx, tx.Tx(), effectiveGroups)
+		effectivePermissions, err = getEffectivePermissions(ctx, tx, effectiveGroups)
 		if err != nil {
-			return fmt.Errorf("Failed to get effective permissions: %w", err)
-		}
-
-		permissions, entityURLs, err := dbCluster.GetPermissionEntityURLs(ctx, tx.Tx(), permissions)
-		if err != nil {
-			return fmt.Errorf("Failed to get entity URLs for effective permissions: %w", err)
-		}
-
-		effectivePermissions = make([]api.Permission, 0, len(permissions))
-		for _, permission := range permissions {
-			effectivePermissions = append(effectivePermissions, api.Permission{
-				EntityType:      string(permission.EntityType),
-				EntityReference: entityURLs[entity.Type(permission.EntityType)][permission.EntityID].String(),
-				Entitlement:     string(permission.Entitlement),
-			})
+			return err
 		}
 
 		return nil
@@ -561,6 +759,119 @@ func getCurrentIdentityInfo(d *Daemon, r *http.Request) response.Response {
 	})
 }
 
+// swagger:operation GET /1.0/auth/identities/{authenticationMethod}/{nameOrIdentifier}/effective-permissions identities identity_effective_permissions_get
+//
+//	Get the effective permissions of the identity
+//
+//	Returns the combined and deduplicated list of permissions granted to the identity by the groups it is a member of.
+//	Groups mapped from identity provider groups are only known for the current identity and are not included.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of permissions
+//	          items:
+//	            $ref: "#/definitions/Permission"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func getIdentityEffectivePermissions(d *Daemon, r *http.Request) response.Response {
+	id, err := request.GetCtxValue[*dbCluster.Identity](r.Context(), ctxClusterDBIdentity)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s := d.State()
+
+	// Other identities' permissions can only be viewed by callers that can view all permissions.
+	err = checkCallerCanViewPermissionsOf(r, s.Authorizer, d.identityCache, string(id.AuthMethod), id.Identifier)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var effectivePermissions []api.Permission
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		groups, err := dbCluster.GetAuthGroupsByIdentityID(ctx, tx.Tx(), id.ID)
+		if err != nil {
+			return err
+		}
+
+		groupNames := make([]string, 0, len(groups))
+		for _, group := range groups {
+			groupNames = append(groupNames, group.Name)
+		}
+
+		effectivePermissions, err = getEffectivePermissions(ctx, tx, groupNames)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, effectivePermissions)
+}
+
+// checkCallerCanViewPermissionsOf returns nil if the caller is the identity with the given authentication method and
+// identifier, or if the caller can view all permissions.
+func checkCallerCanViewPermissionsOf(r *http.Request, authorizer auth.Authorizer, identityCache *identity.Cache, authenticationMethod string, identifier string) error {
+	caller, err := auth.GetIdentityFromCtx(r.Context(), identityCache)
+	if err == nil && caller.AuthenticationMethod == authenticationMethod && caller.Identifier == identifier {
+		return nil
+	}
+
+	return authorizer.CheckPermission(r.Context(), entity.ServerURL(), auth.EntitlementCanViewPermissions)
+}
+
+// getEffectivePermissions returns the distinct permissions of the given groups.
+func getEffectivePermissions(ctx context.Context, tx *db.ClusterTx, groupNames []string) ([]api.Permission, error) {
+	permissions, err := dbCluster.GetDistinctPermissionsByGroupNames(ctx, tx.Tx(), groupNames)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get effective permissions: %w", err)
+	}
+
+	permissions, entityURLs, err := dbCluster.GetPermissionEntityURLs(ctx, tx.Tx(), permissions)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get entity URLs for effective permissions: %w", err)
+	}
+
+	effectivePermissions := make([]api.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		effectivePermissions = append(effectivePermissions, api.Permission{
+			EntityType:      string(permission.EntityType),
+			EntityReference: entityURLs[entity.Type(permission.EntityType)][permission.EntityID].String(),
+			Entitlement:     string(permission.Entitlement),
+		})
+	}
+
+	return effectivePermissions, nil
+}
+
 // swagger:operation PUT /1.0/auth/identities/{authenticationMethod}/{nameOrIdentifier} identities identity_put
 //
 //	Update the identity
@@ -632,7 +943,7 @@ func updateIdentity(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Notify other cluster members to update their identity cache.
-	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
 		return response.SmartError(err)
 	}
@@ -733,7 +1044,7 @@ func patchIdentity(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Notify other cluster members to update their identity cache.
-	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
 		return response.SmartError(err)
 	}
@@ -755,6 +1066,67 @@ func patchIdentity(d *Daemon, r *http.Request) response.Response {
 	return response.EmptySyncResponse
 }
 
+// swagger:operation DELETE /1.0/auth/identities/{authenticationMethod}/{nameOrIdentifier} identities identity_delete
+//
+//	Delete the identity
+//
+//	Removes the identity. Only identities with the `bearer` authentication method can be deleted, which revokes the token.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+//	  "501":
+//	    $ref: "#/responses/NotImplemented"
+func deleteIdentity(d *Daemon, r *http.Request) response.Response {
+	id, err := request.GetCtxValue[*dbCluster.Identity](r.Context(), ctxClusterDBIdentity)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if id.AuthMethod != api.AuthenticationMethodBearer {
+		return response.NotImplemented(fmt.Errorf("Deleting identities with authentication method %q is not supported", id.AuthMethod))
+	}
+
+	s := d.State()
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return dbCluster.DeleteIdentity(ctx, tx.Tx(), id.AuthMethod, id.Identifier)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Notify other cluster members to update their identity cache.
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = notifier(func(client lxd.InstanceServer) error {
+		_, _, err := client.RawQuery(http.MethodPost, "/internal/identity-cache-refresh", nil, "")
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Send a lifecycle event for the identity deletion.
+	lc := lifecycle.IdentityDeleted.Event(string(id.AuthMethod), id.Identifier, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	s.UpdateIdentityCache()
+
+	return response.EmptySyncResponse
+}
+
 // updateIdentityCache reads all identities from the database and sets them in the identity.Cache.
 // The certificates in the local database are replaced with identities in the cluster database that
 // are of type api.IdentityTypeCertificateServer. This ensures that this cluster member is able to
@@ -845,6 +1217,14 @@ func updateIdentityCache(d *Daemon) {
 			}
 
 			cacheEntry.Subject = subject
+		} else if cacheEntry.AuthenticationMethod == api.AuthenticationMethodBearer {
+			token, err := id.BearerToken()
+			if err != nil {
+				logger.Warn("Failed to extract bearer token from bearer identity metadata", logger.Ctx{"err": err})
+				continue
+			}
+
+			cacheEntry.BearerToken = token
 		}
 
 		identityCacheEntries = append(identityCacheEntries, cacheEntry)
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -35,6 +36,9 @@ type CacheEntry struct {
 
 	// Subject is optional. It is only set when AuthenticationMethod is api.AuthenticationMethodOIDC.
 	Subject string
+
+	// BearerToken is optional. It is only set when AuthenticationMethod is api.AuthenticationMethodBearer.
+	BearerToken *BearerToken
 }
 
 // Get returns a single CacheEntry by its authentication method and identifier.
@@ -129,6 +133,10 @@ func (c *Cache) ReplaceAll(entries []CacheEntry, idpGroups map[string][]string)
 			return fmt.Errorf("Identity cache entries of type %q must have a certificate", api.AuthenticationMethodTLS)
 		}
 
+		if entry.AuthenticationMethod == api.AuthenticationMethodBearer && entry.BearerToken == nil {
+			return fmt.Errorf("Identity cache entries of type %q must have a bearer token", api.AuthenticationMethodBearer)
+		}
+
 		_, ok := c.entries[entry.AuthenticationMethod]
 		if !ok {
 			c.entries[entry.AuthenticationMethod] = make(map[string]*CacheEntry)
@@ -197,6 +205,32 @@ func (c *Cache) GetByOIDCSubject(subject string) (*CacheEntry, error) {
 	return nil, api.StatusErrorf(http.StatusNotFound, "Identity with OIDC subject %q not found", subject)
 }
 
+// GetByBearerToken validates the given bearer token presented from the given remote address and returns the matching
+// CacheEntry. An api.StatusError with http.StatusForbidden is returned if the token is unknown, expired, or used from
+// an address outside of its allowed CIDRs.
+func (c *Cache) GetByBearerToken(token string, remoteAddr string) (*CacheEntry, error) {
+	identifier, secret, err := ParseBearerToken(token)
+	if err != nil {
+		return nil, api.StatusErrorf(http.StatusForbidden, "%w", err)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[api.AuthenticationMethodBearer][identifier]
+	if !ok || entry == nil || entry.BearerToken == nil {
+		return nil, api.StatusErrorf(http.StatusForbidden, "Invalid bearer token")
+	}
+
+	err = entry.BearerToken.Validate(secret, remoteAddr, time.Now())
+	if err != nil {
+		return nil, api.StatusErrorf(http.StatusForbidden, "%w", err)
+	}
+
+	entryCopy := *entry
+	return &entryCopy, nil
+}
+
 // GetIdentityProviderGroupMapping returns the auth groups that the given identity provider group maps to or an
 // api.StatusError with http.StatusNotFound.
 func (c *Cache) GetIdentityProviderGroupMapping(idpGroup string) ([]string, error) {
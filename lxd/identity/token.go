@@ -0,0 +1,127 @@
+package identity
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// BearerTokenPrefix is prepended to all LXD issued bearer tokens. It allows them to be distinguished from OIDC access
+// tokens in the Authorization header.
+const BearerTokenPrefix = "lxd_"
+
+// BearerToken contains the information required to validate a bearer token. The secret itself is never stored.
+type BearerToken struct {
+	// SecretHash is the hex encoded SHA-256 hash of the token secret.
+	SecretHash string
+
+	// ExpiresAt is the time after which the token is no longer valid.
+	ExpiresAt time.Time
+
+	// AllowedCIDRs restricts the source addresses the token may be used from. Any address is allowed when empty.
+	AllowedCIDRs []*net.IPNet
+}
+
+// NewBearerToken generates a new identifier and secret and returns them along with the token that should be presented
+// by the client.
+func NewBearerToken() (identifier string, secret string, token string, err error) {
+	secret, err = shared.RandomCryptoString()
+	if err != nil {
+		return "", "", "", fmt.Errorf("Failed generating bearer token secret: %w", err)
+	}
+
+	identifier = uuid.New().String()
+
+	return identifier, secret, BearerTokenPrefix + identifier + "." + secret, nil
+}
+
+// HashBearerTokenSecret returns the hex encoded SHA-256 hash of the given secret.
+func HashBearerTokenSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// ParseBearerToken splits a bearer token into its identifier and secret.
+func ParseBearerToken(token string) (identifier string, secret string, err error) {
+	idAndSecret, ok := strings.CutPrefix(token, BearerTokenPrefix)
+	if !ok {
+		return "", "", errors.New("Bearer token has an invalid prefix")
+	}
+
+	identifier, secret, ok = strings.Cut(idAndSecret, ".")
+	if !ok || identifier == "" || secret == "" {
+		return "", "", errors.New("Bearer token is malformed")
+	}
+
+	return identifier, secret, nil
+}
+
+// IsBearerTokenRequest returns the LXD issued bearer token contained in the Authorization header of the request, if
+// any. OIDC access tokens are not matched.
+func IsBearerTokenRequest(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || !strings.HasPrefix(token, BearerTokenPrefix) {
+		return "", false
+	}
+
+	return token, true
+}
+
+// ParseCIDRs parses the given list of CIDR subnets.
+func ParseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	subnets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, subnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CIDR %q: %w", cidr, err)
+		}
+
+		subnets = append(subnets, subnet)
+	}
+
+	return subnets, nil
+}
+
+// Validate checks that the given secret matches the token, that the token has not expired at the given time, and
+// that the remote address is within the allowed CIDRs.
+func (t BearerToken) Validate(secret string, remoteAddr string, now time.Time) error {
+	if subtle.ConstantTimeCompare([]byte(HashBearerTokenSecret(secret)), []byte(t.SecretHash)) != 1 {
+		return errors.New("Invalid bearer token")
+	}
+
+	if !t.ExpiresAt.IsZero() && now.After(t.ExpiresAt) {
+		return errors.New("Bearer token has expired")
+	}
+
+	if len(t.AllowedCIDRs) == 0 {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("Failed parsing remote address %q", remoteAddr)
+	}
+
+	for _, subnet := range t.AllowedCIDRs {
+		if subnet.Contains(ip) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Bearer token cannot be used from %q", ip.String())
+}
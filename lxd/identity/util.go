@@ -27,6 +27,8 @@ func AuthenticationMethodFromIdentityType(identityType string) (string, error) {
 		return api.AuthenticationMethodTLS, nil
 	case api.IdentityTypeOIDCClient:
 		return api.AuthenticationMethodOIDC, nil
+	case api.IdentityTypeBearerToken:
+		return api.AuthenticationMethodBearer, nil
 	}
 
 	return "", fmt.Errorf("Identity type %q not recognized", identityType)
@@ -35,7 +37,7 @@ func AuthenticationMethodFromIdentityType(identityType string) (string, error) {
 // ValidateAuthenticationMethod returns an api.StatusError with http.StatusBadRequest if the given authentication
 // method is not recognised.
 func ValidateAuthenticationMethod(authenticationMethod string) error {
-	if !shared.ValueInSlice(authenticationMethod, []string{api.AuthenticationMethodTLS, api.AuthenticationMethodOIDC}) {
+	if !shared.ValueInSlice(authenticationMethod, []string{api.AuthenticationMethodTLS, api.AuthenticationMethodOIDC, api.AuthenticationMethodBearer}) {
 		return api.StatusErrorf(http.StatusBadRequest, "Unrecognized authentication method %q", authenticationMethod)
 	}
 
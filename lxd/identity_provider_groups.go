@@ -70,6 +70,22 @@ var identityProviderGroupCmd = APIEndpoint{
 //	---
 //	produces:
 //	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -113,6 +129,22 @@ var identityProviderGroupCmd = APIEndpoint{
 //	---
 //	produces:
 //	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -142,7 +174,12 @@ var identityProviderGroupCmd = APIEndpoint{
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
 func getIdentityProviderGroups(d *Daemon, r *http.Request) response.Response {
-	recursion := r.URL.Query().Get("recursion")
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	opts.recursion = r.URL.Query().Get("recursion") == "1"
 	s := d.State()
 
 	canViewIDPGroup, err := s.Authorizer.GetPermissionChecker(r.Context(), auth.EntitlementCanView, entity.TypeIdentityProviderGroup)
@@ -155,46 +192,41 @@ func getIdentityProviderGroups(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	var apiIDPGroups []*api.IdentityProviderGroup
-	var idpGroups []dbCluster.IdentityProviderGroup
+	var apiIDPGroups []api.IdentityProviderGroup
+	var idpGroupNames []string
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		allIDPGroups, err := dbCluster.GetIdentityProviderGroups(ctx, tx.Tx())
 		if err != nil {
 			return err
 		}
 
-		idpGroups = make([]dbCluster.IdentityProviderGroup, 0, len(allIDPGroups))
+		names := make([]string, 0, len(allIDPGroups))
+		idpGroupsByName := make(map[string]dbCluster.IdentityProviderGroup, len(allIDPGroups))
 		for _, idpGroup := range allIDPGroups {
 			if canViewIDPGroup(entity.IdentityProviderGroupURL(idpGroup.Name)) {
-				idpGroups = append(idpGroups, idpGroup)
+				names = append(names, idpGroup.Name)
+				idpGroupsByName[idpGroup.Name] = idpGroup
 			}
 		}
 
-		if recursion == "1" {
-			apiIDPGroups = make([]*api.IdentityProviderGroup, 0, len(idpGroups))
-			for _, idpGroup := range idpGroups {
-				apiIDPGroup, err := idpGroup.ToAPI(ctx, tx.Tx(), canViewGroup)
-				if err != nil {
-					return err
-				}
+		idpGroupNames, apiIDPGroups, err = listEntries(opts, names, func(name string) (*api.IdentityProviderGroup, error) {
+			idpGroup := idpGroupsByName[name]
+			return idpGroup.ToAPI(ctx, tx.Tx(), canViewGroup)
+		})
 
-				apiIDPGroups = append(apiIDPGroups, apiIDPGroup)
-			}
-		}
-
-		return nil
+		return err
 	})
 	if err != nil {
 		return response.SmartError(err)
 	}
 
-	if recursion == "1" {
+	if opts.recursion {
 		return response.SyncResponse(true, apiIDPGroups)
 	}
 
-	idpGroupURLs := make([]string, 0, len(idpGroups))
-	for _, idpGroup := range idpGroups {
-		idpGroupURLs = append(idpGroupURLs, entity.IdentityProviderGroupURL(idpGroup.Name).String())
+	idpGroupURLs := make([]string, 0, len(idpGroupNames))
+	for _, idpGroupName := range idpGroupNames {
+		idpGroupURLs = append(idpGroupURLs, entity.IdentityProviderGroupURL(idpGroupName).String())
 	}
 
 	return response.SyncResponse(true, idpGroupURLs)
@@ -319,7 +351,7 @@ func createIdentityProviderGroup(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Notify other cluster members to update their identity cache.
-	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
 		return response.SmartError(err)
 	}
@@ -388,7 +420,7 @@ func renameIdentityProviderGroup(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Notify other cluster members to update their identity cache.
-	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
 		return response.SmartError(err)
 	}
@@ -477,7 +509,7 @@ func updateIdentityProviderGroup(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Notify other cluster members to update their identity cache.
-	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
 		return response.SmartError(err)
 	}
@@ -573,7 +605,7 @@ func patchIdentityProviderGroup(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Notify other cluster members to update their identity cache.
-	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
 		return response.SmartError(err)
 	}
@@ -628,7 +660,7 @@ func deleteIdentityProviderGroup(d *Daemon, r *http.Request) response.Response {
 	}
 
 	// Notify other cluster members to update their identity cache.
-	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 	if err != nil {
 		return response.SmartError(err)
 	}
@@ -48,7 +48,6 @@ import (
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
-	"github.com/canonical/lxd/shared/filter"
 	"github.com/canonical/lxd/shared/ioprogress"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/osarch"
@@ -124,6 +123,57 @@ var imagePublishLock sync.Mutex
 // stepping on each other's toes.
 var imageTaskMu sync.Mutex
 
+// imageCheckSignature enforces the image signature policy of a project on an image that is about to be added to it.
+// When signatures are required, the "signature" property of the image must hold a valid signature of its fingerprint
+// made by one of the trusted keys.
+func imageCheckSignature(s *state.State, projectName string, fingerprint string, properties map[string]string) error {
+	var p *api.Project
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		project, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return err
+		}
+
+		p, err = project.ToAPI(ctx, tx.Tx())
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	required := s.GlobalConfig.ImagesRequireSignature()
+	if p.Config["images.require_signature"] != "" {
+		required = shared.IsTrue(p.Config["images.require_signature"])
+	}
+
+	if !required {
+		return nil
+	}
+
+	signature := properties["signature"]
+	if signature == "" {
+		return api.StatusErrorf(http.StatusForbidden, "Image %q isn't signed and project %q requires signed images", fingerprint, projectName)
+	}
+
+	keys, err := util.ParseSigningKeys(s.GlobalConfig.ImagesTrustedKeys())
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		return api.StatusErrorf(http.StatusForbidden, "Project %q requires signed images but no trusted keys are configured", projectName)
+	}
+
+	err = util.VerifyImageSignature(keys, fingerprint, signature)
+	if err != nil {
+		return api.StatusErrorf(http.StatusForbidden, "Invalid signature for image %q: %w", fingerprint, err)
+	}
+
+	return nil
+}
+
 func compressFile(compress string, infile io.Reader, outfile io.Writer) error {
 	reproducible := []string{"gzip"}
 	var cmd *exec.Cmd
@@ -428,6 +478,7 @@ func imgPostRemoteInfo(s *state.State, r *http.Request, req api.ImagesPost, op *
 		Budget:            budget,
 		SourceProjectName: req.Source.Project,
 		UserRequested:     true,
+		Signature:         req.Properties["signature"],
 	})
 	if err != nil {
 		return nil, err
@@ -528,6 +579,12 @@ func imgPostURLInfo(s *state.State, r *http.Request, req api.ImagesPost, op *ope
 		return nil, fmt.Errorf("Missing LXD-Image-URL header")
 	}
 
+	// A signature provided with the request takes precedence over the one from the server.
+	signature := req.Properties["signature"]
+	if signature == "" {
+		signature = raw.Header.Get("LXD-Image-Signature")
+	}
+
 	// Import the image
 	info, err := ImageDownload(r, s, op, &ImageDownloadArgs{
 		Server:        url,
@@ -538,6 +595,7 @@ func imgPostURLInfo(s *state.State, r *http.Request, req api.ImagesPost, op *ope
 		ProjectName:   project,
 		Budget:        budget,
 		UserRequested: true,
+		Signature:     signature,
 	})
 	if err != nil {
 		return nil, err
@@ -769,6 +827,28 @@ func getImgPostInfo(s *state.State, r *http.Request, builddir string, project st
 		}
 	}
 
+	// Internal cluster image synchronization deals with images which have already been accepted.
+	if !isClusterNotification(r) {
+		err = imageCheckSignature(s, project, info.Fingerprint, info.Properties)
+		if err != nil {
+			var known bool
+
+			_ = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+				_, _, err := tx.GetImageFromAnyProject(ctx, info.Fingerprint)
+				known = err == nil
+
+				return nil
+			})
+
+			// Don't leave the rejected image files around unless they belong to an existing image.
+			if !known {
+				imageDeleteFromDisk(info.Fingerprint)
+			}
+
+			return nil, err
+		}
+	}
+
 	var profileIDs []int64
 	if len(profilesHeaders) > 0 {
 		p, _ := url.ParseQuery(profilesHeaders)
@@ -1347,23 +1427,13 @@ func getImageMetadata(fname string) (*api.ImageMetadata, string, error) {
 	return &result, imageType, nil
 }
 
-func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectName string, public bool, clauses *filter.ClauseSet, hasPermission auth.PermissionChecker) (any, error) {
-	mustLoadObjects := recursion || (clauses != nil && len(clauses.Clauses) > 0)
-
+func doImagesGet(ctx context.Context, tx *db.ClusterTx, opts *listOptions, projectName string, public bool, hasPermission auth.PermissionChecker) (any, error) {
 	fingerprints, err := tx.GetImagesFingerprints(ctx, projectName, public)
 	if err != nil {
 		return err, err
 	}
 
-	var resultString []string
-	var resultMap []*api.Image
-
-	if recursion {
-		resultMap = make([]*api.Image, 0, len(fingerprints))
-	} else {
-		resultString = make([]string, 0, len(fingerprints))
-	}
-
+	images := make([]api.Image, 0, len(fingerprints))
 	for _, fingerprint := range fingerprints {
 		image, err := doImageGet(ctx, tx, projectName, fingerprint, public)
 		if err != nil {
@@ -1374,30 +1444,21 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 			continue
 		}
 
-		if !mustLoadObjects {
-			resultString = append(resultString, api.NewURL().Path(version.APIVersion, "images", fingerprint).String())
-		} else {
-			if clauses != nil && len(clauses.Clauses) > 0 {
-				match, err := filter.Match(*image, *clauses)
-				if err != nil {
-					return nil, err
-				}
+		images = append(images, *image)
+	}
 
-				if !match {
-					continue
-				}
-			}
+	images, err = listPage(opts, images, func(image api.Image) string { return image.Fingerprint })
+	if err != nil {
+		return nil, err
+	}
 
-			if recursion {
-				resultMap = append(resultMap, image)
-			} else {
-				resultString = append(resultString, api.NewURL().Path(version.APIVersion, "images", image.Fingerprint).String())
-			}
-		}
+	if opts.recursion {
+		return images, nil
 	}
 
-	if recursion {
-		return resultMap, nil
+	resultString := make([]string, 0, len(images))
+	for _, image := range images {
+		resultString = append(resultString, api.NewURL().Path(version.APIVersion, "images", image.Fingerprint).String())
 	}
 
 	return resultString, nil
@@ -1423,6 +1484,16 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 //      description: Collection filter
 //      type: string
 //      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -1477,6 +1548,16 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 //      description: Collection filter
 //      type: string
 //      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -1526,6 +1607,16 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 //      description: Collection filter
 //      type: string
 //      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -1580,6 +1671,16 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 //	    description: Collection filter
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -1610,8 +1711,6 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 //	    $ref: "#/responses/InternalServerError"
 func imagesGet(d *Daemon, r *http.Request) response.Response {
 	projectName := request.ProjectParam(r)
-	filterStr := r.FormValue("filter")
-
 	s := d.State()
 	var effectiveProjectName string
 	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -1643,14 +1742,14 @@ func imagesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	clauses, err := filter.Parse(filterStr, filter.QueryOperatorSet())
+	opts, err := parseListOptions(r)
 	if err != nil {
-		return response.SmartError(fmt.Errorf("Invalid filter: %w", err))
+		return response.BadRequest(err)
 	}
 
 	var result any
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
-		result, err = doImagesGet(ctx, tx, util.IsRecursionRequest(r), projectName, publicOnly, clauses, canViewImage)
+		result, err = doImagesGet(ctx, tx, opts, projectName, publicOnly, canViewImage)
 		if err != nil {
 			return err
 		}
@@ -2732,7 +2831,7 @@ func imageDelete(d *Daemon, r *http.Request) response.Response {
 			}
 
 			// Notify the other nodes about the removed image so they can remove it from disk too.
-			notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAll)
+			notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAll)
 			if err != nil {
 				return err
 			}
@@ -2839,6 +2938,17 @@ func imageDeleteFromDisk(fingerprint string) {
 			logger.Errorf("Error deleting image file %s: %s", fname, err)
 		}
 	}
+
+	// Remove the simplestreams deltas from and to the image.
+	for _, pattern := range []string{fingerprint + ".delta-*", "*.delta-" + fingerprint} {
+		fnames, _ := filepath.Glob(shared.VarPath("images", pattern))
+		for _, fname := range fnames {
+			err := os.Remove(fname)
+			if err != nil && !os.IsNotExist(err) {
+				logger.Errorf("Error deleting image file %s: %s", fname, err)
+			}
+		}
+	}
 }
 
 func doImageGet(ctx context.Context, tx *db.ClusterTx, project, fingerprint string, public bool) (*api.Image, error) {
@@ -3383,6 +3493,21 @@ func imageAliasesPost(d *Daemon, r *http.Request) response.Response {
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -3432,6 +3557,21 @@ func imageAliasesPost(d *Daemon, r *http.Request) response.Response {
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -3461,13 +3601,16 @@ func imageAliasesPost(d *Daemon, r *http.Request) response.Response {
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
 func imageAliasesGet(d *Daemon, r *http.Request) response.Response {
-	recursion := util.IsRecursionRequest(r)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
 
 	s := d.State()
 
 	projectName := request.ProjectParam(r)
 	var effectiveProjectName string
-	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		projectHasImages, err := dbCluster.ProjectHasImages(ctx, tx.Tx(), projectName)
 		if err != nil {
 			return err
@@ -3489,48 +3632,46 @@ func imageAliasesGet(d *Daemon, r *http.Request) response.Response {
 		return response.InternalError(fmt.Errorf("Failed to get a permission checker: %w", err))
 	}
 
-	var responseStr []string
-	var responseMap []api.ImageAliasesEntry
+	var aliasNames []string
+	var aliases []api.ImageAliasesEntry
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
 		names, err := tx.GetImageAliases(ctx, projectName)
 		if err != nil {
 			return err
 		}
 
-		if recursion {
-			responseMap = make([]api.ImageAliasesEntry, 0, len(names))
-		} else {
-			responseStr = make([]string, 0, len(names))
-		}
-
+		allowedNames := make([]string, 0, len(names))
 		for _, name := range names {
-			if !userHasPermission(entity.ImageAliasURL(projectName, name)) {
-				continue
+			if userHasPermission(entity.ImageAliasURL(projectName, name)) {
+				allowedNames = append(allowedNames, name)
 			}
+		}
 
-			if !recursion {
-				responseStr = append(responseStr, api.NewURL().Path(version.APIVersion, "images", "aliases", name).String())
-			} else {
-				_, alias, err := tx.GetImageAlias(ctx, projectName, name, true)
-				if err != nil {
-					continue
-				}
-
-				responseMap = append(responseMap, alias)
+		aliasNames, aliases, err = listEntries(opts, allowedNames, func(name string) (*api.ImageAliasesEntry, error) {
+			_, alias, err := tx.GetImageAlias(ctx, projectName, name, true)
+			if err != nil {
+				return nil, nil
 			}
-		}
 
-		return nil
+			return &alias, nil
+		})
+
+		return err
 	})
 	if err != nil {
 		return response.SmartError(err)
 	}
 
-	if !recursion {
-		return response.SyncResponse(true, responseStr)
+	if opts.recursion {
+		return response.SyncResponse(true, aliases)
+	}
+
+	aliasURLs := make([]string, 0, len(aliasNames))
+	for _, name := range aliasNames {
+		aliasURLs = append(aliasURLs, api.NewURL().Path(version.APIVersion, "images", "aliases", name).String())
 	}
 
-	return response.SyncResponse(true, responseMap)
+	return response.SyncResponse(true, aliasURLs)
 }
 
 // swagger:operation GET /1.0/images/aliases/{name}?public images image_alias_get_untrusted
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+	"gopkg.in/yaml.v2"
+
+	"github.com/canonical/lxd/lxd/instancewriter"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/oci"
+	"github.com/canonical/lxd/shared/osarch"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// imageOCIConfigKeys lists the image properties of converted OCI images which map to instance configuration keys.
+var imageOCIConfigKeys = []string{"oci.entrypoint", "oci.cwd", "oci.uid", "oci.gid"}
+
+// imageOCIConfig applies the runtime configuration recorded in the properties of a converted OCI image to
+// the instance configuration. Keys already set in the configuration are left untouched.
+func imageOCIConfig(img *api.Image, config map[string]string) {
+	for key, value := range img.Properties {
+		configKey := key
+
+		env, found := strings.CutPrefix(key, "oci.env.")
+		if found {
+			configKey = "environment." + env
+		} else if !shared.ValueInSlice(key, imageOCIConfigKeys) {
+			continue
+		}
+
+		_, found = config[configKey]
+		if found {
+			continue
+		}
+
+		config[configKey] = value
+	}
+}
+
+// imageResolveOCI connects to an OCI image store and resolves the image reference for the first of the
+// server's architectures it is available for.
+func imageResolveOCI(s *state.State, server string, certificate string, reference string) (*oci.Client, *oci.Image, error) {
+	httpClient, err := util.HTTPClient(certificate, s.Proxy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := oci.NewClient(server, httpClient, version.UserAgent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = fmt.Errorf("No supported architecture")
+	for _, id := range s.OS.Architectures {
+		var architecture string
+
+		architecture, err = osarch.ArchitectureName(id)
+		if err != nil {
+			continue
+		}
+
+		var img *oci.Image
+
+		img, err = client.Resolve(reference, architecture)
+		if err == nil {
+			return client, img, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("Failed resolving OCI image %q: %w", reference, err)
+}
+
+// imageDownloadOCI downloads an OCI image and converts it into a unified LXD container image at destName.
+// The returned image info holds the fingerprint of the converted image.
+func imageDownloadOCI(s *state.State, client *oci.Client, img *oci.Image, destName string, budget int64, progress func(ioprogress.ProgressData)) (*api.Image, error) {
+	if budget > 0 && img.Size() > budget {
+		return nil, fmt.Errorf("Remote image with size %d exceeds allowed bugdget of %d", img.Size(), budget)
+	}
+
+	architecture, err := img.Architecture()
+	if err != nil {
+		return nil, err
+	}
+
+	buildDir, err := os.MkdirTemp(shared.VarPath("images"), "lxd_build_")
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = os.RemoveAll(buildDir) }()
+
+	rootfs := filepath.Join(buildDir, "rootfs")
+	err = os.Mkdir(rootfs, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.Unpack(img, rootfs, progress)
+	if err != nil {
+		return nil, fmt.Errorf("Failed unpacking OCI image %q: %w", img.Reference, err)
+	}
+
+	// LXC needs the API filesystem mount points to exist.
+	for _, dir := range []string{"dev", "proc", "sys", "run", "tmp"} {
+		info, err := os.Lstat(filepath.Join(rootfs, dir))
+		if err == nil && info.IsDir() {
+			continue
+		}
+
+		if err == nil {
+			return nil, fmt.Errorf("OCI image %q has a %q which isn't a directory", img.Reference, "/"+dir)
+		}
+
+		mode := os.FileMode(0755)
+		if dir == "tmp" {
+			mode = 0777 | os.ModeSticky
+		}
+
+		err = os.Mkdir(filepath.Join(rootfs, dir), mode)
+		if err != nil {
+			return nil, err
+		}
+
+		err = os.Chmod(filepath.Join(rootfs, dir), mode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config := img.Config.Config
+
+	properties := map[string]string{
+		"description":   img.Reference,
+		"architecture":  architecture,
+		"oci.reference": img.Reference,
+		"oci.digest":    img.Digest,
+	}
+
+	entrypoint := append(append([]string{}, config.Entrypoint...), config.Cmd...)
+	if len(entrypoint) > 0 {
+		properties["oci.entrypoint"] = shellquote.Join(entrypoint...)
+	}
+
+	if config.WorkingDir != "" {
+		properties["oci.cwd"] = config.WorkingDir
+	}
+
+	if config.User != "" {
+		uid, gid, err := imageOCIResolveUser(rootfs, config.User)
+		if err != nil {
+			return nil, fmt.Errorf("Failed resolving user of OCI image %q: %w", img.Reference, err)
+		}
+
+		properties["oci.uid"] = strconv.FormatInt(uid, 10)
+		properties["oci.gid"] = strconv.FormatInt(gid, 10)
+	}
+
+	for _, env := range config.Env {
+		key, value, found := strings.Cut(env, "=")
+		if found && key != "" {
+			properties["oci.env."+key] = value
+		}
+	}
+
+	createdAt := time.Now().UTC()
+	if img.Config.Created != nil && !img.Config.Created.IsZero() {
+		createdAt = img.Config.Created.UTC()
+	}
+
+	metadata, err := yaml.Marshal(api.ImageMetadata{
+		Architecture: architecture,
+		CreationDate: createdAt.Unix(),
+		Properties:   properties,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(filepath.Join(buildDir, "metadata.yaml"), metadata, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if progress != nil {
+		progress(ioprogress.ProgressData{Text: "Packing image"})
+	}
+
+	// Create the unified image tarball, compressing and hashing it in one pass.
+	imageFile, err := os.Create(destName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = imageFile.Close() }()
+
+	hash := sha256.New()
+	output := io.MultiWriter(imageFile, hash)
+
+	var tarOutput io.Writer = output
+	var tarPipe *io.PipeWriter
+	var compressErr error
+	wg := sync.WaitGroup{}
+
+	compress := s.GlobalConfig.ImagesCompressionAlgorithm()
+	if compress != "none" {
+		var tarReader *io.PipeReader
+		tarReader, tarPipe = io.Pipe()
+		tarOutput = tarPipe
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			compressErr = compressFile(compress, tarReader, output)
+
+			// If a compression error occurred, close the reader to end the tarball creation.
+			if compressErr != nil {
+				_ = tarReader.CloseWithError(compressErr)
+			}
+		}()
+	}
+
+	err = imageOCIWriteTarball(buildDir, tarOutput)
+	if tarPipe != nil {
+		_ = tarPipe.Close()
+	}
+
+	wg.Wait()
+
+	if compressErr != nil {
+		return nil, fmt.Errorf("Failed compressing image: %w", compressErr)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating image tarball: %w", err)
+	}
+
+	info, err := imageFile.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	err = imageFile.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.Image{
+		Fingerprint:  fmt.Sprintf("%x", hash.Sum(nil)),
+		Size:         info.Size(),
+		Architecture: architecture,
+		CreatedAt:    createdAt,
+		Properties:   properties,
+		Type:         string(api.InstanceTypeContainer),
+	}, nil
+}
+
+// imageOCIWriteTarball writes the content of the image build directory as a tarball.
+func imageOCIWriteTarball(buildDir string, writer io.Writer) error {
+	tarWriter := instancewriter.NewInstanceTarWriter(writer, nil)
+
+	// Path inside the tarball is the pathname starting after buildDir.
+	offset := len(buildDir) + 1
+
+	err := filepath.Walk(buildDir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == buildDir {
+			return nil
+		}
+
+		return tarWriter.WriteFile(path[offset:], path, fi, false)
+	})
+	if err != nil {
+		_ = tarWriter.Close()
+		return err
+	}
+
+	return tarWriter.Close()
+}
+
+// imageOCIResolveUser resolves an OCI "user[:group]" specification into numeric IDs using the image's
+// passwd and group databases.
+func imageOCIResolveUser(rootfs string, spec string) (int64, int64, error) {
+	userName, groupName, hasGroup := strings.Cut(spec, ":")
+
+	var uid, gid int64 = -1, 0
+
+	passwd, err := imageOCIReadIDFile(rootfs, "etc/passwd")
+	if err != nil {
+		return -1, -1, err
+	}
+
+	for _, fields := range passwd {
+		if len(fields) < 4 || (fields[0] != userName && fields[2] != userName) {
+			continue
+		}
+
+		uid, err = strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return -1, -1, fmt.Errorf("Invalid user ID for user %q: %w", fields[0], err)
+		}
+
+		gid, err = strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return -1, -1, fmt.Errorf("Invalid group ID for user %q: %w", fields[0], err)
+		}
+
+		break
+	}
+
+	if uid < 0 {
+		uid, err = strconv.ParseInt(userName, 10, 64)
+		if err != nil {
+			return -1, -1, fmt.Errorf("Unknown user %q", userName)
+		}
+	}
+
+	if !hasGroup {
+		return uid, gid, nil
+	}
+
+	gid, err = strconv.ParseInt(groupName, 10, 64)
+	if err == nil {
+		return uid, gid, nil
+	}
+
+	groups, err := imageOCIReadIDFile(rootfs, "etc/group")
+	if err != nil {
+		return -1, -1, err
+	}
+
+	for _, fields := range groups {
+		if len(fields) < 3 || fields[0] != groupName {
+			continue
+		}
+
+		gid, err = strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return -1, -1, fmt.Errorf("Invalid group ID for group %q: %w", groupName, err)
+		}
+
+		return uid, gid, nil
+	}
+
+	return -1, -1, fmt.Errorf("Unknown group %q", groupName)
+}
+
+// imageOCIReadIDFile returns the colon separated fields of each line of a passwd or group file.
+// A missing file or one that isn't a regular file is treated as empty.
+func imageOCIReadIDFile(rootfs string, name string) ([][]string, error) {
+	path := filepath.Join(rootfs, name)
+
+	// Don't follow symlinks out of the image.
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	entries := [][]string{}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entries = append(entries, strings.Split(line, ":"))
+	}
+
+	return entries, scanner.Err()
+}
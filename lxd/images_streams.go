@@ -0,0 +1,724 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/osarch"
+	"github.com/canonical/lxd/shared/simplestreams"
+)
+
+var apiStreams = []APIEndpoint{
+	imageStreamsCmd,
+	imageStreamsFileCmd,
+}
+
+var imageStreamsCmd = APIEndpoint{
+	Path: "streams/v1/{name}",
+
+	Get: APIEndpointAction{Handler: imageStreamsGet, AllowUntrusted: true},
+}
+
+var imageStreamsFileCmd = APIEndpoint{
+	Path: "streams/images/{fingerprint}/{file}",
+
+	Get: APIEndpointAction{Handler: imageStreamsFileGet, AllowUntrusted: true},
+}
+
+// imageStreamsProductsPath is the path of the products file listing the served images.
+const imageStreamsProductsPath = "streams/v1/images.json"
+
+// imageStreamsVersionLayout is the layout of version names, as parsed by the simplestreams client.
+const imageStreamsVersionLayout = "20060102_1504"
+
+// imageStreamsFile is a file served through the simplestreams endpoint.
+type imageStreamsFile struct {
+	name     string
+	path     string
+	fileType string
+	sha256   string
+	size     int64
+}
+
+// imageStreamsEntry holds the files of a public image served through the simplestreams endpoint.
+type imageStreamsEntry struct {
+	meta imageStreamsFile
+	root *imageStreamsFile
+
+	// Root filesystem deltas, indexed by the fingerprint of the image they apply to.
+	deltas map[string]imageStreamsFile
+}
+
+// imageStreamsVersion is a single version of a simplestreams product.
+type imageStreamsVersion struct {
+	name  string
+	image api.Image
+}
+
+// imageStreamsMu protects imageStreamsEntries.
+var imageStreamsMu sync.Mutex
+
+// imageStreamsEntries holds the hashed files of the public images available on this server, indexed by fingerprint.
+// As images are immutable, entries are only computed once and dropped when the image goes away.
+var imageStreamsEntries = map[string]*imageStreamsEntry{}
+
+// imageStreamsGet returns the simplestreams index or products file.
+func imageStreamsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.GlobalConfig.ImagesSimpleStreams() {
+		return response.NotFound(nil)
+	}
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	images, err := imageStreamsImages(r.Context(), s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	imageStreamsMu.Lock()
+	products := imageStreamsProducts(images, imageStreamsEntries)
+	imageStreamsMu.Unlock()
+
+	var body any
+
+	switch name {
+	case "index.json":
+		productNames := make([]string, 0, len(products.Products))
+		for productName := range products.Products {
+			productNames = append(productNames, productName)
+		}
+
+		sort.Strings(productNames)
+
+		body = simplestreams.Stream{
+			Format:  "index:1.0",
+			Updated: products.Updated,
+			Index: map[string]simplestreams.StreamIndex{
+				products.ContentID: {
+					DataType: products.DataType,
+					Path:     imageStreamsProductsPath,
+					Updated:  products.Updated,
+					Products: productNames,
+					Format:   products.Format,
+				},
+			},
+		}
+
+	case filepath.Base(imageStreamsProductsPath):
+		body = products
+	default:
+		return response.NotFound(nil)
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		return util.WriteJSON(w, body, nil)
+	})
+}
+
+// imageStreamsFileGet serves an image file referenced by the simplestreams products file.
+func imageStreamsFileGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.GlobalConfig.ImagesSimpleStreams() {
+		return response.NotFound(nil)
+	}
+
+	fingerprint, err := url.PathUnescape(mux.Vars(r)["fingerprint"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	fileName, err := url.PathUnescape(mux.Vars(r)["file"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var files []imageStreamsFile
+
+	imageStreamsMu.Lock()
+	entry := imageStreamsEntries[fingerprint]
+	if entry != nil {
+		files = append(files, entry.meta)
+		if entry.root != nil {
+			files = append(files, *entry.root)
+		}
+
+		for _, delta := range entry.deltas {
+			files = append(files, delta)
+		}
+	}
+
+	imageStreamsMu.Unlock()
+
+	if entry == nil {
+		// The image may only be stored on another cluster member.
+		var address string
+
+		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			public := true
+			projectName := api.ProjectDefaultName
+
+			_, _, err := tx.GetImage(ctx, fingerprint, dbCluster.ImageFilter{Project: &projectName, Public: &public})
+			if err != nil {
+				return err
+			}
+
+			address, err = tx.LocateImage(ctx, fingerprint)
+
+			return err
+		})
+		if err != nil && api.StatusErrorCheck(err, http.StatusNotFound) {
+			return response.NotFound(nil)
+		} else if err != nil {
+			return response.SmartError(err)
+		}
+
+		if address == "" {
+			return response.NotFound(nil)
+		}
+
+		client, err := cluster.Connect(address, s.Endpoints.NetworkCert(), s.ServerCert(), r, false)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.ForwardedResponse(client, r)
+	}
+
+	for _, file := range files {
+		if file.name != fileName {
+			continue
+		}
+
+		return response.FileResponse(r, []response.FileResponseEntry{{Identifier: file.name, Path: file.path, Filename: file.name}}, nil)
+	}
+
+	return response.NotFound(nil)
+}
+
+// imageStreamsImages returns the public images of the default project.
+// Cached images are only included once made public, as they're private copies of images from other remotes.
+func imageStreamsImages(ctx context.Context, s *state.State) ([]api.Image, error) {
+	images := []api.Image{}
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		projectName := api.ProjectDefaultName
+		public := true
+
+		fingerprints, err := tx.GetImagesFingerprints(ctx, projectName, public)
+		if err != nil {
+			return err
+		}
+
+		for _, fingerprint := range fingerprints {
+			_, image, err := tx.GetImage(ctx, fingerprint, dbCluster.ImageFilter{Project: &projectName, Public: &public})
+			if err != nil {
+				return err
+			}
+
+			images = append(images, *image)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading public images: %w", err)
+	}
+
+	return images, nil
+}
+
+// imageStreamsProductName returns the name of the simplestreams product an image is a version of.
+// Images are grouped by operating system, release, variant and architecture when those properties are
+// set, and by their first alias otherwise.
+func imageStreamsProductName(image api.Image) string {
+	fields := []string{}
+
+	if image.Properties["os"] != "" && image.Properties["release"] != "" {
+		variant := image.Properties["variant"]
+		if variant == "" {
+			variant = "default"
+		}
+
+		fields = append(fields, image.Properties["os"], image.Properties["release"], imageStreamsArchitecture(image), variant)
+	} else if len(image.Aliases) > 0 {
+		fields = append(fields, image.Aliases[0].Name, imageStreamsArchitecture(image))
+	} else {
+		fields = append(fields, image.Fingerprint)
+	}
+
+	if image.Type == string(api.InstanceTypeVM) {
+		fields = append(fields, "vm")
+	}
+
+	return strings.ToLower(strings.Join(fields, ":"))
+}
+
+// imageStreamsArchitecture returns the architecture name of an image as used in simplestreams products.
+func imageStreamsArchitecture(image api.Image) string {
+	architecture := image.Properties["architecture"]
+
+	_, err := osarch.ArchitectureId(architecture)
+	if err != nil {
+		return image.Architecture
+	}
+
+	return architecture
+}
+
+// imageStreamsGroup groups images into products, each holding the versions ordered from oldest to newest.
+func imageStreamsGroup(images []api.Image) map[string][]imageStreamsVersion {
+	sorted := append([]api.Image{}, images...)
+	sort.SliceStable(sorted, func(i int, j int) bool {
+		if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+		}
+
+		return sorted[i].Fingerprint < sorted[j].Fingerprint
+	})
+
+	products := map[string][]imageStreamsVersion{}
+	versionNames := map[string]bool{}
+
+	for _, image := range sorted {
+		productName := imageStreamsProductName(image)
+
+		createdAt := image.CreatedAt
+		if createdAt.Unix() <= 0 {
+			createdAt = image.UploadedAt
+		}
+
+		// Version names must start with the creation date, disambiguate images created in the same minute.
+		versionName := createdAt.UTC().Format(imageStreamsVersionLayout)
+		if versionNames[productName+"/"+versionName] {
+			versionName = fmt.Sprintf("%s_%s", versionName, image.Fingerprint[0:12])
+		}
+
+		versionNames[productName+"/"+versionName] = true
+		products[productName] = append(products[productName], imageStreamsVersion{name: versionName, image: image})
+	}
+
+	return products
+}
+
+// imageStreamsProducts builds the simplestreams products of the images which have files in entries.
+func imageStreamsProducts(images []api.Image, entries map[string]*imageStreamsEntry) *simplestreams.Products {
+	available := []api.Image{}
+	for _, image := range images {
+		if entries[image.Fingerprint] != nil {
+			available = append(available, image)
+		}
+	}
+
+	products := &simplestreams.Products{
+		ContentID: "images",
+		DataType:  "image-downloads",
+		Format:    "products:1.0",
+		Products:  map[string]simplestreams.Product{},
+		Updated:   time.Now().UTC().Format(time.RFC1123Z),
+	}
+
+	for productName, versions := range imageStreamsGroup(available) {
+		// Product wide fields are taken from the latest version.
+		latest := versions[len(versions)-1].image
+
+		product := simplestreams.Product{
+			Architecture:    imageStreamsArchitecture(latest),
+			OperatingSystem: latest.Properties["os"],
+			Release:         latest.Properties["release"],
+			ReleaseTitle:    latest.Properties["version"],
+			Version:         latest.Properties["version"],
+			Variant:         latest.Properties["variant"],
+			Versions:        map[string]simplestreams.ProductVersion{},
+		}
+
+		if product.ReleaseTitle == "" {
+			product.ReleaseTitle = product.Release
+		}
+
+		if product.ReleaseTitle == "" {
+			product.ReleaseTitle = latest.Properties["description"]
+		}
+
+		if !latest.ExpiresAt.IsZero() && latest.ExpiresAt.Unix() > 0 {
+			product.SupportedEOL = latest.ExpiresAt.UTC().Format("2006-01-02")
+		}
+
+		for key, value := range latest.Properties {
+			requirement, found := strings.CutPrefix(key, "requirements.")
+			if !found {
+				continue
+			}
+
+			if product.Requirements == nil {
+				product.Requirements = map[string]string{}
+			}
+
+			product.Requirements[requirement] = value
+		}
+
+		aliases := []string{}
+		versionNames := map[string]string{}
+
+		for _, version := range versions {
+			versionNames[version.image.Fingerprint] = version.name
+
+			for _, alias := range version.image.Aliases {
+				if !shared.ValueInSlice(alias.Name, aliases) {
+					aliases = append(aliases, alias.Name)
+				}
+			}
+		}
+
+		product.Aliases = strings.Join(aliases, ",")
+
+		for _, version := range versions {
+			entry := entries[version.image.Fingerprint]
+			fingerprint := version.image.Fingerprint
+			filePath := func(name string) string {
+				return fmt.Sprintf("streams/images/%s/%s", fingerprint, name)
+			}
+
+			meta := simplestreams.ProductVersionItem{
+				FileType:     "lxd_combined.tar.gz",
+				Path:         filePath(entry.meta.name),
+				HashSha256:   entry.meta.sha256,
+				Size:         entry.meta.size,
+				LXDSignature: version.image.Properties["signature"],
+			}
+
+			items := map[string]simplestreams.ProductVersionItem{}
+
+			if entry.root != nil {
+				meta.FileType = "lxd.tar.xz"
+
+				switch entry.root.fileType {
+				case "squashfs":
+					meta.LXDHashSha256SquashFs = fingerprint
+				case "disk-kvm.img":
+					meta.LXDHashSha256DiskKvmImg = fingerprint
+				default:
+					meta.LXDHashSha256RootXz = fingerprint
+				}
+
+				items[entry.root.fileType] = simplestreams.ProductVersionItem{
+					FileType:   entry.root.fileType,
+					Path:       filePath(entry.root.name),
+					HashSha256: entry.root.sha256,
+					Size:       entry.root.size,
+				}
+
+				for source, delta := range entry.deltas {
+					deltaBase, found := versionNames[source]
+					if !found {
+						continue
+					}
+
+					items[fmt.Sprintf("%s.%s.vcdiff", deltaBase, entry.root.fileType)] = simplestreams.ProductVersionItem{
+						FileType:   delta.fileType,
+						Path:       filePath(delta.name),
+						HashSha256: delta.sha256,
+						Size:       delta.size,
+						DeltaBase:  deltaBase,
+					}
+				}
+			}
+
+			items[meta.FileType] = meta
+
+			product.Versions[version.name] = simplestreams.ProductVersion{
+				Items: items,
+				Label: version.image.Properties["label"],
+			}
+		}
+
+		products.Products[productName] = product
+	}
+
+	return products
+}
+
+// imageStreamsHashFile returns the SHA-256 hash and size of a file.
+func imageStreamsHashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", -1, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return "", -1, err
+	}
+
+	return fmt.Sprintf("%x", hash.Sum(nil)), size, nil
+}
+
+// imageStreamsLoadEntry hashes the files of a locally stored image.
+func imageStreamsLoadEntry(image api.Image) (*imageStreamsEntry, error) {
+	imagePath := shared.VarPath("images", image.Fingerprint)
+	rootfsPath := imagePath + ".rootfs"
+
+	_, ext, _, err := shared.DetectCompression(imagePath)
+	if err != nil {
+		ext = ""
+	}
+
+	entry := &imageStreamsEntry{
+		meta:   imageStreamsFile{name: image.Fingerprint + ext, path: imagePath},
+		deltas: map[string]imageStreamsFile{},
+	}
+
+	if !shared.PathExists(rootfsPath) {
+		// Unified images are identified by the hash of their only file.
+		entry.meta.sha256 = image.Fingerprint
+		entry.meta.size = image.Size
+
+		return entry, nil
+	}
+
+	entry.meta.name = "meta-" + entry.meta.name
+	entry.meta.sha256, entry.meta.size, err = imageStreamsHashFile(imagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	_, ext, _, err = shared.DetectCompression(rootfsPath)
+	if err != nil {
+		ext = ""
+	}
+
+	entry.root = &imageStreamsFile{name: image.Fingerprint + ext, path: rootfsPath, fileType: "root.tar.xz"}
+	if image.Type == string(api.InstanceTypeVM) {
+		entry.root.fileType = "disk-kvm.img"
+	} else if ext == ".squashfs" {
+		entry.root.fileType = "squashfs"
+	}
+
+	entry.root.sha256, entry.root.size, err = imageStreamsHashFile(rootfsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pick up deltas generated before a restart.
+	deltaPaths, err := filepath.Glob(shared.VarPath("images", image.Fingerprint+".delta-*"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, deltaPath := range deltaPaths {
+		source := strings.TrimPrefix(filepath.Base(deltaPath), image.Fingerprint+".delta-")
+
+		delta, err := imageStreamsLoadDelta(entry, source, deltaPath)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.deltas[source] = *delta
+	}
+
+	return entry, nil
+}
+
+// imageStreamsLoadDelta hashes a root filesystem delta from the source image.
+func imageStreamsLoadDelta(entry *imageStreamsEntry, source string, deltaPath string) (*imageStreamsFile, error) {
+	sha256, size, err := imageStreamsHashFile(deltaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &imageStreamsFile{
+		name:     fmt.Sprintf("delta-%s.vcdiff", source),
+		path:     deltaPath,
+		fileType: entry.root.fileType + ".vcdiff",
+		sha256:   sha256,
+		size:     size,
+	}, nil
+}
+
+// imageStreamsUpdate hashes the files of the public images stored on this server and generates the root
+// filesystem deltas between consecutive versions of a product.
+func imageStreamsUpdate(ctx context.Context, s *state.State) error {
+	images, err := imageStreamsImages(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	var localFingerprints []string
+
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		localFingerprints, err = tx.GetLocalImagesFingerprints(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading local images: %w", err)
+	}
+
+	imageStreamsMu.Lock()
+	current := imageStreamsEntries
+	imageStreamsMu.Unlock()
+
+	entries := map[string]*imageStreamsEntry{}
+	local := []api.Image{}
+
+	for _, image := range images {
+		if !shared.ValueInSlice(image.Fingerprint, localFingerprints) || !shared.PathExists(shared.VarPath("images", image.Fingerprint)) {
+			continue
+		}
+
+		entry := current[image.Fingerprint]
+		if entry != nil {
+			// Drop the deltas from images which have since been deleted.
+			imageStreamsMu.Lock()
+			for source, delta := range entry.deltas {
+				if !shared.PathExists(delta.path) {
+					delete(entry.deltas, source)
+				}
+			}
+
+			imageStreamsMu.Unlock()
+		} else {
+			entry, err = imageStreamsLoadEntry(image)
+			if err != nil {
+				return fmt.Errorf("Failed loading files of image %q: %w", image.Fingerprint, err)
+			}
+		}
+
+		entries[image.Fingerprint] = entry
+		local = append(local, image)
+	}
+
+	// Make the hashed images available before generating the deltas.
+	imageStreamsMu.Lock()
+	imageStreamsEntries = entries
+	imageStreamsMu.Unlock()
+
+	_, err = exec.LookPath("xdelta3")
+	if err != nil {
+		logger.Debug("Skipping generation of simplestreams image deltas as xdelta3 isn't available")
+		return nil
+	}
+
+	for _, versions := range imageStreamsGroup(local) {
+		for i := 1; i < len(versions); i++ {
+			err = ctx.Err()
+			if err != nil {
+				return err
+			}
+
+			source := entries[versions[i-1].image.Fingerprint]
+			target := entries[versions[i].image.Fingerprint]
+			sourceFingerprint := versions[i-1].image.Fingerprint
+
+			// Deltas are only used by clients for squashfs and VM images.
+			if source.root == nil || target.root == nil || source.root.fileType != target.root.fileType || !shared.ValueInSlice(target.root.fileType, []string{"squashfs", "disk-kvm.img"}) {
+				continue
+			}
+
+			imageStreamsMu.Lock()
+			_, found := target.deltas[sourceFingerprint]
+			imageStreamsMu.Unlock()
+
+			if found {
+				continue
+			}
+
+			deltaPath := shared.VarPath("images", fmt.Sprintf("%s.delta-%s", versions[i].image.Fingerprint, sourceFingerprint))
+
+			_, err = shared.RunCommandContext(ctx, "xdelta3", "-f", "-e", "-s", source.root.path, target.root.path, deltaPath)
+			if err != nil {
+				_ = os.Remove(deltaPath)
+				return fmt.Errorf("Failed generating delta between images %q and %q: %w", sourceFingerprint, versions[i].image.Fingerprint, err)
+			}
+
+			delta, err := imageStreamsLoadDelta(target, sourceFingerprint, deltaPath)
+			if err != nil {
+				return err
+			}
+
+			imageStreamsMu.Lock()
+			target.deltas[sourceFingerprint] = *delta
+			imageStreamsMu.Unlock()
+		}
+	}
+
+	return nil
+}
+
+func imageStreamsUpdateTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		if !s.GlobalConfig.ImagesSimpleStreams() {
+			imageStreamsMu.Lock()
+			imageStreamsEntries = map[string]*imageStreamsEntry{}
+			imageStreamsMu.Unlock()
+
+			return
+		}
+
+		opRun := func(op *operations.Operation) error {
+			return imageStreamsUpdate(ctx, s)
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.ImagesStreamsUpdate, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating simplestreams image index update operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Debug("Acquiring image task lock")
+		imageTaskMu.Lock()
+		defer imageTaskMu.Unlock()
+		logger.Debug("Acquired image task lock")
+
+		logger.Info("Updating simplestreams image index")
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting simplestreams image index update operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed updating simplestreams image index", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Done updating simplestreams image index")
+	}
+
+	return f, task.Hourly()
+}
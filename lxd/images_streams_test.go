@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestImageStreamsProducts(t *testing.T) {
+	newImage := func(fingerprint string, createdAt time.Time, aliases ...string) api.Image {
+		image := api.Image{
+			Fingerprint:  fingerprint,
+			Architecture: "x86_64",
+			Type:         string(api.InstanceTypeContainer),
+			CreatedAt:    createdAt,
+			Public:       true,
+		}
+
+		image.Properties = map[string]string{"os": "Ubuntu", "release": "noble", "version": "24.04", "architecture": "amd64"}
+
+		for _, alias := range aliases {
+			image.Aliases = append(image.Aliases, api.ImageAlias{Name: alias})
+		}
+
+		return image
+	}
+
+	old := newImage("1111111111111111111111111111111111111111111111111111111111111111", time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	current := newImage("2222222222222222222222222222222222222222222222222222222222222222", time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC), "ubuntu/noble")
+	unified := newImage("3333333333333333333333333333333333333333333333333333333333333333", time.Date(2024, 6, 2, 10, 0, 0, 0, time.UTC), "custom")
+	unified.Properties = nil
+
+	entries := map[string]*imageStreamsEntry{}
+	for _, image := range []api.Image{old, current} {
+		entries[image.Fingerprint] = &imageStreamsEntry{
+			meta:   imageStreamsFile{name: "meta-" + image.Fingerprint + ".tar.xz", sha256: "meta", size: 10},
+			root:   &imageStreamsFile{name: image.Fingerprint + ".squashfs", fileType: "squashfs", sha256: "root", size: 100},
+			deltas: map[string]imageStreamsFile{},
+		}
+	}
+
+	entries[current.Fingerprint].deltas[old.Fingerprint] = imageStreamsFile{name: "delta-" + old.Fingerprint + ".vcdiff", fileType: "squashfs.vcdiff", sha256: "delta", size: 5}
+	entries[unified.Fingerprint] = &imageStreamsEntry{meta: imageStreamsFile{name: unified.Fingerprint + ".tar.gz", sha256: unified.Fingerprint, size: 50}}
+
+	// Images without files on this server are skipped.
+	missing := newImage("4444444444444444444444444444444444444444444444444444444444444444", time.Now())
+
+	products := imageStreamsProducts([]api.Image{old, current, unified, missing}, entries)
+	require.Len(t, products.Products, 2)
+
+	product, found := products.Products["ubuntu:noble:amd64:default"]
+	require.True(t, found)
+	assert.Equal(t, "ubuntu/noble", product.Aliases)
+	assert.Len(t, product.Versions, 2)
+	assert.Equal(t, "20240501_1000", product.Versions["20240601_1000"].Items["20240501_1000.squashfs.vcdiff"].DeltaBase)
+
+	images, downloads := products.ToLXD()
+	require.Len(t, images, 3)
+
+	fingerprints := []string{}
+	for _, image := range images {
+		fingerprints = append(fingerprints, image.Fingerprint)
+	}
+
+	assert.ElementsMatch(t, []string{old.Fingerprint, current.Fingerprint, unified.Fingerprint}, fingerprints)
+
+	// The latest version can be patched from the previous one.
+	require.Len(t, downloads[current.Fingerprint], 3)
+	assert.Equal(t, []string{"streams/images/" + current.Fingerprint + "/delta-" + old.Fingerprint + ".vcdiff", "delta", "root.delta-" + old.Fingerprint, "5"}, downloads[current.Fingerprint][2])
+
+	require.Len(t, downloads[unified.Fingerprint], 1)
+	assert.Equal(t, "streams/images/"+unified.Fingerprint+"/"+unified.Fingerprint+".tar.gz", downloads[unified.Fingerprint][0][0])
+}
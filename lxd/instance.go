@@ -32,7 +32,7 @@ import (
 // Helper functions
 
 // instanceCreateAsEmpty creates an empty instance.
-func instanceCreateAsEmpty(s *state.State, args db.InstanceArgs) (instance.Instance, error) {
+func instanceCreateAsEmpty(s *state.State, args db.InstanceArgs, op *operations.Operation) (instance.Instance, error) {
 	revert := revert.New()
 	defer revert.Fail()
 
@@ -45,6 +45,11 @@ func instanceCreateAsEmpty(s *state.State, args db.InstanceArgs) (instance.Insta
 	revert.Add(cleanup)
 	defer instOp.Done(err)
 
+	err = operationAddInstanceRollback(op, inst)
+	if err != nil {
+		return nil, err
+	}
+
 	pool, err := storagePools.LoadByInstance(s, inst)
 	if err != nil {
 		return nil, fmt.Errorf("Failed loading instance storage pool: %w", err)
@@ -149,6 +154,9 @@ func instanceCreateFromImage(s *state.State, img *api.Image, args db.InstanceArg
 		}
 	}
 
+	// Apply the runtime configuration of OCI images unless overridden.
+	imageOCIConfig(img, args.Config)
+
 	// Set the BaseImage field (regardless of previous value).
 	args.BaseImage = img.Fingerprint
 
@@ -161,6 +169,11 @@ func instanceCreateFromImage(s *state.State, img *api.Image, args db.InstanceArg
 	revert.Add(cleanup)
 	defer instOp.Done(nil)
 
+	err = operationAddInstanceRollback(op, inst)
+	if err != nil {
+		return err
+	}
+
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		err = tx.UpdateImageLastUseDate(ctx, args.Project, img.Fingerprint, time.Now().UTC())
 		if err != nil {
@@ -235,6 +248,7 @@ type instanceCreateAsCopyOpts struct {
 	refresh              bool              // Refresh an existing target instance.
 	applyTemplateTrigger bool              // Apply deferred TemplateTriggerCopy.
 	allowInconsistent    bool              // Ignore some copy errors
+	rollback             bool              // Delete the new instance if the operation is interrupted by a restart.
 }
 
 // instanceCreateAsCopy create a new instance by copying from an existing instance.
@@ -273,6 +287,13 @@ func instanceCreateAsCopy(s *state.State, opts instanceCreateAsCopyOpts, op *ope
 
 	defer instOp.Done(err)
 
+	if opts.rollback && !opts.refresh {
+		err = operationAddInstanceRollback(op, inst)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// At this point we have already figured out the instance's root disk device so we can simply retrieve it
 	// from the expanded devices.
 	instRootDiskDeviceKey, instRootDiskDevice, err := instancetype.GetRootDiskDevice(inst.ExpandedDevices().CloneNative())
@@ -488,6 +509,16 @@ func autoCreateInstanceSnapshots(ctx context.Context, s *state.State, instances
 
 		l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
 
+		// Check the project limits again as the snapshots created earlier in this run count towards them.
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			p := inst.Project()
+			return project.AllowSnapshotCreation(tx, &p)
+		})
+		if err != nil {
+			l.Warn("Skipping scheduled snapshot", logger.Ctx{"err": err})
+			continue
+		}
+
 		snapshotName, err := instance.NextSnapshotName(s, inst, "snap%d")
 		if err != nil {
 			l.Error("Error retrieving next snapshot name", logger.Ctx{"err": err})
@@ -500,7 +531,9 @@ func autoCreateInstanceSnapshots(ctx context.Context, s *state.State, instances
 			return err
 		}
 
+		thaw := instanceFreezeFilesystems(inst, nil)
 		err = inst.Snapshot(snapshotName, expiry, false)
+		thaw()
 		if err != nil {
 			l.Error("Error creating snapshot", logger.Ctx{"snapshot": snapshotName, "err": err})
 			return err
@@ -619,7 +652,7 @@ func pruneExpiredAndAutoCreateInstanceSnapshotsTask(d *Daemon) (task.Func, task.
 
 		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
 			return tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
-				err = project.AllowSnapshotCreation(&p)
+				err = project.AllowSnapshotCreation(tx, &p)
 				if err != nil {
 					return nil
 				}
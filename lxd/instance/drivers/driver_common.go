@@ -1014,6 +1014,11 @@ func (d *common) validateStartup(statusCode api.StatusCode) error {
 		return api.StatusErrorf(http.StatusServiceUnavailable, "Storage pool %q unavailable on this server", rootDiskConf["pool"])
 	}
 
+	// Replicas are refreshed by the server of the replicated instance, so they can only be started once promoted.
+	if shared.IsTrue(d.expandedConfig["replication.replica"]) {
+		return api.StatusErrorf(http.StatusBadRequest, "Instance is a replica and must be promoted before being started")
+	}
+
 	// Must happen before creating operation Start lock to avoid the status check returning Stopped due to the
 	// existence of a Start operation lock.
 	err = d.isStartableStatusCode(statusCode)
@@ -1051,6 +1051,41 @@ func (d *lxc) initLXC(config bool) (*liblxc.Container, error) {
 		}
 	}
 
+	// Setup the OCI entry point
+	if d.expandedConfig["oci.entrypoint"] != "" {
+		err = lxcSetConfigItem(cc, "lxc.init.cmd", d.expandedConfig["oci.entrypoint"])
+		if err != nil {
+			return nil, err
+		}
+
+		if d.expandedConfig["oci.cwd"] != "" {
+			err = lxcSetConfigItem(cc, "lxc.init.cwd", d.expandedConfig["oci.cwd"])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if d.expandedConfig["oci.uid"] != "" {
+			err = lxcSetConfigItem(cc, "lxc.init.uid", d.expandedConfig["oci.uid"])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if d.expandedConfig["oci.gid"] != "" {
+			err = lxcSetConfigItem(cc, "lxc.init.gid", d.expandedConfig["oci.gid"])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Application entry points expect SIGTERM rather than the SIGPWR used to halt init systems.
+		err = lxcSetConfigItem(cc, "lxc.signal.halt", "SIGTERM")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Setup NVIDIA runtime
 	if shared.IsTrue(d.expandedConfig["nvidia.runtime"]) {
 		hookDir := os.Getenv("LXD_LXC_HOOK")
@@ -2649,11 +2684,18 @@ func (d *lxc) Stop(stateful bool) error {
 
 	// Handle stateful stop
 	if stateful {
+		err := d.CheckpointCheck()
+		if err != nil {
+			err = fmt.Errorf("Unable to perform a stateful stop: %w", err)
+			op.Done(err)
+			return err
+		}
+
 		// Cleanup any existing state
 		stateDir := d.StatePath()
 		_ = os.RemoveAll(stateDir)
 
-		err := os.MkdirAll(stateDir, 0700)
+		err = os.MkdirAll(stateDir, 0700)
 		if err != nil {
 			op.Done(err)
 			return err
@@ -3372,9 +3414,9 @@ func (d *lxc) snapshot(name string, expiry time.Time, stateful bool) error {
 			return fmt.Errorf("Unable to create a stateful snapshot. The instance isn't running")
 		}
 
-		_, err := exec.LookPath("criu")
+		err := d.CheckpointCheck()
 		if err != nil {
-			return fmt.Errorf("Unable to create a stateful snapshot. CRIU isn't installed")
+			return fmt.Errorf("Unable to create a stateful snapshot: %w", err)
 		}
 
 		// Cleanup any existing state
@@ -3526,46 +3568,20 @@ func (d *lxc) Restore(sourceContainer instance.Instance, stateful bool) error {
 	// This is required so we can actually unmount the container and restore its rootfs.
 	d.stopForkfile(false)
 
-	// Initialize storage interface for the container and mount the rootfs for criu state check.
+	// Initialize storage interface for the container.
 	pool, err := storagePools.LoadByInstance(d.state, d)
 	if err != nil {
 		op.Done(err)
 		return err
 	}
 
-	d.logger.Debug("Mounting instance to check for CRIU state path existence")
-
-	revert := revert.New()
-	defer revert.Fail()
-
-	// Ensure that storage is mounted for state path checks and for backup.yaml updates.
-	_, err = d.mount()
-	if err != nil {
-		op.Done(err)
-		return err
-	}
-
-	revert.Add(func() { _ = d.unmount() })
-
 	// Check for CRIU if necessary, before doing a bunch of filesystem manipulations.
-	// Requires container be mounted to check StatePath exists.
-	if shared.PathExists(d.StatePath()) {
-		_, err := exec.LookPath("criu")
-		if err != nil {
-			err = fmt.Errorf("Failed to restore container state. CRIU isn't installed")
-			op.Done(err)
-			return err
-		}
-	}
-
-	err = d.unmount()
-	if err != nil {
+	if stateful && !d.state.OS.CRIU {
+		err = fmt.Errorf("Failed to restore container state. CRIU isn't installed or lacks kernel support")
 		op.Done(err)
 		return err
 	}
 
-	revert.Success()
-
 	// Restore the rootfs.
 	err = pool.RestoreInstanceSnapshot(d, sourceContainer, nil)
 	if err != nil {
@@ -3596,7 +3612,22 @@ func (d *lxc) Restore(sourceContainer instance.Instance, stateful bool) error {
 
 	// If the container wasn't running but was stateful, should we restore it as running?
 	if stateful {
-		if !shared.PathExists(d.StatePath()) {
+		// Mount the restored volume to look for the checkpoint taken along with the snapshot.
+		_, err = d.mount()
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+
+		hasState := shared.PathExists(d.StatePath())
+
+		err = d.unmount()
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+
+		if !hasState {
 			err = fmt.Errorf("Stateful snapshot restore requested but snapshot is stateless")
 			op.Done(err)
 			return err
@@ -3615,11 +3646,11 @@ func (d *lxc) Restore(sourceContainer instance.Instance, stateful bool) error {
 			PreDumpDir:   "",
 		}
 
-		// Checkpoint.
+		// Restore the checkpoint.
 		err = d.migrate(&criuMigrationArgs)
 		if err != nil {
 			op.Done(err)
-			return fmt.Errorf("Failed taking stateful checkpoint: %w", err)
+			return fmt.Errorf("Failed restoring stateful checkpoint: %w", err)
 		}
 
 		// Remove the state from the parent container; we only keep this in snapshots.
@@ -3630,6 +3661,7 @@ func (d *lxc) Restore(sourceContainer instance.Instance, stateful bool) error {
 		}
 
 		d.logger.Debug("Performed stateful restore", ctxMap)
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceRestored.Event(d, map[string]any{"snapshot": sourceContainer.Name()}))
 		d.logger.Info("Restored instance", ctxMap)
 		return nil
 	}
@@ -5119,8 +5151,7 @@ func getCRIULogErrors(imagesDir string, method string) (string, error) {
 // Check if CRIU supports pre-dumping and number of pre-dump iterations.
 func (d *lxc) migrationSendCheckForPreDumpSupport() (bool, int) {
 	// Check if this architecture/kernel/criu combination supports pre-copy dirty memory tracking feature.
-	_, err := shared.RunCommand("criu", "check", "--feature", "mem_dirty_track")
-	if err != nil {
+	if !d.state.OS.CRIUMemDirtyTracking {
 		// CRIU says it does not know about dirty memory tracking.
 		// This means the rest of this function is irrelevant.
 		return false, 0
@@ -5521,8 +5552,7 @@ func (d *lxc) MigrateSend(args instance.MigrateSendArgs) error {
 							return err
 						}
 
-						preDumpDir = fmt.Sprintf("%03d", preDumpCounter)
-						preDumpCounter++
+						preDumpDir = dumpDir
 					}
 				} else {
 					d.logger.Debug("The other side does not support pre-copy")
@@ -6361,6 +6391,57 @@ func (d *lxc) ConversionReceive(args instance.ConversionReceiveArgs) error {
 	return nil
 }
 
+// CheckpointCheck returns an error explaining why the running state of the container can't be checkpointed
+// and restored with CRIU, or nil if it can.
+func (d *lxc) CheckpointCheck() error {
+	if !d.state.OS.CRIU {
+		_, err := exec.LookPath("criu")
+		if err != nil {
+			return fmt.Errorf("CRIU isn't installed")
+		}
+
+		return fmt.Errorf(`CRIU is installed but reports missing kernel support (see "criu check")`)
+	}
+
+	var unsupported []string
+
+	if shared.IsTrue(d.expandedConfig["security.nesting"]) {
+		unsupported = append(unsupported, `nested containers ("security.nesting")`)
+	}
+
+	needsIntercept, err := seccomp.InstanceNeedsIntercept(d.state, d)
+	if err != nil {
+		return err
+	}
+
+	if needsIntercept {
+		unsupported = append(unsupported, `system call interception ("security.syscalls.intercept.*")`)
+	}
+
+	for _, entry := range d.expandedDevices.Sorted() {
+		switch entry.Config["type"] {
+		case "gpu", "infiniband", "tpm", "unix-block", "unix-char", "unix-hotplug", "usb":
+			unsupported = append(unsupported, fmt.Sprintf("%q device %q", entry.Config["type"], entry.Name))
+		case "nic":
+			nicType, err := nictype.NICType(d.state, d.project.Name, entry.Config)
+			if err != nil {
+				return err
+			}
+
+			// CRIU can only recreate the veth based network interfaces.
+			if !shared.ValueInSlice(nicType, []string{"bridged", "p2p", "routed"}) {
+				unsupported = append(unsupported, fmt.Sprintf("%q NIC %q", nicType, entry.Name))
+			}
+		}
+	}
+
+	if len(unsupported) > 0 {
+		return fmt.Errorf("The container uses features CRIU can't checkpoint: %s", strings.Join(unsupported, ", "))
+	}
+
+	return nil
+}
+
 // Migrate migrates the instance to another node.
 func (d *lxc) migrate(args *instance.CriuMigrationArgs) error {
 	ctxMap := logger.Ctx{
@@ -6373,8 +6454,7 @@ func (d *lxc) migrate(args *instance.CriuMigrationArgs) error {
 		"features":     args.Features,
 		"stop":         args.Stop}
 
-	_, err := exec.LookPath("criu")
-	if err != nil {
+	if !d.state.OS.CRIU {
 		return migration.ErrNoLiveMigration
 	}
 
@@ -7976,6 +7976,44 @@ func (d *qemu) agentGetState() (*api.InstanceState, error) {
 	return status, nil
 }
 
+// FreezeFilesystems asks the lxd-agent to freeze the guest filesystems and returns a function to thaw them.
+func (d *qemu) FreezeFilesystems() (func() error, error) {
+	client, err := d.getAgentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := lxd.ConnectLXDHTTP(nil, client)
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to agent: %w", err)
+	}
+
+	req := agentAPI.FilesystemFreezePost{Timeout: agentAPI.FilesystemFreezeDefaultTimeout}
+
+	_, _, err = agent.RawQuery("POST", "/1.0/fsfreeze", req, "")
+	if err != nil {
+		agent.Disconnect()
+		return nil, fmt.Errorf("Failed freezing guest filesystems: %w", err)
+	}
+
+	d.logger.Debug("Guest filesystems frozen")
+
+	thaw := func() error {
+		defer agent.Disconnect()
+
+		_, _, err := agent.RawQuery("DELETE", "/1.0/fsfreeze", nil, "")
+		if err != nil {
+			return fmt.Errorf("Failed thawing guest filesystems: %w", err)
+		}
+
+		d.logger.Debug("Guest filesystems thawed")
+
+		return nil
+	}
+
+	return thaw, nil
+}
+
 // IsRunning returns whether or not the instance is running.
 func (d *qemu) IsRunning() bool {
 	return d.isRunningStatusCode(d.statusCode())
@@ -184,6 +184,7 @@ type Container interface {
 	InsertSeccompUnixDevice(prefix string, m deviceConfig.Device, pid int) error
 	DevptsFd() (*os.File, error)
 	IdmappedStorage(path string, fstype string) idmap.IdmapStorageType
+	CheckpointCheck() error
 }
 
 // VM interface is for VM specific functions.
@@ -195,6 +196,9 @@ type VM interface {
 	// UEFI vars handling.
 	UEFIVars() (*api.InstanceUEFIVars, error)
 	UEFIVarsUpdate(newUEFIVarsSet api.InstanceUEFIVars) error
+
+	// Guest filesystem quiescing.
+	FreezeFilesystems() (func() error, error)
 }
 
 // CriuMigrationArgs arguments for CRIU migration.
@@ -609,6 +609,16 @@ func SuitableArchitectures(ctx context.Context, s *state.State, tx *db.ClusterTx
 				if err != nil {
 					return nil, err
 				}
+			} else if req.Source.Protocol == "oci" {
+				// OCI image store.
+				remote, err = lxd.ConnectOCI(req.Source.Server, &lxd.ConnectionArgs{
+					TLSServerCert: req.Source.Certificate,
+					UserAgent:     version.UserAgent,
+					Proxy:         s.Proxy,
+				})
+				if err != nil {
+					return nil, err
+				}
 			} else {
 				return nil, api.StatusErrorf(http.StatusBadRequest, "Unsupported remote image server protocol %q", req.Source.Protocol)
 			}
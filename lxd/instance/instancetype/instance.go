@@ -202,6 +202,103 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	//  shortdesc: What to do when evacuating the instance
 	"cluster.evacuate": validate.Optional(validate.IsOneOf("auto", "migrate", "live-migrate", "stop")),
 
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=cluster.rebalance)
+	// The `cluster.rebalance` allows the instance to be moved by the cluster rebalancer when the load of the
+	// cluster members is unbalanced.
+	//
+	// Available Modes:
+	//   - `none` *(default)*: The instance is never moved by the rebalancer.
+	//   - `auto`: The instance is live-migrated if possible, otherwise it is stopped, moved and started again.
+	//   - `live-migrate`: The instance is only moved if it can be live-migrated.
+	//   - `migrate`: The instance is stopped, moved and started again.
+	//
+	// See {ref}`clustering-rebalance` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `none`
+	//  liveupdate: yes
+	//  shortdesc: Whether the cluster rebalancer can move the instance
+	"cluster.rebalance": validate.Optional(validate.IsOneOf("none", "auto", "migrate", "live-migrate")),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=placement.group)
+	// Instances of the same project that share a placement group are placed on cluster members according to
+	// the group's {config:option}`instance-miscellaneous:placement.policy`.
+	// The rules are enforced when creating, moving, evacuating and restoring instances.
+	//
+	// See {ref}`clustering-instance-placement-groups` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Placement group of the instance
+	"placement.group": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=placement.policy)
+	// Possible values are `spread` (anti-affinity: no two instances of the group share a cluster member or failure domain)
+	// and `compact` (affinity: all instances of the group share the same cluster member or failure domain).
+	// ---
+	//  type: string
+	//  defaultdesc: `spread`
+	//  liveupdate: yes
+	//  shortdesc: How instances of the placement group are placed
+	"placement.policy": validate.Optional(validate.IsOneOf("spread", "compact")),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=placement.scope)
+	// Possible values are `member` and `failure-domain`.
+	// ---
+	//  type: string
+	//  defaultdesc: `member`
+	//  liveupdate: yes
+	//  shortdesc: Whether the placement policy applies to cluster members or failure domains
+	"placement.scope": validate.Optional(validate.IsOneOf("member", "failure-domain")),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=replication.target)
+	// Specify the URL of the LXD server or cluster that the instance is replicated to, for example
+	// `https://backup.example.com:8443`.
+	// The target server must trust the server certificate of this server (the cluster certificate in a cluster).
+	//
+	// See {ref}`instances-replication` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Server that the instance is replicated to
+	"replication.target": validate.Optional(validate.IsRequestURL),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=replication.target.certificate)
+	// Only required if the certificate of the target server isn't signed by a trusted certificate authority.
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: PEM encoded certificate of the replication target server
+	"replication.target.certificate": validate.Optional(validate.IsX509Certificate),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=replication.target.project)
+	//
+	// ---
+	//  type: string
+	//  defaultdesc: same project as the instance
+	//  liveupdate: yes
+	//  shortdesc: Project of the replication target server that the replica is created in
+	"replication.target.project": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=replication.schedule)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable replication (the default).
+	// ---
+	//  type: string
+	//  liveupdate: yes
+	//  shortdesc: Schedule for refreshing the replica of the instance
+	"replication.schedule": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"})),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=replication.replica)
+	// This option is set on the replicas created by the replication of an instance.
+	// A replica can't be started, and it is only refreshed by the replication while this option is set.
+	// Unset it to promote the replica.
+	// ---
+	//  type: bool
+	//  defaultdesc: `false`
+	//  liveupdate: yes
+	//  shortdesc: Whether the instance is a replica of an instance on another server
+	"replication.replica": validate.Optional(validate.IsBool),
+
 	// lxdmeta:generate(entities=instance; group=resource-limits; key=limits.cpu)
 	// A number or a specific range of CPUs to expose to the instance.
 	//
@@ -392,6 +489,21 @@ var InstanceConfigKeysAny = map[string]func(value string) error{
 	"volatile.last_state.power": validate.IsAny,
 	"volatile.last_state.ready": validate.IsBool,
 	"volatile.apply_quota":      validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.replication.last_success)
+	//
+	// ---
+	//  type: string
+	//  shortdesc: Time of the last successful replication of the instance
+	"volatile.replication.last_success": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.replication.last_error)
+	//
+	// ---
+	//  type: string
+	//  shortdesc: Error of the last failed replication of the instance
+	"volatile.replication.last_error": validate.IsAny,
+
 	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.uuid)
 	// The instance UUID is globally unique across all servers and projects.
 	// ---
@@ -874,6 +986,48 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: Whether to handle the `sysinfo` system call
 	"security.syscalls.intercept.sysinfo": validate.Optional(validate.IsBool),
 
+	// lxdmeta:generate(entities=instance; group=oci; key=oci.entrypoint)
+	// When set, the command is run as the container init process instead of `/sbin/init`.
+	// Arguments are separated by spaces and can be quoted.
+	//
+	// This option is set from the image configuration when creating a container from an OCI image.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Command to run as the init process of the container
+	"oci.entrypoint": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=oci; key=oci.cwd)
+	// This option is only used together with {config:option}`instance-oci:oci.entrypoint`.
+	// ---
+	//  type: string
+	//  defaultdesc: `/`
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Working directory of the entry point
+	"oci.cwd": validate.Optional(validate.IsAbsFilePath),
+
+	// lxdmeta:generate(entities=instance; group=oci; key=oci.uid)
+	// This option is only used together with {config:option}`instance-oci:oci.entrypoint`.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0`
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: User ID the entry point runs as
+	"oci.uid": validate.Optional(validate.IsUint32),
+
+	// lxdmeta:generate(entities=instance; group=oci; key=oci.gid)
+	// This option is only used together with {config:option}`instance-oci:oci.entrypoint`.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0`
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Group ID the entry point runs as
+	"oci.gid": validate.Optional(validate.IsUint32),
+
 	// lxdmeta:generate(entities=instance; group=volatile; key=volatile.last_state.idmap)
 	//
 	// ---
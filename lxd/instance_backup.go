@@ -20,7 +20,6 @@ import (
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
-	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/version"
@@ -41,6 +40,21 @@ import (
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -90,6 +104,21 @@ import (
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -146,7 +175,10 @@ func instanceBackupsGet(d *Daemon, r *http.Request) response.Response {
 		return resp
 	}
 
-	recursion := util.IsRecursionRequest(r)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
 
 	c, err := instance.LoadByProjectAndName(s, projectName, cname)
 	if err != nil {
@@ -158,21 +190,28 @@ func instanceBackupsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	resultString := []string{}
-	resultMap := []*api.InstanceBackup{}
+	backupNames := make([]string, 0, len(backups))
+	backupIndexes := make(map[string]int, len(backups))
+	for i, b := range backups {
+		backupName := strings.Split(b.Name(), "/")[1]
+		backupNames = append(backupNames, backupName)
+		backupIndexes[backupName] = i
+	}
 
-	for _, backup := range backups {
-		if !recursion {
-			url := fmt.Sprintf("/%s/instances/%s/backups/%s",
-				version.APIVersion, cname, strings.Split(backup.Name(), "/")[1])
+	resultNames, resultMap, err := listEntries(opts, backupNames, func(backupName string) (*api.InstanceBackup, error) {
+		return backups[backupIndexes[backupName]].Render(), nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !opts.recursion {
+		resultString := make([]string, 0, len(resultNames))
+		for _, backupName := range resultNames {
+			url := fmt.Sprintf("/%s/instances/%s/backups/%s", version.APIVersion, cname, backupName)
 			resultString = append(resultString, url)
-		} else {
-			render := backup.Render()
-			resultMap = append(resultMap, render)
 		}
-	}
 
-	if !recursion {
 		return response.SyncResponse(true, resultString)
 	}
 
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// instancePlacementGroupInstances returns the instances of the project that are part of the given placement group.
+func instancePlacementGroupInstances(ctx context.Context, tx *db.ClusterTx, projectName string, groupName string) ([]db.InstanceArgs, error) {
+	instances := []db.InstanceArgs{}
+
+	err := tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+		expandedConfig := instancetype.ExpandInstanceConfig(nil, inst.Config, inst.Profiles)
+		if expandedConfig["placement.group"] == groupName {
+			instances = append(instances, inst)
+		}
+
+		return nil
+	}, dbCluster.InstanceFilter{Project: &projectName})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instances of placement group %q: %w", groupName, err)
+	}
+
+	return instances, nil
+}
+
+// instancePlacementMemberDomains returns a map associating each cluster member name with its failure domain ID.
+func instancePlacementMemberDomains(ctx context.Context, tx *db.ClusterTx) (map[string]uint64, error) {
+	members, err := tx.GetNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting cluster members: %w", err)
+	}
+
+	domainsByAddress, err := tx.GetNodesFailureDomains(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting failure domains: %w", err)
+	}
+
+	domains := make(map[string]uint64, len(members))
+	for _, member := range members {
+		domains[member.Name] = domainsByAddress[member.Address]
+	}
+
+	return domains, nil
+}
+
+// instancePlacementFilter returns the candidate members satisfying the placement group rules found in the
+// expanded config of an instance, given the cluster members hosting the other instances of its group and the
+// failure domain of each cluster member.
+func instancePlacementFilter(config map[string]string, groupLocations []string, domains map[string]uint64, candidates []db.NodeInfo) ([]db.NodeInfo, error) {
+	groupName := config["placement.group"]
+	if groupName == "" {
+		return candidates, nil
+	}
+
+	policy := config["placement.policy"]
+	if policy == "" {
+		policy = "spread"
+	}
+
+	scope := config["placement.scope"]
+	if scope == "" {
+		scope = "member"
+	}
+
+	// The key identifying a placement location depends on the scope of the rule.
+	locationKey := func(memberName string) string {
+		if scope == "failure-domain" {
+			return fmt.Sprintf("%d", domains[memberName])
+		}
+
+		return memberName
+	}
+
+	used := make(map[string]bool, len(groupLocations))
+	for _, memberName := range groupLocations {
+		used[locationKey(memberName)] = true
+	}
+
+	// An empty group can be placed anywhere.
+	if policy == "compact" && len(used) == 0 {
+		return candidates, nil
+	}
+
+	filtered := make([]db.NodeInfo, 0, len(candidates))
+	for _, candidate := range candidates {
+		if used[locationKey(candidate.Name)] == (policy == "compact") {
+			filtered = append(filtered, candidate)
+		}
+	}
+
+	if len(filtered) == 0 {
+		location := "cluster member"
+		if scope == "failure-domain" {
+			location = "failure domain"
+		}
+
+		if policy == "compact" {
+			return nil, api.StatusErrorf(http.StatusNotFound, "No suitable cluster member could be found: placement group %q requires the same %s as its other instances", groupName, location)
+		}
+
+		return nil, api.StatusErrorf(http.StatusNotFound, "No suitable cluster member could be found: placement group %q requires a %s not hosting any of its other instances", groupName, location)
+	}
+
+	return filtered, nil
+}
+
+// instancePlacementCandidates returns the candidate members satisfying the placement group rules of the instance.
+// The optional location function overrides the cluster member considered for the other instances of the group,
+// an empty location meaning the instance isn't taken into account.
+func instancePlacementCandidates(ctx context.Context, tx *db.ClusterTx, projectName string, instanceName string, config map[string]string, candidates []db.NodeInfo, location func(inst db.InstanceArgs) string) ([]db.NodeInfo, error) {
+	groupName := config["placement.group"]
+	if groupName == "" {
+		return candidates, nil
+	}
+
+	instances, err := instancePlacementGroupInstances(ctx, tx, projectName, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	groupLocations := make([]string, 0, len(instances))
+	for _, inst := range instances {
+		if inst.Name == instanceName {
+			continue
+		}
+
+		memberName := inst.Node
+		if location != nil {
+			memberName = location(inst)
+		}
+
+		if memberName != "" {
+			groupLocations = append(groupLocations, memberName)
+		}
+	}
+
+	domains, err := instancePlacementMemberDomains(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return instancePlacementFilter(config, groupLocations, domains, candidates)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestInstancePlacementFilter(t *testing.T) {
+	candidates := []db.NodeInfo{{Name: "m1"}, {Name: "m2"}, {Name: "m3"}, {Name: "m4"}}
+	domains := map[string]uint64{"m1": 0, "m2": 0, "m3": 1, "m4": 2}
+
+	names := func(members []db.NodeInfo) []string {
+		result := []string{}
+		for _, member := range members {
+			result = append(result, member.Name)
+		}
+
+		return result
+	}
+
+	// Instances without placement group can go anywhere.
+	members, err := instancePlacementFilter(map[string]string{}, []string{"m1"}, domains, candidates)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m1", "m2", "m3", "m4"}, names(members))
+
+	// Spread across members by default.
+	members, err = instancePlacementFilter(map[string]string{"placement.group": "web"}, []string{"m1", "m3"}, domains, candidates)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m2", "m4"}, names(members))
+
+	// Spread across failure domains.
+	members, err = instancePlacementFilter(map[string]string{"placement.group": "web", "placement.scope": "failure-domain"}, []string{"m1", "m3"}, domains, candidates)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m4"}, names(members))
+
+	// Compact on a member.
+	members, err = instancePlacementFilter(map[string]string{"placement.group": "db", "placement.policy": "compact"}, []string{"m2"}, domains, candidates)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m2"}, names(members))
+
+	// Compact on a failure domain.
+	members, err = instancePlacementFilter(map[string]string{"placement.group": "db", "placement.policy": "compact", "placement.scope": "failure-domain"}, []string{"m2"}, domains, candidates)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m1", "m2"}, names(members))
+
+	// The first instance of a compact group can go anywhere.
+	members, err = instancePlacementFilter(map[string]string{"placement.group": "db", "placement.policy": "compact"}, nil, domains, candidates)
+	require.NoError(t, err)
+	assert.Len(t, members, 4)
+
+	// No member satisfying the rules.
+	_, err = instancePlacementFilter(map[string]string{"placement.group": "web", "placement.scope": "failure-domain"}, []string{"m1", "m3", "m4"}, domains, candidates)
+	assert.True(t, api.StatusErrorCheck(err, 404))
+
+	_, err = instancePlacementFilter(map[string]string{"placement.group": "db", "placement.policy": "compact"}, []string{"m2"}, domains, candidates[2:])
+	assert.True(t, api.StatusErrorCheck(err, 404))
+}
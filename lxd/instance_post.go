@@ -223,6 +223,18 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 				if err != nil {
 					return err
 				}
+
+				// Only keep the members satisfying the placement group rules of the instance.
+				candidateMembers, err = instancePlacementCandidates(ctx, tx, projectName, name, inst.ExpandedConfig(), candidateMembers, nil)
+				if err != nil {
+					return err
+				}
+			} else {
+				// Check that the targeted member satisfies the placement group rules of the instance.
+				_, err = instancePlacementCandidates(ctx, tx, projectName, name, inst.ExpandedConfig(), []db.NodeInfo{*targetMemberInfo}, nil)
+				if err != nil {
+					return err
+				}
 			}
 
 			return nil
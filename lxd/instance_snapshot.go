@@ -26,6 +26,7 @@ import (
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/validate"
 	"github.com/canonical/lxd/shared/version"
 )
@@ -45,6 +46,21 @@ import (
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -94,6 +110,21 @@ import (
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -150,11 +181,15 @@ func instanceSnapshotsGet(d *Daemon, r *http.Request) response.Response {
 		return resp
 	}
 
-	recursion := util.IsRecursionRequest(r)
-	resultString := []string{}
-	resultMap := []*api.InstanceSnapshot{}
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	var snapNames []string
+	snapsByName := map[string]instance.Instance{}
 
-	if !recursion {
+	if !opts.recursion && !opts.filtered() {
 		var snaps []string
 
 		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -170,13 +205,7 @@ func instanceSnapshotsGet(d *Daemon, r *http.Request) response.Response {
 
 		for _, snap := range snaps {
 			_, snapName, _ := api.GetParentAndSnapshotName(snap)
-			if projectName == api.ProjectDefaultName {
-				url := fmt.Sprintf("/%s/instances/%s/snapshots/%s", version.APIVersion, cname, snapName)
-				resultString = append(resultString, url)
-			} else {
-				url := fmt.Sprintf("/%s/instances/%s/snapshots/%s?project=%s", version.APIVersion, cname, snapName, projectName)
-				resultString = append(resultString, url)
-			}
+			snapNames = append(snapNames, snapName)
 		}
 	} else {
 		c, err := instance.LoadByProjectAndName(s, projectName, cname)
@@ -190,16 +219,38 @@ func instanceSnapshotsGet(d *Daemon, r *http.Request) response.Response {
 		}
 
 		for _, snap := range snaps {
-			render, _, err := snap.Render(storagePools.RenderSnapshotUsage(s, snap))
-			if err != nil {
-				continue
-			}
+			_, snapName, _ := api.GetParentAndSnapshotName(snap.Name())
+			snapNames = append(snapNames, snapName)
+			snapsByName[snapName] = snap
+		}
+	}
+
+	resultNames, resultMap, err := listEntries(opts, snapNames, func(snapName string) (*api.InstanceSnapshot, error) {
+		snap := snapsByName[snapName]
 
-			resultMap = append(resultMap, render.(*api.InstanceSnapshot))
+		render, _, err := snap.Render(storagePools.RenderSnapshotUsage(s, snap))
+		if err != nil {
+			return nil, nil
 		}
+
+		return render.(*api.InstanceSnapshot), nil
+	})
+	if err != nil {
+		return response.SmartError(err)
 	}
 
-	if !recursion {
+	if !opts.recursion {
+		resultString := make([]string, 0, len(resultNames))
+		for _, snapName := range resultNames {
+			if projectName == api.ProjectDefaultName {
+				url := fmt.Sprintf("/%s/instances/%s/snapshots/%s", version.APIVersion, cname, snapName)
+				resultString = append(resultString, url)
+			} else {
+				url := fmt.Sprintf("/%s/instances/%s/snapshots/%s?project=%s", version.APIVersion, cname, snapName, projectName)
+				resultString = append(resultString, url)
+			}
+		}
+
 		return response.SyncResponse(true, resultString)
 	}
 
@@ -267,7 +318,7 @@ func instanceSnapshotsPost(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
-		err = project.AllowSnapshotCreation(p)
+		err = project.AllowSnapshotCreation(tx, p)
 		if err != nil {
 			return err
 		}
@@ -330,6 +381,13 @@ func instanceSnapshotsPost(d *Daemon, r *http.Request) response.Response {
 
 	snapshot := func(op *operations.Operation) error {
 		inst.SetOperation(op)
+
+		// Stateful snapshots include the memory state so only stateless ones need the guest quiesced.
+		if !req.Stateful {
+			thaw := instanceFreezeFilesystems(inst, op)
+			defer thaw()
+		}
+
 		return inst.Snapshot(req.Name, expiry, req.Stateful)
 	}
 
@@ -349,6 +407,48 @@ func instanceSnapshotsPost(d *Daemon, r *http.Request) response.Response {
 	return operations.OperationResponse(op)
 }
 
+// instanceFreezeFilesystems attempts to freeze the guest filesystems of a running VM through the lxd-agent so
+// that a storage snapshot taken before the returned thaw function is called is consistent rather than only
+// crash consistent. Failing to freeze isn't fatal. Whether it succeeded is recorded in the operation metadata.
+func instanceFreezeFilesystems(inst instance.Instance, op *operations.Operation) func() {
+	if inst.Type() != instancetype.VM || !inst.IsRunning() || inst.IsFrozen() {
+		return func() {}
+	}
+
+	vm, ok := inst.(instance.VM)
+	if !ok {
+		return func() {}
+	}
+
+	l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name()})
+
+	thaw, err := vm.FreezeFilesystems()
+	if err != nil {
+		l.Warn("Unable to freeze guest filesystems, storage will only be crash consistent", logger.Ctx{"err": err})
+	}
+
+	if op != nil {
+		meta := op.Metadata()
+		if meta == nil {
+			meta = make(map[string]any)
+		}
+
+		meta["filesystems_frozen"] = err == nil
+		_ = op.UpdateMetadata(meta)
+	}
+
+	return func() {
+		if thaw == nil {
+			return
+		}
+
+		err := thaw()
+		if err != nil {
+			l.Error("Failed thawing guest filesystems", logger.Ctx{"err": err})
+		}
+	}
+}
+
 func instanceSnapshotHandler(d *Daemon, r *http.Request) response.Response {
 	s := d.State()
 
@@ -26,7 +26,6 @@ import (
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
-	"github.com/canonical/lxd/shared/filter"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/version"
 )
@@ -75,6 +74,16 @@ func urlInstanceTypeDetect(r *http.Request) (instancetype.Type, error) {
 //      name: all-projects
 //      description: Retrieve instances from all projects
 //      type: boolean
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -133,6 +142,16 @@ func urlInstanceTypeDetect(r *http.Request) (instancetype.Type, error) {
 //      name: all-projects
 //      description: Retrieve instances from all projects
 //      type: boolean
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -190,6 +209,16 @@ func urlInstanceTypeDetect(r *http.Request) (instancetype.Type, error) {
 //      name: all-projects
 //      description: Retrieve instances from all projects
 //      type: boolean
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -257,13 +286,14 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 		recursion = 0
 	}
 
-	// Parse filter value.
-	filterStr := r.FormValue("filter")
-	clauses, err := filter.Parse(filterStr, filter.QueryOperatorSet())
+	// Parse filter and pagination values.
+	opts, err := parseListOptions(r)
 	if err != nil {
-		return nil, fmt.Errorf("Invalid filter: %w", err)
+		return nil, api.StatusErrorf(http.StatusBadRequest, "%w", err)
 	}
 
+	clauses := opts.clauses
+
 	mustLoadObjects := recursion > 0 || (recursion == 0 && clauses != nil && len(clauses.Clauses) > 0)
 
 	// Detect project mode.
@@ -327,6 +357,48 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 		memberAddressInstances[address] = filteredInstances
 	}
 
+	// Instances of different projects can have the same name so the project is part of the cursor.
+	instanceKey := func(projectName string, instanceName string) string {
+		if allProjects {
+			return projectName + "/" + instanceName
+		}
+
+		return instanceName
+	}
+
+	// Without a filter, the requested page is known from the instance names alone so only its instances are
+	// loaded. Filtered lists are paginated once loaded as the filter may match on any field.
+	var pageKeys map[string]bool
+	if opts.paginated() && !opts.filtered() {
+		keys := []string{}
+		for _, instances := range memberAddressInstances {
+			for _, inst := range instances {
+				keys = append(keys, instanceKey(inst.Project, inst.Name))
+			}
+		}
+
+		pageKeys = make(map[string]bool, len(keys))
+		for _, key := range listKeys(&listOptions{limit: opts.limit, after: opts.after}, keys) {
+			pageKeys[key] = true
+		}
+
+		for address, instances := range memberAddressInstances {
+			var pageInstances []db.Instance
+			for _, inst := range instances {
+				if pageKeys[instanceKey(inst.Project, inst.Name)] {
+					pageInstances = append(pageInstances, inst)
+				}
+			}
+
+			if len(pageInstances) == 0 {
+				delete(memberAddressInstances, address)
+				continue
+			}
+
+			memberAddressInstances[address] = pageInstances
+		}
+	}
+
 	resultErrListAppend := func(inst db.Instance, err error) {
 		instFull := &api.InstanceFull{
 			Instance: api.Instance{
@@ -345,6 +417,11 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 	}
 
 	resultFullListAppend := func(instFull *api.InstanceFull) {
+		// Instances fetched from other members may not be part of the requested page.
+		if instFull != nil && pageKeys != nil && !pageKeys[instanceKey(instFull.Project, instFull.Name)] {
+			return
+		}
+
 		if instFull != nil {
 			resultMu.Lock()
 			resultFullList = append(resultFullList, instFull)
@@ -431,17 +508,28 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 
 			hostInterfaces, _ := net.Interfaces()
 
-			// Get the local instances.
+			// Get the local instances, only loading the ones of the requested page if known.
 			localInstancesByID := make(map[int64]instance.Instance)
-			for _, projectName := range filteredProjects {
-				insts, err := instanceLoadNodeProjectAll(r.Context(), s, projectName, instanceType)
-				if err != nil {
-					return nil, fmt.Errorf("Failed loading instances for project %q: %w", projectName, err)
-				}
+			if pageKeys != nil {
+				for _, dbInst := range instances {
+					inst, err := instance.LoadByProjectAndName(s, dbInst.Project, dbInst.Name)
+					if err != nil {
+						return nil, fmt.Errorf("Failed loading instance %q in project %q: %w", dbInst.Name, dbInst.Project, err)
+					}
 
-				for _, inst := range insts {
 					localInstancesByID[int64(inst.ID())] = inst
 				}
+			} else {
+				for _, projectName := range filteredProjects {
+					insts, err := instanceLoadNodeProjectAll(r.Context(), s, projectName, instanceType)
+					if err != nil {
+						return nil, fmt.Errorf("Failed loading instances for project %q: %w", projectName, err)
+					}
+
+					for _, inst := range insts {
+						localInstancesByID[int64(inst.ID())] = inst
+					}
+				}
 			}
 
 			queue := make(chan db.Instance, threads)
@@ -510,6 +598,13 @@ func doInstancesGet(s *state.State, r *http.Request) (any, error) {
 		}
 	}
 
+	resultFullList, err = listPage(opts.pageOnly(), resultFullList, func(inst *api.InstanceFull) string {
+		return instanceKey(inst.Project, inst.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	if recursion == 0 {
 		resultList := make([]string, 0, len(resultFullList))
 		for i := range resultFullList {
@@ -182,7 +182,7 @@ func createFromNone(s *state.State, r *http.Request, projectName string, profile
 
 	run := func(op *operations.Operation) error {
 		// Actually create the instance.
-		_, err := instanceCreateAsEmpty(s, args)
+		_, err := instanceCreateAsEmpty(s, args, op)
 		if err != nil {
 			return err
 		}
@@ -276,6 +276,8 @@ func createFromMigration(s *state.State, r *http.Request, projectName string, pr
 
 	instanceOnly := req.Source.InstanceOnly || req.Source.ContainerOnly
 
+	// Only a newly created instance is removed if the operation is interrupted by a restart.
+	created := inst == nil
 	if inst == nil {
 		_, err := storagePools.LoadByName(s, storagePool)
 		if err != nil {
@@ -372,6 +374,13 @@ func createFromMigration(s *state.State, r *http.Request, projectName string, pr
 		}
 	}
 
+	if created {
+		err = operationAddInstanceRollback(op, inst)
+		if err != nil {
+			return response.InternalError(err)
+		}
+	}
+
 	revert.Success()
 	return operations.OperationResponse(op)
 }
@@ -478,6 +487,11 @@ func createFromConversion(s *state.State, r *http.Request, projectName string, p
 		return response.InternalError(err)
 	}
 
+	err = operationAddInstanceRollback(op, inst)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
 	revert.Success()
 	return operations.OperationResponse(op)
 }
@@ -621,6 +635,7 @@ func createFromCopy(s *state.State, r *http.Request, projectName string, profile
 			refresh:              req.Source.Refresh,
 			applyTemplateTrigger: true,
 			allowInconsistent:    req.Source.AllowInconsistent,
+			rollback:             true,
 		}, op)
 		if err != nil {
 			return err
@@ -841,6 +856,11 @@ func createFromBackup(s *state.State, r *http.Request, projectName string, data
 		// Clean up created instance if the post hook fails below.
 		runRevert.Add(func() { _ = inst.Delete(true) })
 
+		err = operationAddInstanceRollback(op, inst)
+		if err != nil {
+			return err
+		}
+
 		// Run the storage post hook to perform any final actions now that the instance has been created
 		// in the database (this normally includes unmounting volumes that were mounted).
 		if postHook != nil {
@@ -1239,9 +1259,23 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 				return err
 			}
 
+			// Only keep the members satisfying the placement group rules of the instance.
+			candidateMembers, err = instancePlacementCandidates(ctx, tx, targetProjectName, req.Name, instancetype.ExpandInstanceConfig(nil, req.Config, profiles), candidateMembers, nil)
+			if err != nil {
+				return err
+			}
+
 			return nil
 		}
 
+		if s.ServerClustered && !clusterNotification {
+			// Check that the targeted member satisfies the placement group rules of the instance.
+			_, err = instancePlacementCandidates(ctx, tx, targetProjectName, req.Name, instancetype.ExpandInstanceConfig(nil, req.Config, profiles), []db.NodeInfo{*targetMemberInfo}, nil)
+			if err != nil {
+				return err
+			}
+		}
+
 		if !clusterNotification {
 			// Check that the project's limits are not violated. Note this check is performed after
 			// automatically generated config values (such as ones from an InstanceType) have been set.
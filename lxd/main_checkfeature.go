@@ -579,7 +579,11 @@ static bool kernel_supports_idmapped_mounts(void)
 import "C"
 
 import (
+	"os/exec"
+	"strings"
+
 	_ "github.com/canonical/lxd/lxd/include" // Used by cgo
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/logger"
 )
 
@@ -634,3 +638,39 @@ func canUsePidFdSetns() bool {
 func canUseCoreScheduling() bool {
 	return bool(C.core_scheduling_aware)
 }
+
+// canUseCRIU returns the version of the installed CRIU and whether it reports being able to
+// checkpoint and restore processes with the running kernel.
+func canUseCRIU() (string, bool) {
+	_, err := exec.LookPath("criu")
+	if err != nil {
+		return "", false
+	}
+
+	version := ""
+	out, err := shared.RunCommand("criu", "--version")
+	if err == nil {
+		for _, line := range strings.Split(out, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && fields[0] == "Version:" {
+				version = fields[1]
+				break
+			}
+		}
+	}
+
+	_, err = shared.RunCommand("criu", "check")
+	if err != nil {
+		logger.Debug("CRIU kernel support check failed", logger.Ctx{"err": err})
+		return version, false
+	}
+
+	return version, true
+}
+
+// canUseCRIUMemDirtyTracking returns whether CRIU can track dirty memory pages, which is required
+// for iterative pre-copy dumps during live migration.
+func canUseCRIUMemDirtyTracking() bool {
+	_, err := shared.RunCommand("criu", "check", "--feature", "mem_dirty_track")
+	return err == nil
+}
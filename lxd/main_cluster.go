@@ -3,11 +3,13 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/canonical/go-dqlite/client"
 	"github.com/spf13/cobra"
@@ -17,6 +19,7 @@ import (
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/node"
 	"github.com/canonical/lxd/lxd/sys"
 	"github.com/canonical/lxd/lxd/util"
@@ -56,6 +59,14 @@ func (c *cmdCluster) Command() *cobra.Command {
 	clusterShow := cmdClusterShow{global: c.global}
 	cmd.AddCommand(clusterShow.Command())
 
+	// Back up the cluster database.
+	backupDatabase := cmdClusterBackupDatabase{global: c.global}
+	cmd.AddCommand(backupDatabase.Command())
+
+	// Restore the cluster database.
+	restoreDatabase := cmdClusterRestoreDatabase{global: c.global}
+	cmd.AddCommand(restoreDatabase.Command())
+
 	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
 	cmd.Args = cobra.NoArgs
 	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
@@ -502,3 +513,168 @@ Do you want to proceed? (yes/no): `)
 
 	return nil
 }
+
+type cmdClusterBackupDatabase struct {
+	global *cmdGlobal
+}
+
+func (c *cmdClusterBackupDatabase) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "backup-database"
+	cmd.Short = "Back up the cluster database"
+	cmd.Long = `Description:
+  Back up the cluster database
+
+  This writes a consistent snapshot of the global database, along with the
+  cluster certificate, to the database backups directory and prints its path.
+  The backup can be restored with "lxd cluster restore-database".
+`
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdClusterBackupDatabase) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		_ = cmd.Help()
+		return fmt.Errorf("Invalid arguments")
+	}
+
+	client, err := lxd.ConnectLXDUnix("", &lxd.ConnectionArgs{SkipGetServer: true})
+	if err != nil {
+		return fmt.Errorf("Failed to connect to LXD daemon: %w", err)
+	}
+
+	response, _, err := client.RawQuery("POST", "/internal/cluster/database-backup", nil, "")
+	if err != nil {
+		return err
+	}
+
+	backup := internalClusterDatabaseBackup{}
+	err = json.Unmarshal(response.Metadata, &backup)
+	if err != nil {
+		return fmt.Errorf("Failed to parse backup response: %w", err)
+	}
+
+	fmt.Println(backup.Path)
+
+	return nil
+}
+
+type cmdClusterRestoreDatabase struct {
+	global             *cmdGlobal
+	flagNonInteractive bool
+}
+
+func (c *cmdClusterRestoreDatabase) Command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "restore-database <backup>"
+	cmd.Short = "Restore the cluster database from a backup"
+	cmd.Long = `Description:
+  Restore the cluster database from a backup
+
+  This restores the cluster certificate and stages the global database of a
+  backup made with "lxd cluster backup-database". The database is replaced
+  the next time the LXD daemon starts.
+`
+
+	cmd.RunE = c.Run
+
+	cmd.Flags().BoolVarP(&c.flagNonInteractive, "quiet", "q", false, "Don't require user confirmation")
+
+	return cmd
+}
+
+func (c *cmdClusterRestoreDatabase) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		_ = cmd.Help()
+		return fmt.Errorf("Missing required arguments")
+	}
+
+	// Make sure that the daemon is not running.
+	_, err := lxd.ConnectLXDUnix("", nil)
+	if err == nil {
+		return fmt.Errorf("The LXD daemon is running, please stop it first.")
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("Failed to open backup: %w", err)
+	}
+
+	defer func() { _ = file.Close() }()
+
+	metadata, files, err := clusterDatabaseBackupRead(file)
+	if err != nil {
+		return err
+	}
+
+	if metadata.Schema > dbCluster.SchemaVersion {
+		return fmt.Errorf("The backup was made by a newer version of LXD (%s)", metadata.Version)
+	}
+
+	// Prompt for confirmation unless --quiet was passed.
+	if !c.flagNonInteractive {
+		err := c.promptConfirmation(metadata)
+		if err != nil {
+			return err
+		}
+	}
+
+	varDir := sys.DefaultOS().VarDir
+
+	for _, certName := range clusterDatabaseBackupCertificates {
+		content, ok := files[certName]
+		if !ok {
+			continue
+		}
+
+		path := filepath.Join(varDir, certName)
+
+		// Keep the current file around.
+		if shared.PathExists(path) {
+			err = os.Rename(path, path+".bak")
+			if err != nil {
+				return fmt.Errorf("Failed to back up %q: %w", path, err)
+			}
+		}
+
+		err = os.WriteFile(path, content, 0600)
+		if err != nil {
+			return fmt.Errorf("Failed to restore %q: %w", path, err)
+		}
+	}
+
+	err = os.WriteFile(filepath.Join(varDir, "database", "restore.global.sql"), files["global.sql"], 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to stage the global database: %w", err)
+	}
+
+	fmt.Println("The global database will be restored the next time LXD starts.")
+
+	return nil
+}
+
+func (c *cmdClusterRestoreDatabase) promptConfirmation(metadata *clusterDatabaseBackupMetadata) error {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf(`This backup of the cluster database was made by cluster member %q
+with LXD %s on %s.
+
+Restoring it replaces all the content of the cluster database, for all the
+cluster members, with the content of the backup. Any change made since the
+backup was made is lost.
+
+The cluster certificate is also restored, the current one is kept with a
+".bak" extension.
+
+Do you want to proceed? (yes/no): `, metadata.Member, metadata.Version, metadata.CreatedAt.Format(time.RFC3339))
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSuffix(input, "\n")
+
+	if !shared.ValueInSlice(strings.ToLower(input), []string{"yes"}) {
+		return fmt.Errorf("Restore operation aborted")
+	}
+
+	return nil
+}
@@ -5,14 +5,12 @@ import (
 	"fmt"
 	"io"
 	"net/url"
-	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/instance/operationlock"
-	"github.com/canonical/lxd/lxd/migration"
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/shared"
@@ -40,9 +38,14 @@ func newMigrationSource(inst instance.Instance, stateful bool, instanceOnly bool
 	secretNames := []string{api.SecretNameControl, api.SecretNameFilesystem}
 	if stateful && inst.IsRunning() {
 		if inst.Type() == instancetype.Container {
-			_, err := exec.LookPath("criu")
+			ct, ok := inst.(instance.Container)
+			if !ok {
+				return nil, fmt.Errorf("Invalid instance type")
+			}
+
+			err := ct.CheckpointCheck()
 			if err != nil {
-				return nil, migration.ErrNoLiveMigrationSource
+				return nil, fmt.Errorf("Unable to perform live container migration on the source server: %w", err)
 			}
 		}
 
@@ -174,9 +177,14 @@ func newMigrationSink(args *migrationSinkArgs) (*migrationSink, error) {
 	secretNames := []string{api.SecretNameControl, api.SecretNameFilesystem}
 	if sink.live {
 		if sink.instance.Type() == instancetype.Container {
-			_, err := exec.LookPath("criu")
+			ct, ok := sink.instance.(instance.Container)
+			if !ok {
+				return nil, fmt.Errorf("Invalid instance type")
+			}
+
+			err := ct.CheckpointCheck()
 			if err != nil {
-				return nil, migration.ErrNoLiveMigrationTarget
+				return nil, fmt.Errorf("Unable to perform live container migration on the target server: %w", err)
 			}
 		}
 
@@ -109,8 +109,5 @@ const (
 	toMigrateLive       = "To migrate the container, stop the container before migration or install CRIU"
 )
 
-var (
-	ErrNoLiveMigrationSource = fmt.Errorf("%s CRIU isn't installed on the source server. %s on the source server", unableToLiveMigrate, toMigrateLive)
-	ErrNoLiveMigrationTarget = fmt.Errorf("%s CRIU isn't installed on the target server. %s on the target server", unableToLiveMigrate, toMigrateLive)
-	ErrNoLiveMigration       = fmt.Errorf("%s CRIU isn't installed. %s", unableToLiveMigrate, toMigrateLive)
-)
+// ErrNoLiveMigration is returned when CRIU isn't available to checkpoint or restore a container.
+var ErrNoLiveMigration = fmt.Errorf("%s CRIU isn't installed or lacks kernel support. %s", unableToLiveMigrate, toMigrateLive)
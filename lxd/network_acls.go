@@ -66,6 +66,21 @@ var networkACLLogCmd = APIEndpoint{
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -115,6 +130,21 @@ var networkACLLogCmd = APIEndpoint{
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -151,7 +181,10 @@ func networkACLsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	recursion := util.IsRecursionRequest(r)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
 
 	var aclNames []string
 
@@ -173,30 +206,35 @@ func networkACLsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	resultString := []string{}
-	resultMap := []api.NetworkACL{}
+	allowedNames := make([]string, 0, len(aclNames))
 	for _, aclName := range aclNames {
-		if !userHasPermission(entity.NetworkACLURL(projectName, aclName)) {
-			continue
+		if userHasPermission(entity.NetworkACLURL(projectName, aclName)) {
+			allowedNames = append(allowedNames, aclName)
 		}
+	}
 
-		if !recursion {
-			resultString = append(resultString, fmt.Sprintf("/%s/network-acls/%s", version.APIVersion, aclName))
-		} else {
-			netACL, err := acl.LoadByName(s, projectName, aclName)
-			if err != nil {
-				continue
-			}
+	resultNames, resultMap, err := listEntries(opts, allowedNames, func(aclName string) (*api.NetworkACL, error) {
+		netACL, err := acl.LoadByName(s, projectName, aclName)
+		if err != nil {
+			return nil, nil
+		}
 
-			netACLInfo := netACL.Info()
-			netACLInfo.UsedBy, _ = netACL.UsedBy() // Ignore errors in UsedBy, will return nil.
-			netACLInfo.UsedBy = project.FilterUsedBy(s.Authorizer, r, netACLInfo.UsedBy)
+		netACLInfo := netACL.Info()
+		netACLInfo.UsedBy, _ = netACL.UsedBy() // Ignore errors in UsedBy, will return nil.
+		netACLInfo.UsedBy = project.FilterUsedBy(s.Authorizer, r, netACLInfo.UsedBy)
 
-			resultMap = append(resultMap, *netACLInfo)
-		}
+		return netACLInfo, nil
+	})
+	if err != nil {
+		return response.SmartError(err)
 	}
 
-	if !recursion {
+	if !opts.recursion {
+		resultString := make([]string, 0, len(resultNames))
+		for _, aclName := range resultNames {
+			resultString = append(resultString, fmt.Sprintf("/%s/network-acls/%s", version.APIVersion, aclName))
+		}
+
 		return response.SyncResponse(true, resultString)
 	}
 
@@ -17,7 +17,6 @@ import (
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
-	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/version"
@@ -56,6 +55,21 @@ var networkForwardCmd = APIEndpoint{
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -105,6 +119,21 @@ var networkForwardCmd = APIEndpoint{
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -162,7 +191,16 @@ func networkForwardsGet(d *Daemon, r *http.Request) response.Response {
 
 	memberSpecific := false // Get forwards for all cluster members.
 
-	if util.IsRecursionRequest(r) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	forwardURL := func(listenAddress string) string {
+		return fmt.Sprintf("/%s/networks/%s/forwards/%s", version.APIVersion, url.PathEscape(n.Name()), url.PathEscape(listenAddress))
+	}
+
+	if opts.recursion || opts.filtered() {
 		var records map[int64]*api.NetworkForward
 
 		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -174,12 +212,26 @@ func networkForwardsGet(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(fmt.Errorf("Failed loading network forwards: %w", err))
 		}
 
-		forwards := make([]*api.NetworkForward, 0, len(records))
+		forwards := make([]api.NetworkForward, 0, len(records))
 		for _, record := range records {
-			forwards = append(forwards, record)
+			forwards = append(forwards, *record)
+		}
+
+		forwards, err = listPage(opts, forwards, func(forward api.NetworkForward) string { return forward.ListenAddress })
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if opts.recursion {
+			return response.SyncResponse(true, forwards)
 		}
 
-		return response.SyncResponse(true, forwards)
+		forwardURLs := make([]string, 0, len(forwards))
+		for _, forward := range forwards {
+			forwardURLs = append(forwardURLs, forwardURL(forward.ListenAddress))
+		}
+
+		return response.SyncResponse(true, forwardURLs)
 	}
 
 	var listenAddresses map[int64]string
@@ -193,9 +245,14 @@ func networkForwardsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Failed loading network forwards: %w", err))
 	}
 
-	forwardURLs := make([]string, 0, len(listenAddresses))
+	addresses := make([]string, 0, len(listenAddresses))
 	for _, listenAddress := range listenAddresses {
-		forwardURLs = append(forwardURLs, fmt.Sprintf("/%s/networks/%s/forwards/%s", version.APIVersion, url.PathEscape(n.Name()), url.PathEscape(listenAddress)))
+		addresses = append(addresses, listenAddress)
+	}
+
+	forwardURLs := make([]string, 0, len(addresses))
+	for _, listenAddress := range listKeys(opts, addresses) {
+		forwardURLs = append(forwardURLs, forwardURL(listenAddress))
 	}
 
 	return response.SyncResponse(true, forwardURLs)
@@ -17,7 +17,6 @@ import (
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
-	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/version"
@@ -56,6 +55,21 @@ var networkLoadBalancerCmd = APIEndpoint{
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -105,6 +119,21 @@ var networkLoadBalancerCmd = APIEndpoint{
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -162,7 +191,16 @@ func networkLoadBalancersGet(d *Daemon, r *http.Request) response.Response {
 
 	memberSpecific := false // Get load balancers for all cluster members.
 
-	if util.IsRecursionRequest(r) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	loadBalancerURL := func(listenAddress string) string {
+		return api.NewURL().Path(version.APIVersion, "networks", n.Name(), "load-balancers", listenAddress).String()
+	}
+
+	if opts.recursion || opts.filtered() {
 		var records map[int64]*api.NetworkLoadBalancer
 
 		err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -174,12 +212,26 @@ func networkLoadBalancersGet(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(fmt.Errorf("Failed loading network load balancers: %w", err))
 		}
 
-		loadBalancers := make([]*api.NetworkLoadBalancer, 0, len(records))
+		loadBalancers := make([]api.NetworkLoadBalancer, 0, len(records))
 		for _, record := range records {
-			loadBalancers = append(loadBalancers, record)
+			loadBalancers = append(loadBalancers, *record)
+		}
+
+		loadBalancers, err = listPage(opts, loadBalancers, func(loadBalancer api.NetworkLoadBalancer) string { return loadBalancer.ListenAddress })
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if opts.recursion {
+			return response.SyncResponse(true, loadBalancers)
 		}
 
-		return response.SyncResponse(true, loadBalancers)
+		loadBalancerURLs := make([]string, 0, len(loadBalancers))
+		for _, loadBalancer := range loadBalancers {
+			loadBalancerURLs = append(loadBalancerURLs, loadBalancerURL(loadBalancer.ListenAddress))
+		}
+
+		return response.SyncResponse(true, loadBalancerURLs)
 	}
 
 	var listenAddresses map[int64]string
@@ -193,10 +245,14 @@ func networkLoadBalancersGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Failed loading network load balancers: %w", err))
 	}
 
-	loadBalancerURLs := make([]string, 0, len(listenAddresses))
+	addresses := make([]string, 0, len(listenAddresses))
 	for _, listenAddress := range listenAddresses {
-		u := api.NewURL().Path(version.APIVersion, "networks", n.Name(), "load-balancers", listenAddress)
-		loadBalancerURLs = append(loadBalancerURLs, u.String())
+		addresses = append(addresses, listenAddress)
+	}
+
+	loadBalancerURLs := make([]string, 0, len(addresses))
+	for _, listenAddress := range listKeys(opts, addresses) {
+		loadBalancerURLs = append(loadBalancerURLs, loadBalancerURL(listenAddress))
 	}
 
 	return response.SyncResponse(true, loadBalancerURLs)
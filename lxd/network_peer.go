@@ -16,7 +16,6 @@ import (
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
-	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/version"
@@ -55,6 +54,21 @@ var networkPeerCmd = APIEndpoint{
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -104,6 +118,21 @@ var networkPeerCmd = APIEndpoint{
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -159,7 +188,16 @@ func networkPeersGet(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Network driver %q does not support peering", n.Type()))
 	}
 
-	if util.IsRecursionRequest(r) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	peerURL := func(peerName string) string {
+		return fmt.Sprintf("/%s/networks/%s/peers/%s", version.APIVersion, url.PathEscape(n.Name()), url.PathEscape(peerName))
+	}
+
+	if opts.recursion || opts.filtered() {
 		var records map[int64]*api.NetworkPeer
 
 		err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -171,14 +209,28 @@ func networkPeersGet(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(fmt.Errorf("Failed loading network peers: %w", err))
 		}
 
-		peers := make([]*api.NetworkPeer, 0, len(records))
+		peers := make([]api.NetworkPeer, 0, len(records))
 		for _, record := range records {
 			record.UsedBy, _ = n.PeerUsedBy(record.Name)
 			record.UsedBy = project.FilterUsedBy(s.Authorizer, r, record.UsedBy)
-			peers = append(peers, record)
+			peers = append(peers, *record)
+		}
+
+		peers, err = listPage(opts, peers, func(peer api.NetworkPeer) string { return peer.Name })
+		if err != nil {
+			return response.SmartError(err)
 		}
 
-		return response.SyncResponse(true, peers)
+		if opts.recursion {
+			return response.SyncResponse(true, peers)
+		}
+
+		peerURLs := make([]string, 0, len(peers))
+		for _, peer := range peers {
+			peerURLs = append(peerURLs, peerURL(peer.Name))
+		}
+
+		return response.SyncResponse(true, peerURLs)
 	}
 
 	var peerNames map[int64]string
@@ -192,9 +244,14 @@ func networkPeersGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Failed loading network peers: %w", err))
 	}
 
-	peerURLs := make([]string, 0, len(peerNames))
+	names := make([]string, 0, len(peerNames))
 	for _, peerName := range peerNames {
-		peerURLs = append(peerURLs, fmt.Sprintf("/%s/networks/%s/peers/%s", version.APIVersion, url.PathEscape(n.Name()), url.PathEscape(peerName)))
+		names = append(names, peerName)
+	}
+
+	peerURLs := make([]string, 0, len(names))
+	for _, peerName := range listKeys(opts, names) {
+		peerURLs = append(peerURLs, peerURL(peerName))
 	}
 
 	return response.SyncResponse(true, peerURLs)
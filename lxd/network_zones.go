@@ -56,6 +56,21 @@ var networkZoneCmd = APIEndpoint{
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -105,6 +120,21 @@ var networkZoneCmd = APIEndpoint{
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -141,7 +171,10 @@ func networkZonesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	recursion := util.IsRecursionRequest(r)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
 
 	var zoneNames []string
 
@@ -161,30 +194,35 @@ func networkZonesGet(d *Daemon, r *http.Request) response.Response {
 		return response.InternalError(err)
 	}
 
-	resultString := []string{}
-	resultMap := []api.NetworkZone{}
+	allowedNames := make([]string, 0, len(zoneNames))
 	for _, zoneName := range zoneNames {
-		if !userHasPermission(entity.NetworkZoneURL(projectName, zoneName)) {
-			continue
+		if userHasPermission(entity.NetworkZoneURL(projectName, zoneName)) {
+			allowedNames = append(allowedNames, zoneName)
 		}
+	}
 
-		if !recursion {
-			resultString = append(resultString, api.NewURL().Path(version.APIVersion, "network-zones", zoneName).String())
-		} else {
-			netzone, err := zone.LoadByNameAndProject(s, projectName, zoneName)
-			if err != nil {
-				continue
-			}
+	resultNames, resultMap, err := listEntries(opts, allowedNames, func(zoneName string) (*api.NetworkZone, error) {
+		netzone, err := zone.LoadByNameAndProject(s, projectName, zoneName)
+		if err != nil {
+			return nil, nil
+		}
 
-			netzoneInfo := netzone.Info()
-			netzoneInfo.UsedBy, _ = netzone.UsedBy() // Ignore errors in UsedBy, will return nil.
-			netzoneInfo.UsedBy = project.FilterUsedBy(s.Authorizer, r, netzoneInfo.UsedBy)
+		netzoneInfo := netzone.Info()
+		netzoneInfo.UsedBy, _ = netzone.UsedBy() // Ignore errors in UsedBy, will return nil.
+		netzoneInfo.UsedBy = project.FilterUsedBy(s.Authorizer, r, netzoneInfo.UsedBy)
 
-			resultMap = append(resultMap, *netzoneInfo)
-		}
+		return netzoneInfo, nil
+	})
+	if err != nil {
+		return response.SmartError(err)
 	}
 
-	if !recursion {
+	if !opts.recursion {
+		resultString := make([]string, 0, len(resultNames))
+		for _, zoneName := range resultNames {
+			resultString = append(resultString, api.NewURL().Path(version.APIVersion, "network-zones", zoneName).String())
+		}
+
 		return response.SyncResponse(true, resultString)
 	}
 
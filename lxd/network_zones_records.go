@@ -53,6 +53,21 @@ var networkZoneRecordCmd = APIEndpoint{
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -102,6 +117,21 @@ var networkZoneRecordCmd = APIEndpoint{
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -138,7 +168,10 @@ func networkZoneRecordsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	recursion := util.IsRecursionRequest(r)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
 
 	zoneName, err := url.PathUnescape(mux.Vars(r)["zone"])
 	if err != nil {
@@ -157,21 +190,21 @@ func networkZoneRecordsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	resultString := []string{}
-	resultMap := []api.NetworkZoneRecord{}
-	for _, record := range records {
-		if !recursion {
+	records, err = listPage(opts, records, func(record api.NetworkZoneRecord) string { return record.Name })
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if !opts.recursion {
+		resultString := make([]string, 0, len(records))
+		for _, record := range records {
 			resultString = append(resultString, api.NewURL().Path(version.APIVersion, "network-zones", zoneName, "records", record.Name).String())
-		} else {
-			resultMap = append(resultMap, record)
 		}
-	}
 
-	if !recursion {
 		return response.SyncResponse(true, resultString)
 	}
 
-	return response.SyncResponse(true, resultMap)
+	return response.SyncResponse(true, records)
 }
 
 // swagger:operation POST /1.0/network-zones/{zone}/records network-zones network_zone_records_post
@@ -90,6 +90,21 @@ var networkStateCmd = APIEndpoint{
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -139,6 +154,21 @@ var networkStateCmd = APIEndpoint{
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -177,7 +207,10 @@ func networksGet(d *Daemon, r *http.Request) response.Response {
 
 	request.SetCtxValue(r, request.CtxEffectiveProjectName, projectName)
 
-	recursion := util.IsRecursionRequest(r)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
 
 	var networkNames []string
 
@@ -216,26 +249,31 @@ func networksGet(d *Daemon, r *http.Request) response.Response {
 		return response.InternalError(err)
 	}
 
-	resultString := []string{}
-	resultMap := []api.Network{}
+	allowedNames := make([]string, 0, len(networkNames))
 	for _, networkName := range networkNames {
-		if !userHasPermission(entity.NetworkURL(projectName, networkName)) {
-			continue
+		if userHasPermission(entity.NetworkURL(projectName, networkName)) {
+			allowedNames = append(allowedNames, networkName)
 		}
+	}
 
-		if !recursion {
-			resultString = append(resultString, fmt.Sprintf("/%s/networks/%s", version.APIVersion, networkName))
-		} else {
-			net, err := doNetworkGet(s, r, s.ServerClustered, projectName, reqProject.Config, networkName)
-			if err != nil {
-				continue
-			}
-
-			resultMap = append(resultMap, net)
+	resultNames, resultMap, err := listEntries(opts, allowedNames, func(networkName string) (*api.Network, error) {
+		net, err := doNetworkGet(s, r, s.ServerClustered, projectName, reqProject.Config, networkName)
+		if err != nil {
+			return nil, nil
 		}
+
+		return &net, nil
+	})
+	if err != nil {
+		return response.SmartError(err)
 	}
 
-	if !recursion {
+	if !opts.recursion {
+		resultString := make([]string, 0, len(resultNames))
+		for _, networkName := range resultNames {
+			resultString = append(resultString, fmt.Sprintf("/%s/networks/%s", version.APIVersion, networkName))
+		}
+
 		return response.SyncResponse(true, resultString)
 	}
 
@@ -1003,7 +1041,7 @@ func networkDelete(d *Daemon, r *http.Request) response.Response {
 
 	// If we are clustered, also notify all other nodes, if any.
 	if s.ServerClustered {
-		notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAll)
+		notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAll)
 		if err != nil {
 			return response.SmartError(err)
 		}
@@ -16,17 +16,20 @@ import (
 	"github.com/canonical/lxd/lxd/db"
 	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
 	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/instance"
 	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
 	"github.com/canonical/lxd/lxd/task"
-	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
 )
 
 var operationCmd = APIEndpoint{
@@ -191,6 +194,7 @@ func operationGet(d *Daemon, r *http.Request) response.Response {
 
 	// Then check if the query is from an operation on another node, and, if so, forward it
 	var address string
+	var recorded *api.Operation
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		filter := dbCluster.OperationFilter{UUID: &id}
 		ops, err := dbCluster.GetOperations(ctx, tx.Tx(), filter)
@@ -199,7 +203,9 @@ func operationGet(d *Daemon, r *http.Request) response.Response {
 		}
 
 		if len(ops) < 1 {
-			return api.StatusErrorf(http.StatusNotFound, "Operation not found")
+			// Operations interrupted by a restart of LXD keep their final status for a while.
+			recorded, err = getFinalOperationRecord(ctx, tx, id)
+			return err
 		}
 
 		if len(ops) > 1 {
@@ -215,6 +221,10 @@ func operationGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	if recorded != nil {
+		return response.SyncResponse(true, recorded)
+	}
+
 	client, err := cluster.Connect(address, s.Endpoints.NetworkCert(), s.ServerCert(), r, false)
 	if err != nil {
 		return response.SmartError(err)
@@ -311,6 +321,7 @@ func operationDelete(d *Daemon, r *http.Request) response.Response {
 
 	// Then check if the query is from an operation on another node, and, if so, forward it
 	var address string
+	var recorded *api.Operation
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		filter := dbCluster.OperationFilter{UUID: &id}
 		ops, err := dbCluster.GetOperations(ctx, tx.Tx(), filter)
@@ -319,7 +330,9 @@ func operationDelete(d *Daemon, r *http.Request) response.Response {
 		}
 
 		if len(ops) < 1 {
-			return api.StatusErrorf(http.StatusNotFound, "Operation not found")
+			// Operations interrupted by a restart of LXD keep their final status for a while.
+			recorded, err = getFinalOperationRecord(ctx, tx, id)
+			return err
 		}
 
 		if len(ops) > 1 {
@@ -335,6 +348,10 @@ func operationDelete(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	if recorded != nil {
+		return response.BadRequest(fmt.Errorf("Only running operations can be cancelled"))
+	}
+
 	client, err := cluster.Connect(address, s.Endpoints.NetworkCert(), s.ServerCert(), r, false)
 	if err != nil {
 		return response.SmartError(err)
@@ -419,6 +436,21 @@ func operationCancel(s *state.State, r *http.Request, projectName string, op *ap
 //      name: all-projects
 //      description: Retrieve operations from all projects
 //      type: boolean
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -475,6 +507,21 @@ func operationCancel(s *state.State, r *http.Request, projectName string, op *ap
 //	    name: all-projects
 //	    description: Retrieve operations from all projects
 //	    type: boolean
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -508,7 +555,6 @@ func operationsGet(d *Daemon, r *http.Request) response.Response {
 
 	projectName := request.QueryParam(r, "project")
 	allProjects := shared.IsTrue(request.QueryParam(r, "all-projects"))
-	recursion := util.IsRecursionRequest(r)
 
 	if allProjects && projectName != "" {
 		return response.SmartError(
@@ -518,19 +564,21 @@ func operationsGet(d *Daemon, r *http.Request) response.Response {
 		projectName = api.ProjectDefaultName
 	}
 
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
 	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), auth.EntitlementCanViewOperations, entity.TypeProject)
 	if err != nil {
 		return response.InternalError(fmt.Errorf("Failed to get operation permission checker: %w", err))
 	}
 
-	localOperationURLs := func() (shared.Jmap, error) {
-		// Get all the operations.
-		localOps := operations.Clone()
-
-		// Build a list of URLs.
-		body := shared.Jmap{}
+	// Get the local operations the user can view.
+	localOperations := func() ([]api.Operation, error) {
+		ops := []api.Operation{}
 
-		for _, v := range localOps {
+		for _, v := range operations.Clone() {
 			if !allProjects && v.Project() != "" && v.Project() != projectName {
 				continue
 			}
@@ -539,91 +587,56 @@ func operationsGet(d *Daemon, r *http.Request) response.Response {
 				continue
 			}
 
-			status := strings.ToLower(v.Status().String())
-			_, ok := body[status]
-			if !ok {
-				body[status] = make([]string, 0)
+			_, op, err := v.Render()
+			if err != nil {
+				return nil, err
 			}
 
-			body[status] = append(body[status].([]string), v.URL())
+			ops = append(ops, *op)
 		}
 
-		return body, nil
+		return ops, nil
 	}
 
-	localOperations := func() (shared.Jmap, error) {
-		// Get all the operations.
-		localOps := operations.Clone()
-
-		// Build a list of operations.
+	// Group the operations, or their URLs, by status.
+	operationsByStatus := func(ops []api.Operation) shared.Jmap {
 		body := shared.Jmap{}
 
-		for _, v := range localOps {
-			if !allProjects && v.Project() != "" && v.Project() != projectName {
-				continue
-			}
-
-			if !userHasPermission(entity.ProjectURL(v.Project())) {
-				continue
-			}
-
-			status := strings.ToLower(v.Status().String())
-			_, ok := body[status]
-			if !ok {
-				body[status] = make([]*api.Operation, 0)
-			}
+		for _, op := range ops {
+			status := strings.ToLower(op.Status)
 
-			_, op, err := v.Render()
-			if err != nil {
-				return nil, err
+			if opts.recursion {
+				statusOps, _ := body[status].([]api.Operation)
+				body[status] = append(statusOps, op)
+			} else {
+				statusURLs, _ := body[status].([]string)
+				body[status] = append(statusURLs, fmt.Sprintf("/%s/operations/%s", version.APIVersion, op.ID))
 			}
-
-			body[status] = append(body[status].([]*api.Operation), op)
 		}
 
-		return body, nil
+		return body
+	}
+
+	// Start with local operations.
+	ops, err := localOperations()
+	if err != nil {
+		return response.InternalError(err)
 	}
 
 	// Check if called from a cluster node.
 	if isClusterNotification(r) {
 		// Only return the local data.
-		if recursion {
-			// Recursive queries.
-			body, err := localOperations()
-			if err != nil {
-				return response.InternalError(err)
-			}
-
-			return response.SyncResponse(true, body)
-		}
-
-		// Normal queries
-		body, err := localOperationURLs()
-		if err != nil {
-			return response.InternalError(err)
-		}
-
-		return response.SyncResponse(true, body)
+		return response.SyncResponse(true, operationsByStatus(ops))
 	}
 
-	// Start with local operations.
-	var md shared.Jmap
-
-	if recursion {
-		md, err = localOperations()
-		if err != nil {
-			return response.InternalError(err)
-		}
-	} else {
-		md, err = localOperationURLs()
+	// If not clustered, then just return local operations.
+	if !s.ServerClustered {
+		ops, err = listPage(opts, ops, func(op api.Operation) string { return op.ID })
 		if err != nil {
-			return response.InternalError(err)
+			return response.SmartError(err)
 		}
-	}
 
-	// If not clustered, then just return local operations.
-	if !s.ServerClustered {
-		return response.SyncResponse(true, md)
+		return response.SyncResponse(true, operationsByStatus(ops))
 	}
 
 	// Get all nodes with running operations in this project.
@@ -689,11 +702,11 @@ func operationsGet(d *Daemon, r *http.Request) response.Response {
 		}
 
 		// Get operation data.
-		var ops []api.Operation
+		var memberOps []api.Operation
 		if allProjects {
-			ops, err = client.GetOperationsAllProjects()
+			memberOps, err = client.GetOperationsAllProjects()
 		} else {
-			ops, err = client.UseProject(projectName).GetOperations()
+			memberOps, err = client.UseProject(projectName).GetOperations()
 		}
 
 		if err != nil {
@@ -701,29 +714,15 @@ func operationsGet(d *Daemon, r *http.Request) response.Response {
 			continue
 		}
 
-		// Merge with existing data.
-		for _, o := range ops {
-			op := o // Local var for pointer.
-			status := strings.ToLower(op.Status)
-
-			_, ok := md[status]
-			if !ok {
-				if recursion {
-					md[status] = make([]*api.Operation, 0)
-				} else {
-					md[status] = make([]string, 0)
-				}
-			}
+		ops = append(ops, memberOps...)
+	}
 
-			if recursion {
-				md[status] = append(md[status].([]*api.Operation), &op)
-			} else {
-				md[status] = append(md[status].([]string), fmt.Sprintf("/1.0/operations/%s", op.ID))
-			}
-		}
+	ops, err = listPage(opts, ops, func(op api.Operation) string { return op.ID })
+	if err != nil {
+		return response.SmartError(err)
 	}
 
-	return response.SyncResponse(true, md)
+	return response.SyncResponse(true, operationsByStatus(ops))
 }
 
 // operationsGetByType gets all operations for a project and type.
@@ -1002,6 +1001,7 @@ func operationWaitGet(d *Daemon, r *http.Request) response.Response {
 
 	// Then check if the query is from an operation on another node, and, if so, forward it
 	var address string
+	var recorded *api.Operation
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		filter := dbCluster.OperationFilter{UUID: &id}
 		ops, err := dbCluster.GetOperations(ctx, tx.Tx(), filter)
@@ -1010,7 +1010,9 @@ func operationWaitGet(d *Daemon, r *http.Request) response.Response {
 		}
 
 		if len(ops) < 1 {
-			return api.StatusErrorf(http.StatusNotFound, "Operation not found")
+			// Operations interrupted by a restart of LXD keep their final status for a while.
+			recorded, err = getFinalOperationRecord(ctx, tx, id)
+			return err
 		}
 
 		if len(ops) > 1 {
@@ -1026,6 +1028,15 @@ func operationWaitGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	if recorded != nil {
+		// Recorded operations have no secret.
+		if !trusted {
+			return response.Forbidden(nil)
+		}
+
+		return response.SyncResponse(true, recorded)
+	}
+
 	client, err := cluster.Connect(address, s.Endpoints.NetworkCert(), s.ServerCert(), r, false)
 	if err != nil {
 		return response.SmartError(err)
@@ -1245,6 +1256,12 @@ func autoRemoveOrphanedOperations(ctx context.Context, s *state.State) error {
 				return fmt.Errorf("Failed to delete operations: %w", err)
 			}
 		}
+
+		err = tx.DeleteOperationRecordsBefore(ctx, time.Now().Add(-operationRecordRetention))
+		if err != nil {
+			return fmt.Errorf("Failed to delete expired operation records: %w", err)
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -1255,3 +1272,183 @@ func autoRemoveOrphanedOperations(ctx context.Context, s *state.State) error {
 
 	return nil
 }
+
+// operationRecordRetention is how long the final status of operations interrupted by a restart of LXD is kept.
+const operationRecordRetention = 24 * time.Hour
+
+// operationRecordToAPI converts the recorded state of an operation to an API operation.
+func operationRecordToAPI(record *db.OperationRecord) *api.Operation {
+	return &api.Operation{
+		ID:          record.UUID,
+		Class:       operations.OperationClass(record.Class).String(),
+		Description: record.Type.Description(),
+		CreatedAt:   record.CreatedAt,
+		UpdatedAt:   record.UpdatedAt,
+		Status:      record.StatusCode.String(),
+		StatusCode:  record.StatusCode,
+		Resources:   record.Resources,
+		Metadata:    map[string]any{},
+		MayCancel:   false,
+		Err:         record.Error,
+		Location:    record.Location,
+	}
+}
+
+// getFinalOperationRecord returns the final status of an operation interrupted by a restart of LXD.
+func getFinalOperationRecord(ctx context.Context, tx *db.ClusterTx, id string) (*api.Operation, error) {
+	record, err := tx.GetOperationRecord(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The operation is either still being recovered or its member is offline.
+	if !record.StatusCode.IsFinal() {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Operation not found")
+	}
+
+	return operationRecordToAPI(record), nil
+}
+
+// recoverInterruptedOperations rolls back the changes of the persistent operations of the local member which were
+// interrupted by a restart of LXD, and records their final failure status so that clients waiting for them are
+// notified. It also removes the expired final status of operations across the cluster.
+func recoverInterruptedOperations(s *state.State) error {
+	var records []db.OperationRecord
+
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err := tx.DeleteOperationRecordsBefore(ctx, time.Now().Add(-operationRecordRetention))
+		if err != nil {
+			return fmt.Errorf("Failed deleting expired operation records: %w", err)
+		}
+
+		records, err = tx.GetInterruptedOperationRecords(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		l := logger.AddContext(logger.Ctx{"operation": record.UUID, "project": record.Project, "description": record.Type.Description()})
+		l.Warn("Recovering operation interrupted by restart")
+
+		errMsg := "Operation interrupted by a restart of LXD"
+		if len(record.Rollback) > 0 {
+			errMsg += ", changes were rolled back"
+		}
+
+		// Delete the entities created by the operation, most recent first.
+		for i := len(record.Rollback) - 1; i >= 0; i-- {
+			err := rollbackOperationEntity(s, record.Rollback[i])
+			if err != nil {
+				l.Error("Failed rolling back interrupted operation", logger.Ctx{"entity": record.Rollback[i].URL, "err": err})
+				errMsg = fmt.Sprintf("Operation interrupted by a restart of LXD, failed rolling back %q: %v", record.Rollback[i].URL, err)
+				break
+			}
+		}
+
+		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			return tx.UpdateOperationRecordStatus(ctx, record.UUID, api.Failure, errMsg)
+		})
+		if err != nil {
+			return fmt.Errorf("Failed updating status of operation %q: %w", record.UUID, err)
+		}
+
+		// Notify the clients listening for operation events.
+		record.StatusCode = api.Failure
+		record.Error = errMsg
+		record.UpdatedAt = time.Now().UTC()
+		_ = s.Events.Send(record.Project, api.EventTypeOperation, operationRecordToAPI(&record))
+	}
+
+	return nil
+}
+
+// operationAddInstanceRollback records the instance the operation has just created, so that it's deleted if the
+// operation is interrupted by a restart of LXD.
+func operationAddInstanceRollback(op *operations.Operation, inst instance.Instance) error {
+	return op.AddRollback(api.NewURL().Path(version.APIVersion, "instances", inst.Name()).Project(inst.Project().Name), inst.LocalConfig()["volatile.uuid"])
+}
+
+// rollbackOperationEntity deletes an entity created by an interrupted operation. Entities which don't exist, or
+// whose UUID doesn't match the one recorded by the operation, are left untouched. Instances are never force
+// deleted, so that their delete protection is honoured.
+func rollbackOperationEntity(s *state.State, rollback db.OperationRollback) error {
+	u, err := url.Parse(rollback.URL)
+	if err != nil {
+		return err
+	}
+
+	entityType, projectName, _, pathArgs, err := entity.ParseURL(*u)
+	if err != nil {
+		return err
+	}
+
+	switch entityType {
+	case entity.TypeInstance:
+		inst, err := instance.LoadByProjectAndName(s, projectName, pathArgs[0])
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		if inst.Location() != s.ServerName || inst.LocalConfig()["volatile.uuid"] != rollback.UUID {
+			logger.Warn("Not rolling back instance created by someone else", logger.Ctx{"project": projectName, "instance": inst.Name()})
+			return nil
+		}
+
+		return inst.Delete(false)
+	case entity.TypeInstanceBackup:
+		backup, err := instance.BackupLoadByName(s, projectName, pathArgs[0]+shared.SnapshotDelimiter+pathArgs[1])
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		return backup.Delete()
+	case entity.TypeStorageVolume:
+		if pathArgs[1] != dbCluster.StoragePoolVolumeTypeNameCustom {
+			return fmt.Errorf("Rolling back %q volumes isn't supported", pathArgs[1])
+		}
+
+		pool, err := storagePools.LoadByName(s, pathArgs[0])
+		if err != nil {
+			return err
+		}
+
+		dbVol, err := storagePools.VolumeDBGet(pool, projectName, pathArgs[2], storageDrivers.VolumeTypeCustom)
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		if dbVol.Config["volatile.uuid"] != rollback.UUID {
+			logger.Warn("Not rolling back storage volume created by someone else", logger.Ctx{"project": projectName, "pool": pathArgs[0], "volume": pathArgs[2]})
+			return nil
+		}
+
+		return pool.DeleteCustomVolume(projectName, pathArgs[2], nil)
+	case entity.TypeStorageVolumeBackup:
+		backup, err := storagePoolVolumeBackupLoadByName(s, projectName, pathArgs[0], pathArgs[2]+shared.SnapshotDelimiter+pathArgs[3])
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		return backup.Delete()
+	}
+
+	return fmt.Errorf("Rolling back %q entities isn't supported", entityType)
+}
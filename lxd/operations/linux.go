@@ -5,6 +5,7 @@ package operations
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
@@ -55,6 +56,57 @@ func removeDBOperation(op *Operation) error {
 	return err
 }
 
+// recordDBOperation records the state of a persistent operation.
+func recordDBOperation(op *Operation) error {
+	if op.state == nil || !op.persistent {
+		return nil
+	}
+
+	_, apiOp, err := op.Render()
+	if err != nil {
+		return err
+	}
+
+	op.lock.Lock()
+	record := db.OperationRecord{
+		UUID:       op.id,
+		Project:    op.projectName,
+		Type:       op.dbOpType,
+		Class:      int64(op.class),
+		StatusCode: apiOp.StatusCode,
+		Resources:  apiOp.Resources,
+		Rollback:   make([]db.OperationRollback, 0, len(op.rollback)),
+		CreatedAt:  op.createdAt,
+		UpdatedAt:  time.Now().UTC(),
+	}
+
+	for _, rollback := range op.rollback {
+		record.Rollback = append(record.Rollback, db.OperationRollback{URL: rollback.url, UUID: rollback.uuid})
+	}
+
+	op.lock.Unlock()
+
+	err = op.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.CreateOrReplaceOperationRecord(ctx, record)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed recording operation %s: %w", op.id, err)
+	}
+
+	return nil
+}
+
+// removeDBOperationRecord deletes the recorded state of a persistent operation.
+func removeDBOperationRecord(op *Operation) error {
+	if op.state == nil {
+		return nil
+	}
+
+	return op.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteOperationRecord(ctx, op.id)
+	})
+}
+
 func (op *Operation) sendEvent(eventMessage any) {
 	if op.events == nil {
 		return
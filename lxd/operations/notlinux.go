@@ -25,6 +25,22 @@ func removeDBOperation(op *Operation) error {
 	return nil
 }
 
+func recordDBOperation(op *Operation) error {
+	if op.state != nil && op.persistent {
+		return fmt.Errorf("recordDBOperation not supported on this platform")
+	}
+
+	return nil
+}
+
+func removeDBOperationRecord(op *Operation) error {
+	if op.state != nil {
+		return fmt.Errorf("removeDBOperationRecord not supported on this platform")
+	}
+
+	return nil
+}
+
 func (op *Operation) sendEvent(eventMessage any) {
 	if op.events == nil {
 		return
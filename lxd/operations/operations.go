@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/canonical/lxd/lxd/auth"
 	"github.com/canonical/lxd/lxd/db/operationtype"
@@ -15,6 +17,7 @@ import (
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/tracing"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/cancel"
@@ -111,6 +114,15 @@ type Operation struct {
 	requestor   *api.EventLifecycleRequestor
 	logger      logger.Logger
 
+	// Whether the state of the operation is recorded in the database, and the entities to delete if the
+	// operation is interrupted by a restart of LXD.
+	persistent bool
+	rollback   []rollbackEntity
+
+	// Span covering the operation from its creation until it's done, and a context carrying it.
+	span     trace.Span
+	traceCtx context.Context
+
 	// Those functions are called at various points in the Operation lifecycle
 	onRun     func(*Operation) error
 	onCancel  func(*Operation) error
@@ -155,6 +167,18 @@ func OperationCreate(s *state.State, projectName string, opClass OperationClass,
 		op.SetEventServer(s.Events)
 	}
 
+	// Trace the operation as part of the request which created it.
+	traceCtx := context.Background()
+	if r != nil {
+		traceCtx = tracing.Detach(r.Context())
+	}
+
+	op.traceCtx, op.span = tracing.Start(traceCtx, "operation "+op.description,
+		attribute.String("lxd.operation.id", op.id),
+		attribute.String("lxd.operation.class", op.class.String()),
+		attribute.String("lxd.project", op.projectName),
+	)
+
 	newMetadata, err := shared.ParseMetadata(opMetadata)
 	if err != nil {
 		return nil, err
@@ -198,6 +222,14 @@ func OperationCreate(s *state.State, projectName string, opClass OperationClass,
 		return nil, err
 	}
 
+	// Record the state of long-running operations so they can be recovered after a restart.
+	op.persistent = opType.Persistent() && op.class != OperationClassToken
+
+	err = recordDBOperation(&op)
+	if err != nil {
+		return nil, err
+	}
+
 	op.logger.Debug("New operation")
 	_, md, _ := op.Render()
 
@@ -218,6 +250,62 @@ func (op *Operation) SetRequestor(r *http.Request) {
 	op.requestor = request.CreateRequestor(r)
 }
 
+// TraceContext returns a context carrying the span of the operation. It is used as parent of the spans started
+// while running the operation and doesn't carry any cancellation.
+func (op *Operation) TraceContext() context.Context {
+	if op == nil || op.traceCtx == nil {
+		return context.Background()
+	}
+
+	return op.traceCtx
+}
+
+// rollbackEntity is an entity created by an operation, to delete if the operation is interrupted.
+type rollbackEntity struct {
+	url  string
+	uuid string
+}
+
+// AddRollback records the URL and UUID (for entities which have one) of an entity the operation has just created.
+// If the operation is interrupted by a restart of LXD, the entity is deleted when LXD starts again, provided it
+// still has the same UUID. It must only be called once the entity exists, so that an entity with the same name
+// which existed before the operation is never deleted. It has no effect on operations which aren't persistent.
+func (op *Operation) AddRollback(u *api.URL, entityUUID string) error {
+	if op == nil {
+		return nil
+	}
+
+	op.lock.Lock()
+	if !op.persistent || op.readonly {
+		op.lock.Unlock()
+		return nil
+	}
+
+	op.rollback = append(op.rollback, rollbackEntity{url: u.String(), uuid: entityUUID})
+	op.lock.Unlock()
+
+	return recordDBOperation(op)
+}
+
+// ClearRollback forgets the entities recorded with AddRollback, so that they are kept even if the operation is
+// interrupted by a restart of LXD.
+func (op *Operation) ClearRollback() error {
+	if op == nil {
+		return nil
+	}
+
+	op.lock.Lock()
+	if !op.persistent || op.readonly || len(op.rollback) == 0 {
+		op.lock.Unlock()
+		return nil
+	}
+
+	op.rollback = nil
+	op.lock.Unlock()
+
+	return recordDBOperation(op)
+}
+
 // Requestor returns the initial requestor for this operation.
 func (op *Operation) Requestor() *api.EventLifecycleRequestor {
 	return op.requestor
@@ -234,8 +322,22 @@ func (op *Operation) done() {
 	op.onCancel = nil
 	op.onConnect = nil
 	op.finished.Cancel()
+
+	if op.span != nil {
+		op.span.SetAttributes(attribute.String("lxd.operation.status", op.status.String()))
+		tracing.End(op.span, op.err)
+	}
+
 	op.lock.Unlock()
 
+	// The operation is done, there's nothing to recover anymore.
+	if op.persistent {
+		err := removeDBOperationRecord(op)
+		if err != nil {
+			op.logger.Warn("Failed to delete operation record", logger.Ctx{"err": err})
+		}
+	}
+
 	go func() {
 		shutdownCtx := context.Background()
 		if op.state != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -91,6 +92,7 @@ var patches = []patch{
 	{name: "storage_move_custom_iso_block_volumes_v2", stage: patchPostDaemonStorage, run: patchStorageRenameCustomISOBlockVolumesV2},
 	{name: "storage_unset_invalid_block_settings_v2", stage: patchPostDaemonStorage, run: patchStorageUnsetInvalidBlockSettingsV2},
 	{name: "config_remove_core_trust_password", stage: patchPreLoadClusterConfig, run: patchRemoveCoreTrustPassword},
+	{name: "backups_set_size", stage: patchPostDaemonStorage, run: patchBackupsSetSize},
 }
 
 type patch struct {
@@ -1447,4 +1449,94 @@ func patchRemoveCoreTrustPassword(_ string, d *Daemon) error {
 	return nil
 }
 
+// patchBackupsSetSize records the size of the backups created before their size was tracked, so that they count
+// towards the limits.backups.size project limit. Each member records the size of the backup files it stores.
+func patchBackupsSetSize(_ string, d *Daemon) error {
+	type backupFile struct {
+		table string
+		id    int64
+		path  string
+	}
+
+	s := d.State()
+	err := s.DB.Cluster.Transaction(s.ShutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var backups []backupFile
+
+		q := `
+SELECT backups.id, projects.name, backups.name
+FROM instances_backups AS backups
+JOIN instances ON instances.id=backups.instance_id
+JOIN projects ON projects.id=instances.project_id
+WHERE backups.size=0
+`
+		err := query.Scan(ctx, tx.Tx(), q, func(scan func(dest ...any) error) error {
+			var projectName, backupName string
+			b := backupFile{table: "instances_backups"}
+
+			err := scan(&b.id, &projectName, &backupName)
+			if err != nil {
+				return err
+			}
+
+			b.path = shared.VarPath("backups", "instances", project.Instance(projectName, backupName))
+			backups = append(backups, b)
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("Failed getting instance backups: %w", err)
+		}
+
+		q = `
+SELECT backups.id, storage_pools.name, projects.name, backups.name
+FROM storage_volumes_backups AS backups
+JOIN storage_volumes ON storage_volumes.id=backups.storage_volume_id
+JOIN storage_pools ON storage_pools.id=storage_volumes.storage_pool_id
+JOIN projects ON projects.id=storage_volumes.project_id
+WHERE backups.size=0
+`
+		err = query.Scan(ctx, tx.Tx(), q, func(scan func(dest ...any) error) error {
+			var poolName, projectName, backupName string
+			b := backupFile{table: "storage_volumes_backups"}
+
+			err := scan(&b.id, &poolName, &projectName, &backupName)
+			if err != nil {
+				return err
+			}
+
+			b.path = shared.VarPath("backups", "custom", poolName, project.StorageVolume(projectName, backupName))
+			backups = append(backups, b)
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("Failed getting storage volume backups: %w", err)
+		}
+
+		for _, b := range backups {
+			// The backup files of other members are recorded by the patch running on them.
+			fi, err := os.Stat(b.path)
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+
+				return err
+			}
+
+			_, err = tx.Tx().ExecContext(ctx, fmt.Sprintf("UPDATE %s SET size=? WHERE id=?", b.table), fi.Size(), b.id)
+			if err != nil {
+				return fmt.Errorf("Failed recording size of backup %q: %w", b.path, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("Failed recording size of backups: %w", err)
+	}
+
+	return nil
+}
+
 // Patches end here
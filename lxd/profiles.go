@@ -65,6 +65,21 @@ var profileCmd = APIEndpoint{
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -114,6 +129,21 @@ var profileCmd = APIEndpoint{
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -150,7 +180,10 @@ func profilesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	recursion := util.IsRecursionRequest(r)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
 
 	request.SetCtxValue(r, request.CtxEffectiveProjectName, p.Name)
 	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), auth.EntitlementCanView, entity.TypeProfile)
@@ -158,8 +191,8 @@ func profilesGet(d *Daemon, r *http.Request) response.Response {
 		return response.InternalError(err)
 	}
 
-	var apiProfiles []*api.Profile
-	var profileURLs []string
+	var apiProfiles []api.Profile
+	var profileNames []string
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		filter := dbCluster.ProfileFilter{
 			Project: &p.Name,
@@ -170,34 +203,32 @@ func profilesGet(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
-		if recursion {
-			apiProfiles = make([]*api.Profile, 0, len(profiles))
-			for _, profile := range profiles {
-				if !userHasPermission(entity.ProfileURL(p.Name, profile.Name)) {
-					continue
-				}
+		allowedNames := make([]string, 0, len(profiles))
+		profilesByName := make(map[string]dbCluster.Profile, len(profiles))
+		for _, profile := range profiles {
+			if !userHasPermission(entity.ProfileURL(p.Name, profile.Name)) {
+				continue
+			}
 
-				apiProfile, err := profile.ToAPI(ctx, tx.Tx())
-				if err != nil {
-					return err
-				}
+			allowedNames = append(allowedNames, profile.Name)
+			profilesByName[profile.Name] = profile
+		}
 
-				apiProfile.UsedBy, err = profileUsedBy(ctx, tx, profile)
-				if err != nil {
-					return err
-				}
+		profileNames, apiProfiles, err = listEntries(opts, allowedNames, func(profileName string) (*api.Profile, error) {
+			profile := profilesByName[profileName]
 
-				apiProfiles = append(apiProfiles, apiProfile)
+			apiProfile, err := profile.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return nil, err
 			}
-		} else {
-			profileURLs = make([]string, 0, len(profiles))
-			for _, profile := range profiles {
-				profileURL := entity.ProfileURL(p.Name, profile.Name)
-				if userHasPermission(profileURL) {
-					profileURLs = append(profileURLs, profileURL.String())
-				}
+
+			apiProfile.UsedBy, err = profileUsedBy(ctx, tx, profile)
+			if err != nil {
+				return nil, err
 			}
-		}
+
+			return apiProfile, nil
+		})
 
 		return err
 	})
@@ -205,12 +236,17 @@ func profilesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	if !recursion {
+	if !opts.recursion {
+		profileURLs := make([]string, 0, len(profileNames))
+		for _, profileName := range profileNames {
+			profileURLs = append(profileURLs, entity.ProfileURL(p.Name, profileName).String())
+		}
+
 		return response.SyncResponse(true, profileURLs)
 	}
 
-	for _, apiProfile := range apiProfiles {
-		apiProfile.UsedBy = project.FilterUsedBy(s.Authorizer, r, apiProfile.UsedBy)
+	for i := range apiProfiles {
+		apiProfiles[i].UsedBy = project.FilterUsedBy(s.Authorizer, r, apiProfiles[i].UsedBy)
 	}
 
 	return response.SyncResponse(true, apiProfiles)
@@ -530,7 +566,7 @@ func profilePut(d *Daemon, r *http.Request) response.Response {
 
 	if err == nil && !isClusterNotification(r) {
 		// Notify all other nodes. If a node is down, it will be ignored.
-		notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+		notifier, err := cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
 		if err != nil {
 			return response.SmartError(err)
 		}
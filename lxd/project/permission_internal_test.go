@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/canonical/lxd/lxd/idmap"
+	"github.com/canonical/lxd/shared/api"
 )
 
 func TestParseHostIDMapRange(t *testing.T) {
@@ -75,3 +76,37 @@ func TestParseHostIDMapRange(t *testing.T) {
 		assert.Equal(t, idmaps, expected)
 	}
 }
+
+func TestGetInstanceLimits_Network(t *testing.T) {
+	inst := api.Instance{
+		Name:    "c1",
+		Project: "p1",
+		Devices: map[string]map[string]string{
+			"eth0": {"type": "nic", "limits.ingress": "10Mbit", "limits.egress": "5Mbit"},
+			"eth1": {"type": "nic", "limits.max": "1Mbit"},
+			"root": {"type": "disk", "path": "/", "pool": "default"},
+		},
+	}
+
+	limits, err := getInstanceLimits(inst, []string{"limits.network.ingress", "limits.network.egress"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11000000), limits["limits.network.ingress"])
+	assert.Equal(t, int64(6000000), limits["limits.network.egress"])
+
+	// NICs without limits are only allowed when unset values are skipped.
+	inst.Devices["eth2"] = map[string]string{"type": "nic"}
+
+	_, err = getInstanceLimits(inst, []string{"limits.network.ingress"}, false)
+	assert.Error(t, err)
+
+	limits, err = getInstanceLimits(inst, []string{"limits.network.ingress"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11000000), limits["limits.network.ingress"])
+}
+
+func TestValidateCountLimit(t *testing.T) {
+	assert.NoError(t, validateCountLimit(5, "limits.snapshots", "", "p1"))
+	assert.NoError(t, validateCountLimit(5, "limits.snapshots", "5", "p1"))
+	assert.Error(t, validateCountLimit(5, "limits.snapshots", "4", "p1"))
+	assert.Error(t, validateCountLimit(0, "limits.backups", "foo", "p1"))
+}
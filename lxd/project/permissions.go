@@ -250,6 +250,11 @@ func AllowVolumeCreation(globalConfig *clusterConfig.Config, tx *db.ClusterTx, p
 		return nil
 	}
 
+	err = AllowReplication(&info.Project, req.Config)
+	if err != nil {
+		return err
+	}
+
 	// If "limits.disk" is not set, there's nothing to do.
 	if info.Project.Config["limits.disk"] == "" {
 		return nil
@@ -456,6 +461,7 @@ func parseHostIDMapRange(isUID bool, isGID bool, listValue string) ([]idmap.Idma
 // Check that the project's restrictions are not violated across the given
 // instances and profiles.
 func checkRestrictions(project api.Project, instances []api.Instance, profiles []api.Profile) error {
+	instanceConfigChecks := map[string]func(value string) error{}
 	containerConfigChecks := map[string]func(value string) error{}
 	devicesChecks := map[string]func(value map[string]string) error{}
 
@@ -665,6 +671,23 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 			if err != nil {
 				return fmt.Errorf("Failed parsing %q: %w", "restricted.idmap.uid", err)
 			}
+
+		case "restricted.replication":
+			instanceConfigChecks["replication.target"] = func(instanceValue string) error {
+				if restrictionValue != "allow" && instanceValue != "" {
+					return fmt.Errorf("Replication is forbidden")
+				}
+
+				return nil
+			}
+
+			instanceConfigChecks["replication.target.project"] = func(instanceValue string) error {
+				if instanceValue != "" {
+					return fmt.Errorf("Replicating to another project is forbidden")
+				}
+
+				return nil
+			}
 		}
 	}
 
@@ -706,8 +729,8 @@ func checkRestrictions(project api.Project, instances []api.Instance, profiles [
 				}
 			}
 
-			var checker func(value string) error
-			if isContainerOrProfile {
+			checker := instanceConfigChecks[key]
+			if checker == nil && isContainerOrProfile {
 				checker = containerConfigChecks[key]
 			}
 
@@ -804,6 +827,8 @@ var allAggregateLimits = []string{
 	"limits.cpu",
 	"limits.disk",
 	"limits.memory",
+	"limits.network.egress",
+	"limits.network.ingress",
 	"limits.processes",
 }
 
@@ -831,6 +856,7 @@ var allRestrictions = map[string]string{
 	"restricted.idmap.uid":                 "",
 	"restricted.idmap.gid":                 "",
 	"restricted.networks.access":           "",
+	"restricted.replication":               "block",
 	"restricted.snapshots":                 "block",
 }
 
@@ -948,6 +974,11 @@ func AllowVolumeUpdate(globalConfig *clusterConfig.Config, tx *db.ClusterTx, pro
 		return nil
 	}
 
+	err = AllowReplication(&info.Project, req.Config)
+	if err != nil {
+		return err
+	}
+
 	// If "limits.disk" is not set, there's nothing to do.
 	if info.Project.Config["limits.disk"] == "" {
 		return nil
@@ -1056,6 +1087,35 @@ func AllowProjectUpdate(globalConfig *clusterConfig.Config, tx *db.ClusterTx, pr
 				return fmt.Errorf("Can't change %q in project %q: %w", key, projectName, err)
 			}
 
+		case "limits.snapshots":
+			count, err := tx.GetProjectSnapshotsCount(context.Background(), projectName)
+			if err != nil {
+				return err
+			}
+
+			err = validateCountLimit(count, key, config[key], projectName)
+			if err != nil {
+				return err
+			}
+
+		case "limits.backups":
+			fallthrough
+		case "limits.backups.size":
+			count, size, err := tx.GetProjectBackupsUsage(context.Background(), projectName)
+			if err != nil {
+				return err
+			}
+
+			if key == "limits.backups" {
+				err = validateCountLimit(count, key, config[key], projectName)
+			} else {
+				err = validateAggregateLimit(map[string]int64{key: size}, key, config[key])
+			}
+
+			if err != nil {
+				return err
+			}
+
 		case "limits.processes":
 			fallthrough
 		case "limits.cpu":
@@ -1063,6 +1123,10 @@ func AllowProjectUpdate(globalConfig *clusterConfig.Config, tx *db.ClusterTx, pr
 		case "limits.memory":
 			fallthrough
 		case "limits.disk":
+			fallthrough
+		case "limits.network.ingress":
+			fallthrough
+		case "limits.network.egress":
 			aggregateKeys = append(aggregateKeys, key)
 		}
 	}
@@ -1137,6 +1201,25 @@ func validateInstanceCountLimit(instances []api.Instance, key, value, project st
 	return nil
 }
 
+// Check that a limit on the number of entities of a project, such as limits.snapshots or limits.backups, is equal
+// to or above the current count.
+func validateCountLimit(count int64, key, value, project string) error {
+	if value == "" {
+		return nil
+	}
+
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	if limit < count {
+		return fmt.Errorf("%q is too low: there currently are %d in project %q", key, count, project)
+	}
+
+	return nil
+}
+
 var countConfigInstanceType = map[string]api.InstanceType{
 	"limits.containers":       api.InstanceTypeContainer,
 	"limits.virtual-machines": api.InstanceTypeVM,
@@ -1392,6 +1475,38 @@ func getInstanceLimits(instance api.Instance, keys []string, skipUnset bool) (ma
 
 				limit += sizeStateLimit
 			}
+		} else if key == "limits.network.ingress" || key == "limits.network.egress" {
+			// Sum the limits of all the NICs of the instance, which can also be set for both directions at once.
+			nicKey := "limits." + strings.TrimPrefix(key, "limits.network.")
+			for deviceName, device := range instance.Devices {
+				if device["type"] != "nic" {
+					continue
+				}
+
+				value := device[nicKey]
+				if value == "" {
+					value = device["limits.max"]
+				}
+
+				if value == "" {
+					if skipUnset {
+						continue
+					}
+
+					return nil, fmt.Errorf("NIC %q of instance %q in project %q has no %q config, either directly or via a profile", deviceName, instance.Name, instance.Project, nicKey)
+				}
+
+				nicLimit, err := parser(value)
+				if err != nil {
+					if skipUnset {
+						continue
+					}
+
+					return nil, fmt.Errorf("Failed parsing %q of NIC %q for instance %q in project %q", nicKey, deviceName, instance.Name, instance.Project)
+				}
+
+				limit += nicLimit
+			}
 		} else {
 			value, ok := instance.Config[key]
 			if !ok || value == "" {
@@ -1449,6 +1564,15 @@ var aggregateLimitConfigValueParsers = map[string]func(string) (int64, error){
 	"limits.disk": func(value string) (int64, error) {
 		return units.ParseByteSizeString(value)
 	},
+	"limits.backups.size": func(value string) (int64, error) {
+		return units.ParseByteSizeString(value)
+	},
+	"limits.network.ingress": func(value string) (int64, error) {
+		return units.ParseBitSizeString(value)
+	},
+	"limits.network.egress": func(value string) (int64, error) {
+		return units.ParseBitSizeString(value)
+	},
 }
 
 var aggregateLimitConfigValuePrinters = map[string]func(int64) string{
@@ -1464,6 +1588,15 @@ var aggregateLimitConfigValuePrinters = map[string]func(int64) string{
 	"limits.disk": func(limit int64) string {
 		return units.GetByteSizeStringIEC(limit, 1)
 	},
+	"limits.backups.size": func(limit int64) string {
+		return units.GetByteSizeStringIEC(limit, 1)
+	},
+	"limits.network.ingress": func(limit int64) string {
+		return fmt.Sprintf("%dbit", limit)
+	},
+	"limits.network.egress": func(limit int64) string {
+		return fmt.Sprintf("%dbit", limit)
+	},
 }
 
 // FilterUsedBy filters a UsedBy list based on the entities that the requestor is able to view.
@@ -1520,6 +1653,21 @@ func FilterUsedBy(authorizer auth.Authorizer, r *http.Request, entries []string)
 	return usedBy
 }
 
+// AllowReplication returns an error if the replication settings of an instance or custom volume with the given
+// config are forbidden by the restrictions of the project. Restricted projects can only replicate when
+// "restricted.replication" is set to "allow", and only to the project of the same name on the target server.
+func AllowReplication(project *api.Project, config map[string]string) error {
+	if config["replication.target"] != "" && projectHasRestriction(project, "restricted.replication", "block") {
+		return fmt.Errorf("Project %q doesn't allow replication", project.Name)
+	}
+
+	if config["replication.target.project"] != "" && shared.IsTrue(project.Config["restricted"]) {
+		return fmt.Errorf("Project %q doesn't allow replicating to another project", project.Name)
+	}
+
+	return nil
+}
+
 // Return true if particular restriction in project is violated.
 func projectHasRestriction(project *api.Project, restrictionKey string, blockValue string) bool {
 	if shared.IsFalseOrEmpty(project.Config["restricted"]) {
@@ -1571,16 +1719,135 @@ func AllowBackupCreation(tx *db.ClusterTx, projectName string) error {
 		return fmt.Errorf("Project %q doesn't allow for backup creation", projectName)
 	}
 
+	if project.Config["limits.backups"] == "" && project.Config["limits.backups.size"] == "" {
+		return nil
+	}
+
+	count, size, err := tx.GetProjectBackupsUsage(ctx, projectName)
+	if err != nil {
+		return err
+	}
+
+	if project.Config["limits.backups"] != "" {
+		limit, err := strconv.ParseInt(project.Config["limits.backups"], 10, 64)
+		if err != nil {
+			return err
+		}
+
+		if count >= limit {
+			return fmt.Errorf("Reached maximum number of backups (%d) in project %q", limit, projectName)
+		}
+	}
+
+	if project.Config["limits.backups.size"] != "" {
+		limit, err := units.ParseByteSizeString(project.Config["limits.backups.size"])
+		if err != nil {
+			return err
+		}
+
+		if size >= limit {
+			return fmt.Errorf("Reached maximum total size of backups %q in project %q", project.Config["limits.backups.size"], projectName)
+		}
+	}
+
+	return nil
+}
+
+// CheckBackupsSizeLimit returns an error if the total size of the backups of a project exceeds its
+// "limits.backups.size" once a new backup has been written and its size recorded.
+func CheckBackupsSizeLimit(tx *db.ClusterTx, projectName string) error {
+	ctx := context.Background()
+	dbProject, err := cluster.GetProject(ctx, tx.Tx(), projectName)
+	if err != nil {
+		return err
+	}
+
+	config, err := cluster.GetProjectConfig(ctx, tx.Tx(), dbProject.ID)
+	if err != nil {
+		return err
+	}
+
+	if config["limits.backups.size"] == "" {
+		return nil
+	}
+
+	limit, err := units.ParseByteSizeString(config["limits.backups.size"])
+	if err != nil {
+		return err
+	}
+
+	_, size, err := tx.GetProjectBackupsUsage(ctx, projectName)
+	if err != nil {
+		return err
+	}
+
+	if size > limit {
+		return fmt.Errorf("Backup would exceed the maximum total size of backups %q in project %q", config["limits.backups.size"], projectName)
+	}
+
+	return nil
+}
+
+// CheckSnapshotsLimit returns an error if the number of snapshots of a project exceeds its "limits.snapshots" once
+// a new snapshot has been created.
+func CheckSnapshotsLimit(tx *db.ClusterTx, projectName string) error {
+	ctx := context.Background()
+	dbProject, err := cluster.GetProject(ctx, tx.Tx(), projectName)
+	if err != nil {
+		return err
+	}
+
+	config, err := cluster.GetProjectConfig(ctx, tx.Tx(), dbProject.ID)
+	if err != nil {
+		return err
+	}
+
+	if config["limits.snapshots"] == "" {
+		return nil
+	}
+
+	limit, err := strconv.ParseInt(config["limits.snapshots"], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	count, err := tx.GetProjectSnapshotsCount(ctx, projectName)
+	if err != nil {
+		return err
+	}
+
+	if count > limit {
+		return fmt.Errorf("Reached maximum number of snapshots (%d) in project %q", limit, projectName)
+	}
+
 	return nil
 }
 
 // AllowSnapshotCreation returns an error if any project-specific restriction is violated
 // when creating a new snapshot in a project.
-func AllowSnapshotCreation(p *api.Project) error {
+func AllowSnapshotCreation(tx *db.ClusterTx, p *api.Project) error {
 	if projectHasRestriction(p, "restricted.snapshots", "block") {
 		return fmt.Errorf("Project %q doesn't allow for snapshot creation", p.Name)
 	}
 
+	if p.Config["limits.snapshots"] == "" {
+		return nil
+	}
+
+	limit, err := strconv.ParseInt(p.Config["limits.snapshots"], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	count, err := tx.GetProjectSnapshotsCount(context.Background(), p.Name)
+	if err != nil {
+		return err
+	}
+
+	if count >= limit {
+		return fmt.Errorf("Reached maximum number of snapshots (%d) in project %q", limit, p.Name)
+	}
+
 	return nil
 }
 
@@ -278,3 +278,21 @@ func TestCheckClusterTargetRestriction_RestrictedFalse(t *testing.T) {
 	err = project.CheckClusterTargetRestriction(authorizer, req, p, "n1")
 	assert.NoError(t, err)
 }
+
+// Restricted projects only replicate when allowed, and only to the project of the same name.
+func TestAllowReplication(t *testing.T) {
+	p := &api.Project{Name: "p1", ProjectPut: api.ProjectPut{Config: map[string]string{}}}
+	config := map[string]string{"replication.target": "https://backup.example.com:8443"}
+	targetProjectConfig := map[string]string{"replication.target": "https://backup.example.com:8443", "replication.target.project": "other"}
+
+	assert.NoError(t, project.AllowReplication(p, config))
+	assert.NoError(t, project.AllowReplication(p, targetProjectConfig))
+
+	p.Config["restricted"] = "true"
+	assert.Error(t, project.AllowReplication(p, config))
+	assert.NoError(t, project.AllowReplication(p, map[string]string{}))
+
+	p.Config["restricted.replication"] = "allow"
+	assert.NoError(t, project.AllowReplication(p, config))
+	assert.Error(t, project.AllowReplication(p, targetProjectConfig))
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/units"
 )
 
 // GetCurrentAllocations returns the current resource utilization for a given project.
@@ -38,7 +39,8 @@ func GetCurrentAllocations(globalConfig map[string]any, ctx context.Context, tx
 	result["cpu"] = raw["limits.cpu"]
 	result["disk"] = raw["limits.disk"]
 	result["memory"] = raw["limits.memory"]
-	result["networks"] = raw["limits.networks"]
+	result["network.egress"] = raw["limits.network.egress"]
+	result["network.ingress"] = raw["limits.network.ingress"]
 	result["processes"] = raw["limits.processes"]
 
 	// Get the instance count values.
@@ -92,5 +94,55 @@ func GetCurrentAllocations(globalConfig map[string]any, ctx context.Context, tx
 		Usage: int64(len(networks[projectName])),
 	}
 
+	// Get the snapshot and backup limits and usage.
+	snapshots, err := tx.GetProjectSnapshotsCount(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	backups, backupsSize, err := tx.GetProjectBackupsUsage(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	result["snapshots"] = api.ProjectStateResource{
+		Limit: -1,
+		Usage: snapshots,
+	}
+
+	result["backups"] = api.ProjectStateResource{
+		Limit: -1,
+		Usage: backups,
+	}
+
+	result["backups.size"] = api.ProjectStateResource{
+		Limit: -1,
+		Usage: backupsSize,
+	}
+
+	for _, key := range []string{"snapshots", "backups"} {
+		value, ok := info.Project.Config["limits."+key]
+		if ok {
+			resource := result[key]
+			resource.Limit, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+
+			result[key] = resource
+		}
+	}
+
+	value, ok := info.Project.Config["limits.backups.size"]
+	if ok {
+		resource := result["backups.size"]
+		resource.Limit, err = units.ParseByteSizeString(value)
+		if err != nil {
+			return nil, err
+		}
+
+		result["backups.size"] = resource
+	}
+
 	return result, nil
 }
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// projectUsageInterval is how often the resource usage of the projects is recorded.
+const projectUsageInterval = 10 * time.Minute
+
+// projectUsageRetention is how long the usage history of the projects is kept.
+const projectUsageRetention = 400 * 24 * time.Hour
+
+// projectUsageCounters are the cumulative usage counters of an instance.
+type projectUsageCounters struct {
+	cpuNanoseconds int64
+	bytesReceived  int64
+	bytesSent      int64
+}
+
+// projectUsageCounterDelta returns the increase of a cumulative counter since its last value, taking into account
+// that the counters are reset when the instance restarts.
+func projectUsageCounterDelta(last int64, current int64) int64 {
+	if current < last {
+		return current
+	}
+
+	return current - last
+}
+
+// projectUsageAdd adds the usage of an instance or custom volume over an interval to the usage of its project.
+// The memory and disk usage are sampled values, while the counters are the increase since the last sample.
+func projectUsageAdd(usage *db.ProjectUsage, interval time.Duration, memoryBytes int64, diskBytes int64, counters projectUsageCounters) {
+	gib := float64(1024 * 1024 * 1024)
+
+	usage.CPUSeconds += float64(counters.cpuNanoseconds) / float64(time.Second)
+	usage.MemoryGiBHours += float64(memoryBytes) / gib * interval.Hours()
+	usage.DiskGiBDays += float64(diskBytes) / gib * interval.Hours() / 24
+	usage.NetworkBytesReceived += counters.bytesReceived
+	usage.NetworkBytesSent += counters.bytesSent
+}
+
+// projectUsagePeriod parses the first and last days of a project usage period, which default to the current month
+// up to the current day.
+func projectUsagePeriod(fromValue string, toValue string, now time.Time) (from time.Time, to time.Time, err error) {
+	to = db.ProjectUsageDay(now)
+	if toValue != "" {
+		to, err = time.Parse(time.DateOnly, toValue)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("Invalid \"to\" date %q: %w", toValue, err)
+		}
+	}
+
+	from = to.AddDate(0, 0, 1-to.Day())
+	if fromValue != "" {
+		from, err = time.Parse(time.DateOnly, fromValue)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("Invalid \"from\" date %q: %w", fromValue, err)
+		}
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("The \"from\" date must not be after the \"to\" date")
+	}
+
+	return from, to, nil
+}
+
+// projectUsageCollector samples the resource usage of the instances and custom volumes of the local member.
+type projectUsageCollector struct {
+	// Counters of the local instances at the last sample, by instance ID. Nil until the first sample.
+	lastCounters map[int]projectUsageCounters
+
+	// Time of the last sample. Zero until the first sample.
+	lastSample time.Time
+}
+
+// sample returns the usage of each project since the last sample.
+// Volumes shared by all cluster members are only included when sampleRemoteVolumes is true.
+func (c *projectUsageCollector) sample(ctx context.Context, s *state.State, sampleRemoteVolumes bool) (map[string]*db.ProjectUsage, error) {
+	var instances []instance.Instance
+	var volumes []db.StorageVolumeArgs
+
+	filter := dbCluster.InstanceFilter{Node: &s.ServerName}
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		err := tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+			inst, err := instance.Load(s, dbInst, p)
+			if err != nil {
+				return fmt.Errorf("Failed loading instance %q (project %q) for usage task: %w", dbInst.Name, dbInst.Project, err)
+			}
+
+			instances = append(instances, inst)
+
+			return nil
+		}, filter)
+		if err != nil {
+			return err
+		}
+
+		allVolumes, err := tx.GetStoragePoolVolumesWithType(ctx, dbCluster.StoragePoolVolumeTypeCustom, true)
+		if err != nil {
+			return fmt.Errorf("Failed getting volumes for usage task: %w", err)
+		}
+
+		for _, v := range allVolumes {
+			if v.NodeID < 0 && !sampleRemoteVolumes {
+				continue
+			}
+
+			volumes = append(volumes, v)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The sampled values are accounted for the time elapsed since the last sample, as the task can run late.
+	now := time.Now()
+	interval := projectUsageInterval
+	if !c.lastSample.IsZero() {
+		interval = now.Sub(c.lastSample)
+	}
+
+	c.lastSample = now
+
+	hostInterfaces, _ := net.Interfaces()
+
+	usages := map[string]*db.ProjectUsage{}
+	projectUsage := func(projectName string) *db.ProjectUsage {
+		usage, ok := usages[projectName]
+		if !ok {
+			usage = &db.ProjectUsage{}
+			usages[projectName] = usage
+		}
+
+		return usage
+	}
+
+	counters := make(map[int]projectUsageCounters, len(instances))
+	for _, inst := range instances {
+		instState, err := inst.RenderState(hostInterfaces)
+		if err != nil {
+			logger.Warn("Failed getting instance state for usage task", logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "err": err})
+			continue
+		}
+
+		// Only the root disk is accounted for here, as attached custom volumes are accounted for below.
+		var diskBytes int64
+		rootDiskName, _, err := instancetype.GetRootDiskDevice(inst.ExpandedDevices().CloneNative())
+		if err == nil {
+			diskBytes = instState.Disk[rootDiskName].Usage
+		}
+
+		current := projectUsageCounters{cpuNanoseconds: instState.CPU.Usage}
+		for name, network := range instState.Network {
+			if name == "lo" {
+				continue
+			}
+
+			current.bytesReceived += network.Counters.BytesReceived
+			current.bytesSent += network.Counters.BytesSent
+		}
+
+		counters[inst.ID()] = current
+
+		// On the first sample the counters only serve as a reference, as their increase can't be known.
+		// Instances that appeared since the last sample were started since then.
+		delta := projectUsageCounters{}
+		if c.lastCounters != nil {
+			last := c.lastCounters[inst.ID()]
+			delta.cpuNanoseconds = projectUsageCounterDelta(last.cpuNanoseconds, current.cpuNanoseconds)
+			delta.bytesReceived = projectUsageCounterDelta(last.bytesReceived, current.bytesReceived)
+			delta.bytesSent = projectUsageCounterDelta(last.bytesSent, current.bytesSent)
+		}
+
+		projectUsageAdd(projectUsage(inst.Project().Name), interval, instState.Memory.Usage, diskBytes, delta)
+	}
+
+	c.lastCounters = counters
+
+	pools := map[string]storagePools.Pool{}
+	for _, vol := range volumes {
+		pool, ok := pools[vol.PoolName]
+		if !ok {
+			pool, err = storagePools.LoadByName(s, vol.PoolName)
+			if err != nil {
+				logger.Warn("Failed loading storage pool for usage task", logger.Ctx{"pool": vol.PoolName, "err": err})
+				continue
+			}
+
+			pools[vol.PoolName] = pool
+		}
+
+		volUsage, err := pool.GetCustomVolumeUsage(vol.ProjectName, vol.Name)
+		if err != nil {
+			continue // Not all drivers can report the usage of volumes.
+		}
+
+		projectUsageAdd(projectUsage(vol.ProjectName), interval, 0, int64(volUsage.Used), projectUsageCounters{})
+	}
+
+	return usages, nil
+}
+
+func projectUsageTask(d *Daemon) (task.Func, task.Schedule) {
+	collector := &projectUsageCollector{}
+
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		// Volumes shared by all cluster members and the pruning of the history are handled by the leader.
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil && !errors.Is(err, cluster.ErrNodeIsNotClustered) {
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		isLeader := err != nil || s.LocalConfig.ClusterAddress() == leader
+
+		usages, err := collector.sample(ctx, s, isLeader)
+		if err != nil {
+			logger.Error("Failed sampling project usage", logger.Ctx{"err": err})
+			return
+		}
+
+		now := time.Now()
+
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			for projectName, usage := range usages {
+				err := tx.AddProjectUsage(ctx, projectName, now, *usage)
+				if err != nil && !api.StatusErrorCheck(err, http.StatusNotFound) {
+					return err
+				}
+			}
+
+			if isLeader {
+				return tx.DeleteProjectUsageBefore(ctx, now.Add(-projectUsageRetention))
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed recording project usage", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(projectUsageInterval, task.SkipFirst)
+}
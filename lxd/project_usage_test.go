@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/db"
+)
+
+func TestProjectUsageCounterDelta(t *testing.T) {
+	assert.Equal(t, int64(50), projectUsageCounterDelta(100, 150))
+
+	// The counter was reset by a restart of the instance.
+	assert.Equal(t, int64(30), projectUsageCounterDelta(100, 30))
+}
+
+func TestProjectUsageAdd(t *testing.T) {
+	usage := &db.ProjectUsage{}
+	counters := projectUsageCounters{cpuNanoseconds: int64(90 * time.Second), bytesReceived: 10, bytesSent: 20}
+
+	projectUsageAdd(usage, 6*time.Hour, 2*1024*1024*1024, 4*1024*1024*1024, counters)
+	projectUsageAdd(usage, 6*time.Hour, 0, 4*1024*1024*1024, projectUsageCounters{})
+
+	assert.Equal(t, db.ProjectUsage{CPUSeconds: 90, MemoryGiBHours: 12, DiskGiBDays: 2, NetworkBytesReceived: 10, NetworkBytesSent: 20}, *usage)
+}
+
+func TestProjectUsagePeriod(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 30, 0, 0, time.UTC)
+
+	// Defaults to the current month up to the current day.
+	from, to, err := projectUsagePeriod("", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), to)
+
+	// The default start is the first day of the month of the end.
+	from, to, err = projectUsagePeriod("", "2024-01-31", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), to)
+
+	_, _, err = projectUsagePeriod("2024-02-01", "2024-01-31", now)
+	assert.Error(t, err)
+
+	_, _, err = projectUsagePeriod("yesterday", "", now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	lxd "github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// replicationRunning tracks the instances and custom volumes being replicated, so that a replication taking
+// longer than the schedule interval isn't started twice.
+var replicationRunning = sync.Map{}
+
+// replicationReplicaConfig returns the config of the replica of an instance or custom volume with the given config.
+// The replication settings aren't copied, so that the replica doesn't replicate itself, and the replica is marked
+// as such.
+func replicationReplicaConfig(config map[string]string) map[string]string {
+	replicaConfig := make(map[string]string, len(config)+1)
+	for key, value := range config {
+		if strings.HasPrefix(key, "replication.") || strings.HasPrefix(key, "volatile.replication.") {
+			continue
+		}
+
+		replicaConfig[key] = value
+	}
+
+	// Strip the last_state.power key as the replica is never running.
+	delete(replicaConfig, "volatile.last_state.power")
+
+	replicaConfig["replication.replica"] = "true"
+
+	return replicaConfig
+}
+
+// replicationIsDue returns whether the instance or custom volume with the given config should be replicated now.
+func replicationIsDue(config map[string]string, subjectID int64) bool {
+	if config["replication.target"] == "" || config["replication.schedule"] == "" {
+		return false
+	}
+
+	// Replicas of replicas aren't supported.
+	if shared.IsTrue(config["replication.replica"]) {
+		return false
+	}
+
+	return snapshotIsScheduledNow(config["replication.schedule"], subjectID)
+}
+
+// replicationConnectTarget connects to the server that an instance or custom volume with the given config is
+// replicated to, using the project of the replica. The restrictions of the project are checked again as the
+// replication settings may predate them.
+func replicationConnectTarget(s *state.State, config map[string]string, p *api.Project) (lxd.InstanceServer, error) {
+	err := project.AllowReplication(p, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the cluster certificate so that the target only has to trust a single certificate for all members.
+	cert := s.Endpoints.NetworkCert()
+
+	args := &lxd.ConnectionArgs{
+		TLSClientCert: string(cert.PublicKey()),
+		TLSClientKey:  string(cert.PrivateKey()),
+		TLSServerCert: config["replication.target.certificate"],
+		UserAgent:     version.UserAgent,
+		Proxy:         s.Proxy,
+	}
+
+	target, err := lxd.ConnectLXD(config["replication.target"], args)
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to replication target %q: %w", config["replication.target"], err)
+	}
+
+	projectName := p.Name
+	if config["replication.target.project"] != "" {
+		projectName = config["replication.target.project"]
+	}
+
+	return target.UseProject(projectName), nil
+}
+
+// replicateInstance creates or refreshes the replica of an instance on its replication target.
+func replicateInstance(s *state.State, local lxd.InstanceServer, inst instance.Instance) error {
+	instProject := inst.Project()
+	target, err := replicationConnectTarget(s, inst.ExpandedConfig(), &instProject)
+	if err != nil {
+		return err
+	}
+
+	source := local.UseProject(inst.Project().Name)
+
+	srcInst, _, err := source.GetInstance(inst.Name())
+	if err != nil {
+		return fmt.Errorf("Failed getting instance: %w", err)
+	}
+
+	// Only refresh existing instances if they are replicas, to never overwrite a promoted replica.
+	refresh := true
+	replica, _, err := target.GetInstance(inst.Name())
+	if err != nil {
+		if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return fmt.Errorf("Failed getting replica: %w", err)
+		}
+
+		refresh = false
+	} else if shared.IsFalseOrEmpty(replica.Config["replication.replica"]) {
+		return fmt.Errorf("Instance %q of the replication target isn't a replica", inst.Name())
+	}
+
+	srcInst.Config = replicationReplicaConfig(srcInst.Config)
+
+	op, err := target.CopyInstance(source, *srcInst, &lxd.InstanceCopyArgs{Mode: "push", Refresh: refresh})
+	if err != nil {
+		return fmt.Errorf("Failed copying instance: %w", err)
+	}
+
+	err = op.Wait()
+	if err != nil {
+		return fmt.Errorf("Failed copying instance: %w", err)
+	}
+
+	if !refresh {
+		return nil
+	}
+
+	// A refresh only transfers the storage volumes, so also update the config of the replica.
+	replica, etag, err := target.GetInstance(inst.Name())
+	if err != nil {
+		return fmt.Errorf("Failed getting replica: %w", err)
+	}
+
+	writable := srcInst.Writable()
+
+	// Ensure we don't change the replica's volatile.idmap.next value.
+	writable.Config["volatile.idmap.next"] = replica.Config["volatile.idmap.next"]
+
+	// Ensure we don't change the replica's root disk pool.
+	srcRootDiskDeviceKey, _, _ := instancetype.GetRootDiskDevice(writable.Devices)
+	replicaRootDiskDeviceKey, replicaRootDiskDevice, _ := instancetype.GetRootDiskDevice(replica.Devices)
+	if srcRootDiskDeviceKey != "" && srcRootDiskDeviceKey == replicaRootDiskDeviceKey {
+		writable.Devices[replicaRootDiskDeviceKey]["pool"] = replicaRootDiskDevice["pool"]
+	}
+
+	updateOp, err := target.UpdateInstance(inst.Name(), writable, etag)
+	if err != nil {
+		return fmt.Errorf("Failed updating replica: %w", err)
+	}
+
+	err = updateOp.Wait()
+	if err != nil {
+		return fmt.Errorf("Failed updating replica: %w", err)
+	}
+
+	return nil
+}
+
+// replicateCustomVolume creates or refreshes the replica of a custom volume on its replication target.
+func replicateCustomVolume(s *state.State, local lxd.InstanceServer, vol db.StorageVolumeArgs) error {
+	var volProject *api.Project
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), vol.ProjectName)
+		if err != nil {
+			return err
+		}
+
+		volProject, err = dbProject.ToAPI(ctx, tx.Tx())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading project %q: %w", vol.ProjectName, err)
+	}
+
+	target, err := replicationConnectTarget(s, vol.Config, volProject)
+	if err != nil {
+		return err
+	}
+
+	source := local.UseProject(vol.ProjectName)
+	if vol.NodeID >= 0 && s.ServerClustered {
+		source = source.UseTarget(s.ServerName)
+	}
+
+	srcVol, _, err := source.GetStoragePoolVolume(vol.PoolName, dbCluster.StoragePoolVolumeTypeNameCustom, vol.Name)
+	if err != nil {
+		return fmt.Errorf("Failed getting volume: %w", err)
+	}
+
+	targetPool := vol.Config["replication.target.pool"]
+	if targetPool == "" {
+		targetPool = vol.PoolName
+	}
+
+	// Only refresh existing volumes if they are replicas, to never overwrite a promoted replica.
+	refresh := true
+	replica, _, err := target.GetStoragePoolVolume(targetPool, dbCluster.StoragePoolVolumeTypeNameCustom, vol.Name)
+	if err != nil {
+		if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return fmt.Errorf("Failed getting replica: %w", err)
+		}
+
+		refresh = false
+	} else if shared.IsFalseOrEmpty(replica.Config["replication.replica"]) {
+		return fmt.Errorf("Volume %q of the replication target isn't a replica", vol.Name)
+	}
+
+	srcVol.Config = replicationReplicaConfig(srcVol.Config)
+
+	op, err := target.CopyStoragePoolVolume(targetPool, source, vol.PoolName, *srcVol, &lxd.StoragePoolVolumeCopyArgs{Name: vol.Name, Mode: "push", Refresh: refresh})
+	if err != nil {
+		return fmt.Errorf("Failed copying volume: %w", err)
+	}
+
+	err = op.Wait()
+	if err != nil {
+		return fmt.Errorf("Failed copying volume: %w", err)
+	}
+
+	if !refresh {
+		return nil
+	}
+
+	// A refresh only transfers the volume, so also update the config of the replica.
+	replica, etag, err := target.GetStoragePoolVolume(targetPool, dbCluster.StoragePoolVolumeTypeNameCustom, vol.Name)
+	if err != nil {
+		return fmt.Errorf("Failed getting replica: %w", err)
+	}
+
+	writable := srcVol.Writable()
+
+	// Keep the volatile keys of the replica as they can't be changed.
+	for key := range writable.Config {
+		if strings.HasPrefix(key, instancetype.ConfigVolatilePrefix) {
+			delete(writable.Config, key)
+		}
+	}
+
+	for key, value := range replica.Config {
+		if strings.HasPrefix(key, instancetype.ConfigVolatilePrefix) {
+			writable.Config[key] = value
+		}
+	}
+
+	err = target.UpdateStoragePoolVolume(targetPool, dbCluster.StoragePoolVolumeTypeNameCustom, vol.Name, writable, etag)
+	if err != nil {
+		return fmt.Errorf("Failed updating replica: %w", err)
+	}
+
+	return nil
+}
+
+// replicationStatus returns the volatile keys recording the outcome of a replication.
+func replicationStatus(replicationErr error) map[string]string {
+	if replicationErr != nil {
+		return map[string]string{"volatile.replication.last_error": replicationErr.Error()}
+	}
+
+	return map[string]string{
+		"volatile.replication.last_success": time.Now().UTC().Format(time.RFC3339),
+		"volatile.replication.last_error":   "",
+	}
+}
+
+// replicationCustomVolumeStatusSet records the outcome of the replication of a custom volume in its config.
+func replicationCustomVolumeStatusSet(ctx context.Context, s *state.State, volumeID int64, replicationErr error) error {
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		vol, err := tx.GetStoragePoolVolumeWithID(ctx, int(volumeID))
+		if err != nil {
+			return err
+		}
+
+		poolID, err := tx.GetStoragePoolID(ctx, vol.PoolName)
+		if err != nil {
+			return err
+		}
+
+		for key, value := range replicationStatus(replicationErr) {
+			if value == "" {
+				delete(vol.Config, key)
+			} else {
+				vol.Config[key] = value
+			}
+		}
+
+		return tx.UpdateStoragePoolVolume(ctx, vol.ProjectName, vol.Name, vol.Type, poolID, vol.Description, vol.Config)
+	})
+}
+
+// replicate replicates the given instances and custom volumes, recording the outcome of each replication.
+func replicate(ctx context.Context, s *state.State, local lxd.InstanceServer, instances []instance.Instance, volumes []db.StorageVolumeArgs) error {
+	for _, inst := range instances {
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		key := fmt.Sprintf("instance/%d", inst.ID())
+		_, loaded := replicationRunning.LoadOrStore(key, struct{}{})
+		if loaded {
+			continue // Replication of this instance is already running, skip.
+		}
+
+		l := logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "target": inst.ExpandedConfig()["replication.target"]})
+		l.Debug("Replicating instance")
+
+		replicationErr := replicateInstance(s, local, inst)
+		replicationRunning.Delete(key)
+		if replicationErr != nil {
+			l.Error("Failed replicating instance", logger.Ctx{"err": replicationErr})
+		}
+
+		err = inst.VolatileSet(replicationStatus(replicationErr))
+		if err != nil {
+			l.Error("Failed recording instance replication status", logger.Ctx{"err": err})
+		}
+	}
+
+	for _, vol := range volumes {
+		err := ctx.Err()
+		if err != nil {
+			return err
+		}
+
+		key := fmt.Sprintf("volume/%d", vol.ID)
+		_, loaded := replicationRunning.LoadOrStore(key, struct{}{})
+		if loaded {
+			continue // Replication of this volume is already running, skip.
+		}
+
+		l := logger.AddContext(logger.Ctx{"project": vol.ProjectName, "pool": vol.PoolName, "volume": vol.Name, "target": vol.Config["replication.target"]})
+		l.Debug("Replicating custom volume")
+
+		replicationErr := replicateCustomVolume(s, local, vol)
+		replicationRunning.Delete(key)
+		if replicationErr != nil {
+			l.Error("Failed replicating custom volume", logger.Ctx{"err": replicationErr})
+		}
+
+		err = replicationCustomVolumeStatusSet(ctx, s, vol.ID, replicationErr)
+		if err != nil {
+			l.Error("Failed recording custom volume replication status", logger.Ctx{"err": err})
+		}
+	}
+
+	return nil
+}
+
+func replicationTask(d *Daemon) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := d.State()
+
+		// Volumes shared by all cluster members are replicated by the leader.
+		leader, err := d.gateway.LeaderAddress()
+		if err != nil && !errors.Is(err, cluster.ErrNodeIsNotClustered) {
+			logger.Error("Failed to get leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		isLeader := err != nil || s.LocalConfig.ClusterAddress() == leader
+
+		var instances []instance.Instance
+		var volumes []db.StorageVolumeArgs
+
+		// Get the instances of the local member and the custom volumes that are due to be replicated.
+		filter := dbCluster.InstanceFilter{Node: &s.ServerName}
+
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			err := tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+				inst, err := instance.Load(s, dbInst, p)
+				if err != nil {
+					return fmt.Errorf("Failed loading instance %q (project %q) for replication task: %w", dbInst.Name, dbInst.Project, err)
+				}
+
+				if !replicationIsDue(inst.ExpandedConfig(), int64(inst.ID())) {
+					return nil
+				}
+
+				logger.Debug("Scheduling instance replication", logger.Ctx{"instance": inst.Name(), "project": inst.Project().Name})
+				instances = append(instances, inst)
+
+				return nil
+			}, filter)
+			if err != nil {
+				return err
+			}
+
+			allVolumes, err := tx.GetStoragePoolVolumesWithType(ctx, dbCluster.StoragePoolVolumeTypeCustom, true)
+			if err != nil {
+				return fmt.Errorf("Failed getting volumes for replication task: %w", err)
+			}
+
+			for _, v := range allVolumes {
+				if v.NodeID < 0 && !isLeader {
+					continue
+				}
+
+				if !replicationIsDue(v.Config, v.ID) {
+					continue
+				}
+
+				logger.Debug("Scheduling custom volume replication", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName})
+				volumes = append(volumes, v)
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed getting replication schedule info", logger.Ctx{"err": err})
+			return
+		}
+
+		if len(instances) == 0 && len(volumes) == 0 {
+			return
+		}
+
+		// The migrations are driven through the API of the local member, like when copying from the CLI.
+		local, err := lxd.ConnectLXDUnix(d.UnixSocket(), &lxd.ConnectionArgs{UserAgent: version.UserAgent})
+		if err != nil {
+			logger.Error("Failed connecting to local LXD for replication", logger.Ctx{"err": err})
+			return
+		}
+
+		opRun := func(op *operations.Operation) error {
+			err := replicate(ctx, s, local, instances, volumes)
+			if err != nil {
+				return err
+			}
+
+			logger.Info("Done replicating instances and custom volumes")
+
+			return nil
+		}
+
+		op, err := operations.OperationCreate(s, "", operations.OperationClassTask, operationtype.Replicate, nil, nil, opRun, nil, nil, nil)
+		if err != nil {
+			logger.Error("Failed creating replication operation", logger.Ctx{"err": err})
+			return
+		}
+
+		logger.Info("Replicating instances and custom volumes")
+
+		// Don't wait for the replication to complete, so that the instances and volumes scheduled while it runs
+		// aren't skipped.
+		err = op.Start()
+		if err != nil {
+			logger.Error("Failed starting replication operation", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
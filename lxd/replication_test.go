@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplicationReplicaConfig(t *testing.T) {
+	config := map[string]string{
+		"limits.cpu":                        "2",
+		"volatile.eth0.hwaddr":              "00:16:3e:00:00:01",
+		"volatile.last_state.power":         "RUNNING",
+		"volatile.replication.last_success": "2024-01-01T00:00:00Z",
+		"replication.target":                "https://backup.example.com:8443",
+		"replication.schedule":              "@hourly",
+	}
+
+	// The replication settings are stripped and the replica is marked, other keys are kept.
+	assert.Equal(t, map[string]string{
+		"limits.cpu":           "2",
+		"volatile.eth0.hwaddr": "00:16:3e:00:00:01",
+		"replication.replica":  "true",
+	}, replicationReplicaConfig(config))
+
+	// The original config isn't modified.
+	assert.Equal(t, "@hourly", config["replication.schedule"])
+}
+
+func TestReplicationIsDue(t *testing.T) {
+	assert.False(t, replicationIsDue(map[string]string{"replication.schedule": "* * * * *"}, 1))
+	assert.False(t, replicationIsDue(map[string]string{"replication.target": "https://backup.example.com:8443"}, 1))
+	assert.False(t, replicationIsDue(map[string]string{"replication.target": "https://backup.example.com:8443", "replication.schedule": "* * * * *", "replication.replica": "true"}, 1))
+	assert.True(t, replicationIsDue(map[string]string{"replication.target": "https://backup.example.com:8443", "replication.schedule": "* * * * *"}, 1))
+}
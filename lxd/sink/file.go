@@ -0,0 +1,136 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// fileSink appends the events as JSON lines to a file, rotating it once it reaches the maximum size.
+type fileSink struct {
+	cfg *Config
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// newFile returns a sink writing the events to the file at the path of the configuration.
+func newFile(ctx context.Context, cfg *Config) (Sink, error) {
+	if cfg.MaxSize <= 0 {
+		return nil, fmt.Errorf("Maximum file size must be positive")
+	}
+
+	if cfg.MaxFiles < 0 {
+		return nil, fmt.Errorf("Maximum number of files can't be negative")
+	}
+
+	s := &fileSink{cfg: cfg}
+
+	err := s.open()
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.Stop()
+	}()
+
+	return s, nil
+}
+
+// open opens the file for appending.
+func (s *fileSink) open() error {
+	f, err := os.OpenFile(s.cfg.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed opening %q: %w", s.cfg.Path, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	s.file = f
+	s.size = info.Size()
+
+	return nil
+}
+
+// rotate renames the file to "<path>.1", shifting the existing rotated files and removing the oldest one, and
+// opens a new file.
+func (s *fileSink) rotate() error {
+	err := s.file.Close()
+	if err != nil {
+		return err
+	}
+
+	s.file = nil
+
+	if s.cfg.MaxFiles == 0 {
+		err = os.Remove(s.cfg.Path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return s.open()
+	}
+
+	for i := s.cfg.MaxFiles - 1; i > 0; i-- {
+		err = os.Rename(fmt.Sprintf("%s.%d", s.cfg.Path, i), fmt.Sprintf("%s.%d", s.cfg.Path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	err = os.Rename(s.cfg.Path, s.cfg.Path+".1")
+	if err != nil {
+		return err
+	}
+
+	return s.open()
+}
+
+// HandleEvent writes the event to the file.
+func (s *fileSink) HandleEvent(event api.Event) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return
+	}
+
+	if s.size > 0 && s.size+int64(len(line)) > s.cfg.MaxSize {
+		err = s.rotate()
+		if err != nil {
+			return
+		}
+	}
+
+	n, _ := s.file.Write(line)
+	s.size += int64(n)
+}
+
+// Stop closes the file.
+func (s *fileSink) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file != nil {
+		_ = s.file.Close()
+		s.file = nil
+	}
+}
@@ -0,0 +1,18 @@
+package sink
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/canonical/lxd/lxd/loki"
+)
+
+// newLoki returns a sink pushing the events to a Loki server.
+func newLoki(ctx context.Context, cfg *Config) (Sink, error) {
+	u, err := url.Parse(cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	return loki.NewClient(ctx, u, cfg.Username, cfg.Password, cfg.CACert, cfg.Instance, cfg.Location, cfg.LogLevel, cfg.Labels, cfg.Types), nil
+}
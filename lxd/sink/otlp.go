@@ -0,0 +1,282 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// otlpSeverities maps log levels to OpenTelemetry severity numbers.
+var otlpSeverities = map[logrus.Level]int{
+	logrus.PanicLevel: 21, // FATAL
+	logrus.FatalLevel: 21, // FATAL
+	logrus.ErrorLevel: 17, // ERROR
+	logrus.WarnLevel:  13, // WARN
+	logrus.InfoLevel:  9,  // INFO
+	logrus.DebugLevel: 5,  // DEBUG
+	logrus.TraceLevel: 1,  // TRACE
+}
+
+// otlpBatchSize is the maximum number of records sent in a single request.
+const otlpBatchSize = 100
+
+// otlpBatchWait is the maximum time a record waits before being sent.
+const otlpBatchWait = time.Second
+
+// otlpSink exports the events as OpenTelemetry log records using OTLP over HTTP with JSON encoding.
+type otlpSink struct {
+	cfg    *Config
+	url    string
+	client *http.Client
+
+	records chan *record
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// OTLP/HTTP JSON types, see https://opentelemetry.io/docs/specs/otlp/.
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano   string         `json:"timeUnixNano"`
+	SeverityNumber int            `json:"severityNumber"`
+	SeverityText   string         `json:"severityText"`
+	Body           otlpAnyValue   `json:"body"`
+	Attributes     []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeLogs struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpResourceLogs struct {
+	Resource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	} `json:"resource"`
+
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpLogsRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+// newOTLP returns a sink exporting the events to the OTLP/HTTP endpoint at the address of the configuration.
+// The "/v1/logs" path is added to addresses without a path.
+func newOTLP(ctx context.Context, cfg *Config) (Sink, error) {
+	u, err := url.Parse(cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("Unsupported OTLP endpoint scheme %q", u.Scheme)
+	}
+
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/v1/logs"
+	}
+
+	s := &otlpSink{
+		cfg:     cfg,
+		url:     u.String(),
+		client:  &http.Client{Timeout: 10 * time.Second},
+		records: make(chan *record, 1000),
+	}
+
+	if cfg.CACert != "" {
+		tlsConfig, err := shared.GetTLSConfigMem("", "", cfg.CACert, "", false)
+		if err != nil {
+			return nil, err
+		}
+
+		s.client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.wg.Add(1)
+	go s.run()
+
+	return s, nil
+}
+
+// HandleEvent queues the event. Events are dropped if the queue is full.
+func (s *otlpSink) HandleEvent(event api.Event) {
+	r, err := newRecord(event, s.cfg.Location)
+	if err != nil {
+		return
+	}
+
+	select {
+	case s.records <- r:
+	default:
+	}
+}
+
+// Stop sends the pending records and stops exporting events.
+func (s *otlpSink) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *otlpSink) run() {
+	defer s.wg.Done()
+
+	batch := make([]*record, 0, otlpBatchSize)
+	ticker := time.NewTicker(otlpBatchWait)
+	defer ticker.Stop()
+
+	flush := func(ctx context.Context) {
+		if len(batch) == 0 {
+			return
+		}
+
+		_ = s.send(ctx, batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			// Give the pending records a last chance.
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			flush(ctx)
+			cancel()
+			return
+		case r := <-s.records:
+			batch = append(batch, r)
+			if len(batch) >= otlpBatchSize {
+				flush(s.ctx)
+			}
+
+		case <-ticker.C:
+			flush(s.ctx)
+		}
+	}
+}
+
+// send exports the records, retrying on connection errors, 429 and 5xx responses.
+func (s *otlpSink) send(ctx context.Context, records []*record) error {
+	body, err := json.Marshal(s.request(records))
+	if err != nil {
+		return err
+	}
+
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		status, err := s.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= 3 || (status > 0 && status != http.StatusTooManyRequests && status/100 != 5) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+func (s *otlpSink) post(ctx context.Context, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.cfg.Username != "" && s.cfg.Password != "" {
+		req.SetBasicAuth(s.cfg.Username, s.cfg.Password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode, fmt.Errorf("Server returned HTTP status %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// request builds the export request for the records. Records are grouped by location, which is exported as the
+// "host.name" resource attribute.
+func (s *otlpSink) request(records []*record) otlpLogsRequest {
+	byLocation := map[string][]otlpLogRecord{}
+	for _, r := range records {
+		attributes := []otlpKeyValue{{Key: "lxd.event.type", Value: otlpAnyValue{StringValue: r.eventType}}}
+		if r.project != "" {
+			attributes = append(attributes, otlpKeyValue{Key: "lxd.project", Value: otlpAnyValue{StringValue: r.project}})
+		}
+
+		keys := make([]string, 0, len(r.attributes))
+		for k := range r.attributes {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+		for _, k := range keys {
+			attributes = append(attributes, otlpKeyValue{Key: "lxd." + strings.ReplaceAll(k, "-", "_"), Value: otlpAnyValue{StringValue: r.attributes[k]}})
+		}
+
+		byLocation[r.location] = append(byLocation[r.location], otlpLogRecord{
+			TimeUnixNano:   strconv.FormatInt(r.time.UnixNano(), 10),
+			SeverityNumber: otlpSeverities[r.level],
+			SeverityText:   strings.ToUpper(r.level.String()),
+			Body:           otlpAnyValue{StringValue: r.message},
+			Attributes:     attributes,
+		})
+	}
+
+	req := otlpLogsRequest{}
+	for location, logRecords := range byLocation {
+		resourceLogs := otlpResourceLogs{}
+		resourceLogs.Resource.Attributes = []otlpKeyValue{{Key: "service.name", Value: otlpAnyValue{StringValue: "lxd"}}}
+		if location != "" {
+			resourceLogs.Resource.Attributes = append(resourceLogs.Resource.Attributes, otlpKeyValue{Key: "host.name", Value: otlpAnyValue{StringValue: location}})
+		}
+
+		scopeLogs := otlpScopeLogs{LogRecords: logRecords}
+		scopeLogs.Scope.Name = "lxd"
+		resourceLogs.ScopeLogs = []otlpScopeLogs{scopeLogs}
+
+		req.ResourceLogs = append(req.ResourceLogs, resourceLogs)
+	}
+
+	return req
+}
@@ -0,0 +1,301 @@
+// Package sink forwards LXD events to external log targets.
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/units"
+)
+
+// Sink is an external target events are forwarded to.
+type Sink interface {
+	// HandleEvent forwards the event to the target. It doesn't block on the target.
+	HandleEvent(event api.Event)
+
+	// Stop stops forwarding events.
+	Stop()
+}
+
+// Supported target types.
+const (
+	TypeLoki   = "loki"
+	TypeSyslog = "syslog"
+	TypeOTLP   = "otlp"
+	TypeFile   = "file"
+)
+
+// Types is the list of supported target types.
+var Types = []string{TypeLoki, TypeSyslog, TypeOTLP, TypeFile}
+
+// EventTypes is the list of event types that can be forwarded.
+var EventTypes = []string{api.EventTypeLifecycle, api.EventTypeLogging, api.EventTypeOVN}
+
+// Config is the configuration of a sink.
+type Config struct {
+	Name string
+	Type string
+
+	// Address is the URL of Loki and OTLP targets, and the "tcp://", "udp://" or "tls://" address of syslog targets.
+	Address  string
+	Username string
+	Password string
+	CACert   string
+
+	// Instance and Labels are only used by Loki targets.
+	Instance string
+	Labels   []string
+
+	// Path, MaxSize and MaxFiles are only used by file targets.
+	Path     string
+	MaxSize  int64
+	MaxFiles int
+
+	// Location overrides the location of the events (used on standalone systems).
+	Location string
+
+	// Filters.
+	Types    []string
+	LogLevel string
+	Projects []string
+}
+
+// ParseConfig returns the configuration of the sink with the given name from its configuration keys (without the
+// "logging.<name>." prefix). The path of file targets is resolved within logDir.
+func ParseConfig(name string, config map[string]string, logDir string) (*Config, error) {
+	cfg := &Config{
+		Name:     name,
+		Type:     config["target.type"],
+		Address:  config["target.address"],
+		Username: config["target.username"],
+		Password: config["target.password"],
+		CACert:   config["target.ca_cert"],
+		Instance: config["target.instance"],
+		Labels:   shared.SplitNTrimSpace(config["target.labels"], ",", -1, true),
+		Types:    shared.SplitNTrimSpace(config["types"], ",", -1, true),
+		LogLevel: config["logging.level"],
+		Projects: shared.SplitNTrimSpace(config["projects"], ",", -1, true),
+		MaxSize:  100 * 1024 * 1024,
+		MaxFiles: 5,
+	}
+
+	if cfg.Types == nil {
+		cfg.Types = []string{api.EventTypeLifecycle, api.EventTypeLogging}
+	}
+
+	if cfg.LogLevel == "" {
+		cfg.LogLevel = logrus.InfoLevel.String()
+	}
+
+	switch cfg.Type {
+	case "":
+		return nil, fmt.Errorf("Missing target type")
+	case TypeLoki, TypeSyslog, TypeOTLP:
+		if cfg.Address == "" {
+			return nil, fmt.Errorf("Missing target address")
+		}
+
+	case TypeFile:
+		if config["target.path"] == "" {
+			return nil, fmt.Errorf("Missing target path")
+		}
+
+		cfg.Path = filepath.Join(logDir, config["target.path"])
+
+		if config["target.max_size"] != "" {
+			size, err := units.ParseByteSizeString(config["target.max_size"])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid maximum file size: %w", err)
+			}
+
+			cfg.MaxSize = size
+		}
+
+		if config["target.max_files"] != "" {
+			files, err := strconv.Atoi(config["target.max_files"])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid maximum number of files: %w", err)
+			}
+
+			cfg.MaxFiles = files
+		}
+
+	default:
+		return nil, fmt.Errorf("Unsupported target type %q", cfg.Type)
+	}
+
+	return cfg, nil
+}
+
+// New starts the sink with the given configuration. It runs until stopped or until ctx is cancelled.
+func New(ctx context.Context, cfg *Config) (Sink, error) {
+	var s Sink
+	var err error
+
+	switch cfg.Type {
+	case TypeLoki:
+		s, err = newLoki(ctx, cfg)
+	case TypeSyslog:
+		s, err = newSyslog(ctx, cfg)
+	case TypeOTLP:
+		s, err = newOTLP(ctx, cfg)
+	case TypeFile:
+		s, err = newFile(ctx, cfg)
+	default:
+		err = fmt.Errorf("Unsupported target type %q", cfg.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &filtered{Sink: s, cfg: cfg}, nil
+}
+
+// filtered only passes the events matching the filters of the sink configuration.
+type filtered struct {
+	Sink
+
+	cfg *Config
+}
+
+// HandleEvent forwards the event if it matches the filters.
+func (f *filtered) HandleEvent(event api.Event) {
+	if f.cfg.Matches(event) {
+		f.Sink.HandleEvent(event)
+	}
+}
+
+// Matches returns whether the event passes the type, level and project filters. Events that aren't tied to a
+// project are not subject to the project filter.
+func (cfg *Config) Matches(event api.Event) bool {
+	if !slices.Contains(cfg.Types, event.Type) {
+		return false
+	}
+
+	if event.Project != "" && len(cfg.Projects) > 0 && !slices.Contains(cfg.Projects, event.Project) {
+		return false
+	}
+
+	if event.Type == api.EventTypeLogging || event.Type == api.EventTypeOVN {
+		logEvent := api.EventLogging{}
+		err := json.Unmarshal(event.Metadata, &logEvent)
+		if err != nil {
+			return false
+		}
+
+		// The errors can be ignored as the values are validated elsewhere.
+		level, _ := logrus.ParseLevel(logEvent.Level)
+		minLevel, _ := logrus.ParseLevel(cfg.LogLevel)
+		if level > minLevel {
+			return false
+		}
+	}
+
+	return true
+}
+
+// record is the target independent representation of an event.
+type record struct {
+	time       time.Time
+	eventType  string
+	level      logrus.Level
+	location   string
+	project    string
+	message    string
+	attributes map[string]string
+}
+
+// newRecord converts a lifecycle, logging or OVN event to a record.
+func newRecord(event api.Event, location string) (*record, error) {
+	r := &record{
+		time:       event.Timestamp,
+		eventType:  event.Type,
+		level:      logrus.InfoLevel,
+		location:   event.Location,
+		project:    event.Project,
+		attributes: map[string]string{},
+	}
+
+	if location != "" {
+		r.location = location
+	}
+
+	switch event.Type {
+	case api.EventTypeLifecycle:
+		lifecycleEvent := api.EventLifecycle{}
+		err := json.Unmarshal(event.Metadata, &lifecycleEvent)
+		if err != nil {
+			return nil, err
+		}
+
+		r.message = lifecycleEvent.Action
+		r.attributes["action"] = lifecycleEvent.Action
+		r.attributes["source"] = lifecycleEvent.Source
+
+		if lifecycleEvent.Name != "" {
+			r.attributes["name"] = lifecycleEvent.Name
+		}
+
+		if lifecycleEvent.Requestor != nil {
+			r.attributes["requester-address"] = lifecycleEvent.Requestor.Address
+			r.attributes["requester-protocol"] = lifecycleEvent.Requestor.Protocol
+			r.attributes["requester-username"] = lifecycleEvent.Requestor.Username
+		}
+
+		for k, v := range lifecycleEvent.Context {
+			r.attributes["context-"+k] = fmt.Sprintf("%v", v)
+		}
+
+	case api.EventTypeLogging, api.EventTypeOVN:
+		logEvent := api.EventLogging{}
+		err := json.Unmarshal(event.Metadata, &logEvent)
+		if err != nil {
+			return nil, err
+		}
+
+		level, err := logrus.ParseLevel(logEvent.Level)
+		if err == nil {
+			r.level = level
+		}
+
+		r.message = logEvent.Message
+		for k, v := range logEvent.Context {
+			r.attributes[k] = v
+		}
+
+	default:
+		return nil, fmt.Errorf("Unsupported event type %q", event.Type)
+	}
+
+	return r, nil
+}
+
+// text returns the message followed by the sorted attributes in key="value" form.
+func (r *record) text() string {
+	keys := make([]string, 0, len(r.attributes))
+	for k := range r.attributes {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(r.message)
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%q", k, r.attributes[k])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,179 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func lifecycleEvent(t *testing.T, project string, action string) api.Event {
+	metadata, err := json.Marshal(api.EventLifecycle{Action: action, Source: "/1.0/instances/c1"})
+	require.NoError(t, err)
+
+	return api.Event{Type: api.EventTypeLifecycle, Timestamp: time.Now(), Location: "lxd01", Project: project, Metadata: metadata}
+}
+
+func loggingEvent(t *testing.T, level string, message string) api.Event {
+	metadata, err := json.Marshal(api.EventLogging{Level: level, Message: message, Context: map[string]string{"key": "value"}})
+	require.NoError(t, err)
+
+	return api.Event{Type: api.EventTypeLogging, Timestamp: time.Now(), Location: "lxd01", Metadata: metadata}
+}
+
+func TestParseConfig(t *testing.T) {
+	_, err := ParseConfig("foo", map[string]string{}, "/var/log/lxd")
+	assert.Error(t, err)
+
+	_, err = ParseConfig("foo", map[string]string{"target.type": "syslog"}, "/var/log/lxd")
+	assert.Error(t, err)
+
+	cfg, err := ParseConfig("foo", map[string]string{"target.type": "file", "target.path": "events.log", "target.max_size": "1MiB"}, "/var/log/lxd")
+	require.NoError(t, err)
+	assert.Equal(t, "/var/log/lxd/events.log", cfg.Path)
+	assert.Equal(t, int64(1024*1024), cfg.MaxSize)
+	assert.Equal(t, 5, cfg.MaxFiles)
+	assert.Equal(t, []string{api.EventTypeLifecycle, api.EventTypeLogging}, cfg.Types)
+	assert.Equal(t, "info", cfg.LogLevel)
+}
+
+func TestConfigMatches(t *testing.T) {
+	cfg := &Config{Types: []string{api.EventTypeLifecycle, api.EventTypeLogging}, LogLevel: "warning", Projects: []string{"foo"}}
+
+	assert.True(t, cfg.Matches(lifecycleEvent(t, "foo", "instance-started")))
+	assert.False(t, cfg.Matches(lifecycleEvent(t, "bar", "instance-started")))
+	assert.True(t, cfg.Matches(loggingEvent(t, "error", "Failed")))
+	assert.False(t, cfg.Matches(loggingEvent(t, "info", "Done")))
+	assert.False(t, cfg.Matches(api.Event{Type: api.EventTypeOVN}))
+}
+
+func TestSyslog(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	lines := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer func() { _ = conn.Close() }()
+
+		reader := bufio.NewReader(conn)
+		length, err := reader.ReadString(' ')
+		if err != nil {
+			return
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(length))
+		if err != nil {
+			return
+		}
+
+		msg := make([]byte, n)
+		_, err = io.ReadFull(reader, msg)
+		if err != nil {
+			return
+		}
+
+		lines <- string(msg)
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s, err := New(ctx, &Config{Type: TypeSyslog, Address: "tcp://" + listener.Addr().String(), Types: []string{api.EventTypeLogging}, LogLevel: "info"})
+	require.NoError(t, err)
+	defer s.Stop()
+
+	s.HandleEvent(loggingEvent(t, "error", "Something failed"))
+
+	select {
+	case line := <-lines:
+		// RFC 5424 header with daemon facility and error severity.
+		assert.Regexp(t, `^<27>1 \S+ lxd01 lxd \d+ logging - Something failed key="value"`, line)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for syslog message")
+	}
+}
+
+func TestOTLP(t *testing.T) {
+	requests := make(chan otlpLogsRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/logs", r.URL.Path)
+
+		req := otlpLogsRequest{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests <- req
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s, err := New(ctx, &Config{Type: TypeOTLP, Address: server.URL, Types: []string{api.EventTypeLifecycle}, LogLevel: "info"})
+	require.NoError(t, err)
+	defer s.Stop()
+
+	s.HandleEvent(lifecycleEvent(t, "default", "instance-started"))
+
+	select {
+	case req := <-requests:
+		require.Len(t, req.ResourceLogs, 1)
+		assert.Contains(t, req.ResourceLogs[0].Resource.Attributes, otlpKeyValue{Key: "host.name", Value: otlpAnyValue{StringValue: "lxd01"}})
+
+		records := req.ResourceLogs[0].ScopeLogs[0].LogRecords
+		require.Len(t, records, 1)
+		assert.Equal(t, "instance-started", records[0].Body.StringValue)
+		assert.Equal(t, "INFO", records[0].SeverityText)
+		assert.Contains(t, records[0].Attributes, otlpKeyValue{Key: "lxd.project", Value: otlpAnyValue{StringValue: "default"}})
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for OTLP request")
+	}
+}
+
+func TestFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	event := lifecycleEvent(t, "default", "instance-started")
+	line, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	// Each file holds two events and a single rotated file is kept.
+	s, err := New(ctx, &Config{Type: TypeFile, Path: path, MaxSize: int64(2 * (len(line) + 1)), MaxFiles: 1, Types: []string{api.EventTypeLifecycle}})
+	require.NoError(t, err)
+	defer s.Stop()
+
+	for i := 0; i < 5; i++ {
+		s.HandleEvent(event)
+	}
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(current), "\n"))
+
+	rotated, err := os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(rotated), "\n"))
+
+	assert.NoFileExists(t, path+".2")
+}
@@ -0,0 +1,196 @@
+package sink
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// syslogFacilityDaemon is the syslog facility used for all messages.
+const syslogFacilityDaemon = 3
+
+// syslogSeverities maps log levels to syslog severities.
+var syslogSeverities = map[logrus.Level]int{
+	logrus.PanicLevel: 1, // Alert
+	logrus.FatalLevel: 2, // Critical
+	logrus.ErrorLevel: 3, // Error
+	logrus.WarnLevel:  4, // Warning
+	logrus.InfoLevel:  6, // Informational
+	logrus.DebugLevel: 7, // Debug
+	logrus.TraceLevel: 7, // Debug
+}
+
+// syslogSink sends the events to a syslog server as RFC 5424 messages.
+type syslogSink struct {
+	cfg       *Config
+	network   string
+	address   string
+	tlsConfig *tls.Config
+
+	conn     net.Conn
+	messages chan []byte
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// newSyslog returns a sink sending the events to the syslog server at the "tcp://", "udp://" or "tls://" address
+// of the configuration. Messages sent over TCP and TLS are framed using octet counting (RFC 6587).
+func newSyslog(ctx context.Context, cfg *Config) (Sink, error) {
+	u, err := url.Parse(cfg.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &syslogSink{
+		cfg:      cfg,
+		messages: make(chan []byte, 1000),
+	}
+
+	port := "514"
+	switch u.Scheme {
+	case "tcp", "udp":
+		s.network = u.Scheme
+	case "tls":
+		s.network = "tcp"
+		port = "6514"
+
+		s.tlsConfig = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+		if cfg.CACert != "" {
+			s.tlsConfig, err = shared.GetTLSConfigMem("", "", cfg.CACert, "", false)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("Unsupported syslog protocol %q", u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("Missing syslog server address")
+	}
+
+	s.address = u.Host
+	if u.Port() == "" {
+		s.address = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.wg.Add(1)
+	go s.run()
+
+	return s, nil
+}
+
+// HandleEvent queues the event. Events are dropped if the queue is full.
+func (s *syslogSink) HandleEvent(event api.Event) {
+	r, err := newRecord(event, s.cfg.Location)
+	if err != nil {
+		return
+	}
+
+	select {
+	case s.messages <- formatSyslog(r):
+	default:
+	}
+}
+
+// Stop stops sending events and closes the connection.
+func (s *syslogSink) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *syslogSink) run() {
+	defer s.wg.Done()
+
+	defer func() {
+		if s.conn != nil {
+			_ = s.conn.Close()
+		}
+	}()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case msg := <-s.messages:
+			// Reconnect once if the connection was closed by the server.
+			for attempt := 0; attempt < 2; attempt++ {
+				err := s.send(msg)
+				if err == nil {
+					break
+				}
+
+				if s.conn != nil {
+					_ = s.conn.Close()
+					s.conn = nil
+				}
+
+				// Don't hammer an unavailable server.
+				select {
+				case <-s.ctx.Done():
+					return
+				case <-time.After(time.Second):
+				}
+			}
+		}
+	}
+}
+
+// send writes the message to the server, connecting to it first if needed.
+func (s *syslogSink) send(msg []byte) error {
+	if s.conn == nil {
+		dialer := &net.Dialer{Timeout: 10 * time.Second}
+
+		var err error
+		if s.tlsConfig != nil {
+			s.conn, err = tls.DialWithDialer(dialer, s.network, s.address, s.tlsConfig)
+		} else {
+			s.conn, err = dialer.DialContext(s.ctx, s.network, s.address)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.network == "tcp" {
+		msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+	}
+
+	err := s.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if err != nil {
+		return err
+	}
+
+	_, err = s.conn.Write(msg)
+	return err
+}
+
+// formatSyslog returns the RFC 5424 message for the record. The event type is used as message ID and the record
+// attributes are appended to the message.
+func formatSyslog(r *record) []byte {
+	severity, ok := syslogSeverities[r.level]
+	if !ok {
+		severity = syslogSeverities[logrus.InfoLevel]
+	}
+
+	hostname := r.location
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	return []byte(fmt.Sprintf("<%d>1 %s %s lxd %d %s - %s", syslogFacilityDaemon*8+severity, r.time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"), hostname, os.Getpid(), r.eventType, r.text()))
+}
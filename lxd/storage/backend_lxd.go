@@ -3129,7 +3129,9 @@ func (b *lxdBackend) CleanupInstancePaths(inst instance.Instance, op *operations
 }
 
 // BackupInstance creates an instance backup.
-func (b *lxdBackend) BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, op *operations.Operation) error {
+// The optional freeze function is called right before the storage driver takes the temporary snapshot the backup
+// is read from and returns the function to call once it has been taken.
+func (b *lxdBackend) BackupInstance(inst instance.Instance, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots bool, freeze func() func(), op *operations.Operation) error {
 	l := b.logger.AddContext(logger.Ctx{"project": inst.Project().Name, "instance": inst.Name(), "optimized": optimized, "snapshots": snapshots})
 	l.Debug("BackupInstance started")
 	defer l.Debug("BackupInstance finished")
@@ -3186,6 +3188,10 @@ func (b *lxdBackend) BackupInstance(inst instance.Instance, tarWriter *instancew
 		}
 	}
 
+	if freeze != nil {
+		vol.SetFreezer(freeze)
+	}
+
 	volCopy := drivers.NewVolumeCopy(vol, sourceSnapshots...)
 
 	err = b.driver.BackupVolume(volCopy, tarWriter, optimized, snapNames, op)
@@ -5121,6 +5127,13 @@ func (b *lxdBackend) GetBucketURL(bucketName string) *url.URL {
 	return b.driver.GetBucketURL(bucketName)
 }
 
+// addCustomVolumeRollback records the custom volume the operation has just created in the database, so that it's
+// deleted if the operation is interrupted by a restart of LXD.
+func (b *lxdBackend) addCustomVolumeRollback(projectName string, volName string, vol drivers.Volume, op *operations.Operation) error {
+	u := api.NewURL().Path(version.APIVersion, "storage-pools", b.name, "volumes", cluster.StoragePoolVolumeTypeNameCustom, volName).Project(projectName)
+	return op.AddRollback(u, vol.Config()["volatile.uuid"])
+}
+
 // CreateCustomVolume creates an empty custom volume.
 func (b *lxdBackend) CreateCustomVolume(projectName string, volName string, desc string, config map[string]string, contentType drivers.ContentType, op *operations.Operation) error {
 	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volName": volName, "desc": desc, "config": config, "contentType": contentType})
@@ -5291,6 +5304,11 @@ func (b *lxdBackend) CreateCustomVolumeFromCopy(projectName string, srcProjectNa
 
 		revert.Add(func() { _ = VolumeDBDelete(b, projectName, volName, vol.Type()) })
 
+		err = b.addCustomVolumeRollback(projectName, volName, vol, op)
+		if err != nil {
+			return err
+		}
+
 		targetSnapshots := make([]drivers.Volume, 0, len(snapshotNames))
 
 		// Create database entries for new storage volume snapshots.
@@ -5695,6 +5713,11 @@ func (b *lxdBackend) CreateCustomVolumeFromMigration(projectName string, conn io
 		}
 
 		revert.Add(func() { _ = VolumeDBDelete(b, projectName, args.Name, vol.Type()) })
+
+		err = b.addCustomVolumeRollback(projectName, args.Name, vol, op)
+		if err != nil {
+			return err
+		}
 	}
 
 	if len(args.Snapshots) > 0 {
@@ -7574,6 +7597,11 @@ func (b *lxdBackend) CreateCustomVolumeFromISO(projectName string, volName strin
 
 	revert.Add(func() { _ = VolumeDBDelete(b, projectName, volName, vol.Type()) })
 
+	err = b.addCustomVolumeRollback(projectName, volName, vol, op)
+	if err != nil {
+		return err
+	}
+
 	_, err = srcData.Seek(0, io.SeekStart)
 	if err != nil {
 		return err
@@ -7671,6 +7699,11 @@ func (b *lxdBackend) CreateCustomVolumeFromBackup(srcBackup backup.Info, srcData
 
 	revert.Add(func() { _ = VolumeDBDelete(b, srcBackup.Project, srcBackup.Name, vol.Type()) })
 
+	err = b.addCustomVolumeRollback(srcBackup.Project, srcBackup.Name, vol, op)
+	if err != nil {
+		return err
+	}
+
 	sourceSnapshots := make([]drivers.Volume, 0, len(srcBackup.Config.VolumeSnapshots))
 
 	// Create database entries fro new storage volume snapshots.
@@ -1406,8 +1406,11 @@ func (d *btrfs) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceT
 		// Because the generic backup method will not take a consistent backup if files are being modified
 		// as they are copied to the tarball, as BTRFS allows us to take a quick snapshot without impacting
 		// the parent volume we do so here to ensure the backup taken is consistent.
-		if vol.contentType == ContentTypeFS {
+		// The root disk file of virtual machines lives in the same subvolume so it is covered as well.
+		if vol.contentType == ContentTypeFS || vol.IsVMBlock() {
+			thaw := vol.freeze()
 			snapshotPath, cleanup, err := d.readonlySnapshot(vol.Volume)
+			thaw()
 			if err != nil {
 				return err
 			}
@@ -1616,7 +1619,9 @@ func (d *btrfs) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceT
 
 	// Create the read-only snapshot.
 	targetVolume := fmt.Sprintf("%s/.backup", tmpInstanceMntPoint)
+	thaw := vol.freeze()
 	_, err = d.snapshotSubvolume(sourceVolume, targetVolume, true)
+	thaw()
 	if err != nil {
 		return err
 	}
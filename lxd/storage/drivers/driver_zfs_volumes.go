@@ -2763,7 +2763,9 @@ func (d *zfs) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTar
 		// as they are copied to the tarball, as ZFS allows us to take a quick snapshot without impacting
 		// the parent volume we do so here to ensure the backup taken is consistent.
 		if vol.contentType == ContentTypeFS && !d.isBlockBacked(vol.Volume) {
+			thaw := vol.freeze()
 			snapshotPath, cleanup, err := d.readonlySnapshot(vol.Volume)
+			thaw()
 			if err != nil {
 				return err
 			}
@@ -2887,7 +2889,9 @@ func (d *zfs) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTar
 
 	// Create a temporary read-only snapshot.
 	srcSnapshot := fmt.Sprintf("%s@backup-%s", d.dataset(vol.Volume, false), uuid.New().String())
+	thaw := vol.freeze()
 	_, err := shared.RunCommand("zfs", "snapshot", "-r", srcSnapshot)
+	thaw()
 	if err != nil {
 		return err
 	}
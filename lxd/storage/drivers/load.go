@@ -46,7 +46,7 @@ func Load(state *state.State, driverName string, name string, config map[string]
 		return nil, err
 	}
 
-	return d, nil
+	return &tracedDriver{Driver: d}, nil
 }
 
 // SupportedDrivers returns a list of supported storage drivers by loading each storage driver and running its
@@ -0,0 +1,181 @@
+package drivers
+
+import (
+	"io"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/canonical/lxd/lxd/backup"
+	"github.com/canonical/lxd/lxd/instancewriter"
+	"github.com/canonical/lxd/lxd/migration"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/tracing"
+	"github.com/canonical/lxd/shared/revert"
+)
+
+// tracedDriver records a span for each of the long running calls to the wrapped driver. The spans are children of
+// the span of the operation the call is part of.
+type tracedDriver struct {
+	Driver
+}
+
+// start starts the span of a call to the driver about the given volume.
+func (d *tracedDriver) start(op *operations.Operation, method string, vol Volume) trace.Span {
+	_, span := tracing.Start(op.TraceContext(), "storage "+method,
+		attribute.String("lxd.storage.driver", d.Info().Name),
+		attribute.String("lxd.storage.pool", d.Name()),
+		attribute.String("lxd.storage.volume", vol.Name()),
+		attribute.String("lxd.storage.volume.type", string(vol.Type())),
+	)
+
+	return span
+}
+
+// Delete removes the storage pool.
+func (d *tracedDriver) Delete(op *operations.Operation) (err error) {
+	_, span := tracing.Start(op.TraceContext(), "storage Delete",
+		attribute.String("lxd.storage.driver", d.Info().Name),
+		attribute.String("lxd.storage.pool", d.Name()),
+	)
+
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.Delete(op)
+}
+
+// CreateBucket creates a storage bucket.
+func (d *tracedDriver) CreateBucket(bucket Volume, op *operations.Operation) (err error) {
+	span := d.start(op, "CreateBucket", bucket)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.CreateBucket(bucket, op)
+}
+
+// DeleteBucket deletes a storage bucket.
+func (d *tracedDriver) DeleteBucket(bucket Volume, op *operations.Operation) (err error) {
+	span := d.start(op, "DeleteBucket", bucket)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.DeleteBucket(bucket, op)
+}
+
+// CreateVolume creates a volume.
+func (d *tracedDriver) CreateVolume(vol Volume, filler *VolumeFiller, op *operations.Operation) (err error) {
+	span := d.start(op, "CreateVolume", vol)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.CreateVolume(vol, filler, op)
+}
+
+// CreateVolumeFromCopy creates a volume by copying another one.
+func (d *tracedDriver) CreateVolumeFromCopy(vol VolumeCopy, srcVol VolumeCopy, allowInconsistent bool, op *operations.Operation) (err error) {
+	span := d.start(op, "CreateVolumeFromCopy", vol.Volume)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.CreateVolumeFromCopy(vol, srcVol, allowInconsistent, op)
+}
+
+// RefreshVolume refreshes a volume from another one.
+func (d *tracedDriver) RefreshVolume(vol VolumeCopy, srcVol VolumeCopy, refreshSnapshots []string, allowInconsistent bool, op *operations.Operation) (err error) {
+	span := d.start(op, "RefreshVolume", vol.Volume)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.RefreshVolume(vol, srcVol, refreshSnapshots, allowInconsistent, op)
+}
+
+// DeleteVolume deletes a volume.
+func (d *tracedDriver) DeleteVolume(vol Volume, op *operations.Operation) (err error) {
+	span := d.start(op, "DeleteVolume", vol)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.DeleteVolume(vol, op)
+}
+
+// RenameVolume renames a volume.
+func (d *tracedDriver) RenameVolume(vol Volume, newName string, op *operations.Operation) (err error) {
+	span := d.start(op, "RenameVolume", vol)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.RenameVolume(vol, newName, op)
+}
+
+// SetVolumeQuota sets the quota of a volume.
+func (d *tracedDriver) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, op *operations.Operation) (err error) {
+	span := d.start(op, "SetVolumeQuota", vol)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.SetVolumeQuota(vol, size, allowUnsafeResize, op)
+}
+
+// MountVolume mounts a volume.
+func (d *tracedDriver) MountVolume(vol Volume, op *operations.Operation) (err error) {
+	span := d.start(op, "MountVolume", vol)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.MountVolume(vol, op)
+}
+
+// UnmountVolume unmounts a volume.
+func (d *tracedDriver) UnmountVolume(vol Volume, keepBlockDev bool, op *operations.Operation) (unmounted bool, err error) {
+	span := d.start(op, "UnmountVolume", vol)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.UnmountVolume(vol, keepBlockDev, op)
+}
+
+// CreateVolumeSnapshot creates a volume snapshot.
+func (d *tracedDriver) CreateVolumeSnapshot(snapVol Volume, op *operations.Operation) (err error) {
+	span := d.start(op, "CreateVolumeSnapshot", snapVol)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.CreateVolumeSnapshot(snapVol, op)
+}
+
+// DeleteVolumeSnapshot deletes a volume snapshot.
+func (d *tracedDriver) DeleteVolumeSnapshot(snapVol Volume, op *operations.Operation) (err error) {
+	span := d.start(op, "DeleteVolumeSnapshot", snapVol)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.DeleteVolumeSnapshot(snapVol, op)
+}
+
+// RestoreVolume restores a volume from a snapshot.
+func (d *tracedDriver) RestoreVolume(vol Volume, snapVol Volume, op *operations.Operation) (err error) {
+	span := d.start(op, "RestoreVolume", vol)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.RestoreVolume(vol, snapVol, op)
+}
+
+// MigrateVolume sends a volume for migration.
+func (d *tracedDriver) MigrateVolume(vol VolumeCopy, conn io.ReadWriteCloser, volSrcArgs *migration.VolumeSourceArgs, op *operations.Operation) (err error) {
+	span := d.start(op, "MigrateVolume", vol.Volume)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.MigrateVolume(vol, conn, volSrcArgs, op)
+}
+
+// CreateVolumeFromMigration creates a volume being sent via a migration.
+func (d *tracedDriver) CreateVolumeFromMigration(vol VolumeCopy, conn io.ReadWriteCloser, volTargetArgs migration.VolumeTargetArgs, preFiller *VolumeFiller, op *operations.Operation) (err error) {
+	span := d.start(op, "CreateVolumeFromMigration", vol.Volume)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.CreateVolumeFromMigration(vol, conn, volTargetArgs, preFiller, op)
+}
+
+// BackupVolume writes a backup of a volume.
+func (d *tracedDriver) BackupVolume(vol VolumeCopy, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots []string, op *operations.Operation) (err error) {
+	span := d.start(op, "BackupVolume", vol.Volume)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.BackupVolume(vol, tarWriter, optimized, snapshots, op)
+}
+
+// CreateVolumeFromBackup restores a backup into a new volume.
+func (d *tracedDriver) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, srcData io.ReadSeeker, op *operations.Operation) (postHook VolumePostHook, revertHook revert.Hook, err error) {
+	span := d.start(op, "CreateVolumeFromBackup", vol.Volume)
+	defer func() { tracing.End(span, err) }()
+
+	return d.Driver.CreateVolumeFromBackup(vol, srcBackup, srcData, op)
+}
@@ -2,9 +2,14 @@ package drivers
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -249,3 +254,137 @@ func CephKeyring(cluster string, client string) (string, error) {
 
 	return cephSecret, nil
 }
+
+// CephBlocklistExpiry is how long a client fenced by LXD stays blocklisted in Ceph.
+// Ceph expires blocklist entries after an hour by default, which could let a fenced client that is still running
+// write to its RBD images again once its instances run elsewhere. The entries must instead be removed by the
+// operator with "ceph osd blocklist rm <address>" once the client is known to be stopped.
+const CephBlocklistExpiry = 10 * 365 * 24 * time.Hour
+
+// cephRunCommand runs the ceph and rbd commands, it is overridden in tests.
+var cephRunCommand = shared.RunCommandContext
+
+// cephBlocklistAddArgs returns the arguments of the ceph command blocklisting a client address until it expires.
+func cephBlocklistAddArgs(cluster string, user string, address string, expiry time.Duration) []string {
+	return []string{
+		"--name", fmt.Sprintf("client.%s", user),
+		"--cluster", cluster,
+		"osd",
+		"blocklist",
+		"add",
+		address,
+		strconv.FormatInt(int64(expiry.Seconds()), 10),
+	}
+}
+
+// CephBlocklistAdd blocklists a client address in a Ceph cluster for CephBlocklistExpiry, so that a client which
+// may still be running can't write to the RBD images anymore.
+func CephBlocklistAdd(ctx context.Context, cluster string, user string, address string) error {
+	_, err := cephRunCommand(ctx, "ceph", cephBlocklistAddArgs(cluster, user, address, CephBlocklistExpiry)...)
+	if err != nil {
+		return fmt.Errorf("Failed blocklisting %q in Ceph cluster %q: %w", address, cluster, err)
+	}
+
+	return nil
+}
+
+// CephRBDClientsFence fences the clients using an RBD image and removes their locks.
+// The clients are found through the watchers of the image, which every client mapping it registers, and the owners
+// of its locks. Each of them is blocklisted by the address it uses to reach the Ceph cluster before the locks are
+// removed, so that it can't write to the image anymore once it is used by another client.
+// It returns the addresses of the blocklisted clients.
+func CephRBDClientsFence(ctx context.Context, cluster string, user string, pool string, image string) ([]string, error) {
+	out, err := cephRunCommand(ctx, "rbd",
+		"--id", user,
+		"--cluster", cluster,
+		"--pool", pool,
+		"status",
+		"--format", "json",
+		image)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting status of RBD image %q: %w", image, err)
+	}
+
+	status := struct {
+		Watchers []struct {
+			Address string `json:"address"`
+		} `json:"watchers"`
+	}{}
+
+	if strings.TrimSpace(out) != "" {
+		err = json.Unmarshal([]byte(out), &status)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing status of RBD image %q: %w", image, err)
+		}
+	}
+
+	out, err = cephRunCommand(ctx, "rbd",
+		"--id", user,
+		"--cluster", cluster,
+		"--pool", pool,
+		"lock",
+		"list",
+		"--format", "json",
+		image)
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing locks of RBD image %q: %w", image, err)
+	}
+
+	locks := []struct {
+		ID      string `json:"id"`
+		Locker  string `json:"locker"`
+		Address string `json:"address"`
+	}{}
+
+	if strings.TrimSpace(out) != "" {
+		err = json.Unmarshal([]byte(out), &locks)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing locks of RBD image %q: %w", image, err)
+		}
+	}
+
+	addresses := []string{}
+	for _, watcher := range status.Watchers {
+		if watcher.Address == "" {
+			return nil, fmt.Errorf("Missing address of watcher of RBD image %q", image)
+		}
+
+		if !slices.Contains(addresses, watcher.Address) {
+			addresses = append(addresses, watcher.Address)
+		}
+	}
+
+	for _, lock := range locks {
+		if lock.Address == "" {
+			return nil, fmt.Errorf("Missing owner address of lock %q of RBD image %q", lock.ID, image)
+		}
+
+		if !slices.Contains(addresses, lock.Address) {
+			addresses = append(addresses, lock.Address)
+		}
+	}
+
+	for _, address := range addresses {
+		err = CephBlocklistAdd(ctx, cluster, user, address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, lock := range locks {
+		_, err = cephRunCommand(ctx, "rbd",
+			"--id", user,
+			"--cluster", cluster,
+			"--pool", pool,
+			"lock",
+			"remove",
+			image,
+			lock.ID,
+			lock.Locker)
+		if err != nil {
+			return nil, fmt.Errorf("Failed removing lock %q of RBD image %q: %w", lock.ID, image, err)
+		}
+	}
+
+	return addresses, nil
+}
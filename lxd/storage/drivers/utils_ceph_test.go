@@ -0,0 +1,131 @@
+package drivers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_cephBlocklistAddArgs(t *testing.T) {
+	args := cephBlocklistAddArgs("ceph", "admin", "10.0.0.2:0/3710147553", 2*time.Hour)
+	assert.Equal(t, []string{"--name", "client.admin", "--cluster", "ceph", "osd", "blocklist", "add", "10.0.0.2:0/3710147553", "7200"}, args)
+
+	// The default expiry outlasts the one hour used by Ceph when none is given.
+	args = cephBlocklistAddArgs("ceph", "admin", "10.0.0.2:0/3710147553", CephBlocklistExpiry)
+	assert.Equal(t, "315360000", args[len(args)-1])
+}
+
+func TestCephRBDClientsFence(t *testing.T) {
+	statusCmd := "rbd --id admin --cluster ceph --pool lxd status --format json container_c1"
+	lockListCmd := "rbd --id admin --cluster ceph --pool lxd lock list --format json container_c1"
+
+	tests := []struct {
+		name          string
+		status        string
+		locks         string
+		failOn        string
+		wantCmds      []string
+		wantAddresses []string
+		wantErr       bool
+	}{
+		{
+			name:          "No watchers and no locks",
+			status:        `{"watchers":[]}`,
+			locks:         "[]",
+			wantCmds:      []string{statusCmd, lockListCmd},
+			wantAddresses: []string{},
+		},
+		{
+			name:   "Watchers of an image without locks are blocklisted",
+			status: `{"watchers":[{"address":"10.0.0.2:0/3710147553","client":4123,"cookie":18446462598732840961}]}`,
+			locks:  "[]",
+			wantCmds: []string{
+				statusCmd,
+				lockListCmd,
+				"ceph --name client.admin --cluster ceph osd blocklist add 10.0.0.2:0/3710147553 315360000",
+			},
+			wantAddresses: []string{"10.0.0.2:0/3710147553"},
+		},
+		{
+			name:   "Lock owners are blocklisted before their locks are removed",
+			status: `{"watchers":[{"address":"10.0.0.2:0/3710147553","client":4123,"cookie":18446462598732840961}]}`,
+			locks:  `[{"id":"auto 1","locker":"client.4123","address":"10.0.0.2:0/3710147553"},{"id":"auto 2","locker":"client.4124","address":"10.0.0.3:0/1275346112"}]`,
+			wantCmds: []string{
+				statusCmd,
+				lockListCmd,
+				"ceph --name client.admin --cluster ceph osd blocklist add 10.0.0.2:0/3710147553 315360000",
+				"ceph --name client.admin --cluster ceph osd blocklist add 10.0.0.3:0/1275346112 315360000",
+				"rbd --id admin --cluster ceph --pool lxd lock remove container_c1 auto 1 client.4123",
+				"rbd --id admin --cluster ceph --pool lxd lock remove container_c1 auto 2 client.4124",
+			},
+			wantAddresses: []string{"10.0.0.2:0/3710147553", "10.0.0.3:0/1275346112"},
+		},
+		{
+			name:   "Lock isn't removed if its owner can't be blocklisted",
+			status: `{"watchers":[]}`,
+			locks:  `[{"id":"auto 1","locker":"client.4123","address":"10.0.0.2:0/3710147553"}]`,
+			failOn: "blocklist",
+			wantCmds: []string{
+				statusCmd,
+				lockListCmd,
+				"ceph --name client.admin --cluster ceph osd blocklist add 10.0.0.2:0/3710147553 315360000",
+			},
+			wantErr: true,
+		},
+		{
+			name:     "Lock without owner address",
+			status:   `{"watchers":[]}`,
+			locks:    `[{"id":"auto 1","locker":"client.4123"}]`,
+			wantCmds: []string{statusCmd, lockListCmd},
+			wantErr:  true,
+		},
+		{
+			name:     "Status failure",
+			failOn:   "status",
+			wantCmds: []string{statusCmd},
+			wantErr:  true,
+		},
+	}
+
+	runCommand := cephRunCommand
+	defer func() { cephRunCommand = runCommand }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmds := []string{}
+			cephRunCommand = func(ctx context.Context, name string, arg ...string) (string, error) {
+				cmd := strings.Join(append([]string{name}, arg...), " ")
+				cmds = append(cmds, cmd)
+
+				if tt.failOn != "" && strings.Contains(cmd, tt.failOn) {
+					return "", fmt.Errorf("Command failed")
+				}
+
+				if strings.Contains(cmd, " status ") {
+					return tt.status, nil
+				}
+
+				if strings.Contains(cmd, "lock list") {
+					return tt.locks, nil
+				}
+
+				return "", nil
+			}
+
+			addresses, err := CephRBDClientsFence(context.Background(), "ceph", "admin", "lxd", "container_c1")
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantAddresses, addresses)
+			}
+
+			assert.Equal(t, tt.wantCmds, cmds)
+		})
+	}
+}
@@ -94,10 +94,11 @@ type Volume struct {
 	contentType          ContentType
 	config               map[string]string
 	driver               Driver
-	mountCustomPath      string // Mount the filesystem volume at a custom location.
-	mountFilesystemProbe bool   // Probe filesystem type when mounting volume (when needed).
-	hasSource            bool   // Whether the volume is created from a source volume.
-	parentUUID           string // Set to the parent volume's volatile.uuid (if snapshot).
+	mountCustomPath      string        // Mount the filesystem volume at a custom location.
+	mountFilesystemProbe bool          // Probe filesystem type when mounting volume (when needed).
+	hasSource            bool          // Whether the volume is created from a source volume.
+	parentUUID           string        // Set to the parent volume's volatile.uuid (if snapshot).
+	freezer              func() func() // Freezes the instance's filesystems around temporary snapshots.
 }
 
 // VolumeCopy represents a volume and its snapshots for copy and refresh operations.
@@ -565,6 +566,21 @@ func (v *Volume) SetParentUUID(parentUUID string) {
 	v.parentUUID = parentUUID
 }
 
+// SetFreezer sets the function called right before the driver takes a temporary snapshot of the volume to read
+// from (like for a backup). It returns the function to call once the snapshot has been taken.
+func (v *Volume) SetFreezer(freezer func() func()) {
+	v.freezer = freezer
+}
+
+// freeze calls the volume's freezer (if any) and returns the function undoing it.
+func (v Volume) freeze() func() {
+	if v.freezer == nil {
+		return func() {}
+	}
+
+	return v.freezer()
+}
+
 // Clone returns a copy of the volume.
 func (v Volume) Clone() Volume {
 	// Copy the config map to avoid internal modifications affecting external state.
@@ -561,6 +561,61 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		rules["volatile.uuid"] = validate.Optional(validate.IsUUID)
 	}
 
+	// Replication is only supported for custom volumes.
+	if vol != nil && vol.Type() == drivers.VolumeTypeCustom {
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=replication.target)
+		// Specify the URL of the LXD server or cluster that the volume is replicated to.
+		//
+		// See {ref}`instances-replication` for more information.
+		// ---
+		//  type: string
+		//  condition: custom volume
+		//  shortdesc: Server that the volume is replicated to
+		rules["replication.target"] = validate.Optional(validate.IsRequestURL)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=replication.target.certificate)
+		// Only required if the certificate of the target server isn't signed by a trusted certificate authority.
+		// ---
+		//  type: string
+		//  condition: custom volume
+		//  shortdesc: PEM encoded certificate of the replication target server
+		rules["replication.target.certificate"] = validate.Optional(validate.IsX509Certificate)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=replication.target.project)
+		//
+		// ---
+		//  type: string
+		//  condition: custom volume
+		//  defaultdesc: same project as the volume
+		//  shortdesc: Project of the replication target server that the replica is created in
+		rules["replication.target.project"] = validate.IsAny
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=replication.target.pool)
+		//
+		// ---
+		//  type: string
+		//  condition: custom volume
+		//  defaultdesc: same storage pool as the volume
+		//  shortdesc: Storage pool of the replication target server that the replica is created in
+		rules["replication.target.pool"] = validate.IsAny
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=replication.schedule)
+		// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable replication (the default).
+		// ---
+		//  type: string
+		//  condition: custom volume
+		//  shortdesc: Schedule for refreshing the replica of the volume
+		rules["replication.schedule"] = validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"}))
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex; group=volume-conf; key=replication.replica)
+		// This option is set on the replicas created by the replication of a volume.
+		// A replica is only refreshed by the replication while this option is set.
+		// Unset it to promote the replica.
+		// ---
+		//  type: bool
+		//  condition: custom volume
+		//  defaultdesc: `false`
+		//  shortdesc: Whether the volume is a replica of a volume on another server
+		rules["replication.replica"] = validate.Optional(validate.IsBool)
+		rules["volatile.replication.last_success"] = validate.IsAny
+		rules["volatile.replication.last_error"] = validate.IsAny
+	}
+
 	return rules
 }
 
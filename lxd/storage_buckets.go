@@ -17,7 +17,6 @@ import (
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	storagePools "github.com/canonical/lxd/lxd/storage"
-	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/revert"
@@ -72,6 +71,21 @@ var storagePoolBucketKeyCmd = APIEndpoint{
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -121,6 +135,21 @@ var storagePoolBucketKeyCmd = APIEndpoint{
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -173,6 +202,11 @@ func storagePoolBucketsGet(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Storage pool driver %q does not support buckets", driverInfo.Name))
 	}
 
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
 	memberSpecific := false // Get buckets for all cluster members.
 
 	var dbBuckets []*db.StorageBucket
@@ -219,23 +253,31 @@ func storagePoolBucketsGet(d *Daemon, r *http.Request) response.Response {
 		return bucketA.Name < bucketB.Name
 	})
 
-	if util.IsRecursionRequest(r) {
-		buckets := make([]*api.StorageBucket, 0, len(filteredDBBuckets))
-		for _, dbBucket := range filteredDBBuckets {
-			u := pool.GetBucketURL(dbBucket.Name)
-			if u != nil {
-				dbBucket.S3URL = u.String()
-			}
-
-			buckets = append(buckets, &dbBucket.StorageBucket)
+	buckets := make([]api.StorageBucket, 0, len(filteredDBBuckets))
+	for _, dbBucket := range filteredDBBuckets {
+		u := pool.GetBucketURL(dbBucket.Name)
+		if u != nil {
+			dbBucket.S3URL = u.String()
 		}
 
+		buckets = append(buckets, dbBucket.StorageBucket)
+	}
+
+	// Buckets of local pools on different members can have the same name so their URL is used as cursor.
+	buckets, err = listPage(opts, buckets, func(bucket api.StorageBucket) string {
+		return bucket.URL(version.APIVersion, poolName, requestProjectName).String()
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if opts.recursion {
 		return response.SyncResponse(true, buckets)
 	}
 
-	urls := make([]string, 0, len(filteredDBBuckets))
-	for _, dbBucket := range filteredDBBuckets {
-		urls = append(urls, dbBucket.StorageBucket.URL(version.APIVersion, poolName, requestProjectName).String())
+	urls := make([]string, 0, len(buckets))
+	for _, bucket := range buckets {
+		urls = append(urls, bucket.URL(version.APIVersion, poolName, requestProjectName).String())
 	}
 
 	return response.SyncResponse(true, urls)
@@ -658,6 +700,21 @@ func storagePoolBucketDelete(d *Daemon, r *http.Request) response.Response {
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -707,6 +764,21 @@ func storagePoolBucketDelete(d *Daemon, r *http.Request) response.Response {
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -743,6 +815,11 @@ func storagePoolBucketKeysGet(d *Daemon, r *http.Request) response.Response {
 		return resp
 	}
 
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
 	bucketProjectName, err := project.StorageBucketProject(r.Context(), s.DB.Cluster, request.ProjectParam(r))
 	if err != nil {
 		return response.SmartError(err)
@@ -791,18 +868,23 @@ func storagePoolBucketKeysGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	if util.IsRecursionRequest(r) {
-		bucketKeys := make([]*api.StorageBucketKey, 0, len(dbBucketKeys))
-		for _, dbBucketKey := range dbBucketKeys {
-			bucketKeys = append(bucketKeys, &dbBucketKey.StorageBucketKey)
-		}
+	bucketKeys := make([]api.StorageBucketKey, 0, len(dbBucketKeys))
+	for _, dbBucketKey := range dbBucketKeys {
+		bucketKeys = append(bucketKeys, dbBucketKey.StorageBucketKey)
+	}
+
+	bucketKeys, err = listPage(opts, bucketKeys, func(bucketKey api.StorageBucketKey) string { return bucketKey.Name })
+	if err != nil {
+		return response.SmartError(err)
+	}
 
+	if opts.recursion {
 		return response.SyncResponse(true, bucketKeys)
 	}
 
-	bucketKeyURLs := make([]string, 0, len(dbBucketKeys))
-	for _, dbBucketKey := range dbBucketKeys {
-		bucketKeyURLs = append(bucketKeyURLs, dbBucketKey.URL(version.APIVersion, poolName, bucketProjectName, bucketName).String())
+	bucketKeyURLs := make([]string, 0, len(bucketKeys))
+	for _, bucketKey := range bucketKeys {
+		bucketKeyURLs = append(bucketKeyURLs, bucketKey.URL(version.APIVersion, poolName, bucketProjectName, bucketName).String())
 	}
 
 	return response.SyncResponse(true, bucketKeyURLs)
@@ -65,6 +65,21 @@ var storagePoolCmd = APIEndpoint{
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -114,6 +129,21 @@ var storagePoolCmd = APIEndpoint{
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -145,11 +175,14 @@ var storagePoolCmd = APIEndpoint{
 func storagePoolsGet(d *Daemon, r *http.Request) response.Response {
 	s := d.State()
 
-	recursion := util.IsRecursionRequest(r)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
 
 	var poolNames []string
 
-	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		var err error
 
 		poolNames, err = tx.GetStoragePoolNames(ctx)
@@ -165,46 +198,48 @@ func storagePoolsGet(d *Daemon, r *http.Request) response.Response {
 		return response.InternalError(err)
 	}
 
-	resultString := []string{}
-	resultMap := []api.StoragePool{}
-	for _, poolName := range poolNames {
-		if !recursion {
-			resultString = append(resultString, fmt.Sprintf("/%s/storage-pools/%s", version.APIVersion, poolName))
-		} else {
-			pool, err := storagePools.LoadByName(s, poolName)
-			if err != nil {
-				return response.SmartError(err)
-			}
+	resultNames, resultMap, err := listEntries(opts, poolNames, func(poolName string) (*api.StoragePool, error) {
+		pool, err := storagePools.LoadByName(s, poolName)
+		if err != nil {
+			return nil, err
+		}
 
-			// Get all users of the storage pool.
-			poolUsedBy, err := storagePools.UsedBy(r.Context(), s, pool, false, false)
-			if err != nil {
-				return response.SmartError(err)
-			}
+		// Get all users of the storage pool.
+		poolUsedBy, err := storagePools.UsedBy(r.Context(), s, pool, false, false)
+		if err != nil {
+			return nil, err
+		}
 
-			poolAPI := pool.ToAPI()
-			poolAPI.UsedBy = project.FilterUsedBy(s.Authorizer, r, poolUsedBy)
+		poolAPI := pool.ToAPI()
+		poolAPI.UsedBy = project.FilterUsedBy(s.Authorizer, r, poolUsedBy)
 
-			if !hasEditPermission(entity.StoragePoolURL(poolName)) {
-				// Don't allow non-admins to see pool config as sensitive info can be stored there.
-				poolAPI.Config = nil
-			}
+		if !hasEditPermission(entity.StoragePoolURL(poolName)) {
+			// Don't allow non-admins to see pool config as sensitive info can be stored there.
+			poolAPI.Config = nil
+		}
 
-			// If no member is specified and the daemon is clustered, we omit the node-specific fields.
-			if s.ServerClustered {
-				for _, key := range db.NodeSpecificStorageConfig {
-					delete(poolAPI.Config, key)
-				}
-			} else {
-				// Use local status if not clustered. To allow seeing unavailable pools.
-				poolAPI.Status = pool.LocalStatus()
+		// If no member is specified and the daemon is clustered, we omit the node-specific fields.
+		if s.ServerClustered {
+			for _, key := range db.NodeSpecificStorageConfig {
+				delete(poolAPI.Config, key)
 			}
-
-			resultMap = append(resultMap, poolAPI)
+		} else {
+			// Use local status if not clustered. To allow seeing unavailable pools.
+			poolAPI.Status = pool.LocalStatus()
 		}
+
+		return &poolAPI, nil
+	})
+	if err != nil {
+		return response.SmartError(err)
 	}
 
-	if !recursion {
+	if !opts.recursion {
+		resultString := make([]string, 0, len(resultNames))
+		for _, poolName := range resultNames {
+			resultString = append(resultString, fmt.Sprintf("/%s/storage-pools/%s", version.APIVersion, poolName))
+		}
+
 		return response.SyncResponse(true, resultString)
 	}
 
@@ -948,7 +983,7 @@ func storagePoolDelete(d *Daemon, r *http.Request) response.Response {
 		}
 
 		// Get the cluster notifier
-		notifier, err = cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAll)
+		notifier, err = cluster.NewNotifierContext(r.Context(), s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAll)
 		if err != nil {
 			return response.SmartError(err)
 		}
@@ -110,6 +110,16 @@ var storagePoolVolumeTypeCmd = APIEndpoint{
 //      description: Collection filter
 //      type: string
 //      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -176,6 +186,16 @@ var storagePoolVolumeTypeCmd = APIEndpoint{
 //      description: Collection filter
 //      type: string
 //      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -348,6 +368,16 @@ var storagePoolVolumeTypeCmd = APIEndpoint{
 //      description: Collection filter
 //      type: string
 //      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -414,6 +444,16 @@ var storagePoolVolumeTypeCmd = APIEndpoint{
 //      description: Collection filter
 //      type: string
 //      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -589,12 +629,13 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
-	filterStr := r.FormValue("filter")
-	clauses, err := filter.Parse(filterStr, filter.QueryOperatorSet())
+	opts, err := parseListOptions(r)
 	if err != nil {
-		return response.SmartError(fmt.Errorf("Invalid filter: %w", err))
+		return response.BadRequest(err)
 	}
 
+	clauses := opts.clauses
+
 	var poolID int64
 
 	if !allPools {
@@ -750,16 +791,27 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	if util.IsRecursionRequest(r) {
+	allowedVolumes := make([]*db.StorageVolume, 0, len(dbVolumes))
+	for _, dbVol := range dbVolumes {
+		volumeName, _, _ := api.GetParentAndSnapshotName(dbVol.Name)
+		if userHasPermission(entity.StorageVolumeURL(dbVol.Project, "", dbVol.Pool, dbVol.Type, volumeName)) {
+			allowedVolumes = append(allowedVolumes, dbVol)
+		}
+	}
+
+	// Volumes of different pools and projects can have the same name so their URL is used as cursor.
+	dbVolumes, err = listPage(opts.pageOnly(), allowedVolumes, func(dbVol *db.StorageVolume) string {
+		return dbVol.StorageVolume.URL(version.APIVersion).String()
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if opts.recursion {
 		volumes := make([]*api.StorageVolume, 0, len(dbVolumes))
 		for _, dbVol := range dbVolumes {
 			vol := &dbVol.StorageVolume
 
-			volumeName, _, _ := api.GetParentAndSnapshotName(vol.Name)
-			if !userHasPermission(entity.StorageVolumeURL(vol.Project, "", dbVol.Pool, dbVol.Type, volumeName)) {
-				continue
-			}
-
 			// Fill in UsedBy if we haven't previously done so.
 			if clauses == nil || len(clauses.Clauses) == 0 {
 				volumeUsedBy, err := storagePoolVolumeUsedByGet(s, requestProjectName, dbVol)
@@ -778,12 +830,6 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) response.Response {
 
 	urls := make([]string, 0, len(dbVolumes))
 	for _, dbVol := range dbVolumes {
-		volumeName, _, _ := api.GetParentAndSnapshotName(dbVol.Name)
-
-		if !userHasPermission(entity.StorageVolumeURL(dbVol.Project, "", dbVol.Pool, dbVol.Type, volumeName)) {
-			continue
-		}
-
 		urls = append(urls, dbVol.StorageVolume.URL(version.APIVersion).String())
 	}
 
@@ -1903,6 +1949,12 @@ func storagePoolVolumeTypePostMove(s *state.State, r *http.Request, poolName str
 			return err
 		}
 
+		// The copy is the only remaining volume once the source is deleted, so keep it from now on.
+		err = op.ClearRollback()
+		if err != nil {
+			return err
+		}
+
 		err = pool.DeleteCustomVolume(requestProjectName, vol.Name, op)
 		if err != nil {
 			return err
@@ -2707,7 +2759,7 @@ func createStoragePoolVolumeFromBackup(s *state.State, r *http.Request, requestP
 		}
 
 		// Dump tarball to storage.
-		err = pool.CreateCustomVolumeFromBackup(*bInfo, backupFile, nil)
+		err = pool.CreateCustomVolumeFromBackup(*bInfo, backupFile, op)
 		if err != nil {
 			return fmt.Errorf("Create custom volume from backup: %w", err)
 		}
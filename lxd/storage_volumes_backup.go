@@ -22,7 +22,6 @@ import (
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	storagePools "github.com/canonical/lxd/lxd/storage"
-	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
@@ -71,6 +70,21 @@ var storagePoolVolumeTypeCustomBackupExportCmd = APIEndpoint{
 //      description: Cluster member name
 //      type: string
 //      example: lxd01
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -125,6 +139,21 @@ var storagePoolVolumeTypeCustomBackupExportCmd = APIEndpoint{
 //	    description: Cluster member name
 //	    type: string
 //	    example: lxd01
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -210,7 +239,10 @@ func storagePoolVolumeTypeCustomBackupsGet(d *Daemon, r *http.Request) response.
 		return resp
 	}
 
-	recursion := util.IsRecursionRequest(r)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
 
 	var volumeBackups []db.StoragePoolVolumeBackup
 
@@ -222,26 +254,30 @@ func storagePoolVolumeTypeCustomBackupsGet(d *Daemon, r *http.Request) response.
 		return response.SmartError(err)
 	}
 
-	backups := make([]*backup.VolumeBackup, len(volumeBackups))
+	backups := make(map[string]*backup.VolumeBackup, len(volumeBackups))
+	backupNames := make([]string, 0, len(volumeBackups))
 
-	for i, b := range volumeBackups {
-		backups[i] = backup.NewVolumeBackup(s, projectName, poolName, volumeName, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.VolumeOnly, b.OptimizedStorage)
+	for _, b := range volumeBackups {
+		volumeBackup := backup.NewVolumeBackup(s, projectName, poolName, volumeName, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.VolumeOnly, b.OptimizedStorage)
+		backupName := strings.Split(volumeBackup.Name(), "/")[1]
+		backupNames = append(backupNames, backupName)
+		backups[backupName] = volumeBackup
 	}
 
-	resultString := []string{}
-	resultMap := []*api.StoragePoolVolumeBackup{}
+	resultNames, resultMap, err := listEntries(opts, backupNames, func(backupName string) (*api.StoragePoolVolumeBackup, error) {
+		return backups[backupName].Render(), nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
 
-	for _, backup := range backups {
-		if !recursion {
-			url := api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "volumes", "custom", volumeName, "backups", strings.Split(backup.Name(), "/")[1]).String()
+	if !opts.recursion {
+		resultString := make([]string, 0, len(resultNames))
+		for _, backupName := range resultNames {
+			url := api.NewURL().Path(version.APIVersion, "storage-pools", poolName, "volumes", "custom", volumeName, "backups", backupName).String()
 			resultString = append(resultString, url)
-		} else {
-			render := backup.Render()
-			resultMap = append(resultMap, render)
 		}
-	}
 
-	if !recursion {
 		return response.SyncResponse(true, resultString)
 	}
 
@@ -444,7 +480,7 @@ func storagePoolVolumeTypeCustomBackupsPost(d *Daemon, r *http.Request) response
 			CompressionAlgorithm: req.CompressionAlgorithm,
 		}
 
-		err := volumeBackupCreate(s, args, projectName, poolName, volumeName)
+		err := volumeBackupCreate(s, args, projectName, poolName, volumeName, op)
 		if err != nil {
 			return fmt.Errorf("Create volume backup: %w", err)
 		}
@@ -138,7 +138,7 @@ func storagePoolVolumeSnapshotsTypePost(d *Daemon, r *http.Request) response.Res
 			return err
 		}
 
-		err = project.AllowSnapshotCreation(p)
+		err = project.AllowSnapshotCreation(tx, p)
 		if err != nil {
 			return err
 		}
@@ -284,6 +284,21 @@ func storagePoolVolumeSnapshotsTypePost(d *Daemon, r *http.Request) response.Res
 //      description: Cluster member name
 //      type: string
 //      example: lxd01
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -338,6 +353,21 @@ func storagePoolVolumeSnapshotsTypePost(d *Daemon, r *http.Request) response.Res
 //	    description: Cluster member name
 //	    type: string
 //	    example: lxd01
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -375,7 +405,10 @@ func storagePoolVolumeSnapshotsTypeGet(d *Daemon, r *http.Request) response.Resp
 		return response.SmartError(err)
 	}
 
-	recursion := util.IsRecursionRequest(r)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
 
 	// Get the name of the volume type.
 	volumeTypeName, err := url.PathUnescape(mux.Vars(r)["type"])
@@ -430,47 +463,59 @@ func storagePoolVolumeSnapshotsTypeGet(d *Daemon, r *http.Request) response.Resp
 		return response.SmartError(err)
 	}
 
-	// Prepare the response.
-	resultString := []string{}
-	resultMap := []*api.StorageVolumeSnapshot{}
-	for _, volume := range volumes {
+	snapshotNames := make([]string, 0, len(volumes))
+	snapshotIndexes := make(map[string]int, len(volumes))
+	for i, volume := range volumes {
 		_, snapshotName, _ := api.GetParentAndSnapshotName(volume.Name)
+		snapshotNames = append(snapshotNames, snapshotName)
+		snapshotIndexes[snapshotName] = i
+	}
 
-		if !recursion {
-			resultString = append(resultString, fmt.Sprintf("/%s/storage-pools/%s/volumes/%s/%s/snapshots/%s", version.APIVersion, poolName, volumeTypeName, volumeName, snapshotName))
-		} else {
-			var vol *db.StorageVolume
-			err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
-				vol, err = tx.GetStoragePoolVolume(ctx, poolID, projectName, volumeType, volume.Name, true)
-				return err
-			})
-			if err != nil {
-				return response.SmartError(err)
-			}
+	// Prepare the response.
+	resultNames, resultMap, err := listEntries(opts, snapshotNames, func(snapshotName string) (*api.StorageVolumeSnapshot, error) {
+		volume := volumes[snapshotIndexes[snapshotName]]
 
-			volumeUsedBy, err := storagePoolVolumeUsedByGet(s, projectName, vol)
-			if err != nil {
-				return response.SmartError(err)
-			}
+		var vol *db.StorageVolume
+		err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
 
-			vol.UsedBy = project.FilterUsedBy(s.Authorizer, r, volumeUsedBy)
+			vol, err = tx.GetStoragePoolVolume(ctx, poolID, projectName, volumeType, volume.Name, true)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
 
-			tmp := &api.StorageVolumeSnapshot{}
-			tmp.Config = vol.Config
-			tmp.Description = vol.Description
-			tmp.Name = vol.Name
-			tmp.CreatedAt = vol.CreatedAt
+		volumeUsedBy, err := storagePoolVolumeUsedByGet(s, projectName, vol)
+		if err != nil {
+			return nil, err
+		}
 
-			expiryDate := volume.ExpiryDate
-			if expiryDate.Unix() > 0 {
-				tmp.ExpiresAt = &expiryDate
-			}
+		vol.UsedBy = project.FilterUsedBy(s.Authorizer, r, volumeUsedBy)
 
-			resultMap = append(resultMap, tmp)
+		tmp := &api.StorageVolumeSnapshot{}
+		tmp.Config = vol.Config
+		tmp.Description = vol.Description
+		tmp.Name = vol.Name
+		tmp.CreatedAt = vol.CreatedAt
+
+		expiryDate := volume.ExpiryDate
+		if expiryDate.Unix() > 0 {
+			tmp.ExpiresAt = &expiryDate
 		}
+
+		return tmp, nil
+	})
+	if err != nil {
+		return response.SmartError(err)
 	}
 
-	if !recursion {
+	if !opts.recursion {
+		resultString := make([]string, 0, len(resultNames))
+		for _, snapshotName := range resultNames {
+			resultString = append(resultString, fmt.Sprintf("/%s/storage-pools/%s/volumes/%s/%s/snapshots/%s", version.APIVersion, poolName, volumeTypeName, volumeName, snapshotName))
+		}
+
 		return response.SyncResponse(true, resultString)
 	}
 
@@ -1216,7 +1261,7 @@ func pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d *Daemon) (task.Func, t
 			}
 
 			for _, v := range allVolumes {
-				err = project.AllowSnapshotCreation(projects[v.ProjectName])
+				err = project.AllowSnapshotCreation(tx, projects[v.ProjectName])
 				if err != nil {
 					continue
 				}
@@ -1437,6 +1482,25 @@ func autoCreateCustomVolumeSnapshots(ctx context.Context, s *state.State, volume
 			return err // Stop if context is cancelled.
 		}
 
+		// Check the project limits again as the snapshots created earlier in this run count towards them.
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			dbProject, err := dbCluster.GetProject(ctx, tx.Tx(), v.ProjectName)
+			if err != nil {
+				return fmt.Errorf("Failed loading project %q: %w", v.ProjectName, err)
+			}
+
+			p, err := dbProject.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return fmt.Errorf("Failed loading project %q: %w", v.ProjectName, err)
+			}
+
+			return project.AllowSnapshotCreation(tx, p)
+		})
+		if err != nil {
+			logger.Warn("Skipping scheduled custom volume snapshot", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName, "err": err})
+			continue
+		}
+
 		snapshotName, err := volumeDetermineNextSnapshotName(s, v, "snap%d")
 		if err != nil {
 			return fmt.Errorf("Error retrieving next snapshot name for volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err)
@@ -99,6 +99,11 @@ type OS struct {
 	// LXC features
 	LXCFeatures map[string]bool
 
+	// CRIU features
+	CRIU                 bool
+	CRIUMemDirtyTracking bool
+	CRIUVersion          string
+
 	// OS info
 	ReleaseInfo   map[string]string
 	KernelVersion version.DottedVersion
@@ -0,0 +1,133 @@
+// Package tracing exports OpenTelemetry traces of API requests, operations, storage driver calls and cluster
+// member requests.
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/canonical/lxd/shared/version"
+)
+
+// tracerName is the instrumentation scope of the spans.
+const tracerName = "github.com/canonical/lxd"
+
+// propagator is used to carry the trace context in HTTP headers.
+var propagator = propagation.TraceContext{}
+
+var providerMu sync.RWMutex
+var provider trace.TracerProvider = noop.NewTracerProvider()
+
+// shutdown flushes and stops the current exporter, if any.
+var shutdown func(ctx context.Context) error
+
+func init() {
+	// Let the client package propagate the trace context of its requests.
+	otel.SetTextMapPropagator(propagator)
+}
+
+// Setup replaces the trace exporter with one sending the traces to the OTLP/gRPC collector at the given URL.
+// A fraction of the traces given by sampleRatio is exported, unless the caller decided to sample the trace.
+// Tracing is disabled if the URL is empty.
+func Setup(ctx context.Context, endpointURL string, sampleRatio float64, instance string) error {
+	var newProvider trace.TracerProvider = noop.NewTracerProvider()
+	var newShutdown func(ctx context.Context) error
+
+	if endpointURL != "" {
+		exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpointURL(endpointURL))
+		if err != nil {
+			return err
+		}
+
+		res := resource.NewSchemaless(
+			attribute.String("service.name", "lxd"),
+			attribute.String("service.version", version.Version),
+			attribute.String("service.instance.id", instance),
+		)
+
+		sdkProvider := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(res),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		)
+
+		newProvider = sdkProvider
+		newShutdown = sdkProvider.Shutdown
+	}
+
+	providerMu.Lock()
+	oldShutdown := shutdown
+	provider = newProvider
+	shutdown = newShutdown
+	providerMu.Unlock()
+
+	if oldShutdown != nil {
+		return oldShutdown(ctx)
+	}
+
+	return nil
+}
+
+// Shutdown sends the pending spans and disables tracing.
+func Shutdown(ctx context.Context) error {
+	return Setup(ctx, "", 0, "")
+}
+
+// Start starts a span with the given name. The span is a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	providerMu.RLock()
+	tracer := provider.Tracer(tracerName)
+	providerMu.RUnlock()
+
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records the error, if any, and ends the span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// Detach returns a context carrying the span of ctx but none of its values, deadline or cancellation.
+// It is used for spans that outlive the request which started them.
+func Detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+}
+
+// Inject adds the trace context of ctx to the HTTP headers.
+func Inject(ctx context.Context, header http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Extract returns a copy of ctx carrying the trace context found in the HTTP headers, if any.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// StartRequest starts the span of an API request, continuing the trace of the caller if any. The route is the
+// URL template of the API endpoint. The returned request carries the span.
+func StartRequest(r *http.Request, route string) (*http.Request, trace.Span) {
+	ctx, span := Start(Extract(r.Context(), r.Header), r.Method+" "+route,
+		attribute.String("http.request.method", r.Method),
+		attribute.String("http.route", route),
+		attribute.String("url.path", r.URL.Path),
+		attribute.String("client.address", r.RemoteAddr),
+	)
+
+	return r.WithContext(ctx), span
+}
@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans makes the spans be recorded in memory for the duration of the test.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+
+	providerMu.Lock()
+	oldProvider := provider
+	provider = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	providerMu.Unlock()
+
+	t.Cleanup(func() {
+		providerMu.Lock()
+		provider = oldProvider
+		providerMu.Unlock()
+	})
+
+	return exporter
+}
+
+func TestPropagation(t *testing.T) {
+	exporter := recordSpans(t)
+
+	// Caller side.
+	ctx, span := Start(context.Background(), "caller")
+	header := http.Header{}
+	Inject(ctx, header)
+	span.End()
+
+	assert.NotEmpty(t, header.Get("traceparent"))
+
+	// Callee side.
+	r := httptest.NewRequest(http.MethodGet, "/1.0/instances/c1", nil)
+	r.Header = header
+
+	r, requestSpan := StartRequest(r, "/1.0/instances/{name}")
+	End(requestSpan, errors.New("Instance not found"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "GET /1.0/instances/{name}", spans[1].Name)
+	assert.Equal(t, spans[0].SpanContext.TraceID(), spans[1].SpanContext.TraceID())
+	assert.Equal(t, spans[0].SpanContext.SpanID(), spans[1].Parent.SpanID())
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), trace.SpanContextFromContext(r.Context()).SpanID())
+}
+
+func TestDetach(t *testing.T) {
+	recordSpans(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, span := Start(ctx, "request")
+	defer span.End()
+
+	detached := Detach(ctx)
+	cancel()
+
+	assert.NoError(t, detached.Err())
+	assert.Equal(t, span.SpanContext(), trace.SpanContextFromContext(detached))
+}
+
+func TestDisabled(t *testing.T) {
+	require.NoError(t, Shutdown(context.Background()))
+
+	ctx, span := Start(context.Background(), "noop")
+	defer span.End()
+
+	header := http.Header{}
+	Inject(ctx, header)
+
+	assert.False(t, span.SpanContext().IsValid())
+	assert.Empty(t, header.Get("traceparent"))
+}
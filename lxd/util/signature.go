@@ -0,0 +1,64 @@
+package util
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// ParseSigningKeys parses a list of PEM encoded Ed25519 public keys.
+func ParseSigningKeys(keys string) ([]ed25519.PublicKey, error) {
+	result := []ed25519.PublicKey{}
+
+	rest := []byte(keys)
+	for {
+		var block *pem.Block
+
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("Unexpected PEM block %q, expected %q", block.Type, "PUBLIC KEY")
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing public key: %w", err)
+		}
+
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("Unsupported public key type %T, only Ed25519 keys are supported", key)
+		}
+
+		result = append(result, edKey)
+	}
+
+	if strings.TrimSpace(string(rest)) != "" {
+		return nil, fmt.Errorf("Invalid PEM data")
+	}
+
+	return result, nil
+}
+
+// VerifyImageSignature checks that the base64 encoded signature of the image fingerprint was made by one of the keys.
+// Images are signed by signing their fingerprint, as a lowercase hexadecimal string.
+func VerifyImageSignature(keys []ed25519.PublicKey, fingerprint string, signature string) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return fmt.Errorf("Invalid image signature encoding: %w", err)
+	}
+
+	for _, key := range keys {
+		if ed25519.Verify(key, []byte(strings.ToLower(fingerprint)), sig) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Image signature doesn't match any trusted key")
+}
@@ -0,0 +1,46 @@
+package util_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/util"
+)
+
+func Test_VerifyImageSignature(t *testing.T) {
+	encode := func(key ed25519.PublicKey) string {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		require.NoError(t, err)
+
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+
+	trustedPub, trustedPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	otherPub, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keys, err := util.ParseSigningKeys(encode(otherPub) + encode(trustedPub))
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	_, err = util.ParseSigningKeys("not a key")
+	assert.Error(t, err)
+
+	fingerprint := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(trustedPriv, []byte(fingerprint)))
+
+	assert.NoError(t, util.VerifyImageSignature(keys, fingerprint, signature))
+	assert.Error(t, util.VerifyImageSignature(keys, "f"+fingerprint[1:], signature))
+	assert.Error(t, util.VerifyImageSignature(keys, fingerprint, "invalid"))
+	assert.Error(t, util.VerifyImageSignature(keys[:1], fingerprint, base64.StdEncoding.EncodeToString(ed25519.Sign(trustedPriv, []byte(fingerprint)))))
+	assert.NoError(t, util.VerifyImageSignature(keys[:1], fingerprint, base64.StdEncoding.EncodeToString(ed25519.Sign(otherPriv, []byte(fingerprint)))))
+}
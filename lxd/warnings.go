@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
-	"strconv"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -25,7 +24,6 @@ import (
 	"github.com/canonical/lxd/lxd/task"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
-	"github.com/canonical/lxd/shared/filter"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/version"
 )
@@ -45,25 +43,6 @@ var warningCmd = APIEndpoint{
 	Delete: APIEndpointAction{Handler: warningDelete, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
 }
 
-func filterWarnings(warnings []api.Warning, clauses *filter.ClauseSet) ([]api.Warning, error) {
-	filtered := []api.Warning{}
-
-	for _, warning := range warnings {
-		match, err := filter.Match(warning, *clauses)
-		if err != nil {
-			return nil, err
-		}
-
-		if !match {
-			continue
-		}
-
-		filtered = append(filtered, warning)
-	}
-
-	return filtered, nil
-}
-
 // swagger:operation GET /1.0/warnings warnings warnings_get
 //
 //  List the warnings
@@ -79,6 +58,21 @@ func filterWarnings(warnings []api.Warning, clauses *filter.ClauseSet) ([]api.Wa
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: filter
+//      description: Collection filter
+//      type: string
+//      example: default
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: after
+//      description: Key of the last entry of the previous page
+//      type: string
+//      example: c1
 //  responses:
 //    "200":
 //      description: Sync response
@@ -126,6 +120,21 @@ func filterWarnings(warnings []api.Warning, clauses *filter.ClauseSet) ([]api.Wa
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -153,19 +162,10 @@ func filterWarnings(warnings []api.Warning, clauses *filter.ClauseSet) ([]api.Wa
 //	  "500":
 //	    $ref: "#/responses/InternalServerError"
 func warningsGet(d *Daemon, r *http.Request) response.Response {
-	// Parse the recursion field
-	recursionStr := r.FormValue("recursion")
-
-	recursion, err := strconv.Atoi(recursionStr)
+	// Parse the recursion, filter and pagination fields
+	opts, err := parseListOptions(r)
 	if err != nil {
-		recursion = 0
-	}
-
-	// Parse filter value
-	filterStr := r.FormValue("filter")
-	clauses, err := filter.Parse(filterStr, filter.QueryOperatorSet())
-	if err != nil {
-		return response.SmartError(fmt.Errorf("Failed to filter warnings: %w", err))
+		return response.BadRequest(fmt.Errorf("Failed to filter warnings: %w", err))
 	}
 
 	// Parse the project field
@@ -201,16 +201,14 @@ func warningsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	var filters []api.Warning
-	if recursion == 0 {
-		var resultList []string
-
-		filters, err = filterWarnings(warnings, clauses)
-		if err != nil {
-			return response.SmartError(err)
-		}
+	warnings, err = listPage(opts, warnings, func(warning api.Warning) string { return warning.UUID })
+	if err != nil {
+		return response.SmartError(err)
+	}
 
-		for _, w := range filters {
+	if !opts.recursion {
+		resultList := make([]string, 0, len(warnings))
+		for _, w := range warnings {
 			url := fmt.Sprintf("/%s/warnings/%s", version.APIVersion, w.UUID)
 			resultList = append(resultList, url)
 		}
@@ -218,15 +216,8 @@ func warningsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SyncResponse(true, resultList)
 	}
 
-	if filters == nil {
-		filters, err = filterWarnings(warnings, clauses)
-		if err != nil {
-			return response.SmartError(err)
-		}
-	}
-
 	// Return detailed list of warning
-	return response.SyncResponse(true, filters)
+	return response.SyncResponse(true, warnings)
 }
 
 // swagger:operation GET /1.0/warnings/{uuid} warnings warning_get
@@ -0,0 +1,231 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// queueSize is the number of events that can be waiting for delivery to a single webhook.
+const queueSize = 1000
+
+// maxAttempts is the number of delivery attempts made before an event is dead-lettered.
+const maxAttempts = 5
+
+// DeadLetterFunc is called with the events that couldn't be delivered to a webhook.
+type DeadLetterFunc func(webhookName string, event api.Event, err error)
+
+// DroppedFunc is called with the number of events dropped because the queue of a webhook was full.
+type DroppedFunc func(webhookName string, count uint64)
+
+// Dispatcher delivers events to the configured webhooks. Each webhook has its own queue and delivery worker so
+// that a slow or unreachable endpoint doesn't hold up the others.
+type Dispatcher struct {
+	ctx        context.Context
+	client     *http.Client
+	deadLetter DeadLetterFunc
+	dropped    DroppedFunc
+
+	// retryDelay is the delay before the first retry, doubled after each failed attempt.
+	retryDelay time.Duration
+
+	// droppedInterval is the minimum interval between two reports of the dropped events of a webhook.
+	droppedInterval time.Duration
+
+	mu     sync.Mutex
+	queues map[string]*queue
+}
+
+// queue holds the events waiting to be delivered to a webhook.
+type queue struct {
+	webhook *Webhook
+	events  chan api.Event
+	cancel  context.CancelFunc
+
+	// dropped is the number of events dropped since the last report.
+	dropped atomic.Uint64
+}
+
+// NewDispatcher returns a Dispatcher running until ctx is cancelled. The deadLetter function is called for each
+// event that couldn't be delivered after all retries. The events dropped because the webhook's queue was full are
+// counted instead, and the dropped function is called with their number at most once a minute per webhook.
+func NewDispatcher(ctx context.Context, deadLetter DeadLetterFunc, dropped DroppedFunc) *Dispatcher {
+	return &Dispatcher{
+		ctx:             ctx,
+		client:          &http.Client{Timeout: 10 * time.Second, Transport: &http.Transport{Proxy: shared.ProxyFromEnvironment}},
+		deadLetter:      deadLetter,
+		dropped:         dropped,
+		retryDelay:      time.Second,
+		droppedInterval: time.Minute,
+		queues:          make(map[string]*queue),
+	}
+}
+
+// SetWebhooks replaces the set of webhooks events are delivered to. Webhooks whose configuration didn't change
+// keep their pending events.
+func (d *Dispatcher) SetWebhooks(webhooks []*Webhook) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	queues := make(map[string]*queue, len(webhooks))
+	for _, w := range webhooks {
+		q, ok := d.queues[w.Name]
+		if ok && reflect.DeepEqual(q.webhook, w) {
+			queues[w.Name] = q
+			delete(d.queues, w.Name)
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(d.ctx)
+		q = &queue{
+			webhook: w,
+			events:  make(chan api.Event, queueSize),
+			cancel:  cancel,
+		}
+
+		queues[w.Name] = q
+		go d.run(ctx, q)
+	}
+
+	// Stop the workers of the removed and modified webhooks.
+	for _, q := range d.queues {
+		q.cancel()
+	}
+
+	d.queues = queues
+}
+
+// Len returns the number of configured webhooks.
+func (d *Dispatcher) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return len(d.queues)
+}
+
+// HandleEvent queues the event for delivery to the webhooks it matches. It doesn't block.
+func (d *Dispatcher) HandleEvent(event api.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var delivered *api.Event
+	for _, q := range d.queues {
+		if !q.webhook.Matches(event) {
+			continue
+		}
+
+		// The secrets of operation events are stripped once for all the webhooks.
+		if delivered == nil {
+			redacted, err := deliveredEvent(event)
+			if err != nil {
+				logger.Warn("Skipping event for webhooks", logger.Ctx{"type": event.Type, "err": err})
+				return
+			}
+
+			delivered = &redacted
+		}
+
+		select {
+		case q.events <- *delivered:
+		default:
+			// Reported by the webhook's worker so that a flood of events doesn't cause a flood of reports.
+			q.dropped.Add(1)
+		}
+	}
+}
+
+// run delivers the queued events of a webhook in order until ctx is cancelled, and reports the events dropped
+// since the last report every droppedInterval.
+func (d *Dispatcher) run(ctx context.Context, q *queue) {
+	ticker := time.NewTicker(d.droppedInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count := q.dropped.Swap(0)
+			if count > 0 {
+				d.dropped(q.webhook.Name, count)
+			}
+
+		case event := <-q.events:
+			err := d.deliver(ctx, q.webhook, event)
+			if err != nil && ctx.Err() == nil {
+				d.deadLetter(q.webhook.Name, event, err)
+			}
+		}
+	}
+}
+
+// deliver sends the event to the webhook, retrying with an exponential backoff on failure.
+func (d *Dispatcher) deliver(ctx context.Context, w *Webhook, event api.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	delay := d.retryDelay
+	for attempt := 1; ; attempt++ {
+		err = d.send(ctx, w, event.Type, body)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= maxAttempts {
+			return fmt.Errorf("Failed after %d attempts: %w", attempt, err)
+		}
+
+		logger.Debug("Failed delivering event to webhook, retrying", logger.Ctx{"webhook": w.Name, "attempt": attempt, "err": err})
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+// send makes a single delivery attempt.
+func (d *Dispatcher) send(ctx context.Context, w *Webhook, eventType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", version.UserAgent)
+	req.Header.Set(NameHeader, w.Name)
+	req.Header.Set(EventTypeHeader, eventType)
+
+	if w.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Unexpected response status %q", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+// Package webhook delivers LXD events to outbound HTTP webhooks.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// SignatureHeader is the header carrying the HMAC-SHA256 signature of the request body.
+const SignatureHeader = "X-LXD-Signature-256"
+
+// EventTypeHeader is the header carrying the type of the delivered event.
+const EventTypeHeader = "X-LXD-Event-Type"
+
+// NameHeader is the header carrying the name of the webhook.
+const NameHeader = "X-LXD-Webhook"
+
+// Types are the event types that can be delivered.
+var Types = []string{api.EventTypeLifecycle, api.EventTypeOperation}
+
+// DefaultTypes are the event types delivered when the "types" key isn't set.
+var DefaultTypes = []string{api.EventTypeLifecycle}
+
+// Webhook is an endpoint events are delivered to.
+type Webhook struct {
+	Name             string
+	URL              string
+	Secret           string
+	Types            []string
+	LifecycleActions []string
+	Projects         []string
+}
+
+// ValidateConfig validates the configuration of a webhook.
+func ValidateConfig(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=webhook; group=common; key=url)
+		// Only `http` and `https` URLs are supported.
+		// ---
+		//  type: string
+		//  required: yes
+		//  shortdesc: URL the events are delivered to
+		"url": func(value string) error {
+			err := validate.IsRequestURL(value)
+			if err != nil {
+				return err
+			}
+
+			u, err := url.Parse(value)
+			if err != nil {
+				return err
+			}
+
+			if u.Scheme != "http" && u.Scheme != "https" {
+				return fmt.Errorf("Unsupported URL scheme %q", u.Scheme)
+			}
+
+			return nil
+		},
+
+		// lxdmeta:generate(entities=webhook; group=common; key=secret)
+		// When set, each request carries an `X-LXD-Signature-256` header holding `sha256=` followed by the hex
+		// encoded HMAC-SHA256 of the request body keyed with this secret.
+		// ---
+		//  type: string
+		//  shortdesc: Secret used to sign the requests
+		"secret": validate.IsAny,
+
+		// lxdmeta:generate(entities=webhook; group=common; key=types)
+		// Specify a comma-separated list of event types to deliver (`lifecycle` or `operation`).
+		// Operation events only include the ID, class, status and resources of the operation.
+		// ---
+		//  type: string
+		//  defaultdesc: `lifecycle`
+		//  shortdesc: Types of events to deliver
+		"types": validate.Optional(validate.IsListOf(validate.IsOneOf(Types...))),
+
+		// lxdmeta:generate(entities=webhook; group=common; key=lifecycle.actions)
+		// Specify a comma-separated list of lifecycle actions to deliver (for example, `instance-started`).
+		// A trailing `*` matches any action with the given prefix (for example, `instance-*`).
+		// If empty, all lifecycle actions are delivered.
+		// ---
+		//  type: string
+		//  shortdesc: Lifecycle actions to deliver
+		"lifecycle.actions": validate.Optional(validate.IsListOf(validate.IsNotEmpty)),
+
+		// lxdmeta:generate(entities=webhook; group=common; key=projects)
+		// Specify a comma-separated list of projects whose events are delivered.
+		// If empty, events from all projects are delivered, including those not tied to a project.
+		// ---
+		//  type: string
+		//  shortdesc: Projects whose events are delivered
+		"projects": validate.Optional(validate.IsListOf(validate.IsNotEmpty)),
+	}
+
+	for k, v := range config {
+		validator, ok := rules[k]
+		if !ok {
+			return fmt.Errorf("Invalid webhook configuration key %q", k)
+		}
+
+		err := validator(v)
+		if err != nil {
+			return fmt.Errorf("Invalid value for webhook configuration key %q: %w", k, err)
+		}
+	}
+
+	if config["url"] == "" {
+		return fmt.Errorf("Missing required webhook configuration key %q", "url")
+	}
+
+	return nil
+}
+
+// New returns the webhook with the given name and configuration.
+func New(name string, config map[string]string) (*Webhook, error) {
+	err := ValidateConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Webhook{
+		Name:             name,
+		URL:              config["url"],
+		Secret:           config["secret"],
+		Types:            DefaultTypes,
+		LifecycleActions: shared.SplitNTrimSpace(config["lifecycle.actions"], ",", -1, true),
+		Projects:         shared.SplitNTrimSpace(config["projects"], ",", -1, true),
+	}
+
+	if config["types"] != "" {
+		w.Types = shared.SplitNTrimSpace(config["types"], ",", -1, true)
+	}
+
+	return w, nil
+}
+
+// Matches returns whether the event should be delivered to the webhook.
+func (w *Webhook) Matches(event api.Event) bool {
+	if !slices.Contains(w.Types, event.Type) {
+		return false
+	}
+
+	if len(w.Projects) > 0 && !slices.Contains(w.Projects, event.Project) {
+		return false
+	}
+
+	if event.Type != api.EventTypeLifecycle || len(w.LifecycleActions) == 0 {
+		return true
+	}
+
+	lifecycle := api.EventLifecycle{}
+	err := json.Unmarshal(event.Metadata, &lifecycle)
+	if err != nil {
+		return false
+	}
+
+	for _, action := range w.LifecycleActions {
+		prefix, wildcard := strings.CutSuffix(action, "*")
+		if action == lifecycle.Action || (wildcard && strings.HasPrefix(lifecycle.Action, prefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// operationSummary is the part of an operation delivered to webhooks. The rest of the operation, especially its
+// metadata, may hold secrets like the credentials of the exec, console and migration websockets.
+type operationSummary struct {
+	ID         string              `json:"id"`
+	Class      string              `json:"class"`
+	Status     string              `json:"status"`
+	StatusCode api.StatusCode      `json:"status_code"`
+	Resources  map[string][]string `json:"resources"`
+}
+
+// deliveredEvent returns the event as delivered to webhooks, with the metadata of operation events reduced to
+// their summary.
+func deliveredEvent(event api.Event) (api.Event, error) {
+	if event.Type != api.EventTypeOperation {
+		return event, nil
+	}
+
+	op := api.Operation{}
+	err := json.Unmarshal(event.Metadata, &op)
+	if err != nil {
+		return api.Event{}, fmt.Errorf("Failed parsing operation event: %w", err)
+	}
+
+	event.Metadata, err = json.Marshal(operationSummary{
+		ID:         op.ID,
+		Class:      op.Class,
+		Status:     op.Status,
+		StatusCode: op.StatusCode,
+		Resources:  op.Resources,
+	})
+	if err != nil {
+		return api.Event{}, err
+	}
+
+	return event, nil
+}
+
+// Sign returns the value of the signature header for the given body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
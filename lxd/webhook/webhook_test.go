@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func lifecycleEvent(t *testing.T, project string, action string) api.Event {
+	metadata, err := json.Marshal(api.EventLifecycle{Action: action})
+	require.NoError(t, err)
+
+	return api.Event{Type: api.EventTypeLifecycle, Project: project, Metadata: metadata}
+}
+
+func TestValidateConfig(t *testing.T) {
+	assert.NoError(t, ValidateConfig(map[string]string{"url": "https://example.com/hook"}))
+	assert.NoError(t, ValidateConfig(map[string]string{"url": "http://example.com", "types": "lifecycle", "projects": "default,foo"}))
+	assert.Error(t, ValidateConfig(map[string]string{}))
+	assert.Error(t, ValidateConfig(map[string]string{"url": "ftp://example.com"}))
+	assert.Error(t, ValidateConfig(map[string]string{"url": "https://example.com", "types": "logging"}))
+	assert.Error(t, ValidateConfig(map[string]string{"url": "https://example.com", "foo": "bar"}))
+}
+
+func TestWebhookMatches(t *testing.T) {
+	w, err := New("test", map[string]string{
+		"url":               "https://example.com",
+		"lifecycle.actions": "instance-*,project-created",
+		"projects":          "default",
+	})
+	require.NoError(t, err)
+
+	assert.True(t, w.Matches(lifecycleEvent(t, "default", "instance-started")))
+	assert.True(t, w.Matches(lifecycleEvent(t, "default", "project-created")))
+	assert.False(t, w.Matches(lifecycleEvent(t, "default", "project-deleted")))
+	assert.False(t, w.Matches(lifecycleEvent(t, "foo", "instance-started")))
+	assert.False(t, w.Matches(api.Event{Type: api.EventTypeLogging, Project: "default"}))
+
+	// Operation events are only delivered when requested.
+	assert.False(t, w.Matches(api.Event{Type: api.EventTypeOperation, Project: "default"}))
+
+	w, err = New("test", map[string]string{"url": "https://example.com", "types": "lifecycle,operation"})
+	require.NoError(t, err)
+	assert.True(t, w.Matches(api.Event{Type: api.EventTypeOperation, Project: "default"}))
+}
+
+func TestDeliveredEvent(t *testing.T) {
+	metadata, err := json.Marshal(api.Operation{
+		ID:         "4b3d7f6e-0d6a-4f2b-9a54-7c0e1f5b2d8a",
+		Class:      api.OperationClassWebsocket,
+		Status:     api.Running.String(),
+		StatusCode: api.Running,
+		Resources:  map[string][]string{"instances": {"/1.0/instances/c1?project=default"}},
+		Metadata:   map[string]any{"fds": map[string]any{"0": "secret", "control": "secret"}},
+	})
+	require.NoError(t, err)
+
+	event, err := deliveredEvent(api.Event{Type: api.EventTypeOperation, Metadata: metadata})
+	require.NoError(t, err)
+	assert.NotContains(t, string(event.Metadata), "secret")
+	assert.JSONEq(t, `{"id": "4b3d7f6e-0d6a-4f2b-9a54-7c0e1f5b2d8a", "class": "websocket", "status": "Running", "status_code": 103, "resources": {"instances": ["/1.0/instances/c1?project=default"]}}`, string(event.Metadata))
+
+	// Other events are delivered as is.
+	lifecycle := lifecycleEvent(t, "default", "instance-started")
+	event, err = deliveredEvent(lifecycle)
+	require.NoError(t, err)
+	assert.Equal(t, lifecycle, event)
+}
+
+func TestDispatcherDelivery(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := NewDispatcher(ctx, func(string, api.Event, error) { t.Error("Unexpected dead-lettered event") }, func(string, uint64) { t.Error("Unexpected dropped events") })
+
+	w, err := New("test", map[string]string{"url": server.URL, "secret": "s3cr3t"})
+	require.NoError(t, err)
+	d.SetWebhooks([]*Webhook{w})
+
+	d.HandleEvent(lifecycleEvent(t, "default", "instance-started"))
+
+	select {
+	case r := <-received:
+		body := <-bodies
+		assert.Equal(t, api.EventTypeLifecycle, r.Header.Get(EventTypeHeader))
+		assert.Equal(t, "test", r.Header.Get(NameHeader))
+		assert.Equal(t, Sign("s3cr3t", body), r.Header.Get(SignatureHeader))
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for delivery")
+	}
+}
+
+func TestDispatcherDeadLetter(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deadLettered := make(chan string, 1)
+	d := NewDispatcher(ctx, func(name string, event api.Event, err error) { deadLettered <- name }, func(string, uint64) { t.Error("Unexpected dropped events") })
+	d.retryDelay = time.Millisecond
+
+	w, err := New("failing", map[string]string{"url": server.URL})
+	require.NoError(t, err)
+	d.SetWebhooks([]*Webhook{w})
+
+	d.HandleEvent(lifecycleEvent(t, "default", "instance-started"))
+
+	select {
+	case name := <-deadLettered:
+		assert.Equal(t, "failing", name)
+		assert.Equal(t, int32(maxAttempts), attempts.Load())
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for dead-lettered event")
+	}
+}
+
+func TestDispatcherDropped(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dropped := make(chan uint64, 10)
+	d := NewDispatcher(ctx, func(string, api.Event, error) { t.Error("Unexpected dead-lettered event") }, func(name string, count uint64) { dropped <- count })
+	d.droppedInterval = 10 * time.Millisecond
+
+	w, err := New("slow", map[string]string{"url": server.URL})
+	require.NoError(t, err)
+	d.SetWebhooks([]*Webhook{w})
+
+	// The worker holds at most one event, the others fill the queue or are dropped.
+	for i := 0; i < queueSize+5; i++ {
+		d.HandleEvent(lifecycleEvent(t, "default", "instance-started"))
+	}
+
+	close(release)
+
+	// The dropped events are reported together by the worker.
+	select {
+	case count := <-dropped:
+		assert.GreaterOrEqual(t, count, uint64(4))
+		assert.LessOrEqual(t, count, uint64(5))
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for dropped events")
+	}
+}
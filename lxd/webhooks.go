@@ -0,0 +1,581 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/warningtype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/lxd/webhook"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/validate"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var webhooksCmd = APIEndpoint{
+	Path: "webhooks",
+
+	Get:  APIEndpointAction{Handler: webhooksGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Post: APIEndpointAction{Handler: webhooksPost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+var webhookCmd = APIEndpoint{
+	Path: "webhooks/{name}",
+
+	Delete: APIEndpointAction{Handler: webhookDelete, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Get:    APIEndpointAction{Handler: webhookGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Patch:  APIEndpointAction{Handler: webhookPatch, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Put:    APIEndpointAction{Handler: webhookPut, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+// webhookURL returns the API URL of the webhook with the given name.
+func webhookURL(name string) string {
+	return api.NewURL().Path(version.APIVersion, "webhooks", name).String()
+}
+
+// swagger:operation GET /1.0/webhooks webhooks webhooks_get
+//
+//	Get the webhooks
+//
+//	Returns a list of webhooks (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/webhooks/chatops",
+//	              "/1.0/webhooks/ci"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/webhooks?recursion=1 webhooks webhooks_get_recursion1
+//
+//	Get the webhooks
+//
+//	Returns a list of webhooks (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: filter
+//	    description: Collection filter
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: after
+//	    description: Key of the last entry of the previous page
+//	    type: string
+//	    example: c1
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of webhooks
+//	          items:
+//	            $ref: "#/definitions/Webhook"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func webhooksGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	var webhooks []api.Webhook
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		webhooks, err = tx.GetWebhooks(ctx)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	webhooks, err = listPage(opts, webhooks, func(w api.Webhook) string { return w.Name })
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if opts.recursion {
+		return response.SyncResponse(true, webhooks)
+	}
+
+	urls := make([]string, 0, len(webhooks))
+	for _, w := range webhooks {
+		urls = append(urls, webhookURL(w.Name))
+	}
+
+	return response.SyncResponse(true, urls)
+}
+
+// swagger:operation POST /1.0/webhooks webhooks webhooks_post
+//
+//	Add a webhook
+//
+//	Creates a new webhook events are delivered to.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: webhook
+//	    description: Webhook
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/WebhooksPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func webhooksPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	req := api.WebhooksPost{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Name == "" {
+		return response.BadRequest(fmt.Errorf("No name provided"))
+	}
+
+	err = validate.IsURLSegmentSafe(req.Name)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Invalid webhook name %q: %w", req.Name, err))
+	}
+
+	err = webhook.ValidateConfig(req.Config)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := tx.CreateWebhook(ctx, req)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed creating webhook: %w", err))
+	}
+
+	err = notifyWebhooksChanged(d)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	lc := lifecycle.WebhookCreated.Event(req.Name, request.CreateRequestor(r), nil)
+	s.Events.SendLifecycle(api.ProjectDefaultName, lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation GET /1.0/webhooks/{name} webhooks webhook_get
+//
+//	Get the webhook
+//
+//	Gets a specific webhook.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Webhook
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/Webhook"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func webhookGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var info *api.Webhook
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, info, err = tx.GetWebhook(ctx, name)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, info, info.Writable())
+}
+
+// swagger:operation PUT /1.0/webhooks/{name} webhooks webhook_put
+//
+//	Update the webhook
+//
+//	Updates the entire webhook configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: webhook
+//	    description: Webhook configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/WebhookPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation PATCH /1.0/webhooks/{name} webhooks webhook_patch
+//
+//	Partially update the webhook
+//
+//	Updates a subset of the webhook configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: webhook
+//	    description: Webhook configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/WebhookPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func webhookPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.WebhookPut{}
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, info, err := tx.GetWebhook(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		err = util.EtagCheck(r, info.Writable())
+		if err != nil {
+			return err
+		}
+
+		if r.Method == http.MethodPatch {
+			if req.Description == "" {
+				req.Description = info.Description
+			}
+
+			for k, v := range info.Config {
+				_, ok := req.Config[k]
+				if !ok {
+					if req.Config == nil {
+						req.Config = map[string]string{}
+					}
+
+					req.Config[k] = v
+				}
+			}
+		}
+
+		err = webhook.ValidateConfig(req.Config)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "%w", err)
+		}
+
+		return tx.UpdateWebhook(ctx, id, req)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = notifyWebhooksChanged(d)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.WebhookUpdated.Event(name, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+func webhookPatch(d *Daemon, r *http.Request) response.Response {
+	return webhookPut(d, r)
+}
+
+// swagger:operation DELETE /1.0/webhooks/{name} webhooks webhook_delete
+//
+//	Delete the webhook
+//
+//	Removes the webhook. Events still waiting to be delivered to it are discarded.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func webhookDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name, err := url.PathUnescape(mux.Vars(r)["name"])
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		id, _, err := tx.GetWebhook(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		return tx.DeleteWebhook(ctx, id)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = notifyWebhooksChanged(d)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	s.Events.SendLifecycle(api.ProjectDefaultName, lifecycle.WebhookDeleted.Event(name, request.CreateRequestor(r), nil))
+
+	return response.EmptySyncResponse
+}
+
+// notifyWebhooksChanged reloads the webhooks on all cluster members.
+func notifyWebhooksChanged(d *Daemon) error {
+	s := d.State()
+
+	notifier, err := cluster.NewNotifier(s, s.Endpoints.NetworkCert(), s.ServerCert(), cluster.NotifyAlive)
+	if err != nil {
+		return err
+	}
+
+	err = notifier(func(client lxd.InstanceServer) error {
+		_, _, err := client.RawQuery(http.MethodPost, "/internal/webhooks-refresh", nil, "")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return d.refreshWebhooks(s.ShutdownCtx)
+}
+
+// refreshWebhooks loads the webhooks from the database and passes them to the dispatcher. The dispatcher is only
+// attached to the internal event listener while webhooks are configured.
+func (d *Daemon) refreshWebhooks(ctx context.Context) error {
+	var configs []api.Webhook
+	err := d.db.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		configs, err = tx.GetWebhooks(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading webhooks: %w", err)
+	}
+
+	webhooks := make([]*webhook.Webhook, 0, len(configs))
+	for _, config := range configs {
+		w, err := webhook.New(config.Name, config.Config)
+		if err != nil {
+			logger.Error("Skipping invalid webhook", logger.Ctx{"webhook": config.Name, "err": err})
+			continue
+		}
+
+		webhooks = append(webhooks, w)
+	}
+
+	d.webhooks.SetWebhooks(webhooks)
+
+	if len(webhooks) == 0 {
+		d.internalListener.RemoveHandler("webhooks")
+		return nil
+	}
+
+	d.internalListener.AddHandler("webhooks", func(event api.Event) {
+		// Each member delivers the events it generated itself, so events received from other members are skipped.
+		if event.Location != "" && event.Location != d.serverName {
+			return
+		}
+
+		d.webhooks.HandleEvent(event)
+	})
+
+	return nil
+}
+
+// webhookDeadLetter records the events that couldn't be delivered to a webhook as a warning.
+func (d *Daemon) webhookDeadLetter(name string, event api.Event, deliveryErr error) {
+	logger.Warn("Failed delivering event to webhook", logger.Ctx{"webhook": name, "type": event.Type, "err": deliveryErr})
+
+	err := d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarningLocalNode(ctx, "", "", -1, warningtype.WebhookDeliveryFailed, fmt.Sprintf("Webhook %q: %v", name, deliveryErr))
+	})
+	if err != nil {
+		logger.Warn("Failed to create warning", logger.Ctx{"err": err})
+	}
+}
+
+// webhookDropped records the events dropped because the delivery queue of a webhook was full as a warning.
+func (d *Daemon) webhookDropped(name string, count uint64) {
+	logger.Warn("Dropped events for webhook as its delivery queue is full", logger.Ctx{"webhook": name, "count": count})
+
+	err := d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpsertWarningLocalNode(ctx, "", "", -1, warningtype.WebhookDeliveryFailed, fmt.Sprintf("Webhook %q: Dropped %d events as the delivery queue is full", name, count))
+	})
+	if err != nil {
+		logger.Warn("Failed to create warning", logger.Ctx{"err": err})
+	}
+}
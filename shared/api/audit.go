@@ -0,0 +1,56 @@
+package api
+
+import (
+	"time"
+)
+
+// AuditLogEntry represents a mutating API request recorded in the audit log of a cluster member.
+//
+// swagger:model
+//
+// API extension: audit_log.
+type AuditLogEntry struct {
+	// When the request was handled
+	// Example: 2024-06-01T12:00:00.753398689Z
+	Date time.Time `json:"date" yaml:"date"`
+
+	// What cluster member handled the request
+	// Example: lxd01
+	Location string `json:"location" yaml:"location"`
+
+	// Identifier of the identity that made the request
+	// Example: foo@example.com
+	Identity string `json:"identity" yaml:"identity"`
+
+	// Authentication method used by the identity
+	// Example: oidc
+	AuthenticationMethod string `json:"authentication_method" yaml:"authentication_method"`
+
+	// Address the request originated from
+	// Example: 10.0.0.1:54321
+	SourceAddress string `json:"source_address" yaml:"source_address"`
+
+	// HTTP method of the request
+	// Example: POST
+	Method string `json:"method" yaml:"method"`
+
+	// Request URL
+	// Example: /1.0/instances?project=default
+	URL string `json:"url" yaml:"url"`
+
+	// Type of the entity targeted by the request, if known
+	// Example: instance
+	EntityType string `json:"entity_type" yaml:"entity_type"`
+
+	// URL of the entity targeted by the request, if known
+	// Example: /1.0/instances/c1?project=default
+	EntityURL string `json:"entity_url" yaml:"entity_url"`
+
+	// Hex encoded SHA-256 digest of the first MiB of the request body
+	// Example: e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855
+	BodySHA256 string `json:"body_sha256" yaml:"body_sha256"`
+
+	// HTTP status code of the response
+	// Example: 202
+	StatusCode int `json:"status_code" yaml:"status_code"`
+}
@@ -1,11 +1,18 @@
 package api
 
+import (
+	"time"
+)
+
 const (
 	// AuthenticationMethodTLS is the default authentication method for interacting with LXD remotely.
 	AuthenticationMethodTLS = "tls"
 
 	// AuthenticationMethodOIDC is a token based authentication method.
 	AuthenticationMethodOIDC = "oidc"
+
+	// AuthenticationMethodBearer is an authentication method using LXD issued bearer tokens.
+	AuthenticationMethodBearer = "bearer"
 )
 
 const (
@@ -26,6 +33,9 @@ const (
 
 	// IdentityTypeOIDCClient represents an identity that authenticates with OIDC.
 	IdentityTypeOIDCClient = "OIDC client"
+
+	// IdentityTypeBearerToken represents an identity that authenticates with a LXD issued bearer token.
+	IdentityTypeBearerToken = "Bearer token"
 )
 
 // Identity is the type for an authenticated party that can make requests to the HTTPS API.
@@ -99,6 +109,48 @@ type IdentityPut struct {
 	Groups []string `json:"groups" yaml:"groups"`
 }
 
+// IdentityBearerTokenPost is used for issuing a new bearer token identity.
+//
+// swagger:model
+//
+// API extension: auth_bearer_tokens.
+type IdentityBearerTokenPost struct {
+	// Name is the name of the token identity.
+	// Example: ci-pipeline
+	Name string `json:"name" yaml:"name"`
+
+	// Groups is the list of groups the token identity is a member of.
+	// Example: ["ci"]
+	Groups []string `json:"groups" yaml:"groups"`
+
+	// ExpiresAt is the time after which the token is no longer valid.
+	// Example: 2025-01-01T00:00:00Z
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+
+	// AllowedCIDRs restricts the source addresses the token may be used from. Any address is allowed when empty.
+	// Example: ["10.0.0.0/8"]
+	AllowedCIDRs []string `json:"allowed_cidrs" yaml:"allowed_cidrs"`
+}
+
+// IdentityBearerToken is returned once when a bearer token identity is created.
+//
+// swagger:model
+//
+// API extension: auth_bearer_tokens.
+type IdentityBearerToken struct {
+	// Identifier is the identifier of the token identity.
+	// Example: 1b1b2f3e-40ef-4ab4-8b52-41f9b1c12fd3
+	Identifier string `json:"id" yaml:"id"`
+
+	// Token is the secret bearer token. It cannot be retrieved again.
+	// Example: lxd_1b1b2f3e-40ef-4ab4-8b52-41f9b1c12fd3.6e0d7c4f0b8a...
+	Token string `json:"token" yaml:"token"`
+
+	// ExpiresAt is the time after which the token is no longer valid.
+	// Example: 2025-01-01T00:00:00Z
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
+
 // AuthGroup is the type for a LXD group.
 //
 // swagger:model
@@ -240,6 +292,48 @@ type Permission struct {
 	Entitlement string `json:"entitlement" yaml:"entitlement"`
 }
 
+// AuthCheckPost is used to check whether an identity has an entitlement on an entity.
+//
+// swagger:model
+//
+// API extension: auth_check.
+type AuthCheckPost struct {
+	// AuthenticationMethod is the authentication method of the identity to check.
+	// The caller is checked if this and Identity are empty.
+	// Example: oidc
+	AuthenticationMethod string `json:"authentication_method" yaml:"authentication_method"`
+
+	// Identity is the name or identifier of the identity to check.
+	// Example: jane.doe@example.com
+	Identity string `json:"identity" yaml:"identity"`
+
+	// EntityReference is the URL of the entity to check.
+	// Example: /1.0/instances/c1?project=default
+	EntityReference string `json:"url" yaml:"url"`
+
+	// Entitlement is the entitlement to check.
+	// Example: can_exec
+	Entitlement string `json:"entitlement" yaml:"entitlement"`
+}
+
+// AuthCheck is the result of a permission check.
+//
+// swagger:model
+//
+// API extension: auth_check.
+type AuthCheck struct {
+	// Allowed is whether the identity has the entitlement on the entity.
+	// Example: true
+	Allowed bool `json:"allowed" yaml:"allowed"`
+
+	// Groups is the list of groups of the identity that grant the entitlement.
+	// Example: ["operators"]
+	Groups []string `json:"groups" yaml:"groups"`
+
+	// Permissions is the list of permissions of those groups on the entity, its project or the server.
+	Permissions []Permission `json:"permissions" yaml:"permissions"`
+}
+
 // PermissionInfo expands a Permission to include any groups that may have the specified Permission.
 //
 // swagger:model
@@ -0,0 +1,49 @@
+package api
+
+// BulkPost represents an action applied to all the instances matching a filter.
+//
+// swagger:model
+//
+// API extension: bulk_operations.
+type BulkPost struct {
+	// Filter selecting the instances (refer to the filtering section of doc/rest-api.md)
+	// Example: status eq Running and config.user.tier eq web
+	Filter string `json:"filter" yaml:"filter"`
+
+	// Action to apply (start, stop, restart, snapshot, delete or patch)
+	// Example: restart
+	Action string `json:"action" yaml:"action"`
+
+	// Options of the start, stop and restart actions
+	State *InstanceStatePut `json:"state,omitempty" yaml:"state,omitempty"`
+
+	// Options of the snapshot action
+	Snapshot *InstanceSnapshotsPost `json:"snapshot,omitempty" yaml:"snapshot,omitempty"`
+
+	// Configuration keys to set with the patch action, an empty value unsets the key
+	// Example: {"limits.cpu": "2"}
+	Config map[string]string `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// BulkResult represents the result of a bulk action for a single instance.
+//
+// swagger:model
+//
+// API extension: bulk_operations.
+type BulkResult struct {
+	// Name of the instance
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// Project of the instance
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Cluster member the instance is located on
+	// Example: lxd01
+	Location string `json:"location" yaml:"location"`
+
+	// Error applying the action to the instance, empty if it succeeded
+	// Example: Instance is running
+	Error string `json:"error" yaml:"error"`
+}
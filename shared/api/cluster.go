@@ -368,3 +368,106 @@ func (c *ClusterGroup) SetWritable(put ClusterGroupPut) {
 	c.Description = put.Description
 	c.Members = put.Members
 }
+
+// ClusterRebalance represents the instance moves planned to reduce the load imbalance between cluster members.
+//
+// swagger:model
+//
+// API extension: cluster_rebalance.
+type ClusterRebalance struct {
+	// Estimated load of the cluster members before the moves (percentage)
+	// Example: {"lxd01": 82.5, "lxd02": 31.2}
+	Members map[string]float64 `json:"members" yaml:"members"`
+
+	// List of planned instance moves
+	// Example: [{"name": "c1", "project": "default", "source": "lxd01", "target": "lxd02", "live": true}]
+	Moves []ClusterRebalanceMove `json:"moves" yaml:"moves"`
+}
+
+// ClusterRebalanceMove represents an instance move planned by the cluster rebalancer.
+//
+// swagger:model
+//
+// API extension: cluster_rebalance.
+type ClusterRebalanceMove struct {
+	// Name of the instance
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// Project of the instance
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Cluster member currently running the instance
+	// Example: lxd01
+	Source string `json:"source" yaml:"source"`
+
+	// Cluster member the instance is moved to
+	// Example: lxd02
+	Target string `json:"target" yaml:"target"`
+
+	// Whether the instance is live-migrated
+	// Example: true
+	Live bool `json:"live" yaml:"live"`
+}
+
+// ClusterUpgrade represents a rolling upgrade of the cluster members.
+//
+// swagger:model
+//
+// API extension: cluster_rolling_upgrade.
+type ClusterUpgrade struct {
+	// Status of the rolling upgrade (running, paused, aborted or completed)
+	// Example: running
+	Status string `json:"status" yaml:"status"`
+
+	// Details about the current step of the rolling upgrade
+	// Example: Waiting for cluster member "lxd01" to be upgraded
+	Message string `json:"message" yaml:"message"`
+
+	// When the rolling upgrade was started
+	// Example: 2024-06-01T10:00:00Z
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+
+	// When the rolling upgrade was last updated
+	// Example: 2024-06-01T10:12:00Z
+	UpdatedAt time.Time `json:"updated_at" yaml:"updated_at"`
+
+	// Cluster members in upgrade order
+	// Example: [{"name": "lxd01", "status": "done", "version": "5.21"}]
+	Members []ClusterUpgradeMember `json:"members" yaml:"members"`
+}
+
+// ClusterUpgradeMember represents the upgrade status of a cluster member.
+//
+// swagger:model
+//
+// API extension: cluster_rolling_upgrade.
+type ClusterUpgradeMember struct {
+	// Name of the cluster member
+	// Example: lxd01
+	Name string `json:"name" yaml:"name"`
+
+	// Upgrade status of the member (pending, evacuating, upgrading, upgraded, restoring or done)
+	// Example: upgrading
+	Status string `json:"status" yaml:"status"`
+
+	// LXD version of the member when the rolling upgrade was started
+	// Example: 5.21
+	Version string `json:"version" yaml:"version"`
+}
+
+// ClusterUpgradePost represents the fields required to control a rolling upgrade of the cluster.
+//
+// swagger:model
+//
+// API extension: cluster_rolling_upgrade.
+type ClusterUpgradePost struct {
+	// The action to be performed. Valid actions are "start", "pause", "resume" and "abort".
+	// Example: start
+	Action string `json:"action" yaml:"action"`
+
+	// Cluster members to upgrade, in order (only used when starting, defaults to all members)
+	// Example: ["lxd02", "lxd03", "lxd01"]
+	Members []string `json:"members" yaml:"members"`
+}
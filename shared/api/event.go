@@ -41,6 +41,13 @@ type Event struct {
 	//
 	// API extension: event_project
 	Project string `yaml:"project,omitempty" json:"project,omitempty"`
+
+	// Sequence ID of the event on the cluster member serving the event stream.
+	// It can be passed as the `since` parameter of the event stream to replay the events that followed it.
+	// Example: 1718000000000042
+	//
+	// API extension: event_replay
+	ID uint64 `yaml:"id,omitempty" json:"id,omitempty"`
 }
 
 // ToLogging creates log record for the event.
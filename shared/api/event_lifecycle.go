@@ -119,8 +119,12 @@ const (
 	EventLifecycleWarningAcknowledged               = "warning-acknowledged"
 	EventLifecycleWarningDeleted                    = "warning-deleted"
 	EventLifecycleWarningReset                      = "warning-reset"
+	EventLifecycleWebhookCreated                    = "webhook-created"
+	EventLifecycleWebhookDeleted                    = "webhook-deleted"
+	EventLifecycleWebhookUpdated                    = "webhook-updated"
 	EventLifecycleIdentityCreated                   = "identity-created"
 	EventLifecycleIdentityUpdated                   = "identity-updated"
+	EventLifecycleIdentityDeleted                   = "identity-deleted"
 	EventLifecycleAuthGroupCreated                  = "auth-group-created"
 	EventLifecycleAuthGroupUpdated                  = "auth-group-updated"
 	EventLifecycleAuthGroupRenamed                  = "auth-group-renamed"
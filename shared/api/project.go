@@ -1,5 +1,9 @@
 package api
 
+import (
+	"time"
+)
+
 // ProjectDefaultName is the name of the default project that can never be deleted.
 const ProjectDefaultName = "default"
 
@@ -114,3 +118,38 @@ type ProjectStateResource struct {
 	// Example: 4
 	Usage int64
 }
+
+// ProjectUsage represents the resource usage of a LXD project over a period of time
+//
+// swagger:model
+//
+// API extension: project_usage_history.
+type ProjectUsage struct {
+	// First day of the period (UTC)
+	// Example: 2024-01-01T00:00:00Z
+	From time.Time `json:"from" yaml:"from"`
+
+	// Last day of the period (UTC)
+	// Example: 2024-01-31T00:00:00Z
+	To time.Time `json:"to" yaml:"to"`
+
+	// CPU time used by the instances
+	// Example: 86400
+	CPUSeconds float64 `json:"cpu_seconds" yaml:"cpu_seconds"`
+
+	// Memory used by the instances, in GiB-hours
+	// Example: 720
+	MemoryGiBHours float64 `json:"memory_gib_hours" yaml:"memory_gib_hours"`
+
+	// Disk space used by the instances and custom storage volumes, in GiB-days
+	// Example: 310
+	DiskGiBDays float64 `json:"disk_gib_days" yaml:"disk_gib_days"`
+
+	// Bytes received by the instances
+	// Example: 1073741824
+	NetworkBytesReceived int64 `json:"network_bytes_received" yaml:"network_bytes_received"`
+
+	// Bytes sent by the instances
+	// Example: 536870912
+	NetworkBytesSent int64 `json:"network_bytes_sent" yaml:"network_bytes_sent"`
+}
@@ -0,0 +1,47 @@
+package api
+
+// WebhooksPost represents the fields of a new webhook.
+//
+// swagger:model
+//
+// API extension: webhooks.
+type WebhooksPost struct {
+	WebhookPut `yaml:",inline"`
+
+	// The name of the webhook
+	// Example: chatops
+	Name string `json:"name" yaml:"name"`
+}
+
+// WebhookPut represents the modifiable fields of a webhook.
+//
+// swagger:model
+//
+// API extension: webhooks.
+type WebhookPut struct {
+	// Description of the webhook
+	// Example: Notify the operations channel
+	Description string `json:"description" yaml:"description"`
+
+	// Webhook configuration map (refer to doc/webhooks.md)
+	// Example: {"url": "https://chat.example.com/hooks/lxd", "types": "lifecycle"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// Webhook represents an outbound webhook delivering events to an HTTP endpoint.
+//
+// swagger:model
+//
+// API extension: webhooks.
+type Webhook struct {
+	WebhookPut `yaml:",inline"`
+
+	// The name of the webhook
+	// Example: chatops
+	Name string `json:"name" yaml:"name"`
+}
+
+// Writable converts a full Webhook struct into a WebhookPut struct (filters read-only fields).
+func (w *Webhook) Writable() WebhookPut {
+	return w.WebhookPut
+}
@@ -0,0 +1,266 @@
+package oci
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// layout is an OCI image layout (https://github.com/opencontainers/image-spec/blob/main/image-layout.md).
+type layout struct {
+	fsys fs.FS
+}
+
+func newLayout(fsys fs.FS) *layout {
+	return &layout{fsys: fsys}
+}
+
+// index returns the top-level index of the layout.
+func (l *layout) index() (*Index, error) {
+	data, err := fs.ReadFile(l.fsys, "index.json")
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading OCI layout index: %w", err)
+	}
+
+	index := &Index{}
+	err = json.Unmarshal(data, index)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing OCI layout index: %w", err)
+	}
+
+	return index, nil
+}
+
+// layoutRefNames returns the names a layout index entry can be referred to by.
+func layoutRefNames(desc Descriptor) []string {
+	names := []string{}
+
+	refName := desc.Annotations[AnnotationRefName]
+	if refName != "" {
+		names = append(names, refName)
+	}
+
+	fullName := desc.Annotations[annotationContainerdName]
+	if fullName != "" {
+		names = append(names, fullName)
+	}
+
+	return names
+}
+
+func (l *layout) manifest(name string, reference string) ([]byte, string, error) {
+	var desc *Descriptor
+
+	if strings.HasPrefix(reference, "sha256:") {
+		desc = &Descriptor{Digest: reference}
+	} else {
+		index, err := l.index()
+		if err != nil {
+			return nil, "", err
+		}
+
+		// Entries may be named after the tag only, or after the full "name:tag" reference.
+		candidates := []string{name + ":" + reference, reference}
+		if name != "" && reference == "latest" {
+			candidates = append(candidates, name)
+		}
+
+		for i, entry := range index.Manifests {
+			for _, refName := range layoutRefNames(entry) {
+				if shared.ValueInSlice(refName, candidates) {
+					desc = &index.Manifests[i]
+					break
+				}
+			}
+
+			if desc != nil {
+				break
+			}
+		}
+
+		// A layout holding a single image can be used without a reference.
+		if desc == nil && name == "" && reference == "latest" && len(index.Manifests) == 1 {
+			desc = &index.Manifests[0]
+		}
+
+		if desc == nil {
+			return nil, "", fmt.Errorf("Image %q not found in OCI layout", name+":"+reference)
+		}
+	}
+
+	data, err := fs.ReadFile(l.fsys, blobPath(desc.Digest))
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed reading manifest %q: %w", desc.Digest, err)
+	}
+
+	return data, manifestMediaType(desc.MediaType, data), nil
+}
+
+func (l *layout) blob(name string, digest string) (io.ReadCloser, int64, error) {
+	f, err := l.fsys.Open(blobPath(digest))
+	if err != nil {
+		return nil, -1, fmt.Errorf("Failed opening blob %q: %w", digest, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, -1, err
+	}
+
+	return f, info.Size(), nil
+}
+
+func (l *layout) references() ([]string, error) {
+	index, err := l.index()
+	if err != nil {
+		return nil, err
+	}
+
+	refs := []string{}
+	for _, entry := range index.Manifests {
+		names := layoutRefNames(entry)
+		if len(names) == 0 {
+			refs = append(refs, "@"+entry.Digest)
+			continue
+		}
+
+		refs = append(refs, names[0])
+	}
+
+	return refs, nil
+}
+
+// blobPath returns the path of a blob within a layout.
+func blobPath(digest string) string {
+	algorithm, encoded, _ := strings.Cut(digest, ":")
+	return path.Join("blobs", algorithm, path.Clean("/" + encoded)[1:])
+}
+
+// manifestMediaType returns the media type of a manifest, falling back to its mediaType field
+// when the referencing descriptor doesn't carry one.
+func manifestMediaType(mediaType string, data []byte) string {
+	if mediaType != "" {
+		return mediaType
+	}
+
+	content := struct {
+		MediaType string            `json:"mediaType"`
+		Manifests []json.RawMessage `json:"manifests"`
+	}{}
+
+	_ = json.Unmarshal(data, &content)
+
+	if content.MediaType != "" {
+		return content.MediaType
+	}
+
+	if content.Manifests != nil {
+		return MediaTypeImageIndex
+	}
+
+	return MediaTypeImageManifest
+}
+
+// archiveFS exposes the regular files of an uncompressed tarball as a read-only filesystem.
+type archiveFS struct {
+	file    *os.File
+	entries map[string]archiveEntry
+}
+
+type archiveEntry struct {
+	offset int64
+	size   int64
+}
+
+// newArchive returns a layout backed by an oci-archive tarball.
+func newArchive(archivePath string) (*layout, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &archiveFS{file: f, entries: map[string]archiveEntry{}}
+
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("Failed reading OCI archive %q (only uncompressed archives are supported): %w", archivePath, err)
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// The tar reader is positioned at the start of the entry content.
+		offset, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+
+		archive.entries[path.Clean("/" + hdr.Name)[1:]] = archiveEntry{offset: offset, size: hdr.Size}
+	}
+
+	_, found := archive.entries["index.json"]
+	if !found {
+		_ = f.Close()
+		return nil, fmt.Errorf("File %q isn't an OCI archive", archivePath)
+	}
+
+	return newLayout(archive), nil
+}
+
+// Open implements fs.FS.
+func (a *archiveFS) Open(name string) (fs.File, error) {
+	entry, found := a.entries[name]
+	if !found {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return &archiveFile{SectionReader: io.NewSectionReader(a.file, entry.offset, entry.size), name: name}, nil
+}
+
+// archiveFile is a file within an archiveFS.
+type archiveFile struct {
+	*io.SectionReader
+	name string
+}
+
+// Stat implements fs.File.
+func (f *archiveFile) Stat() (fs.FileInfo, error) {
+	return archiveFileInfo{name: path.Base(f.name), size: f.Size()}, nil
+}
+
+// Close implements fs.File.
+func (f *archiveFile) Close() error {
+	return nil
+}
+
+// archiveFileInfo describes a file within an archiveFS.
+type archiveFileInfo struct {
+	name string
+	size int64
+}
+
+func (i archiveFileInfo) Name() string       { return i.name }
+func (i archiveFileInfo) Size() int64        { return i.size }
+func (i archiveFileInfo) Mode() fs.FileMode  { return 0444 }
+func (i archiveFileInfo) ModTime() time.Time { return time.Time{} }
+func (i archiveFileInfo) IsDir() bool        { return false }
+func (i archiveFileInfo) Sys() any           { return nil }
@@ -0,0 +1,386 @@
+// Package oci implements a minimal client for OCI images stored in image layout directories,
+// image layout archives (oci-archive) and registries implementing the OCI distribution API.
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/osarch"
+)
+
+// OCI and Docker media types understood by the client.
+const (
+	MediaTypeImageIndex         = "application/vnd.oci.image.index.v1+json"
+	MediaTypeImageManifest      = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+// AnnotationRefName is the annotation used in image layouts to name a manifest.
+const AnnotationRefName = "org.opencontainers.image.ref.name"
+
+// annotationContainerdName is the annotation used by containerd and Docker in exported layouts.
+const annotationContainerdName = "io.containerd.image.name"
+
+// Descriptor describes the content of a blob.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Platform    *Platform         `json:"platform,omitempty"`
+}
+
+// Platform describes the platform an image manifest applies to.
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// Index references the manifests of a multi-platform image.
+type Index struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType,omitempty"`
+	Manifests     []Descriptor `json:"manifests"`
+}
+
+// Manifest describes the configuration and layers of a single image.
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// ImageConfig is the subset of the image configuration used to run the image.
+type ImageConfig struct {
+	Created      *time.Time `json:"created,omitempty"`
+	Architecture string     `json:"architecture"`
+	Variant      string     `json:"variant,omitempty"`
+	OS           string     `json:"os"`
+	Config       struct {
+		User       string            `json:"User,omitempty"`
+		Env        []string          `json:"Env,omitempty"`
+		Entrypoint []string          `json:"Entrypoint,omitempty"`
+		Cmd        []string          `json:"Cmd,omitempty"`
+		WorkingDir string            `json:"WorkingDir,omitempty"`
+		StopSignal string            `json:"StopSignal,omitempty"`
+		Labels     map[string]string `json:"Labels,omitempty"`
+	} `json:"config"`
+}
+
+// Image is an image manifest resolved for a specific platform.
+type Image struct {
+	Reference string
+	Digest    string
+	Manifest  Manifest
+	Config    ImageConfig
+}
+
+// Fingerprint returns the hexadecimal part of the manifest digest.
+func (i *Image) Fingerprint() string {
+	return strings.TrimPrefix(i.Digest, "sha256:")
+}
+
+// Architecture returns the LXD name of the image architecture.
+func (i *Image) Architecture() (string, error) {
+	id, err := architectureID(i.Config.Architecture, i.Config.Variant)
+	if err != nil {
+		return "", err
+	}
+
+	return osarch.ArchitectureName(id)
+}
+
+// Size returns the compressed size of the image layers.
+func (i *Image) Size() int64 {
+	var size int64
+	for _, layer := range i.Manifest.Layers {
+		size += layer.Size
+	}
+
+	return size
+}
+
+// backend is implemented by the various image stores.
+type backend interface {
+	// manifest returns the raw manifest or index for a tag or digest along with its media type.
+	manifest(name string, reference string) ([]byte, string, error)
+
+	// blob returns a reader for the blob with the given digest along with its size.
+	blob(name string, digest string) (io.ReadCloser, int64, error)
+
+	// references returns the image references available in the store.
+	references() ([]string, error)
+}
+
+// Client retrieves images from an OCI image store.
+type Client struct {
+	backend backend
+}
+
+// NewClient returns a client for the OCI image store at the given URL.
+// A "file://" URL may point to an image layout directory or an oci-archive tarball, while "http://" and
+// "https://" URLs point to a registry implementing the OCI distribution API.
+func NewClient(url string, httpClient *http.Client, userAgent string) (*Client, error) {
+	if strings.HasPrefix(url, "file://") {
+		path := strings.TrimPrefix(url, "file://")
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("Failed accessing OCI image store %q: %w", path, err)
+		}
+
+		if info.IsDir() {
+			return &Client{backend: newLayout(os.DirFS(path))}, nil
+		}
+
+		archive, err := newArchive(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Client{backend: archive}, nil
+	}
+
+	if strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://") {
+		return &Client{backend: newRegistry(url, httpClient, userAgent)}, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported OCI image store URL %q", url)
+}
+
+// References returns the image references available in the store.
+// Registries don't support listing their content.
+func (c *Client) References() ([]string, error) {
+	return c.backend.references()
+}
+
+// Resolve fetches the manifest and configuration of an image reference for the given LXD architecture.
+// The reference is either "name[:tag]" or "name@digest".
+func (c *Client) Resolve(reference string, architecture string) (*Image, error) {
+	archID, err := osarch.ArchitectureId(architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	name, tag := ParseReference(reference)
+
+	data, mediaType, err := c.backend.manifest(name, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+
+	// Select the matching platform when given a multi-platform image.
+	if mediaType == MediaTypeImageIndex || mediaType == MediaTypeDockerManifestList {
+		var index Index
+
+		err = json.Unmarshal(data, &index)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing image index of %q: %w", reference, err)
+		}
+
+		var selected *Descriptor
+		for i, desc := range index.Manifests {
+			if desc.Platform == nil || desc.Platform.OS != "linux" {
+				continue
+			}
+
+			id, err := architectureID(desc.Platform.Architecture, desc.Platform.Variant)
+			if err == nil && id == archID {
+				selected = &index.Manifests[i]
+				break
+			}
+		}
+
+		if selected == nil {
+			return nil, fmt.Errorf("Image %q isn't available for architecture %q", reference, architecture)
+		}
+
+		data, mediaType, err = c.backend.manifest(name, selected.Digest)
+		if err != nil {
+			return nil, err
+		}
+
+		digest = fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+		if digest != selected.Digest {
+			return nil, fmt.Errorf("Manifest digest mismatch for %q: %s != %s", reference, digest, selected.Digest)
+		}
+	}
+
+	if mediaType != MediaTypeImageManifest && mediaType != MediaTypeDockerManifest {
+		return nil, fmt.Errorf("Unsupported manifest media type %q for %q", mediaType, reference)
+	}
+
+	img := &Image{Reference: reference, Digest: digest}
+
+	err = json.Unmarshal(data, &img.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing image manifest of %q: %w", reference, err)
+	}
+
+	body, _, err := c.Blob(name, img.Manifest.Config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching image configuration of %q: %w", reference, err)
+	}
+
+	defer func() { _ = body.Close() }()
+
+	err = json.NewDecoder(body).Decode(&img.Config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing image configuration of %q: %w", reference, err)
+	}
+
+	if img.Config.OS != "" && img.Config.OS != "linux" {
+		return nil, fmt.Errorf("Image %q is for %q rather than Linux", reference, img.Config.OS)
+	}
+
+	id, err := architectureID(img.Config.Architecture, img.Config.Variant)
+	if err != nil || id != archID {
+		return nil, fmt.Errorf("Image %q is for architecture %q rather than %q", reference, img.Config.Architecture, architecture)
+	}
+
+	return img, nil
+}
+
+// Architectures returns the LXD names of the architectures an image reference is available for.
+func (c *Client) Architectures(reference string) ([]string, error) {
+	name, tag := ParseReference(reference)
+
+	data, mediaType, err := c.backend.manifest(name, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	architectures := []string{}
+
+	if mediaType == MediaTypeImageIndex || mediaType == MediaTypeDockerManifestList {
+		var index Index
+
+		err = json.Unmarshal(data, &index)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing image index of %q: %w", reference, err)
+		}
+
+		for _, desc := range index.Manifests {
+			if desc.Platform == nil || desc.Platform.OS != "linux" {
+				continue
+			}
+
+			id, err := architectureID(desc.Platform.Architecture, desc.Platform.Variant)
+			if err != nil {
+				continue
+			}
+
+			architecture, err := osarch.ArchitectureName(id)
+			if err == nil && !shared.ValueInSlice(architecture, architectures) {
+				architectures = append(architectures, architecture)
+			}
+		}
+
+		return architectures, nil
+	}
+
+	var manifest Manifest
+
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing image manifest of %q: %w", reference, err)
+	}
+
+	body, _, err := c.Blob(name, manifest.Config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed fetching image configuration of %q: %w", reference, err)
+	}
+
+	defer func() { _ = body.Close() }()
+
+	img := Image{}
+
+	err = json.NewDecoder(body).Decode(&img.Config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing image configuration of %q: %w", reference, err)
+	}
+
+	architecture, err := img.Architecture()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(architectures, architecture), nil
+}
+
+// Blob returns a reader for the blob described by desc, which verifies the blob digest on EOF.
+func (c *Client) Blob(name string, desc Descriptor) (io.ReadCloser, int64, error) {
+	if !strings.HasPrefix(desc.Digest, "sha256:") {
+		return nil, -1, fmt.Errorf("Unsupported digest %q", desc.Digest)
+	}
+
+	body, size, err := c.backend.blob(name, desc.Digest)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	return &verifyingReader{ReadCloser: body, hash: sha256.New(), digest: desc.Digest}, size, nil
+}
+
+// ParseReference splits an image reference into its name and its tag or digest.
+// The tag defaults to "latest".
+func ParseReference(reference string) (string, string) {
+	name, digest, found := strings.Cut(reference, "@")
+	if found {
+		return name, digest
+	}
+
+	i := strings.LastIndex(reference, ":")
+	if i > strings.LastIndex(reference, "/") {
+		return reference[:i], reference[i+1:]
+	}
+
+	return reference, "latest"
+}
+
+// architectureID returns the LXD architecture ID for an OCI architecture and variant.
+func architectureID(architecture string, variant string) (int, error) {
+	if architecture == "arm" && variant == "v7" {
+		architecture = "armv7"
+	}
+
+	return osarch.ArchitectureId(architecture)
+}
+
+// verifyingReader checks the digest of the content read through it once EOF is reached.
+type verifyingReader struct {
+	io.ReadCloser
+	hash   hash.Hash
+	digest string
+}
+
+// Read implements io.Reader.
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+
+	if errors.Is(err, io.EOF) {
+		digest := fmt.Sprintf("sha256:%x", r.hash.Sum(nil))
+		if digest != r.digest {
+			return n, fmt.Errorf("Blob digest mismatch: %s != %s", digest, r.digest)
+		}
+	}
+
+	return n, err
+}
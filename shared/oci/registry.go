@@ -0,0 +1,248 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// registryAccept lists the manifest media types accepted from registries.
+var registryAccept = []string{MediaTypeImageIndex, MediaTypeImageManifest, MediaTypeDockerManifestList, MediaTypeDockerManifest}
+
+// registry is a registry implementing the OCI distribution API.
+type registry struct {
+	http      *http.Client
+	url       *url.URL
+	userAgent string
+
+	// Docker Hub stores official images under the "library" namespace.
+	dockerHub bool
+
+	tokensMu sync.Mutex
+	tokens   map[string]string
+}
+
+func newRegistry(registryURL string, httpClient *http.Client, userAgent string) *registry {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	r := &registry{http: httpClient, userAgent: userAgent, tokens: map[string]string{}}
+
+	u, err := url.Parse(strings.TrimSuffix(registryURL, "/"))
+	if err != nil {
+		u = &url.URL{Scheme: "https", Host: registryURL}
+	}
+
+	// The Docker Hub registry API isn't served on the public hostname.
+	if shared.ValueInSlice(u.Hostname(), []string{"docker.io", "index.docker.io", "registry-1.docker.io"}) {
+		u.Host = "registry-1.docker.io"
+		r.dockerHub = true
+	}
+
+	r.url = u
+
+	return r
+}
+
+// repository returns the repository path for an image name.
+func (r *registry) repository(name string) string {
+	if r.dockerHub && !strings.Contains(name, "/") {
+		return "library/" + name
+	}
+
+	return name
+}
+
+// do performs a request against the registry, authenticating with a bearer token when challenged.
+func (r *registry) do(name string, path string, accept []string) (*http.Response, error) {
+	repository := r.repository(name)
+	reqURL := fmt.Sprintf("%s://%s/v2/%s/%s", r.url.Scheme, r.url.Host, repository, path)
+
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, reqURL, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if r.userAgent != "" {
+			req.Header.Set("User-Agent", r.userAgent)
+		}
+
+		if len(accept) > 0 {
+			req.Header.Set("Accept", strings.Join(accept, ", "))
+		}
+
+		r.tokensMu.Lock()
+		token := r.tokens[repository]
+		r.tokensMu.Unlock()
+
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		} else if r.url.User != nil {
+			password, _ := r.url.User.Password()
+			req.SetBasicAuth(r.url.User.Username(), password)
+		}
+
+		return req, nil
+	}
+
+	req, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		_ = resp.Body.Close()
+
+		err = r.authenticate(repository, challenge)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err = newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err = r.http.Do(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("Image %q not found in registry %q", name, r.url.Host)
+		}
+
+		return nil, fmt.Errorf("Failed fetching %q from registry %q: %s", path, r.url.Host, resp.Status)
+	}
+
+	return resp, nil
+}
+
+// authenticate retrieves a pull token for the repository following a bearer challenge.
+func (r *registry) authenticate(repository string, challenge string) error {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return fmt.Errorf("Registry %q requires unsupported authentication %q", r.url.Host, scheme)
+	}
+
+	// The challenge parameters have the same syntax as media type parameters.
+	_, fields, err := mime.ParseMediaType("challenge; " + strings.ReplaceAll(params, ",", ";"))
+	if err != nil {
+		return fmt.Errorf("Failed parsing authentication challenge from registry %q: %w", r.url.Host, err)
+	}
+
+	realm, err := url.Parse(fields["realm"])
+	if err != nil || realm.Host == "" {
+		return fmt.Errorf("Invalid authentication realm %q from registry %q", fields["realm"], r.url.Host)
+	}
+
+	query := realm.Query()
+	if fields["service"] != "" {
+		query.Set("service", fields["service"])
+	}
+
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", repository))
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	if r.userAgent != "" {
+		req.Header.Set("User-Agent", r.userAgent)
+	}
+
+	if r.url.User != nil {
+		password, _ := r.url.User.Password()
+		req.SetBasicAuth(r.url.User.Username(), password)
+	}
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed authenticating with registry %q: %w", r.url.Host, err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Failed authenticating with registry %q: %s", r.url.Host, resp.Status)
+	}
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return fmt.Errorf("Failed parsing authentication token from registry %q: %w", r.url.Host, err)
+	}
+
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+
+	if token.Token == "" {
+		return fmt.Errorf("Registry %q didn't provide an authentication token", r.url.Host)
+	}
+
+	r.tokensMu.Lock()
+	r.tokens[repository] = token.Token
+	r.tokensMu.Unlock()
+
+	return nil
+}
+
+func (r *registry) manifest(name string, reference string) ([]byte, string, error) {
+	resp, err := r.do(name, "manifests/"+reference, registryAccept)
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed reading manifest of %q: %w", name, err)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !shared.ValueInSlice(mediaType, registryAccept) {
+		mediaType = ""
+	}
+
+	return data, manifestMediaType(mediaType, data), nil
+}
+
+func (r *registry) blob(name string, digest string) (io.ReadCloser, int64, error) {
+	resp, err := r.do(name, "blobs/"+digest, nil)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	return resp.Body, resp.ContentLength, nil
+}
+
+func (r *registry) references() ([]string, error) {
+	return nil, fmt.Errorf("Listing images isn't supported by OCI registries")
+}
@@ -0,0 +1,355 @@
+//go:build linux
+
+package oci
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/units"
+)
+
+// Whiteout markers used by layers to delete content from the layers below them.
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+// Unpack downloads and applies the image layers in order on top of the rootfs directory.
+// The progress handler, if provided, is called with the download progress of each layer.
+func (c *Client) Unpack(img *Image, rootfs string, progress func(ioprogress.ProgressData)) error {
+	name, _ := ParseReference(img.Reference)
+
+	for i, layer := range img.Manifest.Layers {
+		body, size, err := c.Blob(name, layer)
+		if err != nil {
+			return fmt.Errorf("Failed fetching layer %q: %w", layer.Digest, err)
+		}
+
+		var reader io.Reader = body
+		if progress != nil {
+			if size <= 0 {
+				size = layer.Size
+			}
+
+			prefix := fmt.Sprintf("Layer %d/%d: ", i+1, len(img.Manifest.Layers))
+			reader = &ioprogress.ProgressReader{
+				ReadCloser: body,
+				Tracker: &ioprogress.ProgressTracker{
+					Length: size,
+					Handler: func(percent int64, speed int64) {
+						progress(ioprogress.ProgressData{Text: fmt.Sprintf("%s%d%% (%s/s)", prefix, percent, units.GetByteSizeString(speed, 2))})
+					},
+				},
+			}
+		}
+
+		err = applyLayer(reader, rootfs)
+		_ = body.Close()
+		if err != nil {
+			return fmt.Errorf("Failed applying layer %q: %w", layer.Digest, err)
+		}
+	}
+
+	return nil
+}
+
+// applyLayer extracts a (possibly compressed) layer tarball on top of rootfs, processing whiteouts.
+func applyLayer(r io.Reader, rootfs string) error {
+	buf := bufio.NewReader(r)
+
+	magic, err := buf.Peek(4)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	var tarReader io.Reader = buf
+
+	if bytes.HasPrefix(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(buf)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = gz.Close() }()
+		tarReader = gz
+	} else if bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}) {
+		zr, err := zstd.NewReader(buf)
+		if err != nil {
+			return err
+		}
+
+		defer zr.Close()
+		tarReader = zr
+	}
+
+	// Paths created by this layer, which opaque whiteouts must not remove.
+	created := map[string]bool{}
+
+	// Directory timestamps are applied last as creating their content updates them.
+	type dirTime struct {
+		path  string
+		mtime time.Time
+	}
+
+	dirTimes := []dirTime{}
+
+	tr := tar.NewReader(tarReader)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Clean("/" + hdr.Name)
+		if name == "/" && hdr.Typeflag == tar.TypeDir {
+			continue
+		}
+
+		dir, base := filepath.Split(name)
+
+		parent, err := secureJoin(rootfs, dir)
+		if err != nil {
+			return err
+		}
+
+		if base == whiteoutOpaque {
+			entries, err := os.ReadDir(parent)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+
+			for _, entry := range entries {
+				path := filepath.Join(parent, entry.Name())
+				if created[path] {
+					continue
+				}
+
+				err = os.RemoveAll(path)
+				if err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(base, whiteoutPrefix) {
+			// Only an entry of the parent directory, which is resolved within rootfs, can be whited out. The entry
+			// itself isn't resolved so that whited out symlinks are removed rather than their target.
+			target := strings.TrimPrefix(base, whiteoutPrefix)
+			if target == "" || target == "." || target == ".." || strings.Contains(target, "/") {
+				return fmt.Errorf("Invalid whiteout entry %q", hdr.Name)
+			}
+
+			err = os.RemoveAll(filepath.Join(parent, target))
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err = os.MkdirAll(parent, 0755)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(parent, base)
+		created[path] = true
+
+		// Replace whatever exists at the path, except when a directory is replaced by a directory.
+		info, err := os.Lstat(path)
+		if err == nil && !(info.IsDir() && hdr.Typeflag == tar.TypeDir) {
+			err = os.RemoveAll(path)
+			if err != nil {
+				return err
+			}
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.Mkdir(path, 0755)
+			if err != nil && !os.IsExist(err) {
+				return err
+			}
+
+			dirTimes = append(dirTimes, dirTime{path: path, mtime: hdr.ModTime})
+		case tar.TypeReg:
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.Copy(f, tr)
+			if err != nil {
+				_ = f.Close()
+				return err
+			}
+
+			err = f.Close()
+			if err != nil {
+				return err
+			}
+
+		case tar.TypeSymlink:
+			err = os.Symlink(hdr.Linkname, path)
+			if err != nil {
+				return err
+			}
+
+		case tar.TypeLink:
+			target, err := secureJoin(rootfs, hdr.Linkname)
+			if err != nil {
+				return err
+			}
+
+			err = os.Link(target, path)
+			if err != nil {
+				return err
+			}
+
+			// Hard links share the metadata of their target.
+			continue
+		case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+			mode := uint32(hdr.Mode & 07777)
+			switch hdr.Typeflag {
+			case tar.TypeChar:
+				mode |= unix.S_IFCHR
+			case tar.TypeBlock:
+				mode |= unix.S_IFBLK
+			case tar.TypeFifo:
+				mode |= unix.S_IFIFO
+			}
+
+			err = unix.Mknod(path, mode, int(unix.Mkdev(uint32(hdr.Devmajor), uint32(hdr.Devminor))))
+			if err != nil {
+				// Device nodes can't be created in unprivileged environments and are provided by LXC anyway.
+				if errors.Is(err, unix.EPERM) && hdr.Typeflag != tar.TypeFifo {
+					continue
+				}
+
+				return err
+			}
+
+		default:
+			continue
+		}
+
+		err = applyMetadata(path, hdr)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := len(dirTimes) - 1; i >= 0; i-- {
+		err = os.Chtimes(dirTimes[i].path, dirTimes[i].mtime, dirTimes[i].mtime)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyMetadata sets the ownership, permissions, extended attributes and modification time of an entry.
+func applyMetadata(path string, hdr *tar.Header) error {
+	err := os.Lchown(path, hdr.Uid, hdr.Gid)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range hdr.PAXRecords {
+		xattr, found := strings.CutPrefix(key, "SCHILY.xattr.")
+		if !found {
+			continue
+		}
+
+		err = unix.Lsetxattr(path, xattr, []byte(value), 0)
+		if err != nil && !errors.Is(err, unix.ENOTSUP) && !errors.Is(err, unix.EPERM) {
+			return fmt.Errorf("Failed setting extended attribute %q on %q: %w", xattr, path, err)
+		}
+	}
+
+	if hdr.Typeflag == tar.TypeSymlink {
+		ts := []unix.Timespec{unix.NsecToTimespec(hdr.ModTime.UnixNano()), unix.NsecToTimespec(hdr.ModTime.UnixNano())}
+		return unix.UtimesNanoAt(unix.AT_FDCWD, path, ts, unix.AT_SYMLINK_NOFOLLOW)
+	}
+
+	// Set the mode after the ownership as changing ownership clears the setuid and setgid bits.
+	err = os.Chmod(path, hdr.FileInfo().Mode()&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky))
+	if err != nil {
+		return err
+	}
+
+	if hdr.Typeflag == tar.TypeDir {
+		return nil
+	}
+
+	return os.Chtimes(path, hdr.ModTime, hdr.ModTime)
+}
+
+// secureJoin joins path to root, resolving symlinks within root so that the result never escapes it.
+func secureJoin(root string, path string) (string, error) {
+	current := "/"
+	remaining := path
+	links := 0
+
+	for remaining != "" {
+		component, rest, _ := strings.Cut(remaining, "/")
+		remaining = rest
+
+		if component == "" || component == "." {
+			continue
+		}
+
+		if component == ".." {
+			current = filepath.Dir(current)
+			continue
+		}
+
+		next := filepath.Join(current, component)
+
+		info, err := os.Lstat(filepath.Join(root, next))
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			current = next
+			continue
+		}
+
+		links++
+		if links > 255 {
+			return "", fmt.Errorf("Too many levels of symbolic links in %q", path)
+		}
+
+		target, err := os.Readlink(filepath.Join(root, next))
+		if err != nil {
+			return "", err
+		}
+
+		// Absolute targets are relative to root while relative ones are relative to the link directory.
+		if filepath.IsAbs(target) {
+			current = "/"
+		}
+
+		remaining = target + "/" + remaining
+	}
+
+	return filepath.Join(root, current), nil
+}
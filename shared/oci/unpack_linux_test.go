@@ -0,0 +1,210 @@
+//go:build linux
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/osarch"
+)
+
+// testLayoutEntry is a file in a test layer.
+type testLayoutEntry struct {
+	name     string
+	typeflag byte
+	content  string
+	linkname string
+}
+
+// writeTestBlob stores a blob in the test layout and returns its descriptor.
+func writeTestBlob(t *testing.T, dir string, mediaType string, data []byte) Descriptor {
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, blobPath(digest)), data, 0644))
+
+	return Descriptor{MediaType: mediaType, Digest: digest, Size: int64(len(data))}
+}
+
+// writeTestLayer stores a gzip compressed layer in the test layout.
+func writeTestLayer(t *testing.T, dir string, entries []testLayoutEntry) Descriptor {
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		hdr := &tar.Header{
+			Name:     entry.name,
+			Typeflag: entry.typeflag,
+			Linkname: entry.linkname,
+			Size:     int64(len(entry.content)),
+			Mode:     0644,
+			Uid:      os.Getuid(),
+			Gid:      os.Getgid(),
+		}
+
+		if entry.typeflag == tar.TypeDir {
+			hdr.Mode = 0755
+		}
+
+		require.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(entry.content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	return writeTestBlob(t, dir, "application/vnd.oci.image.layer.v1.tar+gzip", buf.Bytes())
+}
+
+func TestParseReference(t *testing.T) {
+	tests := map[string][2]string{
+		"alpine":                          {"alpine", "latest"},
+		"alpine:3.19":                     {"alpine", "3.19"},
+		"library/alpine:edge":             {"library/alpine", "edge"},
+		"registry:5000/app":               {"registry:5000/app", "latest"},
+		"app@sha256:0123456789abcdef":     {"app", "sha256:0123456789abcdef"},
+		"org/app:1.0@sha256:0123456789ab": {"org/app:1.0", "sha256:0123456789ab"},
+	}
+
+	for reference, expected := range tests {
+		name, tag := ParseReference(reference)
+		assert.Equal(t, expected[0], name, reference)
+		assert.Equal(t, expected[1], tag, reference)
+	}
+}
+
+func TestSecureJoin(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "usr", "lib"), 0755))
+	require.NoError(t, os.Symlink("usr/lib", filepath.Join(root, "lib")))
+	require.NoError(t, os.Symlink("/etc", filepath.Join(root, "escape")))
+	require.NoError(t, os.Symlink("../../../..", filepath.Join(root, "usr", "up")))
+
+	tests := map[string]string{
+		"/lib/foo":        "usr/lib/foo",
+		"escape/passwd":   "etc/passwd",
+		"../../etc":       "etc",
+		"usr/up/etc/file": "etc/file",
+	}
+
+	for path, expected := range tests {
+		result, err := secureJoin(root, path)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(root, expected), result, path)
+	}
+}
+
+func TestUnpack(t *testing.T) {
+	layoutDir := t.TempDir()
+	rootfs := t.TempDir()
+
+	arch, err := osarch.ArchitectureName(osarch.ARCH_64BIT_INTEL_X86)
+	require.NoError(t, err)
+
+	lower := writeTestLayer(t, layoutDir, []testLayoutEntry{
+		{name: "etc/", typeflag: tar.TypeDir},
+		{name: "etc/hostname", typeflag: tar.TypeReg, content: "lower"},
+		{name: "etc/removed", typeflag: tar.TypeReg, content: "lower"},
+		{name: "opt/", typeflag: tar.TypeDir},
+		{name: "opt/old", typeflag: tar.TypeReg, content: "lower"},
+	})
+
+	upper := writeTestLayer(t, layoutDir, []testLayoutEntry{
+		{name: "etc/hostname", typeflag: tar.TypeReg, content: "upper"},
+		{name: "etc/.wh.removed", typeflag: tar.TypeReg},
+		{name: "opt/new", typeflag: tar.TypeReg, content: "upper"},
+		{name: "opt/.wh..wh..opq", typeflag: tar.TypeReg},
+		{name: "bin/", typeflag: tar.TypeDir},
+		{name: "bin/sh", typeflag: tar.TypeSymlink, linkname: "busybox"},
+		{name: "bin/busybox", typeflag: tar.TypeReg, content: "binary"},
+	})
+
+	config := ImageConfig{Architecture: "amd64", OS: "linux"}
+	config.Config.Cmd = []string{"/bin/sh"}
+	configData, err := json.Marshal(config)
+	require.NoError(t, err)
+
+	manifestData, err := json.Marshal(Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		Config:        writeTestBlob(t, layoutDir, "application/vnd.oci.image.config.v1+json", configData),
+		Layers:        []Descriptor{lower, upper},
+	})
+	require.NoError(t, err)
+
+	manifest := writeTestBlob(t, layoutDir, MediaTypeImageManifest, manifestData)
+	manifest.Annotations = map[string]string{AnnotationRefName: "test:1.0"}
+
+	indexData, err := json.Marshal(Index{SchemaVersion: 2, Manifests: []Descriptor{manifest}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(layoutDir, "index.json"), indexData, 0644))
+
+	client, err := NewClient("file://"+layoutDir, nil, "")
+	require.NoError(t, err)
+
+	refs, err := client.References()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test:1.0"}, refs)
+
+	_, err = client.Resolve("test:2.0", arch)
+	assert.Error(t, err)
+
+	img, err := client.Resolve("test:1.0", arch)
+	require.NoError(t, err)
+	assert.Equal(t, manifest.Digest, img.Digest)
+	assert.Equal(t, []string{"/bin/sh"}, img.Config.Config.Cmd)
+
+	imgArch, err := img.Architecture()
+	require.NoError(t, err)
+	assert.Equal(t, arch, imgArch)
+
+	require.NoError(t, client.Unpack(img, rootfs, nil))
+
+	content, err := os.ReadFile(filepath.Join(rootfs, "etc", "hostname"))
+	require.NoError(t, err)
+	assert.Equal(t, "upper", string(content))
+
+	assert.NoFileExists(t, filepath.Join(rootfs, "etc", "removed"))
+	assert.NoFileExists(t, filepath.Join(rootfs, "etc", ".wh.removed"))
+	assert.NoFileExists(t, filepath.Join(rootfs, "opt", "old"))
+	assert.FileExists(t, filepath.Join(rootfs, "opt", "new"))
+
+	target, err := os.Readlink(filepath.Join(rootfs, "bin", "sh"))
+	require.NoError(t, err)
+	assert.Equal(t, "busybox", target)
+}
+
+func TestApplyLayerInvalidWhiteout(t *testing.T) {
+	for _, name := range []string{".wh.", ".wh..", ".wh...", "etc/.wh..", "etc/.wh..."} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			rootfs := filepath.Join(dir, "rootfs")
+			require.NoError(t, os.MkdirAll(filepath.Join(rootfs, "etc"), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(rootfs, "etc", "hostname"), []byte("c1"), 0644))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "sibling"), []byte("build"), 0644))
+
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644}))
+			require.NoError(t, tw.Close())
+
+			assert.Error(t, applyLayer(&buf, rootfs))
+
+			// Neither rootfs nor its parent directory are removed.
+			assert.FileExists(t, filepath.Join(rootfs, "etc", "hostname"))
+			assert.FileExists(t, filepath.Join(dir, "sibling"))
+		})
+	}
+}
@@ -63,6 +63,9 @@ type ProductVersionItem struct {
 	HashSha256               string `json:"sha256,omitempty"`
 	Size                     int64  `json:"size"`
 	DeltaBase                string `json:"delta_base,omitempty"`
+
+	// Non-standard fields (only used on some image servers).
+	LXDSignature string `json:"lxd_signature,omitempty"`
 }
 
 // ToLXD converts the products data into a list of LXD images and associated downloadable files.
@@ -70,7 +73,7 @@ func (s *Products) ToLXD() ([]api.Image, map[string][][]string) {
 	downloads := map[string][][]string{}
 
 	images := []api.Image{}
-	nameLayoutLong := "20060102_0304" // Date and time.
+	nameLayoutLong := "20060102_1504" // Date and time.
 	nameLayoutShort := "20060102"     // Date only.
 	eolLayout := "2006-01-02"
 
@@ -195,6 +198,10 @@ func (s *Products) ToLXD() ([]api.Image, map[string][][]string) {
 					image.Properties["variant"] = product.Variant
 				}
 
+				if meta.LXDSignature != "" {
+					image.Properties["signature"] = meta.LXDSignature
+				}
+
 				image.Type = "container"
 
 				if root != nil {
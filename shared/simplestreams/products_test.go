@@ -0,0 +1,57 @@
+package simplestreams
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductsToLXDCreationDate(t *testing.T) {
+	products := Products{
+		Products: map[string]Product{
+			"ubuntu:noble:amd64:default": {
+				Architecture:    "amd64",
+				OperatingSystem: "Ubuntu",
+				Release:         "noble",
+				Versions: map[string]ProductVersion{
+					"20240315_1742": {
+						Items: map[string]ProductVersionItem{
+							"lxd_combined.tar.gz": {
+								FileType:   "lxd_combined.tar.gz",
+								Path:       "images/ubuntu/noble/amd64/default/20240315_1742/lxd_combined.tar.gz",
+								HashSha256: "0c1d8a42f4c9b8ab1e1a0c86b6bbd0b0b70a0ac1e19c3e7e14ed5ce7e1a5e35e",
+								Size:       1024,
+							},
+						},
+					},
+					"20240316": {
+						Items: map[string]ProductVersionItem{
+							"lxd_combined.tar.gz": {
+								FileType:   "lxd_combined.tar.gz",
+								Path:       "images/ubuntu/noble/amd64/default/20240316/lxd_combined.tar.gz",
+								HashSha256: "5f2b8d0e7a7c8b0f1b3f4f8d5e0a9c1b2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a",
+								Size:       1024,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	images, _ := products.ToLXD()
+	require.Len(t, images, 2)
+
+	created := map[string]time.Time{}
+	for _, image := range images {
+		created[image.Properties["serial"]] = image.CreatedAt
+	}
+
+	// Afternoon versions keep their upload time.
+	assert.Equal(t, time.Date(2024, time.March, 15, 17, 42, 0, 0, time.UTC), created["20240315_1742"])
+
+	// Versions without an upload time only have a date.
+	assert.Equal(t, time.Date(2024, time.March, 16, 0, 0, 0, 0, time.UTC), created["20240316"])
+}
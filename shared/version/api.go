@@ -411,6 +411,28 @@ var APIExtensions = []string{
 	"shared_custom_block_volumes",
 	"instance_import_conversion",
 	"instance_create_start",
+	"vm_filesystem_freeze",
+	"image_oci",
+	"image_simplestreams_server",
+	"image_signatures",
+	"instances_placement_groups",
+	"cluster_rebalance",
+	"cluster_rolling_upgrade",
+	"cluster_healing_fencing",
+	"cluster_database_backup",
+	"instance_replication",
+	"project_usage_history",
+	"project_limits_snapshots_backups_network",
+	"auth_bearer_tokens",
+	"auth_check",
+	"audit_log",
+	"event_replay",
+	"webhooks",
+	"logging_sinks",
+	"tracing",
+	"operations_persistence",
+	"api_pagination",
+	"bulk_operations",
 }
 
 // APIExtensionsCount returns the number of available API extensions.